@@ -0,0 +1,36 @@
+// Command fake-messier serves a fixture in place of the Messier API. Point MESSIER_API_URL at it:
+//
+//	go run ./cmd/fake-messier -addr :8081 -fixture fixture.json
+package main
+
+import (
+	"flag"
+	"log"
+	"neptune/backend/messier/fake"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	fixturePath := flag.String("fixture", "", "JSON fixture to serve, the built-in fixture when empty")
+	flag.Parse()
+
+	fixture, err := loadFixture(*fixturePath)
+	if err != nil {
+		log.Fatalf("Failed to load fixture: %v", err)
+	}
+
+	log.Printf("Fake Messier listening on %s with %d assistants, %d students and %d classes",
+		*addr, len(fixture.Assistants), len(fixture.Students), len(fixture.Classes))
+	if err := http.ListenAndServe(*addr, fake.NewServer(fixture)); err != nil {
+		log.Fatalf("Fake Messier stopped: %v", err)
+	}
+}
+
+// loadFixture reads the fixture at path, or returns the built-in one when path is empty.
+func loadFixture(path string) (fake.Fixture, error) {
+	if path == "" {
+		return fake.DefaultFixture(), nil
+	}
+	return fake.LoadFixture(path)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"neptune/backend/messier/fake"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFixture(t *testing.T) {
+	fixture, err := loadFixture("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixture.Assistants) == 0 || len(fixture.Classes) == 0 {
+		t.Errorf("built-in fixture is empty: %+v", fixture)
+	}
+
+	custom := fake.Fixture{
+		TokenTTLSeconds: 60,
+		Assistants:      []fake.FixtureAssistant{{Username: "AB25-1", Password: "secret"}},
+	}
+	data, err := json.Marshal(custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err = loadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The loaded fixture is what the server answers with
+	server := httptest.NewServer(fake.NewServer(fixture))
+	defer server.Close()
+	for password, want := range map[string]int{"secret": http.StatusOK, "password": http.StatusUnauthorized} {
+		body, _ := json.Marshal(map[string]string{"username": "AB25-1", "password": password})
+		res, err := http.Post(server.URL+"/Account/LogOn", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != want {
+			t.Errorf("log on with %q = %d, want %d", password, res.StatusCode, want)
+		}
+	}
+
+	if _, err := loadFixture(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("a missing fixture file was accepted")
+	}
+}
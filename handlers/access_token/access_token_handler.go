@@ -0,0 +1,185 @@
+package accessTokenHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/requests"
+	accessTokenServ "neptune/backend/services/access_token"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService accessTokenServ.AccessTokenService
+}
+
+func NewAccessTokenHandler(accessTokenService accessTokenServ.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{accessTokenService: accessTokenService}
+}
+
+// CreateToken handles POST /auth/tokens. The token is in the response only this once.
+func (h *AccessTokenHandler) CreateToken(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req requests.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	token, err := h.accessTokenService.CreateToken(ctx, userID, req)
+	if err != nil {
+		writeAccessTokenError(c, "create access token", err)
+		return
+	}
+	c.JSON(http.StatusCreated, token)
+}
+
+// ListTokens handles GET /auth/tokens
+func (h *AccessTokenHandler) ListTokens(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	h.listTokens(c, userID)
+}
+
+// RevokeToken handles DELETE /auth/tokens/:tokenId
+func (h *AccessTokenHandler) RevokeToken(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	h.revokeToken(c, userID)
+}
+
+// GetUsage handles GET /auth/tokens/:tokenId/usage
+func (h *AccessTokenHandler) GetUsage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	h.getUsage(c, userID)
+}
+
+// ListUserTokens handles GET /admin/users/:userId/tokens
+func (h *AccessTokenHandler) ListUserTokens(c *gin.Context) {
+	userID, ok := pathUserID(c)
+	if !ok {
+		return
+	}
+	h.listTokens(c, userID)
+}
+
+// RevokeUserToken handles DELETE /admin/users/:userId/tokens/:tokenId
+func (h *AccessTokenHandler) RevokeUserToken(c *gin.Context) {
+	userID, ok := pathUserID(c)
+	if !ok {
+		return
+	}
+	h.revokeToken(c, userID)
+}
+
+// GetUserTokenUsage handles GET /admin/users/:userId/tokens/:tokenId/usage
+func (h *AccessTokenHandler) GetUserTokenUsage(c *gin.Context) {
+	userID, ok := pathUserID(c)
+	if !ok {
+		return
+	}
+	h.getUsage(c, userID)
+}
+
+func (h *AccessTokenHandler) listTokens(c *gin.Context, userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	tokens, err := h.accessTokenService.ListTokens(ctx, userID)
+	if err != nil {
+		writeAccessTokenError(c, "retrieve access tokens", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+func (h *AccessTokenHandler) revokeToken(c *gin.Context, userID uuid.UUID) {
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+		return
+	}
+	revokedBy, _ := uuid.Parse(c.GetString("user_id"))
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "access_token.revoke", "access_token", tokenID.String())
+	if err := h.accessTokenService.RevokeToken(ctx, userID, tokenID, revokedBy); err != nil {
+		writeAccessTokenError(c, "revoke access token", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Access token revoked"})
+}
+
+func (h *AccessTokenHandler) getUsage(c *gin.Context, userID uuid.UUID) {
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	usage, err := h.accessTokenService.GetUsage(ctx, userID, tokenID, limit, offset)
+	if err != nil {
+		writeAccessTokenError(c, "retrieve access token usage", err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: User ID not found in context"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func pathUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func writeAccessTokenError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, accessTokenServ.ErrTokenNotFound), errors.Is(err, accessTokenServ.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, accessTokenServ.ErrInvalidTokenName), errors.Is(err, accessTokenServ.ErrInvalidScope), errors.Is(err, accessTokenServ.ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
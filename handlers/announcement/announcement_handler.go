@@ -0,0 +1,168 @@
+package announcementHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/requests"
+	announcementServ "neptune/backend/services/announcement"
+	"net/http"
+	"time"
+)
+
+type AnnouncementHandler struct {
+	announcementService announcementServ.AnnouncementService
+}
+
+func NewAnnouncementHandler(announcementService announcementServ.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{announcementService: announcementService}
+}
+
+// GetPublishedAnnouncements handles GET /api/contests/:contestId/announcements?class_transaction_id=
+func (h *AnnouncementHandler) GetPublishedAnnouncements(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contest ID"})
+		return
+	}
+	var classTransactionID *uuid.UUID
+	if raw := c.Query("class_transaction_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid class_transaction_id"})
+			return
+		}
+		classTransactionID = &parsed
+	}
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	announcements, err := h.announcementService.ListPublished(ctx, contestID, classTransactionID, userID, user.Role(c.GetString("role")))
+	if err != nil {
+		writeAnnouncementError(c, "retrieve announcements", err)
+		return
+	}
+	c.JSON(http.StatusOK, announcements)
+}
+
+// GetAnnouncements handles GET /admin/contests/:contestId/announcements
+func (h *AnnouncementHandler) GetAnnouncements(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contest ID"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	announcements, err := h.announcementService.ListAnnouncements(ctx, contestID)
+	if err != nil {
+		writeAnnouncementError(c, "retrieve announcements", err)
+		return
+	}
+	c.JSON(http.StatusOK, announcements)
+}
+
+// CreateAnnouncement handles POST /admin/contests/:contestId/announcements
+func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contest ID"})
+		return
+	}
+	var req requests.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "announcement.create", "contest", contestID.String())
+	announcement, err := h.announcementService.CreateAnnouncement(ctx, contestID, req, requestMakerID(c))
+	if err != nil {
+		writeAnnouncementError(c, "create announcement", err)
+		return
+	}
+	audit.After(c, announcement)
+	c.JSON(http.StatusCreated, announcement)
+}
+
+// UpdateAnnouncement handles PUT /admin/announcements/:announcementId
+func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
+	announcementID, err := uuid.Parse(c.Param("announcementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid announcement ID"})
+		return
+	}
+	var req requests.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "announcement.update", "announcement", announcementID.String())
+	if before, err := h.announcementService.GetAnnouncement(ctx, announcementID); err == nil {
+		audit.Before(c, before)
+	}
+	announcement, err := h.announcementService.UpdateAnnouncement(ctx, announcementID, req)
+	if err != nil {
+		writeAnnouncementError(c, "update announcement", err)
+		return
+	}
+	audit.After(c, announcement)
+	c.JSON(http.StatusOK, announcement)
+}
+
+// DeleteAnnouncement handles DELETE /admin/announcements/:announcementId
+func (h *AnnouncementHandler) DeleteAnnouncement(c *gin.Context) {
+	announcementID, err := uuid.Parse(c.Param("announcementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid announcement ID"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "announcement.delete", "announcement", announcementID.String())
+	if before, err := h.announcementService.GetAnnouncement(ctx, announcementID); err == nil {
+		audit.Before(c, before)
+	}
+	if err := h.announcementService.DeleteAnnouncement(ctx, announcementID); err != nil {
+		writeAnnouncementError(c, "delete announcement", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement deleted"})
+}
+
+func requestMakerID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func writeAnnouncementError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, announcementServ.ErrContestNotFound), errors.Is(err, announcementServ.ErrAnnouncementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, announcementServ.ErrClassNotInContest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, announcementServ.ErrNotParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
@@ -0,0 +1,102 @@
+package auditHand
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log"
+	auditRepo "neptune/backend/repositories/audit"
+	auditServ "neptune/backend/services/audit"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	auditService auditServ.AuditService
+}
+
+func NewAuditHandler(auditService auditServ.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetAuditLogs handles GET /admin/audit?actor_id=&action=&target_type=&target_id=&from=&to=&limit=&offset=
+// with from and to in RFC 3339.
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	entries, err := h.auditService.ListEntries(ctx, filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve audit log: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// ExportAuditLogs handles GET /admin/audit/export with the same filters, answering with a CSV file.
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+	// The status is already sent once rows are streamed, so a late failure can only be logged.
+	if err := h.auditService.ExportCSV(ctx, filter, c.Writer); err != nil {
+		log.Printf("Failed to export audit log: %v", err)
+	}
+}
+
+func parseFilter(c *gin.Context) (auditRepo.Filter, bool) {
+	filter := auditRepo.Filter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		ForcedOnly: c.Query("forced") == "true",
+	}
+	if raw := c.Query("actor_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+			return filter, false
+		}
+		filter.ActorID = &id
+	}
+	for _, bound := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := c.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s, expected RFC 3339", bound.name)})
+			return filter, false
+		}
+		*bound.dest = &parsed
+	}
+	return filter, true
+}
@@ -2,25 +2,34 @@ package caseHandler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log"
+	contestModel "neptune/backend/models/contest"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/audit"
 	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/storage"
+	caseRepository "neptune/backend/repositories/case"
 	caseService "neptune/backend/services/case"
+	contestService "neptune/backend/services/contest"
+	fileServ "neptune/backend/services/file"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type CaseHandler struct {
 	caseService caseService.CaseService
+	blobStore   storage.BlobStore
 }
 
-func NewCaseHandler(caseService caseService.CaseService) *CaseHandler {
-	return &CaseHandler{caseService: caseService}
+func NewCaseHandler(caseService caseService.CaseService, blobStore storage.BlobStore) *CaseHandler {
+	return &CaseHandler{caseService: caseService, blobStore: blobStore}
 }
 
 // CreateCase handles POST /api/cases
@@ -57,27 +66,32 @@ func (h *CaseHandler) CreateCase(c *gin.Context) {
 		return
 	}
 
-	file, err := c.FormFile("pdf_file") // "pdf_file" is the name of the input field in the form
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to get PDF file: %v", err.Error())})
-		return
-	}
-
-	ext := filepath.Ext(file.Filename)
-	if ext != ".pdf" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Only PDF files are allowed"})
-		return
-	}
+	// The PDF is optional now that statements can be written in Markdown.
+	fileURL := ""
+	if file, err := c.FormFile("pdf_file"); err == nil { // "pdf_file" is the name of the input field in the form
+		ext := filepath.Ext(file.Filename)
+		if ext != ".pdf" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only PDF files are allowed"})
+			return
+		}
 
-	uniqueFilename := uuid.New().String() + ext
+		uniqueFilename := uuid.New().String() + ext
+		fileURL = "/private/case_file/" + uniqueFilename
 
-	PDF_UPLOAD := "./public/case_file"
-	filePath := filepath.Join(PDF_UPLOAD, uniqueFilename)
-	fileURL := "/public/case_file/" + uniqueFilename
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to open PDF file: %v", err.Error())})
+			return
+		}
+		defer src.Close()
 
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		log.Printf("Error saving uploaded file %s: %v", filePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save PDF file"})
+		if err := h.blobStore.Put(c.Request.Context(), storage.KeyFromURL(fileURL), src, file.Size, "application/pdf"); err != nil {
+			log.Printf("Error saving uploaded file %s: %v", fileURL, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save PDF file"})
+			return
+		}
+	} else if !errors.Is(err, http.ErrMissingFile) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to get PDF file: %v", err.Error())})
 		return
 	}
 
@@ -86,18 +100,41 @@ func (h *CaseHandler) CreateCase(c *gin.Context) {
 		Description:   description,
 		TimeLimitMs:   timeLimitMs,
 		MemoryLimitMb: memoryLimitMb,
+		CaseStatement: requests.CaseStatement{
+			Statement:    c.PostForm("statement"),
+			InputFormat:  c.PostForm("input_format"),
+			OutputFormat: c.PostForm("output_format"),
+			Constraints:  c.PostForm("constraints"),
+		},
+		CaseMetadata: requests.CaseMetadata{
+			Tags:       splitTags(c.PostFormArray("tags")),
+			Difficulty: c.PostForm("difficulty"),
+			Author:     c.PostForm("author"),
+			Source:     c.PostForm("source"),
+		},
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
-	resp, err := h.caseService.CreateCase(ctx, serviceReq, fileURL)
+	audit.Describe(c, "case.create", "case", "")
+	resp, err := h.caseService.CreateCase(ctx, serviceReq, fileURL, requestMakerID(c))
 	if err != nil {
-		os.Remove(filePath)
+		if fileURL != "" {
+			if delErr := h.blobStore.Delete(context.Background(), storage.KeyFromURL(fileURL)); delErr != nil {
+				log.Printf("Error removing orphaned PDF %s: %v", fileURL, delErr)
+			}
+		}
+		if errors.Is(err, caseService.ErrInvalidMetadata) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create case: %v", err.Error())})
 		return
 	}
 
+	audit.SetTargetID(c, resp.ID.String())
+	audit.After(c, resp)
 	c.JSON(http.StatusCreated, resp)
 
 }
@@ -124,12 +161,49 @@ func (h *CaseHandler) GetCaseByID(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
-// GetAllCases handles GET /api/cases
+// GetAllCases handles GET /api/cases?q=&tag=&difficulty=&author=&source=&used=&sort=&limit=&offset=
+// tag may be repeated or comma separated; a case must carry all of them.
 func (h *CaseHandler) GetAllCases(c *gin.Context) {
+	filter := caseRepository.Filter{
+		Query:      strings.TrimSpace(c.Query("q")),
+		Tags:       splitTags(c.QueryArray("tag")),
+		Difficulty: contestModel.CaseDifficulty(c.Query("difficulty")),
+		Author:     strings.TrimSpace(c.Query("author")),
+		Source:     strings.TrimSpace(c.Query("source")),
+		Sort:       caseRepository.CaseSort(c.DefaultQuery("sort", string(caseRepository.SortNewest))),
+	}
+	if filter.Difficulty != "" && !filter.Difficulty.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "difficulty must be easy, medium or hard"})
+		return
+	}
+	switch filter.Sort {
+	case caseRepository.SortNewest, caseRepository.SortOldest, caseRepository.SortName:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be newest, oldest or name"})
+		return
+	}
+	if raw := c.Query("used"); raw != "" {
+		used, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid used"})
+			return
+		}
+		filter.Used = &used
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.caseService.GetAllCases(ctx)
+	resp, err := h.caseService.ListCases(ctx, filter, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve cases: %v", err.Error())})
 		return
@@ -137,6 +211,19 @@ func (h *CaseHandler) GetAllCases(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GetTags handles GET /api/cases/tags, listing every tag of the bank with how many cases carry it.
+func (h *CaseHandler) GetTags(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.caseService.GetTags(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve tags: %v", err.Error())})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // UpdateCase handles PUT /api/cases/:caseId
 func (h *CaseHandler) UpdateCase(c *gin.Context) {
 	caseID, err := uuid.Parse(c.Param("caseId"))
@@ -149,14 +236,39 @@ func (h *CaseHandler) UpdateCase(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.caseService.UpdateCase(ctx, caseID, req)
+	audit.Describe(c, "case.update", "case", caseID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	if before, err := h.caseService.GetCaseByID(ctx, caseID); err == nil && before != nil {
+		audit.Before(c, before)
+	}
+	resp, err := h.caseService.UpdateCase(ctx, caseID, req, force.Force, requestMakerID(c))
 	if err != nil {
+		if errors.Is(err, contestService.ErrContestLocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, caseService.ErrCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, caseService.ErrInvalidMetadata) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update case: %v", err.Error())})
 		return
 	}
+	audit.After(c, resp)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -167,12 +279,252 @@ func (h *CaseHandler) DeleteCase(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
 		return
 	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	if err := h.caseService.DeleteCase(ctx, caseID); err != nil {
+	audit.Describe(c, "case.delete", "case", caseID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	if before, err := h.caseService.GetCaseByID(ctx, caseID); err == nil && before != nil {
+		audit.Before(c, before)
+	}
+	if err := h.caseService.DeleteCase(ctx, caseID, force.Force); err != nil {
+		if errors.Is(err, contestService.ErrContestLocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete case: %v", err.Error())})
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// GetStatement handles GET /api/cases/:caseId/statement
+func (h *CaseHandler) GetStatement(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	resp, err := h.caseService.GetStatement(ctx, caseID, requestMakerID(c), user.Role(c.GetString("role")))
+	if err != nil {
+		writeCaseError(c, "retrieve statement", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRevisions handles GET /admin/cases/:case_id/revisions
+func (h *CaseHandler) GetRevisions(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("case_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.caseService.GetRevisions(ctx, caseID)
+	if err != nil {
+		writeCaseError(c, "retrieve revisions", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRevision handles GET /admin/cases/:case_id/revisions/:revision
+func (h *CaseHandler) GetRevision(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("case_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.caseService.GetRevision(ctx, caseID, revision)
+	if err != nil {
+		writeCaseError(c, "retrieve revision", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DiffRevisions handles GET /admin/cases/:case_id/revision-diff?from=&to=
+func (h *CaseHandler) DiffRevisions(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("case_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	from, fromErr := strconv.Atoi(c.Query("from"))
+	to, toErr := strconv.Atoi(c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be revision numbers"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	resp, err := h.caseService.DiffRevisions(ctx, caseID, from, to)
+	if err != nil {
+		writeCaseError(c, "diff revisions", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddAttachment handles POST /admin/cases/:case_id/attachments
+func (h *CaseHandler) AddAttachment(c *gin.Context) {
+	var req requests.AddCaseAttachmentRequest
+	if err := req.ParseFormData(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	audit.Describe(c, "case.attachment.add", "case", req.CaseID.String())
+	resp, err := h.caseService.AddAttachment(ctx, req)
+	if err != nil {
+		writeCaseError(c, "add attachment", err)
+		return
+	}
+	audit.After(c, resp)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// DeleteAttachment handles DELETE /admin/cases/:caseId/attachments/:attachmentId
+func (h *CaseHandler) DeleteAttachment(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "case.attachment.delete", "case", caseID.String())
+	audit.Before(c, gin.H{"attachment_id": attachmentID})
+	if err := h.caseService.DeleteAttachment(ctx, caseID, attachmentID); err != nil {
+		writeCaseError(c, "delete attachment", err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// splitTags accepts tags as repeated values, comma separated values or both.
+func splitTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// ImportPackage handles POST /admin/cases/import with a Neptune, Polygon or Kattis package in "package" and
+// an optional "format" to insist on one of them.
+func (h *CaseHandler) ImportPackage(c *gin.Context) {
+	var req requests.ImportCasePackageRequest
+	if err := req.ParseFormData(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	switch caseService.PackageFormat(req.Format) {
+	case "", caseService.PackageNeptune, caseService.PackagePolygon, caseService.PackageKattis:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be neptune, polygon or kattis"})
+		return
+	}
+	// Packages carry all testcases, which are streamed to the blob store like testcase archives
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	audit.Describe(c, "case.import", "case", "")
+	resp, err := h.caseService.ImportPackage(ctx, req, requestMakerID(c))
+	if err != nil {
+		if errors.Is(err, caseService.ErrInvalidPackage) || errors.Is(err, caseService.ErrInvalidMetadata) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to import package: %v", err)})
+		return
+	}
+	audit.SetTargetID(c, resp.Case.ID.String())
+	audit.After(c, resp)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ExportPackage handles GET /admin/cases/:case_id/export, answering with the case as a Neptune package.
+func (h *CaseHandler) ExportPackage(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("case_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	problemCase, err := h.caseService.GetCaseByID(ctx, caseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve case: %v", err.Error())})
+		return
+	}
+	if problemCase == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Case not found"})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fileServ.ContentDisposition("attachment", problemCase.Name+".zip"))
+	c.Status(http.StatusOK)
+	// The status is already sent once the zip is streamed, so a late failure can only be logged.
+	if err := h.caseService.ExportPackage(ctx, caseID, c.Writer); err != nil {
+		log.Printf("Failed to export case %s: %v", caseID, err)
+	}
+}
+
+func requestMakerID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func writeCaseError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, caseService.ErrCaseNotFound), errors.Is(err, caseService.ErrRevisionNotFound),
+		errors.Is(err, caseService.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrCaseHidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, caseService.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
@@ -0,0 +1,132 @@
+package clarificationHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	clarificationServ "neptune/backend/services/clarification"
+	"net/http"
+	"time"
+)
+
+type ClarificationHandler struct {
+	clarificationService clarificationServ.ClarificationService
+}
+
+func NewClarificationHandler(clarificationService clarificationServ.ClarificationService) *ClarificationHandler {
+	return &ClarificationHandler{clarificationService: clarificationService}
+}
+
+// AskQuestion handles POST /api/contests/:contestId/clarifications
+func (h *ClarificationHandler) AskQuestion(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contest ID"})
+		return
+	}
+	userID, ok := requestMaker(c)
+	if !ok {
+		return
+	}
+	var req requests.AskClarificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	clarification, err := h.clarificationService.AskQuestion(ctx, contestID, userID, user.Role(c.GetString("role")), req)
+	if err != nil {
+		writeClarificationError(c, "ask question", err)
+		return
+	}
+	c.JSON(http.StatusCreated, clarification)
+}
+
+// GetClarifications handles GET /api/contests/:contestId/clarifications?class_transaction_id=
+func (h *ClarificationHandler) GetClarifications(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contest ID"})
+		return
+	}
+	var classTransactionID *uuid.UUID
+	if raw := c.Query("class_transaction_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid class_transaction_id"})
+			return
+		}
+		classTransactionID = &parsed
+	}
+	userID, ok := requestMaker(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	clarifications, err := h.clarificationService.ListClarifications(ctx, contestID, classTransactionID, userID, user.Role(c.GetString("role")))
+	if err != nil {
+		writeClarificationError(c, "retrieve clarifications", err)
+		return
+	}
+	c.JSON(http.StatusOK, clarifications)
+}
+
+// AnswerClarification handles POST /api/clarifications/:clarificationId/answer
+func (h *ClarificationHandler) AnswerClarification(c *gin.Context) {
+	clarificationID, err := uuid.Parse(c.Param("clarificationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid clarification ID"})
+		return
+	}
+	userID, ok := requestMaker(c)
+	if !ok {
+		return
+	}
+	var req requests.AnswerClarificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	clarification, err := h.clarificationService.AnswerClarification(ctx, clarificationID, userID, user.Role(c.GetString("role")), req)
+	if err != nil {
+		writeClarificationError(c, "answer clarification", err)
+		return
+	}
+	c.JSON(http.StatusOK, clarification)
+}
+
+func requestMaker(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeClarificationError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, clarificationServ.ErrContestNotFound), errors.Is(err, clarificationServ.ErrClarificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, clarificationServ.ErrCaseNotInContest), errors.Is(err, clarificationServ.ErrInvalidVisibility),
+		errors.Is(err, clarificationServ.ErrBlankQuestion), errors.Is(err, clarificationServ.ErrBlankAnswer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, clarificationServ.ErrNotParticipant), errors.Is(err, clarificationServ.ErrNotContestStaff):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, clarificationServ.ErrContestNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
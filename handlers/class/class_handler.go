@@ -2,13 +2,18 @@ package class
 
 import (
 	"context"
+	"errors"
+	"neptune/backend/messier"
+	"neptune/backend/pkg/audit"
 	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/utils"
 	"neptune/backend/services/internal_class"
+	"strconv"
 	"time"
 
-	"neptune/backend/messier/constants"
-
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ClassHandler struct {
@@ -34,18 +39,46 @@ func (h *ClassHandler) SyncClassesHandler(c *gin.Context) {
 		return
 	}
 
+	audit.Describe(c, "class.sync", "semester", semesterId)
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
 	defer cancel()
 
 	err := h.internalClassService.SyncClasses(ctx, semesterId, requestMakerID.(string))
 	if err != nil {
-		c.JSON(500, gin.H{"error": "failed to sync classes", "details": err.Error()})
+		c.JSON(syncErrorStatus(err), gin.H{"error": "failed to sync classes", "details": err.Error()})
 		return
 	}
 	c.JSON(200, gin.H{"message": "classes synced successfully"})
 }
 
+// SyncClassStudentsHandler syncs the student rosters of a course. Pass ?dry_run=true to only get the diffs
+// and ?force=true to apply diffs that trip the removal guard.
 func (h *ClassHandler) SyncClassStudentsHandler(c *gin.Context) {
+	h.syncRoster(c, "students", h.internalClassService.SyncClassStudents)
+}
+
+// SyncClassAssistantsHandler syncs the assistants of a course; it takes the same query parameters as
+// SyncClassStudentsHandler.
+func (h *ClassHandler) SyncClassAssistantsHandler(c *gin.Context) {
+	h.syncRoster(c, "assistants", h.internalClassService.SyncClassAssistants)
+}
+
+// syncErrorStatus tells a stale or rejected Messier token (log in again) and a Messier outage (try later)
+// apart from our own failures.
+func syncErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, utils.ErrMessierTokenUnavailable), errors.Is(err, messier.ErrUnauthorized):
+		return 401
+	case errors.Is(err, messier.ErrUnavailable), errors.Is(err, messier.ErrRateLimited):
+		return 503
+	default:
+		return 500
+	}
+}
+
+type rosterSyncFunc func(ctx context.Context, semesterID, courseOutlineID, requestMakerID string, opts internal_class.RosterSyncOptions) (internal_class.SyncOutcome, error)
+
+func (h *ClassHandler) syncRoster(c *gin.Context, memberType string, sync rosterSyncFunc) {
 	requestMakerID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(400, gin.H{"error": "user_id not found in context"})
@@ -58,39 +91,87 @@ func (h *ClassHandler) SyncClassStudentsHandler(c *gin.Context) {
 		return
 	}
 
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid dry_run"})
+		return
+	}
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid force"})
+		return
+	}
+
+	audit.Describe(c, "class.sync_"+memberType, "course", req.CourseID)
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
 	defer cancel()
 
-	err := h.internalClassService.SyncClassStudents(ctx, req.SemesterID, req.CourseID, requestMakerID.(string))
+	outcome, err := sync(ctx, req.SemesterID, req.CourseID, requestMakerID.(string), internal_class.RosterSyncOptions{DryRun: dryRun, Force: force})
 	if err != nil {
-		c.JSON(500, gin.H{"error": "failed to sync class students", "details": err.Error()})
+		c.JSON(syncErrorStatus(err), gin.H{"error": "failed to sync class " + memberType, "details": err.Error()})
 		return
 	}
-	c.JSON(200, gin.H{"message": "class students synced successfully"})
+
+	message := "class " + memberType + " synced successfully"
+	switch {
+	case dryRun:
+		message = "dry run, no changes were applied"
+	case len(outcome.FailedClassIDs) > 0:
+		message = "class " + memberType + " synced with errors"
+	}
+	// Only a summary is kept, the diffs of a whole course can be large.
+	audit.After(c, gin.H{
+		"semester_id":      req.SemesterID,
+		"dry_run":          dryRun,
+		"force":            force,
+		"processed":        outcome.Processed,
+		"synced":           outcome.Synced,
+		"failed_class_ids": outcome.FailedClassIDs,
+		"diffs":            len(outcome.Diffs),
+	})
+	diffs := outcome.Diffs
+	if diffs == nil {
+		diffs = []responses.RosterDiffResponse{}
+	}
+	c.JSON(200, responses.RosterSyncResponse{
+		Message:        message,
+		DryRun:         dryRun,
+		Processed:      outcome.Processed,
+		Synced:         outcome.Synced,
+		FailedClassIDs: outcome.FailedClassIDs,
+		Errors:         outcome.Errors,
+		Diffs:          diffs,
+	})
 }
 
-func (h *ClassHandler) SyncClassAssistantsHandler(c *gin.Context) {
-	requestMakerID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(400, gin.H{"error": "user_id not found in context"})
+// GetRosterChangesHandler returns the roster change log of a class, newest first.
+func (h *ClassHandler) GetRosterChangesHandler(c *gin.Context) {
+	classTransactionID := c.Param("classTransactionId")
+	if _, err := uuid.Parse(classTransactionID); err != nil {
+		c.JSON(400, gin.H{"error": "invalid class_transaction_id"})
 		return
 	}
 
-	var req requests.SyncClassStudentAndAssistantRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": "invalid request body", "details": err.Error()})
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(400, gin.H{"error": "invalid limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(400, gin.H{"error": "invalid offset"})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	err := h.internalClassService.SyncClassAssistants(ctx, req.SemesterID, req.CourseID, requestMakerID.(string))
+	changes, err := h.internalClassService.GetRosterChanges(ctx, classTransactionID, limit, offset)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "failed to sync class assistants", "details": err.Error()})
+		c.JSON(500, gin.H{"error": "failed to get roster changes", "details": err.Error()})
 		return
 	}
-	c.JSON(200, gin.H{"message": "class assistants synced successfully"})
+	c.JSON(200, changes)
 }
 
 func (h *ClassHandler) GetClassesBySemesterAndCourseHandler(c *gin.Context) {
@@ -192,16 +273,3 @@ func (h *ClassHandler) GetClassDetailByTransactionIDHandler(c *gin.Context) {
 	}
 	c.JSON(200, classDetail)
 }
-
-type Course struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-}
-
-func GetCoursesHandler(c *gin.Context) {
-	courses := []Course{
-		{ID: constants.AlgoprogID1, Name: "COMP6047001"},
-		{ID: constants.AlgoprogID2, Name: "COMP6878051"},
-	}
-	c.JSON(200, courses)
-}
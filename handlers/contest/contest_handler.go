@@ -2,9 +2,12 @@ package contestHandler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/audit"
 	"neptune/backend/pkg/requests"
 	contestService "neptune/backend/services/contest"
 	"net/http"
@@ -27,8 +30,6 @@ func (h *ContestHandler) CreateContest(c *gin.Context) {
 		return
 	}
 
-	fmt.Println(req)
-
 	if req.Scope == "global" && (req.StartTime == nil || req.EndTime == nil) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "StartTime and EndTime are required for global contests"})
 		return
@@ -37,11 +38,18 @@ func (h *ContestHandler) CreateContest(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
+	audit.Describe(c, "contest.create", "contest", "")
 	resp, err := h.contestService.CreateContest(ctx, req)
 	if err != nil {
+		if errors.Is(err, contestService.ErrContestCourseNotFound) || errors.Is(err, contestService.ErrAccessCodeNotGlobal) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create contest: %v", err.Error())})
 		return
 	}
+	audit.SetTargetID(c, resp.ID.String())
+	audit.After(c, resp)
 	c.JSON(http.StatusCreated, resp)
 }
 
@@ -55,9 +63,9 @@ func (h *ContestHandler) GetContestByID(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.contestService.GetContestByID(ctx, contestID)
+	resp, err := h.contestService.GetContestByID(ctx, contestID, requestMakerID(c), user.Role(c.GetString("role")))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve contest: %v", err.Error())})
+		writeAccessError(c, "retrieve contest", err)
 		return
 	}
 	if resp == nil {
@@ -67,11 +75,34 @@ func (h *ContestHandler) GetContestByID(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// JoinContest handles POST /api/contests/:contestId/join
+func (h *ContestHandler) JoinContest(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var req requests.JoinContestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.contestService.JoinContest(ctx, contestID, req, requestMakerID(c), user.Role(c.GetString("role")))
+	if err != nil {
+		writeAccessError(c, "join contest", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *ContestHandler) GetAllGlobalContestWithoutDetail(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.contestService.FindAllActiveGlobalContests(ctx)
+	resp, err := h.contestService.FindAllActiveGlobalContests(ctx, user.Role(c.GetString("role")))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve global contests: %v", err.Error())})
 		return
@@ -84,7 +115,7 @@ func (h *ContestHandler) GetAllGlobalContestDetail(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.contestService.FindAllActiveGlobalContestsDetail(ctx)
+	resp, err := h.contestService.FindAllActiveGlobalContestsDetail(ctx, user.Role(c.GetString("role")))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve global contest details: %v", err.Error())})
 		return
@@ -92,12 +123,22 @@ func (h *ContestHandler) GetAllGlobalContestDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
-// GetAllContests handles GET /api/contests
+// GetAllContests handles GET /api/contests, optionally filtered with ?course_id=
 func (h *ContestHandler) GetAllContests(c *gin.Context) {
+	var courseID *uuid.UUID
+	if raw := c.Query("course_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+			return
+		}
+		courseID = &parsed
+	}
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.contestService.GetAllContests(ctx)
+	resp, err := h.contestService.GetAllContests(ctx, courseID, user.Role(c.GetString("role")))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve contests: %v", err.Error())})
 		return
@@ -117,14 +158,25 @@ func (h *ContestHandler) UpdateContest(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.contestService.UpdateContest(ctx, contestID, req)
+	audit.Describe(c, "contest.update", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	h.snapshotContest(c, ctx, contestID, audit.Before)
+	resp, err := h.contestService.UpdateContest(ctx, contestID, req, force.Force)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update contest: %v", err.Error())})
+		writeLifecycleError(c, "update contest", err)
 		return
 	}
+	audit.After(c, resp)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -135,11 +187,21 @@ func (h *ContestHandler) DeleteContest(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
 		return
 	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	if err := h.contestService.DeleteContest(ctx, contestID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete contest: %v", err.Error())})
+	audit.Describe(c, "contest.delete", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	h.snapshotContest(c, ctx, contestID, audit.Before)
+	if err := h.contestService.DeleteContest(ctx, contestID, force.Force); err != nil {
+		writeLifecycleError(c, "delete contest", err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -157,16 +219,121 @@ func (h *ContestHandler) AddCasesToContest(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	if err := h.contestService.AddCasesToContest(ctx, contestID, req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to add cases to contest: %v", err.Error())})
+	audit.Describe(c, "contest.add_cases", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	h.snapshotContest(c, ctx, contestID, audit.Before)
+	if err := h.contestService.AddCasesToContest(ctx, contestID, req, force.Force); err != nil {
+		writeContestCaseError(c, "add cases to contest", err)
 		return
 	}
+	h.snapshotContest(c, ctx, contestID, audit.After)
 	c.JSON(http.StatusOK, gin.H{"message": "Cases added to contest successfully"})
 }
 
+// UpdateContestCase handles PUT /admin/contests/:contestId/cases/:caseId
+func (h *ContestHandler) UpdateContestCase(c *gin.Context) {
+	contestID, caseID, ok := contestCaseParams(c)
+	if !ok {
+		return
+	}
+	var req requests.UpdateContestCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "contest.update_case", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	h.snapshotContest(c, ctx, contestID, audit.Before)
+	resp, err := h.contestService.UpdateContestCase(ctx, contestID, caseID, req, force.Force)
+	if err != nil {
+		writeContestCaseError(c, "update contest case", err)
+		return
+	}
+	h.snapshotContest(c, ctx, contestID, audit.After)
+	c.JSON(http.StatusOK, resp)
+}
+
+// RemoveCaseFromContest handles DELETE /admin/contests/:contestId/cases/:caseId
+func (h *ContestHandler) RemoveCaseFromContest(c *gin.Context) {
+	contestID, caseID, ok := contestCaseParams(c)
+	if !ok {
+		return
+	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "contest.remove_case", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	h.snapshotContest(c, ctx, contestID, audit.Before)
+	if err := h.contestService.RemoveCaseFromContest(ctx, contestID, caseID, force.Force); err != nil {
+		writeContestCaseError(c, "remove case from contest", err)
+		return
+	}
+	h.snapshotContest(c, ctx, contestID, audit.After)
+	c.JSON(http.StatusOK, gin.H{"message": "Case removed from contest"})
+}
+
+// ReorderContestCases handles PUT /admin/contests/:contestId/cases/order
+func (h *ContestHandler) ReorderContestCases(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var req requests.ReorderContestCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "contest.reorder_cases", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	h.snapshotContest(c, ctx, contestID, audit.Before)
+	resp, err := h.contestService.ReorderContestCases(ctx, contestID, req, force.Force)
+	if err != nil {
+		writeContestCaseError(c, "reorder contest cases", err)
+		return
+	}
+	h.snapshotContest(c, ctx, contestID, audit.After)
+	c.JSON(http.StatusOK, resp)
+}
+
 // AssignContestToClass handles POST /api/classes/:classTransactionId/contests
 func (h *ContestHandler) AssignContestToClass(c *gin.Context) {
 	classTransactionID, err := uuid.Parse(c.Param("classTransactionId"))
@@ -182,11 +349,74 @@ func (h *ContestHandler) AssignContestToClass(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
+	audit.Describe(c, "class_contest.assign", "class", classTransactionID.String())
 	resp, err := h.contestService.AssignContestToClass(ctx, classTransactionID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to assign contest to class: %v", err.Error())})
 		return
 	}
+	audit.After(c, resp)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// CloneContest handles POST /admin/contests/:contestId/clone
+func (h *ContestHandler) CloneContest(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var req requests.CloneContestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "contest.clone", "contest", "")
+	resp, err := h.contestService.CloneContest(ctx, contestID, req)
+	if err != nil {
+		writeScheduleError(c, "clone contest", err)
+		return
+	}
+	audit.SetTargetID(c, resp.ID.String())
+	audit.After(c, resp)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// BulkAssignContest handles POST /admin/contests/:contestId/assign-classes
+func (h *ContestHandler) BulkAssignContest(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var req requests.BulkAssignContestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "class_contest.bulk_assign", "contest", contestID.String())
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	resp, err := h.contestService.BulkAssignContest(ctx, contestID, req, force.Force)
+	if err != nil {
+		writeScheduleError(c, "assign contest", err)
+		return
+	}
+	audit.After(c, resp)
 	c.JSON(http.StatusCreated, resp)
 }
 
@@ -200,7 +430,7 @@ func (h *ContestHandler) GetContestsForClass(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.contestService.GetContestsForClass(ctx, classTransactionID)
+	resp, err := h.contestService.GetContestsForClass(ctx, classTransactionID, requestMakerID(c), user.Role(c.GetString("role")))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve contests for class: %v", err.Error())})
 		return
@@ -223,9 +453,314 @@ func (h *ContestHandler) RemoveContestFromClass(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
+	audit.Describe(c, "class_contest.remove", "class", classTransactionID.String())
+	if assignments, err := h.contestService.GetContestsForClass(ctx, classTransactionID, uuid.Nil, user.RoleAdmin); err == nil {
+		for _, assignment := range assignments {
+			if assignment.ContestID == contestID {
+				audit.Before(c, assignment)
+			}
+		}
+	}
 	if err := h.contestService.RemoveContestFromClass(ctx, classTransactionID, contestID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to remove contest from class: %v", err.Error())})
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// GetOverrides handles GET /admin/classes/:classTransactionId/contests/:contestId/overrides
+func (h *ContestHandler) GetOverrides(c *gin.Context) {
+	classTransactionID, contestID, ok := classContestParams(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.contestService.GetOverrides(ctx, classTransactionID, contestID)
+	if err != nil {
+		writeOverrideError(c, "retrieve overrides", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetOverride handles PUT /admin/classes/:classTransactionId/contests/:contestId/overrides/:userId
+func (h *ContestHandler) SetOverride(c *gin.Context) {
+	classTransactionID, contestID, ok := classContestParams(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	var req requests.ContestOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "class_contest.set_override", "user", userID.String())
+	resp, err := h.contestService.SetOverride(ctx, classTransactionID, contestID, userID, req, requestMakerID(c))
+	if err != nil {
+		writeOverrideError(c, "save override", err)
+		return
+	}
+	audit.After(c, resp)
+	c.JSON(http.StatusOK, resp)
+}
+
+// RemoveOverride handles DELETE /admin/classes/:classTransactionId/contests/:contestId/overrides/:userId
+func (h *ContestHandler) RemoveOverride(c *gin.Context) {
+	classTransactionID, contestID, ok := classContestParams(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "class_contest.remove_override", "user", userID.String())
+	if overrides, err := h.contestService.GetOverrides(ctx, classTransactionID, contestID); err == nil {
+		for _, override := range overrides {
+			if override.UserID == userID {
+				audit.Before(c, override)
+			}
+		}
+	}
+	if err := h.contestService.RemoveOverride(ctx, classTransactionID, contestID, userID); err != nil {
+		writeOverrideError(c, "remove override", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Override removed"})
+}
+
+// StartVirtualParticipation handles POST /api/contests/:contestId/virtual-participations
+func (h *ContestHandler) StartVirtualParticipation(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var req requests.StartVirtualParticipationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.contestService.StartVirtualParticipation(ctx, contestID, req, requestMakerID(c), user.Role(c.GetString("role")))
+	if err != nil {
+		writeVirtualError(c, "start virtual participation", err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetVirtualParticipation handles GET /api/contests/:contestId/virtual-participations
+func (h *ContestHandler) GetVirtualParticipation(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var classTransactionID *uuid.UUID
+	if raw := c.Query("class_transaction_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class transaction ID format"})
+			return
+		}
+		classTransactionID = &parsed
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.contestService.GetVirtualParticipation(ctx, contestID, classTransactionID, requestMakerID(c))
+	if err != nil {
+		writeVirtualError(c, "retrieve virtual participation", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func contestCaseParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return contestID, caseID, true
+}
+
+func classContestParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	classTransactionID, err := uuid.Parse(c.Param("classTransactionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class transaction ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return classTransactionID, contestID, true
+}
+
+func requestMakerID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func writeOverrideError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, contestService.ErrClassContestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrNotClassStudent), errors.Is(err, contestService.ErrInvalidOverride):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
+
+// GetLifecycle handles GET /admin/contests/:contestId/status
+func (h *ContestHandler) GetLifecycle(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.contestService.GetLifecycle(ctx, contestID)
+	if err != nil {
+		writeLifecycleError(c, "retrieve contest status", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetStatusOverride handles PUT /admin/contests/:contestId/status
+func (h *ContestHandler) SetStatusOverride(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var req requests.ContestStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "contest.set_status", "contest", contestID.String())
+	if before, err := h.contestService.GetLifecycle(ctx, contestID); err == nil {
+		audit.Before(c, before)
+	}
+	resp, err := h.contestService.SetStatusOverride(ctx, contestID, req)
+	if err != nil {
+		writeLifecycleError(c, "set contest status", err)
+		return
+	}
+	audit.After(c, resp)
+	c.JSON(http.StatusOK, resp)
+}
+
+func writeAccessError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, contestService.ErrContestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contest not found"})
+	case errors.Is(err, contestService.ErrAccessCodeRequired), errors.Is(err, contestService.ErrInvalidAccessCode),
+		errors.Is(err, contestService.ErrNotEnrolled):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
+
+func writeLifecycleError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, contestService.ErrContestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrContestLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrContestCourseNotFound), errors.Is(err, contestService.ErrInvalidSchedule),
+		errors.Is(err, contestService.ErrInvalidStatus), errors.Is(err, contestService.ErrAccessCodeNotGlobal):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
+
+func writeContestCaseError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, contestService.ErrCaseNotInContest), errors.Is(err, contestService.ErrContestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrDuplicateProblemCode), errors.Is(err, contestService.ErrContestLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrCaseNotFound), errors.Is(err, contestService.ErrInvalidProblemCode),
+		errors.Is(err, contestService.ErrInvalidColor), errors.Is(err, contestService.ErrInvalidOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
+
+func writeScheduleError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, contestService.ErrContestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrContestCourseNotFound), errors.Is(err, contestService.ErrClassNotFound),
+		errors.Is(err, contestService.ErrInvalidSchedule), errors.Is(err, contestService.ErrNoClassesSelected):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrContestLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
+
+func writeVirtualError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, contestService.ErrContestNotFound), errors.Is(err, contestService.ErrClassContestNotFound),
+		errors.Is(err, contestService.ErrVirtualNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrNotClassStudent):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, contestService.ErrContestNotFinished), errors.Is(err, contestService.ErrVirtualStarted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
+
+// snapshotContest hands the contest with its cases to an audit snapshot function. A failed lookup only
+// leaves the snapshot empty.
+func (h *ContestHandler) snapshotContest(c *gin.Context, ctx context.Context, contestID uuid.UUID, keep func(*gin.Context, interface{})) {
+	contest, err := h.contestService.GetContestByID(ctx, contestID, uuid.Nil, user.RoleAdmin)
+	if err == nil && contest != nil {
+		keep(c, contest)
+	}
+}
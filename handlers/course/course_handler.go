@@ -0,0 +1,145 @@
+package courseHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/requests"
+	courseServ "neptune/backend/services/course"
+	"net/http"
+	"time"
+)
+
+type CourseHandler struct {
+	courseService courseServ.CourseService
+}
+
+func NewCourseHandler(courseService courseServ.CourseService) *CourseHandler {
+	return &CourseHandler{courseService: courseService}
+}
+
+// GetActiveCourses handles GET /api/courses
+func (h *CourseHandler) GetActiveCourses(c *gin.Context) {
+	h.getCourses(c, true)
+}
+
+// GetAllCourses handles GET /admin/courses, including inactive courses.
+func (h *CourseHandler) GetAllCourses(c *gin.Context) {
+	h.getCourses(c, false)
+}
+
+func (h *CourseHandler) getCourses(c *gin.Context, activeOnly bool) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	courses, err := h.courseService.GetCourses(ctx, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve courses: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, courses)
+}
+
+// GetCourseByID handles GET /admin/courses/:courseId
+func (h *CourseHandler) GetCourseByID(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	course, err := h.courseService.GetCourseByID(ctx, courseID)
+	if err != nil {
+		writeCourseError(c, "retrieve", err)
+		return
+	}
+	c.JSON(http.StatusOK, course)
+}
+
+// CreateCourse handles POST /admin/courses
+func (h *CourseHandler) CreateCourse(c *gin.Context) {
+	var req requests.CourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "course.create", "course", "")
+	course, err := h.courseService.CreateCourse(ctx, req)
+	if err != nil {
+		writeCourseError(c, "create", err)
+		return
+	}
+	audit.SetTargetID(c, course.ID.String())
+	audit.After(c, course)
+	c.JSON(http.StatusCreated, course)
+}
+
+// UpdateCourse handles PUT /admin/courses/:courseId
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		return
+	}
+	var req requests.CourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "course.update", "course", courseID.String())
+	if before, err := h.courseService.GetCourseByID(ctx, courseID); err == nil {
+		audit.Before(c, before)
+	}
+	course, err := h.courseService.UpdateCourse(ctx, courseID, req)
+	if err != nil {
+		writeCourseError(c, "update", err)
+		return
+	}
+	audit.After(c, course)
+	c.JSON(http.StatusOK, course)
+}
+
+// DeleteCourse handles DELETE /admin/courses/:courseId. Contests of the course are kept and unlinked.
+func (h *CourseHandler) DeleteCourse(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("courseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "course.delete", "course", courseID.String())
+	if before, err := h.courseService.GetCourseByID(ctx, courseID); err == nil {
+		audit.Before(c, before)
+	}
+	if err := h.courseService.DeleteCourse(ctx, courseID); err != nil {
+		writeCourseError(c, "delete", err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func writeCourseError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, courseServ.ErrCourseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, courseServ.ErrCourseConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, courseServ.ErrInvalidSemester):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s course: %v", action, err)})
+	}
+}
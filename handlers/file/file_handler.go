@@ -0,0 +1,239 @@
+package fileHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"neptune/backend/pkg/responses"
+	fileServ "neptune/backend/services/file"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FileHandler serves protected files. The same handlers sit behind session auth (/api, /admin) and behind
+// signed links (/files), so the frontend can embed a PDF or open a download without sending cookies.
+type FileHandler struct {
+	fileService fileServ.FileService
+}
+
+func NewFileHandler(fileService fileServ.FileService) *FileHandler {
+	return &FileHandler{fileService: fileService}
+}
+
+// CasePDF handles GET /api/cases/:caseId/pdf and its signed /files counterpart. Without a signed link, only
+// users the statement is released to may read it.
+func (h *FileHandler) CasePDF(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if c.GetString("user_id") != "" && !h.authorizeCase(c, ctx, caseID) {
+		return
+	}
+	download, err := h.fileService.OpenCasePDF(ctx, caseID)
+	h.serve(c, download, err, "inline")
+}
+
+// CasePDFLink handles GET /api/cases/:caseId/pdf/link and returns a short-lived signed URL to the PDF.
+// Only users the statement is released to get a link.
+func (h *FileHandler) CasePDFLink(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if !h.authorizeCase(c, ctx, caseID) {
+		return
+	}
+	h.sign(c, fmt.Sprintf("/files/cases/%s/pdf", caseID))
+}
+
+func (h *FileHandler) authorizeCase(c *gin.Context, ctx context.Context, caseID uuid.UUID) bool {
+	viewer, err := fileServ.NewViewer(c.GetString("user_id"), c.GetString("role"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return false
+	}
+	if err := h.fileService.AuthorizeCaseFile(ctx, caseID, viewer); err != nil {
+		writeFileError(c, err)
+		return false
+	}
+	return true
+}
+
+// CaseAttachment handles GET /api/cases/:caseId/attachments/:attachmentId and its signed /files counterpart.
+// Attachments are released together with the statement.
+func (h *FileHandler) CaseAttachment(c *gin.Context) {
+	caseID, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if c.GetString("user_id") != "" && !h.authorizeCase(c, ctx, caseID) {
+		return
+	}
+	download, err := h.fileService.OpenCaseAttachment(ctx, caseID, attachmentID)
+	h.serve(c, download, err, "attachment")
+}
+
+// CaseAttachmentLink handles GET /api/cases/:caseId/attachments/:attachmentId/link.
+func (h *FileHandler) CaseAttachmentLink(c *gin.Context) {
+	caseID, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if !h.authorizeCase(c, ctx, caseID) {
+		return
+	}
+	h.sign(c, fmt.Sprintf("/files/cases/%s/attachments/%s", caseID, attachmentID))
+}
+
+// TestCaseFile handles GET /admin/cases/:case_id/test-cases/:number/:kind and its signed /files counterpart.
+// kind is "input" or "output".
+func (h *FileHandler) TestCaseFile(c *gin.Context) {
+	caseID, number, kind, ok := parseTestCaseParams(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	download, err := h.fileService.OpenTestCaseFile(ctx, caseID, number, kind)
+	h.serve(c, download, err, "attachment")
+}
+
+// TestCaseFileLink handles GET /admin/cases/:case_id/test-cases/:number/:kind/link.
+func (h *FileHandler) TestCaseFileLink(c *gin.Context) {
+	caseID, number, kind, ok := parseTestCaseParams(c)
+	if !ok {
+		return
+	}
+	h.sign(c, fmt.Sprintf("/files/cases/%s/test-cases/%d/%s", caseID, number, kind))
+}
+
+// SubmissionSource handles the signed GET /files/submissions/:submissionId/code.
+func (h *FileHandler) SubmissionSource(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("submissionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	download, err := h.fileService.OpenSubmissionSource(ctx, submissionID)
+	h.serve(c, download, err, "inline")
+}
+
+// SubmissionSourceLink handles GET /api/submissions/:submissionId/code/link. Only users allowed to read
+// the source get a link.
+func (h *FileHandler) SubmissionSourceLink(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("submissionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID format"})
+		return
+	}
+	viewer, err := fileServ.NewViewer(c.GetString("user_id"), c.GetString("role"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.fileService.AuthorizeSubmissionSource(ctx, submissionID, viewer); err != nil {
+		writeFileError(c, err)
+		return
+	}
+	h.sign(c, fmt.Sprintf("/files/submissions/%s/code", submissionID))
+}
+
+func (h *FileHandler) sign(c *gin.Context, path string) {
+	url, expiresAt := h.fileService.SignPath(path)
+	c.JSON(http.StatusOK, responses.SignedURLResponse{URL: url, ExpiresAt: expiresAt})
+}
+
+func (h *FileHandler) serve(c *gin.Context, download *fileServ.FileDownload, err error, disposition string) {
+	if err != nil {
+		writeFileError(c, err)
+		return
+	}
+	defer download.Content.Close()
+
+	c.Header("Content-Type", download.ContentType)
+	c.Header("Content-Disposition", fileServ.ContentDisposition(disposition, download.FileName))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, download.Content); err != nil {
+		log.Printf("Error streaming %s: %v", download.FileName, err)
+	}
+}
+
+func writeFileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, fileServ.ErrFileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	case errors.Is(err, fileServ.ErrFileForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error serving file: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+	}
+}
+
+// parseTestCaseParams reads the case ID (":case_id" on admin routes, ":caseId" on signed routes), the
+// testcase number and the file kind.
+func parseTestCaseParams(c *gin.Context) (uuid.UUID, int, fileServ.TestCaseFileKind, bool) {
+	rawCaseID := c.Param("case_id")
+	if rawCaseID == "" {
+		rawCaseID = c.Param("caseId")
+	}
+	caseID, err := uuid.Parse(rawCaseID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return uuid.Nil, 0, "", false
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid testcase number"})
+		return uuid.Nil, 0, "", false
+	}
+	kind := fileServ.TestCaseFileKind(c.Param("kind"))
+	if kind != fileServ.TestCaseFileInput && kind != fileServ.TestCaseFileOutput {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be \"input\" or \"output\""})
+		return uuid.Nil, 0, "", false
+	}
+	return caseID, number, kind, true
+}
+
+func parseAttachmentParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid case ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return caseID, attachmentID, true
+}
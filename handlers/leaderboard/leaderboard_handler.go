@@ -1,9 +1,12 @@
 package leaderboardHand
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/responses"
 	contestService "neptune/backend/services/contest"
 	"neptune/backend/services/leaderboard"
 	"net/http"
@@ -30,15 +33,19 @@ func (h *LeaderboardHandler) GetGlobalContestLeaderboard(c *gin.Context) {
 		return
 	}
 
+	released, ok := h.authorizeViewer(c, contestID)
+	if !ok {
+		return
+	}
+
 	leaderboardData, err := h.service.GetGlobalContestLeaderboard(c.Request.Context(), contestID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate leaderboard", "details": err.Error()})
 		return
 	}
 
-	contestCases, err := h.contestServ.GetContestCases(c.Request.Context(), contestID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contest cases", "details": err.Error()})
+	contestCases, ok := h.releasedCases(c, contestID, released)
+	if !ok {
 		return
 	}
 
@@ -67,16 +74,19 @@ func (h *LeaderboardHandler) GetClassContestLeaderboard(c *gin.Context) {
 		return
 	}
 
+	released, ok := h.authorizeViewer(c, contestID)
+	if !ok {
+		return
+	}
+
 	leaderboardData, err := h.service.GetContestLeaderboard(c.Request.Context(), classID, contestID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate leaderboard", "details": err.Error()})
 		return
 	}
 
-	// get case
-	contestCases, err := h.contestServ.GetContestCases(c.Request.Context(), contestID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contest cases", "details": err.Error()})
+	contestCases, ok := h.releasedCases(c, contestID, released)
+	if !ok {
 		return
 	}
 
@@ -87,3 +97,69 @@ func (h *LeaderboardHandler) GetClassContestLeaderboard(c *gin.Context) {
 		"leaderboard":          leaderboardData,
 	})
 }
+
+// authorizeViewer lets the request through only when the viewer may see the contest, and reports whether
+// its problems are released to them.
+func (h *LeaderboardHandler) authorizeViewer(c *gin.Context, contestID uuid.UUID) (bool, bool) {
+	viewerID, _ := uuid.Parse(c.GetString("user_id"))
+	released, err := h.contestServ.AuthorizeContestProblems(c.Request.Context(), contestID, viewerID, user.Role(c.GetString("role")))
+	switch {
+	case err == nil:
+		return released, true
+	case errors.Is(err, contestService.ErrContestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contest not found"})
+	case errors.Is(err, contestService.ErrAccessCodeRequired), errors.Is(err, contestService.ErrNotEnrolled):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authorize contest", "details": err.Error()})
+	}
+	return false, false
+}
+
+// releasedCases lists the problems of the contest, or none while they are not released to the viewer.
+func (h *LeaderboardHandler) releasedCases(c *gin.Context, contestID uuid.UUID, released bool) ([]responses.ContestCaseResponse, bool) {
+	if !released {
+		return []responses.ContestCaseResponse{}, true
+	}
+	contestCases, err := h.contestServ.GetContestCases(c.Request.Context(), contestID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contest cases", "details": err.Error()})
+		return nil, false
+	}
+	return contestCases, true
+}
+
+// GetVirtualStanding handles GET /api/virtual-participations/:participationId/standing
+func (h *LeaderboardHandler) GetVirtualStanding(c *gin.Context) {
+	participationID, err := uuid.Parse(c.Param("participationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid participation ID format"})
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	standing, err := h.service.GetVirtualStanding(c.Request.Context(), participationID, userID)
+	if err != nil {
+		if errors.Is(err, leaderboardServ.ErrVirtualParticipationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate standing", "details": err.Error()})
+		return
+	}
+
+	contestCases, err := h.contestServ.GetContestCases(c.Request.Context(), standing.ContestID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contest cases", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"standing": standing,
+		"cases":    contestCases,
+	})
+}
@@ -0,0 +1,157 @@
+package localAccountHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	localAccountServ "neptune/backend/services/local_account"
+	"net/http"
+	"time"
+)
+
+const maxImportFileSize = 2 << 20 // 2 MiB
+
+type LocalAccountHandler struct {
+	localAccountService localAccountServ.LocalAccountService
+}
+
+func NewLocalAccountHandler(localAccountService localAccountServ.LocalAccountService) *LocalAccountHandler {
+	return &LocalAccountHandler{localAccountService: localAccountService}
+}
+
+// CreateAccount handles POST /admin/users/local
+func (h *LocalAccountHandler) CreateAccount(c *gin.Context) {
+	var req requests.LocalAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "user.create_local", "user", "")
+	account, err := h.localAccountService.CreateAccount(ctx, req, requestMakerID(c))
+	if err != nil {
+		writeLocalAccountError(c, "create account", err)
+		return
+	}
+	audit.SetTargetID(c, account.UserID)
+	audit.After(c, withoutResetToken(*account))
+	c.JSON(http.StatusCreated, account)
+}
+
+// ImportAccounts handles POST /admin/users/local/import with a CSV in the "file" form field.
+func (h *LocalAccountHandler) ImportAccounts(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required in the 'file' field"})
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("CSV file must be at most %d bytes", maxImportFileSize)})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open CSV file"})
+		return
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	audit.Describe(c, "user.import_local", "user", "")
+	result, err := h.localAccountService.ImportAccounts(ctx, file, requestMakerID(c))
+	if errors.Is(err, localAccountServ.ErrImportRejected) {
+		audit.After(c, gin.H{"file": fileHeader.Filename, "errors": result.Errors})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "errors": result.Errors})
+		return
+	}
+	if err != nil {
+		writeLocalAccountError(c, "import accounts", err)
+		return
+	}
+	accounts := make([]responses.LocalAccountResponse, 0, len(result.Accounts))
+	for _, account := range result.Accounts {
+		accounts = append(accounts, withoutResetToken(account))
+	}
+	audit.After(c, gin.H{"file": fileHeader.Filename, "created": result.Created, "accounts": accounts})
+	c.JSON(http.StatusCreated, result)
+}
+
+// IssueResetToken handles POST /admin/users/:userId/password-reset
+func (h *LocalAccountHandler) IssueResetToken(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "user.issue_password_reset", "user", userID.String())
+	token, err := h.localAccountService.IssueResetToken(ctx, userID, requestMakerID(c))
+	if err != nil {
+		writeLocalAccountError(c, "issue reset token", err)
+		return
+	}
+	audit.After(c, gin.H{"expires_at": token.ExpiresAt})
+	c.JSON(http.StatusCreated, token)
+}
+
+// ResetPassword handles POST /auth/password-reset. It needs no session, only the reset token.
+func (h *LocalAccountHandler) ResetPassword(c *gin.Context) {
+	var req requests.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.localAccountService.ResetPassword(ctx, req); err != nil {
+		writeLocalAccountError(c, "reset password", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated, you can log in now"})
+}
+
+// withoutResetToken keeps reset tokens out of the audit log, where every admin could read them.
+func withoutResetToken(account responses.LocalAccountResponse) responses.LocalAccountResponse {
+	account.ResetToken = ""
+	return account
+}
+
+func requestMakerID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func writeLocalAccountError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, localAccountServ.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, localAccountServ.ErrUsernameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, localAccountServ.ErrNotLocalAccount):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, localAccountServ.ErrInvalidUsername),
+		errors.Is(err, localAccountServ.ErrInvalidName),
+		errors.Is(err, localAccountServ.ErrInvalidRole),
+		errors.Is(err, localAccountServ.ErrWeakPassword),
+		errors.Is(err, localAccountServ.ErrInvalidResetToken),
+		errors.Is(err, localAccountServ.ErrInvalidCSV):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
@@ -0,0 +1,152 @@
+package roleHand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/requests"
+	roleServ "neptune/backend/services/role"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RoleHandler struct {
+	roleService roleServ.RoleService
+}
+
+func NewRoleHandler(roleService roleServ.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// GetUserRoles handles GET /admin/users/:userId/roles
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	roles, err := h.roleService.GetUserRoles(ctx, userID)
+	if err != nil {
+		writeRoleError(c, "retrieve roles", err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GrantRole handles POST /admin/users/:userId/roles
+func (h *RoleHandler) GrantRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	var req requests.GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "role.grant", "user", userID.String())
+	assignment, err := h.roleService.GrantRole(ctx, userID, req, requestMakerID(c))
+	if err != nil {
+		writeRoleError(c, "grant role", err)
+		return
+	}
+	audit.After(c, assignment)
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// RevokeRole handles DELETE /admin/users/:userId/roles/:assignmentId. The body with a reason is optional.
+func (h *RoleHandler) RevokeRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	assignmentID, err := uuid.Parse(c.Param("assignmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment ID"})
+		return
+	}
+	var req requests.RevokeRoleRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "role.revoke", "user", userID.String())
+	if before, err := h.roleService.GetUserRoles(ctx, userID); err == nil {
+		audit.Before(c, before)
+	}
+	if err := h.roleService.RevokeRole(ctx, userID, assignmentID, req.Reason, requestMakerID(c)); err != nil {
+		writeRoleError(c, "revoke role", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role revoked"})
+}
+
+// GetAuditTrail handles GET /admin/role-audit?user_id=&limit=&offset=
+func (h *RoleHandler) GetAuditTrail(c *gin.Context) {
+	var userID *uuid.UUID
+	if raw := c.Query("user_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		userID = &parsed
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	trail, err := h.roleService.GetAuditTrail(ctx, userID, limit, offset)
+	if err != nil {
+		writeRoleError(c, "retrieve role audit trail", err)
+		return
+	}
+	c.JSON(http.StatusOK, trail)
+}
+
+func requestMakerID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func writeRoleError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, roleServ.ErrUserNotFound), errors.Is(err, roleServ.ErrClassNotFound), errors.Is(err, roleServ.ErrAssignmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, roleServ.ErrInvalidRole), errors.Is(err, roleServ.ErrScopeNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, roleServ.ErrRoleAlreadyGranted), errors.Is(err, roleServ.ErrCannotRevokeOwnAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s: %v", action, err)})
+	}
+}
@@ -2,9 +2,13 @@ package semester
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"neptune/backend/messier"
+	"neptune/backend/pkg/audit"
 	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/utils"
 	"neptune/backend/services/internal_semester"
 	"net/http"
 	"time"
@@ -35,13 +39,16 @@ func (h *SemesterHandler) SyncSemestersHandler(c *gin.Context) {
 		return
 	}
 
+	audit.Describe(c, "semester.sync", "semester", "")
 	err := h.internalSemesterService.SyncSemester(c.Request.Context(), requestMakerIDStr)
 	if err != nil {
 		// Differentiate between auth/permission errors and internal errors
-		if err.Error() == fmt.Sprintf("unauthorized: no Messier token found for user %s", requestMakerIDStr) ||
-			err.Error() == fmt.Sprintf("unauthorized: Messier token for user %s has expired", requestMakerIDStr) {
+		switch {
+		case errors.Is(err, utils.ErrMessierTokenUnavailable), errors.Is(err, messier.ErrUnauthorized):
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		} else {
+		case errors.Is(err, messier.ErrUnavailable), errors.Is(err, messier.ErrRateLimited):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("Messier is unavailable: %v", err)})
+		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to sync semesters: %v", err)})
 		}
 		return
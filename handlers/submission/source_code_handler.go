@@ -1,9 +1,11 @@
 package submissionHand
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	fileServ "neptune/backend/services/file"
 	submissionServ "neptune/backend/services/submission"
 	"net/http"
 )
@@ -25,13 +27,22 @@ func (h *SubmissionReviewHandler) ViewCode(c *gin.Context) {
 		return
 	}
 
-	code, contentType, err := h.service.GetSubmissionCode(c.Request.Context(), submissionID)
+	viewer, err := fileServ.NewViewer(c.GetString("user_id"), c.GetString("role"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	code, contentType, err := h.service.GetSubmissionCode(c.Request.Context(), submissionID, viewer)
+	if err != nil {
+		writeReviewError(c, err)
 		return
 	}
 
 	// Use c.Data to send raw bytes with a specific content type.
+	c.Header("Content-Disposition", fileServ.ContentDisposition("inline", fmt.Sprintf("submission_%s.txt", submissionID)))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
 	c.Data(http.StatusOK, contentType, code)
 }
 
@@ -44,13 +55,28 @@ func (h *SubmissionReviewHandler) DownloadCode(c *gin.Context) {
 		return
 	}
 
-	zipData, downloadFilename, err := h.service.GetSubmissionCodeAsZip(c.Request.Context(), submissionID)
+	viewer, err := fileServ.NewViewer(c.GetString("user_id"), c.GetString("role"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	zipData, downloadFilename, err := h.service.GetSubmissionCodeAsZip(c.Request.Context(), submissionID, viewer)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		writeReviewError(c, err)
 		return
 	}
 
 	// Set headers to prompt the browser to download the file.
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", downloadFilename))
+	c.Header("Content-Disposition", fileServ.ContentDisposition("attachment", downloadFilename))
+	c.Header("Cache-Control", "private, no-store")
 	c.Data(http.StatusOK, "application/zip", zipData.Bytes())
 }
+
+func writeReviewError(c *gin.Context, err error) {
+	if errors.Is(err, fileServ.ErrFileForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+}
@@ -1,11 +1,14 @@
 package submissionHand
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"neptune/backend/models/user"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
+	contestService "neptune/backend/services/contest"
 	submissionServ "neptune/backend/services/submission"
 	"net/http"
 )
@@ -40,9 +43,20 @@ func (h *SubmissionHandler) SubmitCode(c *gin.Context) {
 	}
 
 	// 3. Call the service with the parsed and validated request
-	submission, err := h.service.SubmitCode(c.Request.Context(), &req, uId)
+	submission, err := h.service.SubmitCode(c.Request.Context(), &req, uId, user.Role(c.GetString("role")))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, contestService.ErrContestNotOpen), errors.Is(err, contestService.ErrNotClassStudent),
+			errors.Is(err, contestService.ErrNotEnrolled), errors.Is(err, contestService.ErrAccessCodeRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, contestService.ErrInvalidMode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, contestService.ErrContestNotFound), errors.Is(err, contestService.ErrClassContestNotFound),
+			errors.Is(err, contestService.ErrCaseNotInContest):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -0,0 +1,106 @@
+package syncRunHand
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
+	syncRunRepo "neptune/backend/repositories/sync_run"
+	messierSync "neptune/backend/services/messier_sync"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSyncRunLimit = 20
+	maxSyncRunLimit     = 100
+)
+
+type SyncRunHandler struct {
+	syncService messierSync.SyncService
+}
+
+func NewSyncRunHandler(syncService messierSync.SyncService) *SyncRunHandler {
+	return &SyncRunHandler{syncService: syncService}
+}
+
+// RunSyncNow starts a full Messier sync in the background. The response is the run that was started;
+// poll GetSyncRun for its progress.
+func (h *SyncRunHandler) RunSyncNow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+		return
+	}
+
+	audit.Describe(c, "sync_run.start", "sync_run", "")
+	run, err := h.syncService.RunNow(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, syncRunRepo.ErrSyncRunInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, messierSync.ErrNoSyncCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sync: " + err.Error()})
+		}
+		return
+	}
+
+	audit.SetTargetID(c, run.ID.String())
+	audit.After(c, run)
+	c.JSON(http.StatusAccepted, run)
+}
+
+func (h *SyncRunHandler) GetSyncRuns(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSyncRunLimit)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if limit > maxSyncRunLimit {
+		limit = maxSyncRunLimit
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	runs, err := h.syncService.GetRuns(ctx, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync runs: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+func (h *SyncRunHandler) GetSyncRun(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	runID, err := uuid.Parse(c.Param("runId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sync run ID"})
+		return
+	}
+
+	run, err := h.syncService.GetRun(ctx, runID)
+	if err != nil {
+		if errors.Is(err, messierSync.ErrSyncRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sync run: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
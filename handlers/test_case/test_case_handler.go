@@ -2,9 +2,13 @@ package testCaseHand
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
 	"neptune/backend/pkg/requests"
 	caseService "neptune/backend/services/case"
+	contestService "neptune/backend/services/contest"
 	testCaseServ "neptune/backend/services/test_case"
 	"time"
 )
@@ -27,17 +31,38 @@ func (h *TestCaseHandler) UploadTestCasesHandler(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "Invalid request data"})
 		return
 	}
+	var force requests.ForceRequest
+	if err := force.ParseAndValidate(c); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	// Large archives are streamed to the blob store, which can take a while on S3.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
 	defer cancel()
 
-	err := h.testCaseService.UploadTestCases(ctx, req)
+	audit.Describe(c, "test_case.upload", "case", c.Param("case_id"))
+	if force.Force {
+		audit.Forced(c, force.Reason)
+	}
+	if before, err := h.testCaseService.GetTestCasesByCaseID(ctx, c.Param("case_id")); err == nil {
+		audit.Before(c, before)
+	}
+	report, err := h.testCaseService.UploadTestCases(ctx, req, force.Force)
+	audit.After(c, report)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to upload test cases", "details": err.Error()})
+		status := 500
+		switch {
+		case errors.Is(err, testCaseServ.ErrInvalidArchive):
+			status = 400
+		case errors.Is(err, contestService.ErrContestLocked):
+			status = 409
+		}
+		c.JSON(status, gin.H{"error": "Failed to upload test cases", "details": err.Error(), "report": report})
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Test cases uploaded successfully"})
+	c.JSON(200, gin.H{"message": "Test cases uploaded successfully", "report": report})
 }
 
 func (h *TestCaseHandler) GetTestCasesByCaseIDHandler(c *gin.Context) {
@@ -58,3 +83,33 @@ func (h *TestCaseHandler) GetTestCasesByCaseIDHandler(c *gin.Context) {
 
 	c.JSON(200, testCases)
 }
+
+func (h *TestCaseHandler) SetSamplesHandler(c *gin.Context) {
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid case ID format"})
+		return
+	}
+	var req requests.SetSampleTestCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "test_case.samples", "case", caseID.String())
+	audit.After(c, req)
+	testCases, err := h.testCaseService.SetSamples(ctx, caseID, req)
+	if err != nil {
+		status := 500
+		if errors.Is(err, testCaseServ.ErrUnknownTestCase) {
+			status = 400
+		}
+		c.JSON(status, gin.H{"error": "Failed to set sample test cases", "details": err.Error()})
+		return
+	}
+
+	c.JSON(200, testCases)
+}
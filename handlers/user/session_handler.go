@@ -0,0 +1,164 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/audit"
+	sessionServ "neptune/backend/services/session"
+	"net/http"
+	"os"
+	"time"
+)
+
+// The refresh token cookie is only sent to /auth, so it never travels with ordinary API requests.
+const (
+	accessTokenCookie  = "token"
+	refreshTokenCookie = "refresh_token"
+	refreshCookiePath  = "/auth"
+)
+
+func setAuthCookies(c *gin.Context, tokens *sessionServ.Tokens) {
+	domain := os.Getenv("FRONTEND_URL")
+	// set secure to false on prod
+	secure := os.Getenv("APP_ENV") == "production"
+	c.SetCookie(accessTokenCookie, tokens.AccessToken, cookieMaxAge(tokens.AccessTokenExpires), "/", domain, secure, true)
+	c.SetCookie(refreshTokenCookie, tokens.RefreshToken, cookieMaxAge(tokens.RefreshTokenExpires), refreshCookiePath, domain, secure, true)
+}
+
+func clearAuthCookies(c *gin.Context) {
+	domain := os.Getenv("FRONTEND_URL")
+	secure := os.Getenv("APP_ENV") == "production"
+	// Set cookie with empty value and negative max age to delete it
+	c.SetCookie(accessTokenCookie, "", -1, "/", domain, secure, true)
+	c.SetCookie(refreshTokenCookie, "", -1, refreshCookiePath, domain, secure, true)
+}
+
+func cookieMaxAge(expires time.Time) int {
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		return 1
+	}
+	return maxAge
+}
+
+// RefreshHandler handles POST /auth/refresh. It swaps the refresh token cookie for a new pair of tokens;
+// clients call it when an API request answers 401.
+func (handler *UserHandler) RefreshHandler(c *gin.Context) {
+	refreshToken, _ := c.Cookie(refreshTokenCookie)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	tokens, err := handler.sessionService.Refresh(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, sessionServ.ErrInvalidRefreshToken) || errors.Is(err, sessionServ.ErrRefreshTokenReused) {
+			clearAuthCookies(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to refresh session: %v", err)})
+		return
+	}
+
+	setAuthCookies(c, tokens)
+	c.JSON(http.StatusOK, gin.H{"access_token_expires_at": tokens.AccessTokenExpires})
+}
+
+// GetSessionsHandler handles GET /auth/sessions, listing the active sessions of the current user.
+func (handler *UserHandler) GetSessionsHandler(c *gin.Context) {
+	userID, sessionID, ok := sessionIdentity(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	sessions, err := handler.sessionService.GetActiveSessions(ctx, userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve sessions: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSessionHandler handles DELETE /auth/sessions/:sessionId for one of the user's own sessions.
+func (handler *UserHandler) RevokeSessionHandler(c *gin.Context) {
+	userID, currentSessionID, ok := sessionIdentity(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := handler.sessionService.Revoke(ctx, userID, sessionID, "revoked by user"); err != nil {
+		if errors.Is(err, sessionServ.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to revoke session: %v", err)})
+		return
+	}
+	if sessionID == currentSessionID {
+		clearAuthCookies(c)
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeOtherSessionsHandler handles DELETE /auth/sessions, logging the user out everywhere else.
+func (handler *UserHandler) RevokeOtherSessionsHandler(c *gin.Context) {
+	userID, sessionID, ok := sessionIdentity(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	revoked, err := handler.sessionService.RevokeAllForUser(ctx, userID, sessionID, "revoked by user")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to revoke sessions: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// RevokeUserSessionsHandler handles DELETE /admin/users/:userId/sessions, logging a user out everywhere.
+func (handler *UserHandler) RevokeUserSessionsHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	audit.Describe(c, "user.revoke_sessions", "user", userID.String())
+	revoked, err := handler.sessionService.RevokeAllForUser(ctx, userID, uuid.Nil, "revoked by admin")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to revoke sessions: %v", err)})
+		return
+	}
+	audit.After(c, gin.H{"revoked": revoked})
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+func sessionIdentity(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error: Invalid user ID format in context"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	sessionID, err := uuid.Parse(c.GetString("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error: Invalid session ID format in context"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, sessionID, true
+}
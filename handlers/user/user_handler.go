@@ -2,71 +2,53 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log"
+	"neptune/backend/messier"
 	"neptune/backend/pkg/requests"
-	"neptune/backend/pkg/responses"
 	"neptune/backend/pkg/utils"
+	sessionServ "neptune/backend/services/session"
 	"neptune/backend/services/user"
 	"net/http"
-	"os"
-	"regexp"
 	"time"
 )
 
 type UserHandler struct {
-	service user.UserService
+	service        user.UserService
+	sessionService sessionServ.SessionService
 }
 
-func NewUserHandler(service user.UserService) *UserHandler {
-	return &UserHandler{service: service}
+func NewUserHandler(service user.UserService, sessionService sessionServ.SessionService) *UserHandler {
+	return &UserHandler{service: service, sessionService: sessionService}
 }
 
-var nimRegex = regexp.MustCompile(`^\d{10}$`)
+// messierDown reports login failures caused by Messier itself rather than by wrong credentials.
+func messierDown(err error) bool {
+	return errors.Is(err, messier.ErrUnavailable) || errors.Is(err, messier.ErrRateLimited)
+}
 
 func (handler *UserHandler) LoginHandler(c *gin.Context) {
-	var (
-		req requests.LoginRequest
-		err error
-	)
-
-	err = c.ShouldBindJSON(&req)
-	if err != nil {
+	var req requests.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
 
-	var (
-		loginResp   *responses.LoginResponse
-		accessToken string
-		expires     time.Time
-	)
-	if nimRegex.MatchString(req.Username) {
-		loginResp, accessToken, expires, err = handler.service.LoginStudent(c.Request.Context(), &req)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Invalid credentials %s", err)})
-			return
-		}
-	} else {
-		loginResp, accessToken, expires, err = handler.service.LoginAssistant(c.Request.Context(), &req)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	meta := sessionServ.Meta{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	loginResp, tokens, err := handler.service.Login(c.Request.Context(), &req, meta)
+	if err != nil {
+		if messierDown(err) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Messier is unavailable, please try again later"})
 			return
 		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
 	}
 
-	accessTokenMaxAge := int(time.Until(expires).Seconds())
-	if accessTokenMaxAge <= 0 {
-		accessTokenMaxAge = 3600
-	}
-
-	domain := os.Getenv("FRONTEND_URL")
-
-	// set secure to false on prod
-	secure := os.Getenv("APP_ENV") == "production"
-	c.SetCookie("token", accessToken, accessTokenMaxAge, "/", domain, secure, true)
+	setAuthCookies(c, tokens)
 	c.JSON(http.StatusOK, gin.H{"user": loginResp})
 }
 
@@ -95,12 +77,22 @@ func (handler *UserHandler) MeHandler(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User profile not found"})
 		return
 	}
+	// Report the role the access token carries, which includes granted roles.
+	meResp.Role = c.GetString("role")
 
 	c.JSON(http.StatusOK, gin.H{"user": meResp})
 }
 
 func (handler *UserHandler) LogOutHandler(c *gin.Context) {
-	domain := os.Getenv("FRONTEND_URL")
+	userID, sessionID, ok := sessionIdentity(c)
+	if !ok {
+		return
+	}
+
+	if err := handler.sessionService.Revoke(c.Request.Context(), userID, sessionID, "logout"); err != nil && !errors.Is(err, sessionServ.ErrSessionNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed on Log Out: %v", err)})
+		return
+	}
 
 	// delete user external token if exists
 	err := handler.service.DeleteUserAccessToken(c.Request.Context(), c.GetString("user_id"))
@@ -109,8 +101,6 @@ func (handler *UserHandler) LogOutHandler(c *gin.Context) {
 		utils.CheckPanic(fmt.Errorf("failed deleting access token: %s", err))
 	}
 
-	// set secure to false on prod
-	secure := os.Getenv("APP_ENV") == "production"
-	c.SetCookie("token", "", -1, "/", domain, secure, true) // Set cookie with empty value and negative max age to delete it
+	clearAuthCookies(c)
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
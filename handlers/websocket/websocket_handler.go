@@ -1,12 +1,15 @@
 package websocketHand
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"log"
+	"neptune/backend/models/user"
 	webSocketService "neptune/backend/services/web_socket_service"
 	"net/http"
+	"time"
 )
 
 var upgrader = websocket.Upgrader{
@@ -17,11 +20,12 @@ var upgrader = websocket.Upgrader{
 }
 
 type WebSocketHandler struct {
-	service webSocketService.WebSocketService
+	service       webSocketService.WebSocketService
+	contestAccess webSocketService.ContestAccess
 }
 
-func NewWebSocketHandler(service webSocketService.WebSocketService) *WebSocketHandler {
-	return &WebSocketHandler{service: service}
+func NewWebSocketHandler(service webSocketService.WebSocketService, contestAccess webSocketService.ContestAccess) *WebSocketHandler {
+	return &WebSocketHandler{service: service, contestAccess: contestAccess}
 }
 
 func (h *WebSocketHandler) HandleSubmissionConnection(c *gin.Context) {
@@ -53,3 +57,62 @@ func (h *WebSocketHandler) HandleSubmissionConnection(c *gin.Context) {
 		}
 	}
 }
+
+// HandleContestConnection handles GET /api/ws/contests/:contestId?class_transaction_id=
+// Students and staff of the class contest, or anyone for a global contest, receive its events.
+func (h *WebSocketHandler) HandleContestConnection(c *gin.Context) {
+	contestID, err := uuid.Parse(c.Param("contestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contest ID format"})
+		return
+	}
+	var classTransactionID *uuid.UUID
+	if raw := c.Query("class_transaction_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class_transaction_id format"})
+			return
+		}
+		classTransactionID = &parsed
+	}
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	contestRole, err := h.contestAccess.ContestRoleOf(ctx, contestID, classTransactionID, userID, user.Role(c.GetString("role")))
+	cancel()
+	if err != nil {
+		log.Printf("Failed to check access of user %s to contest %s: %v", userID, contestID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check contest access"})
+		return
+	}
+	if contestRole == webSocketService.ContestOutsider {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not taking part in this contest"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+
+	listener := &webSocketService.ContestListener{
+		Conn:               conn,
+		UserID:             userID,
+		ClassTransactionID: classTransactionID,
+		Staff:              contestRole == webSocketService.ContestStaff,
+	}
+	defer conn.Close()
+	defer h.service.UnregisterContestListener(contestID, listener)
+	h.service.RegisterContestListener(contestID, listener)
+
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			break
+		}
+	}
+}
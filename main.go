@@ -1,10 +1,13 @@
 package main
 
 import (
+	auditModel "neptune/backend/models/audit"
 	models "neptune/backend/models/class"
 	contestModel "neptune/backend/models/contest"
+	courseModel "neptune/backend/models/course"
 	semester "neptune/backend/models/semester"
 	submissionModel "neptune/backend/models/submission"
+	syncRunModel "neptune/backend/models/sync_run"
 	testCaseModel "neptune/backend/models/test_case"
 	"neptune/backend/models/user"
 	"neptune/backend/pkg/container"
@@ -29,6 +32,10 @@ func init() {
 }
 
 func main() {
+	if err := utils.LoadSignedURLSecret(); err != nil {
+		panic(err.Error())
+	}
+
 	// Auto migrate schemas
 	db := database.Connect()
 
@@ -36,17 +43,39 @@ func main() {
 		&user.User{},
 		&semester.Semester{},
 		&user.MessierToken{},
+		&user.LocalCredential{},
+		&user.PasswordResetToken{},
+		&user.Session{},
+		&user.RoleAssignment{},
+		&user.RoleAuditEntry{},
+		&user.PersonalAccessToken{},
+		&user.AccessTokenUsage{},
+		&auditModel.AuditLog{},
 		&models.Class{},
+		&courseModel.Course{},
+		&courseModel.CourseSemester{},
 		&contestModel.Contest{},
 		&contestModel.Case{},
+		&contestModel.CaseStatementRevision{},
+		&contestModel.CaseAttachment{},
+		&contestModel.CaseTag{},
 		&testCaseModel.TestCase{},
 		&models.ClassStudent{},
 		&models.ClassAssistant{},
+		&models.RosterChange{},
 		&contestModel.ContestCase{}, // NEW: Migrate ContestCase (FKs to Contest and Case)
 		&contestModel.ClassContest{},
+		&contestModel.ClassContestOverride{},
+		&contestModel.VirtualParticipation{},
+		&contestModel.ContestAccessGrant{},
 		&submissionModel.Submission{},
 		&submissionModel.SubmissionResult{},
 		&contestModel.GlobalContestDetail{},
+		&contestModel.Clarification{},
+		&contestModel.Announcement{},
+		&contestModel.AnnouncementTarget{},
+		&syncRunModel.SyncRun{},
+		&syncRunModel.SyncRunStep{},
 	); err != nil {
 		utils.CheckPanic(err)
 	}
@@ -65,6 +94,15 @@ func main() {
 		&(handlerContainer.LanguageHandler),
 		&(handlerContainer.LeaderboardHandler),
 		&(handlerContainer.SubmissionReviewHandler),
+		&(handlerContainer.FileHandler),
+		&(handlerContainer.SyncRunHandler),
+		&(handlerContainer.CourseHandler),
+		&(handlerContainer.LocalAccountHandler),
+		&(handlerContainer.RoleHandler),
+		&(handlerContainer.AccessTokenHandler),
+		&(handlerContainer.AuditHandler),
+		&(handlerContainer.ClarificationHandler),
+		&(handlerContainer.AnnouncementHandler),
 	)
 
 	port := os.Getenv("PORT")
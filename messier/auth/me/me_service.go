@@ -5,31 +5,21 @@ import (
 	"fmt"
 	"neptune/backend/messier"
 	"neptune/backend/models/user"
-	"net/http"
-	"os"
-	"strings"
 )
 
 type meService struct {
-	baseURL string
+	client *messier.Client
 }
 
-func NewMeService() MeService {
+func NewMeService(client *messier.Client) MeService {
 	return &meService{
-		baseURL: "https://your-lab-api.com", // Replace with actual Lab API URL
+		client: client,
 	}
 }
 
 func (m *meService) GetAssistantProfile(ctx context.Context, authToken string) (*MeResponse, error) {
 	var resp MeResponse
-
-	baseURL := strings.TrimRight(os.Getenv("MESSIER_API_URL"), "/")
-	err := messier.SendRequest(ctx,
-		http.MethodGet,
-		fmt.Sprintf("%s/Account/Me", baseURL),
-		nil, &resp, authToken)
-
-	if err != nil {
+	if err := m.client.GetNoCache(ctx, "/Account/Me", nil, &resp, authToken); err != nil {
 		return nil, fmt.Errorf("failed to fetch me: %w", err)
 	}
 
@@ -0,0 +1,87 @@
+package messier
+
+import (
+	"sync"
+	"time"
+)
+
+const maxCacheEntries = 1024
+
+type cacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// responseCache keeps raw GET bodies per token and URL. Messier data changes rarely within a sync, while
+// the same lookups (assistant details, semesters) are repeated for every class.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[string]cacheEntry // token hash -> URL -> entry
+	size    int
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]map[string]cacheEntry)}
+}
+
+func (c *responseCache) get(token, endpoint string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[token][endpoint]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries[token], endpoint)
+		c.size--
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *responseCache) put(token, endpoint string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size >= maxCacheEntries {
+		c.evictExpired()
+		if c.size >= maxCacheEntries {
+			// Still full of fresh entries: start over rather than track recency.
+			c.entries = make(map[string]map[string]cacheEntry)
+			c.size = 0
+		}
+	}
+	byURL, ok := c.entries[token]
+	if !ok {
+		byURL = make(map[string]cacheEntry)
+		c.entries[token] = byURL
+	}
+	if _, exists := byURL[endpoint]; !exists {
+		c.size++
+	}
+	byURL[endpoint] = cacheEntry{body: body, expiresAt: time.Now().Add(c.ttl)}
+}
+
+func (c *responseCache) deleteToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size -= len(c.entries[token])
+	delete(c.entries, token)
+}
+
+func (c *responseCache) evictExpired() {
+	now := time.Now()
+	for token, byURL := range c.entries {
+		for endpoint, entry := range byURL {
+			if now.After(entry.expiresAt) {
+				delete(byURL, endpoint)
+				c.size--
+			}
+		}
+		if len(byURL) == 0 {
+			delete(c.entries, token)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package messier
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by Client, wrapped in *APIError when Messier answered. Use errors.Is to check them.
+var (
+	ErrUnauthorized = errors.New("messier: unauthorized")
+	ErrNotFound     = errors.New("messier: not found")
+	ErrRateLimited  = errors.New("messier: rate limited")
+	ErrUnavailable  = errors.New("messier: unavailable")
+	ErrBadResponse  = errors.New("messier: unexpected response")
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 500 * time.Millisecond
+	defaultCacheTTL     = time.Minute
+	maxErrorBodyBytes   = 2048
+)
+
+// APIError is a non-2xx answer from Messier.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("messier %s %s returned status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// Unwrap maps the status code to one of the sentinel errors.
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrUnavailable
+	default:
+		return ErrBadResponse
+	}
+}
+
+type ClientConfig struct {
+	BaseURL      string
+	Timeout      time.Duration // Per attempt
+	MaxRetries   int           // Extra attempts after 5xx, 429, timeouts and connection errors
+	RetryBackoff time.Duration // Doubled after every attempt, with jitter
+	CacheTTL     time.Duration // How long GET responses are reused, 0 disables the cache
+}
+
+// Client talks to the Messier API. It reuses connections, retries transient failures and caches GET
+// responses per token for a short time, so sync loops do not hammer Messier with identical requests.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+	cache      *responseCache
+}
+
+func NewClient(cfg ClientConfig) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	client := &Client{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		maxRetries: cfg.MaxRetries,
+		backoff:    cfg.RetryBackoff,
+	}
+	if cfg.CacheTTL > 0 {
+		client.cache = newResponseCache(cfg.CacheTTL)
+	}
+	return client
+}
+
+// NewClientFromEnv configures a client from MESSIER_API_URL and the optional MESSIER_TIMEOUT_SECONDS,
+// MESSIER_MAX_RETRIES, MESSIER_RETRY_BACKOFF_MS and MESSIER_CACHE_TTL_SECONDS.
+func NewClientFromEnv() *Client {
+	return NewClient(ClientConfig{
+		BaseURL:      os.Getenv("MESSIER_API_URL"),
+		Timeout:      time.Duration(intFromEnv("MESSIER_TIMEOUT_SECONDS", int(defaultTimeout/time.Second))) * time.Second,
+		MaxRetries:   intFromEnv("MESSIER_MAX_RETRIES", defaultMaxRetries),
+		RetryBackoff: time.Duration(intFromEnv("MESSIER_RETRY_BACKOFF_MS", int(defaultRetryBackoff/time.Millisecond))) * time.Millisecond,
+		CacheTTL:     time.Duration(intFromEnv("MESSIER_CACHE_TTL_SECONDS", int(defaultCacheTTL/time.Second))) * time.Second,
+	})
+}
+
+// Get fetches path with the query and decodes the JSON answer into resp.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, resp interface{}, authToken string) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, resp, authToken)
+}
+
+// GetNoCache is Get without the response cache, for requests that check whether a token still works.
+func (c *Client) GetNoCache(ctx context.Context, path string, query url.Values, resp interface{}, authToken string) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, resp, authToken, false)
+}
+
+// Post sends body as JSON and decodes the JSON answer into resp. Posts are never cached.
+func (c *Client) Post(ctx context.Context, path string, body interface{}, resp interface{}, authToken string) error {
+	return c.Do(ctx, http.MethodPost, path, nil, body, resp, authToken)
+}
+
+// InvalidateToken drops every cached response fetched with authToken, e.g. after it was revoked.
+func (c *Client) InvalidateToken(authToken string) {
+	if c.cache != nil {
+		c.cache.deleteToken(tokenHash(authToken))
+	}
+}
+
+// Do sends any request; GET responses go through the cache.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, reqBody interface{}, resp interface{}, authToken string) error {
+	return c.do(ctx, method, path, query, reqBody, resp, authToken, method == http.MethodGet)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, reqBody interface{}, resp interface{}, authToken string, useCache bool) error {
+	if c.baseURL == "" {
+		return fmt.Errorf("MESSIER_API_URL environment variable is not set")
+	}
+
+	endpoint := c.baseURL + "/" + strings.TrimLeft(path, "/")
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if reqBody != nil {
+		var err error
+		if payload, err = json.Marshal(reqBody); err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	cacheable := useCache && c.cache != nil
+	token := tokenHash(authToken)
+	if cacheable {
+		if body, ok := c.cache.get(token, endpoint); ok {
+			return decodeBody(body, resp)
+		}
+	}
+
+	body, err := c.doWithRetry(ctx, method, path, endpoint, payload, authToken)
+	if err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			c.InvalidateToken(authToken)
+		}
+		return err
+	}
+	if cacheable {
+		c.cache.put(token, endpoint, body)
+	}
+	return decodeBody(body, resp)
+}
+
+func (c *Client) doWithRetry(ctx context.Context, method, path, endpoint string, payload []byte, authToken string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.doOnce(ctx, method, path, endpoint, payload, authToken)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+		if attempt >= c.maxRetries || !isRetryable(ctx, err) {
+			return nil, lastErr
+		}
+
+		wait := c.backoff << attempt
+		wait += time.Duration(rand.Int63n(int64(wait)/2 + 1))
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		log.Printf("Messier %s %s failed (attempt %d/%d): %v, retrying in %s", method, path, attempt+1, c.maxRetries+1, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (gave up: %v)", lastErr, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// doOnce performs one attempt and returns the body of a 2xx answer, or an error and the Retry-After delay
+// Messier asked for.
+func (c *Client) doOnce(ctx context.Context, method, path, endpoint string, payload []byte, authToken string) ([]byte, time.Duration, error) {
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bodyReader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build messier request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %s %s: %v", ErrUnavailable, method, path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+		return nil, retryAfter(res.Header.Get("Retry-After")), &APIError{
+			Method:     method,
+			Path:       path,
+			StatusCode: res.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: failed to read %s %s: %v", ErrUnavailable, method, path, err)
+	}
+	return body, 0, nil
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRateLimited)
+}
+
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func decodeBody(body []byte, resp interface{}) error {
+	if resp == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return fmt.Errorf("%w: failed to decode body: %v", ErrBadResponse, err)
+	}
+	return nil
+}
+
+// tokenHash keeps raw tokens out of the cache keys.
+func tokenHash(authToken string) string {
+	sum := sha256.Sum256([]byte(authToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("Invalid %s=%q, using default %d", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"neptune/backend/messier/constants"
+	"os"
+	"time"
+)
+
+const timeLayout = "2006-01-02T15:04:05.999"
+
+// Fixture is the data the fake server answers with. Every password in it is accepted as is.
+type Fixture struct {
+	TokenTTLSeconds int                `json:"token_ttl_seconds"`
+	Assistants      []FixtureAssistant `json:"assistants"`
+	Students        []FixtureStudent   `json:"students"`
+	Semesters       []FixtureSemester  `json:"semesters"`
+	Classes         []FixtureClass     `json:"classes"`
+}
+
+// FixtureAssistant logs on with its initial, e.g. "XY24-1", whose generation is "24-1".
+type FixtureAssistant struct {
+	UserID     string `json:"user_id"`
+	BinusianID string `json:"binusian_id"`
+	Username   string `json:"username"`
+	Name       string `json:"name"`
+	Password   string `json:"password"`
+}
+
+type FixtureStudent struct {
+	BinusianID string `json:"binusian_id"`
+	NIM        string `json:"nim"`
+	Name       string `json:"name"`
+	Password   string `json:"password"`
+}
+
+// FixtureSemester times use the Messier layout, e.g. "2025-02-01T00:00:00". An empty End means open ended.
+type FixtureSemester struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+}
+
+// FixtureClass lists its members by NIM and assistant username.
+type FixtureClass struct {
+	ClassTransactionID string   `json:"class_transaction_id"`
+	ClassCode          string   `json:"class_code"`
+	SemesterID         string   `json:"semester_id"`
+	CourseOutlineID    string   `json:"course_outline_id"`
+	Students           []string `json:"students"`
+	Assistants         []string `json:"assistants"`
+}
+
+func LoadFixture(path string) (Fixture, error) {
+	var fixture Fixture
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixture, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return fixture, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// DefaultFixture has one assistant, three students and two classes of the first default course in a
+// semester that is running today, which is enough to log in and run every sync.
+func DefaultFixture() Fixture {
+	now := time.Now()
+	semesterID := "3f0c1a52-8d44-4b6e-9a51-6c2f5e1d0a01"
+	return Fixture{
+		TokenTTLSeconds: 3600,
+		Assistants: []FixtureAssistant{
+			{
+				UserID:     "7b1e0c9a-2f43-4d8e-b1a6-0d9c3e5f7a11",
+				BinusianID: "7b1e0c9a-2f43-4d8e-b1a6-0d9c3e5f7a12",
+				Username:   "XY24-1",
+				Name:       "Fake Assistant",
+				Password:   "password",
+			},
+		},
+		Students: []FixtureStudent{
+			{BinusianID: "a4d2f6b8-1c3e-4f5a-8b7d-9e0f1a2b3c01", NIM: "2600000001", Name: "Student One", Password: "password"},
+			{BinusianID: "a4d2f6b8-1c3e-4f5a-8b7d-9e0f1a2b3c02", NIM: "2600000002", Name: "Student Two", Password: "password"},
+			{BinusianID: "a4d2f6b8-1c3e-4f5a-8b7d-9e0f1a2b3c03", NIM: "2600000003", Name: "Student Three", Password: "password"},
+		},
+		Semesters: []FixtureSemester{
+			{
+				ID:          semesterID,
+				Description: "Fake Semester",
+				Start:       now.AddDate(0, -1, 0).Format(timeLayout),
+				End:         now.AddDate(0, 5, 0).Format(timeLayout),
+			},
+		},
+		Classes: []FixtureClass{
+			{
+				ClassTransactionID: "c1a55e01-0000-4000-8000-000000000001",
+				ClassCode:          "LA01",
+				SemesterID:         semesterID,
+				CourseOutlineID:    constants.AlgoprogID1,
+				Students:           []string{"2600000001", "2600000002"},
+				Assistants:         []string{"XY24-1"},
+			},
+			{
+				ClassTransactionID: "c1a55e01-0000-4000-8000-000000000002",
+				ClassCode:          "LA02",
+				SemesterID:         semesterID,
+				CourseOutlineID:    constants.AlgoprogID1,
+				Students:           []string{"2600000003"},
+				Assistants:         []string{"XY24-1"},
+			},
+		},
+	}
+}
@@ -0,0 +1,349 @@
+// Package fake is a stand-in for the Messier API that serves a fixture, for local development and for
+// exercising the sync and login flows without Binus credentials.
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type session struct {
+	username string
+	expires  time.Time
+}
+
+type fault struct {
+	status    int
+	remaining int
+}
+
+// Server answers the Messier endpoints the backend uses. Faults can be injected per path with FailNext,
+// or over HTTP with POST /_fake/fail?path=/Student/Class&status=503&times=2.
+type Server struct {
+	fixture Fixture
+	mux     *http.ServeMux
+
+	mu       sync.Mutex
+	sessions map[string]session
+	faults   map[string]*fault
+}
+
+func NewServer(fixture Fixture) *Server {
+	if fixture.TokenTTLSeconds <= 0 {
+		fixture.TokenTTLSeconds = 3600
+	}
+	s := &Server{
+		fixture:  fixture,
+		mux:      http.NewServeMux(),
+		sessions: make(map[string]session),
+		faults:   make(map[string]*fault),
+	}
+
+	s.mux.HandleFunc("/Account/LogOn", s.logOnAssistant)
+	s.mux.HandleFunc("/Account/LogOnBinusian", s.logOnStudent)
+	s.mux.HandleFunc("/Account/Me", s.authorized(s.me))
+	s.mux.HandleFunc("/Semester/GetSemestersWithActiveDate", s.authorized(s.semesters))
+	s.mux.HandleFunc("/ClassTransaction/GetClassBySemesterAndCourseOutline", s.authorized(s.classes))
+	s.mux.HandleFunc("/Student/Class", s.authorized(s.classStudents))
+	s.mux.HandleFunc("/Student/GetStudentClassTransactionWithAssistant", s.authorized(s.studentClasses))
+	s.mux.HandleFunc("/Assistant", s.authorized(s.assistantDetail))
+	s.mux.HandleFunc("/_fake/fail", s.injectFault)
+	s.mux.HandleFunc("/_fake/revoke", s.revoke)
+	return s
+}
+
+// FailNext makes the next times requests to path answer with status instead of the fixture.
+func (s *Server) FailNext(path string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = &fault{status: status, remaining: times}
+}
+
+// RevokeTokens invalidates every issued token, so the next authorized request gets a 401.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]session)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if status, ok := s.takeFault(r.URL.Path); ok {
+		log.Printf("fake messier: injected %d for %s %s", status, r.Method, r.URL.Path)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) takeFault(path string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.faults[path]
+	if !ok {
+		return 0, false
+	}
+	f.remaining--
+	if f.remaining <= 0 {
+		delete(s.faults, path)
+	}
+	return f.status, true
+}
+
+func (s *Server) authorized(next func(w http.ResponseWriter, r *http.Request, username string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		sess, ok := s.sessions[token]
+		s.mu.Unlock()
+		if !ok || time.Now().After(sess.expires) {
+			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r, sess.username)
+	}
+}
+
+func (s *Server) issueToken(username string) (string, time.Time) {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	token := "fake-" + hex.EncodeToString(buf)
+	expires := time.Now().Add(time.Duration(s.fixture.TokenTTLSeconds) * time.Second)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[token] = session{username: username, expires: expires}
+	return token, expires
+}
+
+type logOnRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func readLogOn(w http.ResponseWriter, r *http.Request) (logOnRequest, bool) {
+	var req logOnRequest
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return req, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func (s *Server) logOnAssistant(w http.ResponseWriter, r *http.Request) {
+	req, ok := readLogOn(w, r)
+	if !ok {
+		return
+	}
+	assistant, found := s.findAssistant(req.Username)
+	if !found || assistant.Password != req.Password {
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, _ := s.issueToken(assistant.Username)
+	writeJSON(w, map[string]interface{}{
+		"access_token":  token,
+		"token_type":    "bearer",
+		"expires_in":    s.fixture.TokenTTLSeconds,
+		"refresh_token": "",
+	})
+}
+
+func (s *Server) logOnStudent(w http.ResponseWriter, r *http.Request) {
+	req, ok := readLogOn(w, r)
+	if !ok {
+		return
+	}
+	student, found := s.findStudent(req.Username)
+	if !found || student.Password != req.Password {
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, expires := s.issueToken(student.NIM)
+	writeJSON(w, map[string]interface{}{
+		"User":  map[string]string{"UserId": student.BinusianID, "UserName": student.NIM, "Name": student.Name},
+		"Token": map[string]interface{}{"token": token, "expires": expires},
+	})
+}
+
+func (s *Server) me(w http.ResponseWriter, _ *http.Request, username string) {
+	assistant, found := s.findAssistant(username)
+	if !found {
+		http.Error(w, "not an assistant", http.StatusForbidden)
+		return
+	}
+	writeJSON(w, map[string]string{
+		"UserId":     assistant.UserID,
+		"BinusianId": assistant.BinusianID,
+		"Username":   assistant.Username,
+		"Name":       assistant.Name,
+	})
+}
+
+func (s *Server) semesters(w http.ResponseWriter, _ *http.Request, _ string) {
+	result := make([]map[string]interface{}, 0, len(s.fixture.Semesters))
+	for _, sem := range s.fixture.Semesters {
+		var end interface{}
+		if sem.End != "" {
+			end = sem.End
+		}
+		result = append(result, map[string]interface{}{
+			"SemesterID":  sem.ID,
+			"Description": sem.Description,
+			"Start":       sem.Start,
+			"End":         end,
+		})
+	}
+	writeJSON(w, result)
+}
+
+func (s *Server) classes(w http.ResponseWriter, r *http.Request, _ string) {
+	semesterID := r.URL.Query().Get("semesterId")
+	courseOutlineID := r.URL.Query().Get("courseOutlineId")
+	result := make([]map[string]string, 0)
+	for _, cl := range s.fixture.Classes {
+		if !strings.EqualFold(cl.SemesterID, semesterID) || !strings.EqualFold(cl.CourseOutlineID, courseOutlineID) {
+			continue
+		}
+		result = append(result, map[string]string{
+			"ClassName":          cl.ClassCode,
+			"ClassTransactionId": cl.ClassTransactionID,
+			"CourseOutlineId":    cl.CourseOutlineID,
+			"SemesterId":         cl.SemesterID,
+		})
+	}
+	writeJSON(w, result)
+}
+
+func (s *Server) classStudents(w http.ResponseWriter, r *http.Request, _ string) {
+	q := r.URL.Query()
+	result := make([]map[string]string, 0)
+	for _, cl := range s.fixture.Classes {
+		if !strings.EqualFold(cl.SemesterID, q.Get("semesterId")) || !strings.EqualFold(cl.CourseOutlineID, q.Get("coId")) || cl.ClassCode != q.Get("className") {
+			continue
+		}
+		for _, nim := range cl.Students {
+			if student, found := s.findStudent(nim); found {
+				result = append(result, map[string]string{"BinusianId": student.BinusianID, "Number": student.NIM, "Name": student.Name})
+			}
+		}
+	}
+	writeJSON(w, result)
+}
+
+func (s *Server) studentClasses(w http.ResponseWriter, r *http.Request, _ string) {
+	nim := r.URL.Query().Get("nim")
+	semesterID := r.URL.Query().Get("semesterId")
+	result := make([]map[string]interface{}, 0)
+	for _, cl := range s.fixture.Classes {
+		if !strings.EqualFold(cl.SemesterID, semesterID) || !contains(cl.Students, nim) {
+			continue
+		}
+		assistants := cl.Assistants
+		if assistants == nil {
+			assistants = []string{}
+		}
+		result = append(result, map[string]interface{}{
+			"Assistants":         assistants,
+			"ClassName":          cl.ClassCode,
+			"ClassTransactionId": cl.ClassTransactionID,
+			"CourseOutlineId":    cl.CourseOutlineID,
+		})
+	}
+	writeJSON(w, result)
+}
+
+// assistantDetail answers with an empty list for unknown initials, like Messier does.
+func (s *Server) assistantDetail(w http.ResponseWriter, r *http.Request, _ string) {
+	result := make([]map[string]string, 0, 1)
+	initial := r.URL.Query().Get("initial")
+	generation := r.URL.Query().Get("generation")
+	if assistant, found := s.findAssistant(initial); found && strings.HasSuffix(assistant.Username, generation) {
+		result = append(result, map[string]string{"Name": assistant.Name, "UserId": assistant.UserID, "Username": assistant.Username})
+	}
+	writeJSON(w, result)
+}
+
+func (s *Server) injectFault(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	status, err := strconv.Atoi(q.Get("status"))
+	if err != nil || status < 400 || status > 599 {
+		http.Error(w, "status must be between 400 and 599", http.StatusBadRequest)
+		return
+	}
+	times := 1
+	if raw := q.Get("times"); raw != "" {
+		if times, err = strconv.Atoi(raw); err != nil || times < 1 {
+			http.Error(w, "invalid times", http.StatusBadRequest)
+			return
+		}
+	}
+	if q.Get("path") == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+	s.FailNext(q.Get("path"), status, times)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.RevokeTokens()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) findAssistant(username string) (FixtureAssistant, bool) {
+	for _, a := range s.fixture.Assistants {
+		if strings.EqualFold(a.Username, username) {
+			return a, true
+		}
+	}
+	return FixtureAssistant{}, false
+}
+
+func (s *Server) findStudent(nim string) (FixtureStudent, bool) {
+	for _, st := range s.fixture.Students {
+		if st.NIM == nim {
+			return st, true
+		}
+	}
+	return FixtureStudent{}, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("fake messier: failed to write response: %v", err)
+	}
+}
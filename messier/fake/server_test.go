@@ -0,0 +1,218 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"neptune/backend/messier"
+	"neptune/backend/messier/auth/log_on"
+	"neptune/backend/messier/class"
+	"neptune/backend/messier/constants"
+	"neptune/backend/messier/fake"
+	"neptune/backend/messier/semester"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	assistantUsername = "XY24-1"
+	assistantPassword = "password"
+	semestersPath     = "/Semester/GetSemestersWithActiveDate"
+	logOnPath         = "/Account/LogOn"
+)
+
+// startFake serves fixture and returns the server and a client with fast retries pointed at it.
+func startFake(t *testing.T, fixture fake.Fixture, maxRetries int) (*fake.Server, *messier.Client) {
+	t.Helper()
+	server := fake.NewServer(fixture)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	client := messier.NewClient(messier.ClientConfig{
+		BaseURL:      httpServer.URL,
+		Timeout:      5 * time.Second,
+		MaxRetries:   maxRetries,
+		RetryBackoff: time.Millisecond,
+		CacheTTL:     time.Minute,
+	})
+	return server, client
+}
+
+func logOn(t *testing.T, client *messier.Client) string {
+	t.Helper()
+	resp, err := log_on.NewLogOnService(client).LogOnAssistant(context.Background(), assistantUsername, assistantPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken == "" {
+		t.Fatal("log on returned no token")
+	}
+	return resp.AccessToken
+}
+
+func TestLogOnAndSyncReads(t *testing.T) {
+	ctx := context.Background()
+	_, client := startFake(t, fake.DefaultFixture(), 0)
+
+	if _, err := log_on.NewLogOnService(client).LogOnAssistant(ctx, assistantUsername, "wrong"); !errors.Is(err, messier.ErrUnauthorized) {
+		t.Errorf("log on with a wrong password = %v, want ErrUnauthorized", err)
+	}
+	token := logOn(t, client)
+
+	semesters, err := semester.NewExternalSemesterService(client).GetSemesters(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(semesters) != 1 {
+		t.Fatalf("got %d semesters, want 1", len(semesters))
+	}
+	classService := class.NewMessierClassService(client)
+	classes, err := classService.GetClassesBySemesterAndCourseOutline(ctx, semesters[0].SemesterID, constants.AlgoprogID1, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 2 {
+		t.Fatalf("got %d classes, want 2", len(classes))
+	}
+	students, err := classService.GetStudentFromClassTransaction(ctx, semesters[0].SemesterID, constants.AlgoprogID1, "LA01", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(students) != 2 {
+		t.Errorf("got %d students in LA01, want 2", len(students))
+	}
+}
+
+func TestExpiredTokenIsRejected(t *testing.T) {
+	fixture := fake.DefaultFixture()
+	fixture.TokenTTLSeconds = 1
+	_, client := startFake(t, fixture, 0)
+	token := logOn(t, client)
+
+	var semesters []semester.GetSemestersResponse
+	if err := client.GetNoCache(context.Background(), semestersPath, nil, &semesters, token); err != nil {
+		t.Fatalf("fresh token rejected: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if err := client.GetNoCache(context.Background(), semestersPath, nil, &semesters, token); !errors.Is(err, messier.ErrUnauthorized) {
+		t.Errorf("expired token = %v, want ErrUnauthorized", err)
+	}
+	// Logging on again is all it takes to continue
+	if err := client.GetNoCache(context.Background(), semestersPath, nil, &semesters, logOn(t, client)); err != nil {
+		t.Errorf("new token rejected: %v", err)
+	}
+}
+
+func TestRevokedTokenDropsCacheAndNeedsNewLogOn(t *testing.T) {
+	ctx := context.Background()
+	server, client := startFake(t, fake.DefaultFixture(), 0)
+	token := logOn(t, client)
+
+	var semesters []semester.GetSemestersResponse
+	if err := client.Get(ctx, semestersPath, nil, &semesters, token); err != nil {
+		t.Fatal(err)
+	}
+	server.RevokeTokens()
+	// The cached answer hides the revocation until a request reaches Messier
+	if err := client.Get(ctx, semestersPath, nil, &semesters, token); err != nil {
+		t.Fatalf("cached read = %v, want the cached answer", err)
+	}
+	if err := client.GetNoCache(ctx, semestersPath, nil, &semesters, token); !errors.Is(err, messier.ErrUnauthorized) {
+		t.Fatalf("revoked token = %v, want ErrUnauthorized", err)
+	}
+	// The 401 dropped the cached answers of the token
+	if err := client.Get(ctx, semestersPath, nil, &semesters, token); !errors.Is(err, messier.ErrUnauthorized) {
+		t.Errorf("cached read after the 401 = %v, want ErrUnauthorized", err)
+	}
+	if err := client.Get(ctx, semestersPath, nil, &semesters, logOn(t, client)); err != nil {
+		t.Errorf("new token rejected: %v", err)
+	}
+}
+
+func TestTransientFailuresAreRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		times   int
+		wantErr error
+	}{
+		{"log on after 503", logOnPath, http.StatusServiceUnavailable, 2, nil},
+		{"log on after 429", logOnPath, http.StatusTooManyRequests, 1, nil},
+		{"log on gives up", logOnPath, http.StatusBadGateway, 3, messier.ErrUnavailable},
+		{"sync after 500", semestersPath, http.StatusInternalServerError, 2, nil},
+		{"sync after 429", semestersPath, http.StatusTooManyRequests, 1, nil},
+		{"sync gives up", semestersPath, http.StatusTooManyRequests, 3, messier.ErrRateLimited},
+		{"bad request is not retried", semestersPath, http.StatusBadRequest, 1, messier.ErrBadResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, client := startFake(t, fake.DefaultFixture(), 2)
+			token := ""
+			if tt.path != logOnPath {
+				token = logOn(t, client)
+			}
+			server.FailNext(tt.path, tt.status, tt.times)
+
+			var err error
+			if tt.path == logOnPath {
+				_, err = log_on.NewLogOnService(client).LogOnAssistant(ctx, assistantUsername, assistantPassword)
+			} else {
+				_, err = semester.NewExternalSemesterService(client).GetSemesters(ctx, token)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("err = %v, want success after %d failures", err, tt.times)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFaultInjectionOverHTTP(t *testing.T) {
+	httpServer := httptest.NewServer(fake.NewServer(fake.DefaultFixture()))
+	defer httpServer.Close()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"path=/Student/Class&status=503&times=2", http.StatusNoContent},
+		{"path=/Student/Class&status=200", http.StatusBadRequest},
+		{"path=/Student/Class&status=503&times=0", http.StatusBadRequest},
+		{"status=503", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		res, err := http.Post(httpServer.URL+"/_fake/fail?"+tt.query, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tt.want {
+			t.Errorf("POST /_fake/fail?%s = %d, want %d", tt.query, res.StatusCode, tt.want)
+		}
+	}
+
+	// The accepted fault answers the next two requests, then the fixture answers again
+	for i, want := range []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusUnauthorized} {
+		res, err := http.Get(httpServer.URL + "/Student/Class")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != want {
+			t.Errorf("request %d = %d, want %d", i+1, res.StatusCode, want)
+		}
+	}
+
+	res, err := http.Post(httpServer.URL+"/_fake/revoke", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("POST /_fake/revoke = %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+}
@@ -3,34 +3,24 @@ package semester
 import (
 	"context"
 	"fmt"
+	"log"
 	"neptune/backend/messier"
-	"os"
-	"strings"
 )
 
 type semesterServiceImpl struct {
+	client *messier.Client
 }
 
-func NewExternalSemesterService() MessierSemesterService {
-	return &semesterServiceImpl{}
+func NewExternalSemesterService(client *messier.Client) MessierSemesterService {
+	return &semesterServiceImpl{client: client}
 }
 
 func (s semesterServiceImpl) GetSemesters(ctx context.Context, authToken string) ([]GetSemestersResponse, error) {
 	var result []GetSemestersResponse
-
-	baseURL := strings.TrimRight(os.Getenv("MESSIER_API_URL"), "/")
-	url := fmt.Sprintf("%s/Semester/GetSemestersWithActiveDate", baseURL)
-	fmt.Printf("Fetching semesters from: %s\n", url)
-	err := messier.SendRequest(ctx, "GET", url, nil, &result, authToken)
-	if err != nil {
-		fmt.Printf("Error fetching semesters from Messier: %v\n", err)
-		return nil, err
-	}
-
-	fmt.Printf("Received %d semesters from Messier API\n", len(result))
-	for i, semester := range result {
-		fmt.Printf("Messier Semester %d: ID=%s, Description=%s\n", i+1, semester.SemesterID, semester.Description)
+	if err := s.client.Get(ctx, "/Semester/GetSemestersWithActiveDate", nil, &result, authToken); err != nil {
+		return nil, fmt.Errorf("failed to get semesters: %w", err)
 	}
 
+	log.Printf("Received %d semesters from Messier API", len(result))
 	return result, nil
 }
@@ -0,0 +1,29 @@
+package auditModel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog is one mutating admin request: who made it, what it changed and how it ended. Snapshots are
+// JSON and may be empty when the handler had nothing to compare.
+type AuditLog struct {
+	ID            uint       `gorm:"primaryKey"`
+	ActorID       *uuid.UUID `gorm:"type:uuid;index"`
+	ActorUsername string
+	ActorRole     string
+	TokenID       *uuid.UUID `gorm:"type:uuid"` // Set when the request used a personal access token
+	Action        string     `gorm:"not null;index"`
+	TargetType    string     `gorm:"index"`
+	TargetID      string     `gorm:"index"`
+	Method        string     `gorm:"type:varchar(10);not null"`
+	Path          string     `gorm:"not null"`
+	Status        int        `gorm:"not null"`
+	Before        string     `gorm:"type:text"`
+	After         string     `gorm:"type:text"`
+	IPAddress     string
+	Forced        bool      `gorm:"not null;default:false;index"` // The actor overrode a guard, e.g. deleting a running contest
+	ForceReason   string    `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"not null;index"`
+}
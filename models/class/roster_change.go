@@ -0,0 +1,34 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type RosterChangeType string
+
+const (
+	RosterChangeAdded   RosterChangeType = "added"
+	RosterChangeRemoved RosterChangeType = "removed"
+	RosterChangeRenamed RosterChangeType = "renamed"
+)
+
+const (
+	RosterMemberStudent   = "student"
+	RosterMemberAssistant = "assistant"
+)
+
+// RosterChange records one change a roster sync applied to a class. Changes applied together share a
+// BatchID.
+type RosterChange struct {
+	ID                 uuid.UUID        `gorm:"primaryKey;type:uuid"`
+	BatchID            uuid.UUID        `gorm:"type:uuid;not null;index"`
+	ClassTransactionID uuid.UUID        `gorm:"type:uuid;not null;index:idx_roster_changes_class_created,priority:1"`
+	MemberType         string           `gorm:"type:varchar(20);not null"` // student or assistant
+	Change             RosterChangeType `gorm:"type:varchar(20);not null"`
+	UserID             uuid.UUID        `gorm:"type:uuid;not null"`
+	Username           string           `gorm:"not null"`
+	OldName            string
+	NewName            string
+	CreatedAt          time.Time `gorm:"not null;index:idx_roster_changes_class_created,priority:2"`
+}
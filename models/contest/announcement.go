@@ -0,0 +1,46 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Announcement is a notice to the participants of a contest. It becomes visible at PublishAt, to every
+// class the contest runs in (and the global contest) when AllClasses is set, otherwise only to the
+// classes in Targets.
+type Announcement struct {
+	ID          uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	ContestID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Title       string     `gorm:"not null"`
+	Body        string     `gorm:"type:text"`
+	AllClasses  bool       `gorm:"not null"`
+	PublishAt   time.Time  `gorm:"not null;index"`
+	PublishedAt *time.Time // set once it was pushed to connected clients
+	CreatedBy   *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Targets []AnnouncementTarget `gorm:"foreignKey:AnnouncementID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+type AnnouncementTarget struct {
+	AnnouncementID     uuid.UUID `gorm:"primaryKey;type:uuid"`
+	ClassTransactionID uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+}
+
+// Reaches reports whether the announcement is meant for the class, or the global contest when
+// classTransactionID is nil.
+func (a Announcement) Reaches(classTransactionID *uuid.UUID) bool {
+	if a.AllClasses {
+		return true
+	}
+	if classTransactionID == nil {
+		return false
+	}
+	for _, target := range a.Targets {
+		if target.ClassTransactionID == *classTransactionID {
+			return true
+		}
+	}
+	return false
+}
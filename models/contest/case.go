@@ -10,10 +10,24 @@ type Case struct { // Renamed from "Problem" to "Case" as per your terminology
 	ID            uuid.UUID `gorm:"primaryKey;type:uuid"`
 	Name          string    `gorm:"not null"`
 	Description   string    `gorm:"type:text"`         // Problem description (e.g., Markdown)
-	PDFFileUrl    string    `gorm:"type:varchar(255)"` // URL to the problem statement PDF
+	PDFFileUrl    string    `gorm:"type:varchar(255)"` // URL to the problem statement PDF, optional
 	TimeLimitMs   int       `gorm:"not null"`          // Time limit in milliseconds
 	MemoryLimitMb int       `gorm:"not null"`          // Memory limit in megabytes
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"` // For soft deletes
+
+	// Structured statement, Markdown with $...$ / $$...$$ math
+	Statement    string `gorm:"type:text"`
+	InputFormat  string `gorm:"type:text"`
+	OutputFormat string `gorm:"type:text"`
+	Constraints  string `gorm:"type:text"`
+	// StatementRevision is the number of the latest CaseStatementRevision, 0 before the first one
+	StatementRevision int `gorm:"not null;default:0"`
+
+	// Problem bank metadata
+	Difficulty CaseDifficulty `gorm:"type:varchar(20);not null;default:'';index"`
+	Author     string         `gorm:"type:varchar(100);not null;default:''"`
+	Source     string         `gorm:"type:varchar(255);not null;default:''"` // e.g. "ICPC Jakarta 2023"
+	Tags       []CaseTag      `gorm:"foreignKey:CaseID;references:ID;constraint:OnDelete:CASCADE"`
 }
@@ -0,0 +1,18 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// CaseAttachment is an extra file handed out with a case statement, such as an image, a data file or a
+// grader stub.
+type CaseAttachment struct {
+	ID          uuid.UUID `gorm:"primaryKey;type:uuid"`
+	CaseID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	FileName    string    `gorm:"type:varchar(255);not null"`
+	FileUrl     string    `gorm:"type:varchar(255);not null"` // Stored blob path, never exposed
+	ContentType string    `gorm:"type:varchar(100);not null"`
+	SizeBytes   int64     `gorm:"not null"`
+	CreatedAt   time.Time
+}
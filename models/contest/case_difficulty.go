@@ -0,0 +1,19 @@
+package contestModel
+
+// CaseDifficulty is how hard a problem in the bank is. Empty means it has not been rated.
+type CaseDifficulty string
+
+const (
+	CaseEasy   CaseDifficulty = "easy"
+	CaseMedium CaseDifficulty = "medium"
+	CaseHard   CaseDifficulty = "hard"
+)
+
+// Valid reports whether d is a known difficulty or unrated.
+func (d CaseDifficulty) Valid() bool {
+	switch d {
+	case "", CaseEasy, CaseMedium, CaseHard:
+		return true
+	}
+	return false
+}
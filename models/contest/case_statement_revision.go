@@ -0,0 +1,21 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// CaseStatementRevision is a snapshot of a case statement, saved every time the statement changes so
+// edits made during a contest can be traced and compared.
+type CaseStatementRevision struct {
+	ID           uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	CaseID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_case_statement_revision"`
+	Revision     int        `gorm:"not null;uniqueIndex:idx_case_statement_revision"`
+	Name         string     `gorm:"not null"`
+	Statement    string     `gorm:"type:text"`
+	InputFormat  string     `gorm:"type:text"`
+	OutputFormat string     `gorm:"type:text"`
+	Constraints  string     `gorm:"type:text"`
+	EditedBy     *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,9 @@
+package contestModel
+
+import "github.com/google/uuid"
+
+// CaseTag is a topic a problem in the bank covers, e.g. "graphs" or "dp". Tags are stored lower case.
+type CaseTag struct {
+	CaseID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Tag    string    `gorm:"primaryKey;type:varchar(50);index"`
+}
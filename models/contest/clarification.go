@@ -0,0 +1,39 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"time"
+)
+
+type ClarificationVisibility string
+
+const (
+	// ClarificationPrivate answers are only shown to the student who asked, and to staff.
+	ClarificationPrivate ClarificationVisibility = "private"
+	// ClarificationBroadcast answers are shown to everyone in the class contest or global contest.
+	ClarificationBroadcast ClarificationVisibility = "broadcast"
+)
+
+// Clarification is a question a student asked during a contest, optionally about one of its cases,
+// and the staff answer to it.
+type Clarification struct {
+	ID                 uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	ContestID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ClassTransactionID *uuid.UUID `gorm:"type:uuid;index"` // nil for a global contest
+	CaseID             *uuid.UUID `gorm:"type:uuid"`       // nil for a general question
+	AskedBy            uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Asker              user.User  `gorm:"foreignKey:AskedBy;references:ID;constraint:OnDelete:CASCADE"`
+	Question           string     `gorm:"type:text;not null"`
+	Answer             string     `gorm:"type:text"`
+	AnsweredBy         *uuid.UUID `gorm:"type:uuid"`
+	AnsweredAt         *time.Time
+	Visibility         ClarificationVisibility `gorm:"type:varchar(20);not null;default:'private'"`
+	CreatedAt          time.Time               `gorm:"not null;index"`
+	UpdatedAt          time.Time
+}
+
+// Answered reports whether staff have replied to the question.
+func (c Clarification) Answered() bool {
+	return c.AnsweredAt != nil
+}
@@ -0,0 +1,42 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"time"
+)
+
+// ClassContestOverride gives one student of a class their own window for a class contest. StartTime and
+// EndTime replace the class times; a StartTime alone keeps the class duration, for students who start
+// late. ExtraMinutes is added to the end on top of that.
+type ClassContestOverride struct {
+	ID                 uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	ClassTransactionID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_class_contest_override,priority:1"`
+	ContestID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_class_contest_override,priority:2"`
+	UserID             uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_class_contest_override,priority:3"`
+	User               user.User  `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	StartTime          *time.Time // nil keeps the class start
+	EndTime            *time.Time // nil keeps the class end, or the class duration after StartTime
+	ExtraMinutes       int        `gorm:"not null;default:0"`
+	Reason             string
+	GrantedBy          *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// WindowFor returns when the user may take part in the class contest: the class window, or the one the
+// override gives them when it is not nil.
+func (cc ClassContest) WindowFor(override *ClassContestOverride) (time.Time, time.Time) {
+	if override == nil {
+		return cc.StartTime, cc.EndTime
+	}
+	start, end := cc.StartTime, cc.EndTime
+	if override.StartTime != nil {
+		start = *override.StartTime
+		end = start.Add(cc.EndTime.Sub(cc.StartTime))
+	}
+	if override.EndTime != nil {
+		end = *override.EndTime
+	}
+	return start, end.Add(time.Duration(override.ExtraMinutes) * time.Minute)
+}
@@ -0,0 +1,42 @@
+package contestModel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowFor(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 3, 2, hour, minute, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	class := ClassContest{StartTime: at(9, 0), EndTime: at(11, 0)}
+
+	tests := []struct {
+		name      string
+		override  *ClassContestOverride
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"no override", nil, at(9, 0), at(11, 0)},
+		{"extra time only", &ClassContestOverride{ExtraMinutes: 30}, at(9, 0), at(11, 30)},
+		{"late start keeps the duration", &ClassContestOverride{StartTime: ptr(at(10, 0))}, at(10, 0), at(12, 0)},
+		{"late start with extra time", &ClassContestOverride{StartTime: ptr(at(10, 0)), ExtraMinutes: 15}, at(10, 0), at(12, 15)},
+		{"early start keeps the duration", &ClassContestOverride{StartTime: ptr(at(7, 0))}, at(7, 0), at(9, 0)},
+		{"end only", &ClassContestOverride{EndTime: ptr(at(10, 0))}, at(9, 0), at(10, 0)},
+		{"end and extra time add up", &ClassContestOverride{EndTime: ptr(at(12, 0)), ExtraMinutes: 30}, at(9, 0), at(12, 30)},
+		{"start and end replace the class window", &ClassContestOverride{StartTime: ptr(at(13, 0)), EndTime: ptr(at(14, 0))}, at(13, 0), at(14, 0)},
+		{"window inside the class window", &ClassContestOverride{StartTime: ptr(at(9, 30)), EndTime: ptr(at(10, 30))}, at(9, 30), at(10, 30)},
+		{"window spanning the class window", &ClassContestOverride{StartTime: ptr(at(8, 0)), EndTime: ptr(at(12, 0)), ExtraMinutes: 10}, at(8, 0), at(12, 10)},
+		// SetOverride refuses windows that do not end after they start; WindowFor reports them as they are
+		{"end before start", &ClassContestOverride{StartTime: ptr(at(10, 0)), EndTime: ptr(at(9, 0))}, at(10, 0), at(9, 0)},
+		{"negative extra time", &ClassContestOverride{ExtraMinutes: -30}, at(9, 0), at(10, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := class.WindowFor(tt.override)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("WindowFor = %s - %s, want %s - %s", start.Format("15:04"), end.Format("15:04"),
+					tt.wantStart.Format("15:04"), tt.wantEnd.Format("15:04"))
+			}
+		})
+	}
+}
@@ -3,17 +3,27 @@ package contestModel
 import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	courseModel "neptune/backend/models/course"
 	"time"
 )
 
 type Contest struct {
-	ID          uuid.UUID `gorm:"primaryKey;type:uuid;"`
-	Name        string    `gorm:"not null"`
-	Description string    `gorm:"type:text"`                 // Optional description
-	Scope       string    `gorm:"type:varchar(50);not null"` // e.g., "public", "class"
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   gorm.DeletedAt `gorm:"index"` // For soft deletes
+	ID          uuid.UUID  `gorm:"primaryKey;type:uuid;"`
+	Name        string     `gorm:"not null"`
+	Description string     `gorm:"type:text"`                 // Optional description
+	Scope       string     `gorm:"type:varchar(50);not null"` // e.g., "public", "class"
+	CourseID    *uuid.UUID `gorm:"type:uuid;index"`           // Optional course the contest belongs to
+	// StatusOverride holds a contest as draft or archived whatever its schedule; empty follows the schedule
+	StatusOverride ContestStatus     `gorm:"type:varchar(20);not null;default:''"`
+	Visibility     ContestVisibility `gorm:"type:varchar(20);not null;default:'public'"`
+	// AccessCodeHash is the bcrypt hash of the code users must enter once to see a global contest; empty
+	// when no code is needed
+	AccessCodeHash string `gorm:"type:varchar(100);not null;default:''" json:"-"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"` // For soft deletes
+
+	Course *courseModel.Course `gorm:"foreignKey:CourseID;references:ID;constraint:OnDelete:SET NULL;"`
 
 	// Many-to-many relationship with Case via ContestCase
 	GlobalContestDetail *GlobalContestDetail `gorm:"foreignKey:ContestID;references:ID"`
@@ -0,0 +1,13 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ContestAccessGrant records that a user entered the access code of a global contest.
+type ContestAccessGrant struct {
+	ContestID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+	CreatedAt time.Time
+}
@@ -6,9 +6,15 @@ import (
 )
 
 type ContestCase struct {
-	ContestID   uuid.UUID `gorm:"primaryKey;type:uuid;"` // Composite primary key part 1, FK to Contest
-	CaseID      uuid.UUID `gorm:"primaryKey;type:uuid;"` // Composite primary key part 2, FK to Case
-	ProblemCode string    `gorm:"not null"`              // e.g., "A", "B", "C" for this problem in this contest
+	ContestID   uuid.UUID `gorm:"primaryKey;type:uuid;uniqueIndex:idx_contest_problem_code"`      // Composite primary key part 1, FK to Contest
+	CaseID      uuid.UUID `gorm:"primaryKey;type:uuid;"`                                          // Composite primary key part 2, FK to Case
+	ProblemCode string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_contest_problem_code"` // e.g., "A", "B", "C", unique per contest
+	Position    int       `gorm:"not null;default:0"`                                             // Display order within the contest, starting at 0
+	Color       string    `gorm:"type:varchar(7);not null;default:''"`                            // Optional display color, e.g. "#FF8800"
+
+	// Points is what solving the problem is worth. It is a pointer so an explicit 0 is stored as 0; the
+	// column default only fills rows that existed before points did.
+	Points *int `gorm:"not null;default:100"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
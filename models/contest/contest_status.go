@@ -0,0 +1,36 @@
+package contestModel
+
+import "time"
+
+// ContestStatus is where a contest is in its lifecycle: draft -> scheduled -> running -> ended -> archived.
+type ContestStatus string
+
+const (
+	// ContestDraft contests are not scheduled yet, or are held back by an admin.
+	ContestDraft ContestStatus = "draft"
+	// ContestScheduled contests have a schedule that has not started.
+	ContestScheduled ContestStatus = "scheduled"
+	// ContestRunning contests are inside at least one class or student window.
+	ContestRunning ContestStatus = "running"
+	// ContestEnded contests are past every window.
+	ContestEnded ContestStatus = "ended"
+	// ContestArchived contests were put away by an admin; they only take upsolve submissions.
+	ContestArchived ContestStatus = "archived"
+)
+
+// StatusAt works out the status at now from the manual override and the span of every window of the
+// contest. A contest without any window is a draft.
+func StatusAt(override ContestStatus, start, end *time.Time, now time.Time) ContestStatus {
+	switch {
+	case override == ContestDraft || override == ContestArchived:
+		return override
+	case start == nil || end == nil:
+		return ContestDraft
+	case now.Before(*start):
+		return ContestScheduled
+	case !now.After(*end):
+		return ContestRunning
+	default:
+		return ContestEnded
+	}
+}
@@ -0,0 +1,38 @@
+package contestModel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusAt(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	tests := []struct {
+		name     string
+		override ContestStatus
+		start    *time.Time
+		end      *time.Time
+		now      time.Time
+		want     ContestStatus
+	}{
+		{"no window", "", nil, nil, start, ContestDraft},
+		{"start without end", "", &start, nil, start, ContestDraft},
+		{"before start", "", &start, &end, start.Add(-time.Second), ContestScheduled},
+		{"at start", "", &start, &end, start, ContestRunning},
+		{"inside", "", &start, &end, start.Add(time.Hour), ContestRunning},
+		{"at end", "", &start, &end, end, ContestRunning},
+		{"after end", "", &start, &end, end.Add(time.Second), ContestEnded},
+		{"held back as draft while running", ContestDraft, &start, &end, start.Add(time.Hour), ContestDraft},
+		{"archived while running", ContestArchived, &start, &end, start.Add(time.Hour), ContestArchived},
+		{"archived without a window", ContestArchived, nil, nil, start, ContestArchived},
+		{"other overrides follow the schedule", ContestRunning, &start, &end, end.Add(time.Second), ContestEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusAt(tt.override, tt.start, tt.end, tt.now); got != tt.want {
+				t.Errorf("StatusAt = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
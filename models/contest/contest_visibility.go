@@ -0,0 +1,13 @@
+package contestModel
+
+// ContestVisibility decides who can find a contest.
+type ContestVisibility string
+
+const (
+	// ContestPublic contests are listed to everyone who may take part.
+	ContestPublic ContestVisibility = "public"
+	// ContestUnlisted contests are left out of lists but open to anyone allowed in who has the link.
+	ContestUnlisted ContestVisibility = "unlisted"
+	// ContestHidden contests are only seen by staff.
+	ContestHidden ContestVisibility = "hidden"
+)
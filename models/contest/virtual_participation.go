@@ -0,0 +1,23 @@
+package contestModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// VirtualParticipation is a personal timed run of a finished contest. It lasts as long as the user's
+// own window did, and its submissions are timed from StartedAt.
+type VirtualParticipation struct {
+	ID                 uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	ContestID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ClassTransactionID *uuid.UUID `gorm:"type:uuid;index"` // nil for a global contest
+	UserID             uuid.UUID  `gorm:"type:uuid;not null;index"`
+	StartedAt          time.Time  `gorm:"not null"`
+	EndsAt             time.Time  `gorm:"not null"`
+	CreatedAt          time.Time
+}
+
+// Active reports whether the run is still going at t.
+func (v VirtualParticipation) Active(t time.Time) bool {
+	return !t.Before(v.StartedAt) && t.Before(v.EndsAt)
+}
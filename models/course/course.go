@@ -0,0 +1,25 @@
+package courseModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Course is a Messier course outline whose classes are mirrored and that contests can belong to.
+type Course struct {
+	ID              uuid.UUID `gorm:"primaryKey;type:uuid"`
+	CourseOutlineID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	Code            string    `gorm:"type:varchar(50);not null;uniqueIndex"` // e.g. COMP6047001
+	Name            string    `gorm:"not null"`
+	Active          bool      `gorm:"not null"` // Inactive courses are neither synced nor listed
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	// Semesters restricts the course to these semesters. A course without semesters runs every semester.
+	Semesters []CourseSemester `gorm:"foreignKey:CourseID;references:ID;constraint:OnDelete:CASCADE;"`
+}
+
+type CourseSemester struct {
+	CourseID   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	SemesterID string    `gorm:"primaryKey;type:uuid"`
+}
@@ -20,4 +20,5 @@ type LeaderboardRow struct {
 	SolvedCount    int                   `json:"solved_count"`
 	TotalPenalty   int                   `json:"total_penalty"`
 	ProblemResults map[string]CaseResult `json:"case_results"`
+	Virtual        bool                  `json:"virtual,omitempty"` // the row of a virtual participant
 }
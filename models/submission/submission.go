@@ -21,6 +21,18 @@ func (s SubmissionStatus) String() string {
 	return string(s)
 }
 
+// SubmissionMode tells which standings a submission counts for.
+type SubmissionMode string
+
+const (
+	// SubmissionModeOfficial submissions were made inside the contest window and count for the leaderboard.
+	SubmissionModeOfficial SubmissionMode = "official"
+	// SubmissionModeVirtual submissions belong to a virtual participation and are timed from its start.
+	SubmissionModeVirtual SubmissionMode = "virtual"
+	// SubmissionModeUpsolve submissions were made after the contest and are judged but never ranked.
+	SubmissionModeUpsolve SubmissionMode = "upsolve"
+)
+
 type Submission struct {
 	ID                 uuid.UUID        `gorm:"primaryKey;type:uuid;"`
 	CaseID             uuid.UUID        `gorm:"type:uuid;not null"`
@@ -31,7 +43,18 @@ type Submission struct {
 	Score              int              `gorm:"default:0"`
 	ContestID          *uuid.UUID       `gorm:"type:uuid"`
 	ClassTransactionID *uuid.UUID       `gorm:"type:uuid"`
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
-	SubmissionResults  []SubmissionResult `gorm:"foreignKey:SubmissionID"`
+	Mode               SubmissionMode   `gorm:"type:varchar(20);not null;default:'official';index"`
+	// VirtualParticipationID is set for virtual submissions.
+	VirtualParticipationID *uuid.UUID `gorm:"type:uuid;index"`
+
+	// Judging lease: the worker currently judging this submission and its last heartbeat.
+	// JudgingAttempt is bumped every time a worker acquires the lease, so results
+	// produced by an older attempt can be recognised and dropped.
+	JudgingWorkerID    string     `gorm:"type:varchar(100);index"`
+	JudgingHeartbeatAt *time.Time `gorm:"index"`
+	JudgingAttempt     int        `gorm:"default:0;not null"`
+
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	SubmissionResults []SubmissionResult `gorm:"foreignKey:SubmissionID"`
 }
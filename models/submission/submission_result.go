@@ -8,7 +8,7 @@ type SubmissionResult struct {
 	Status         SubmissionStatus `gorm:"type:varchar(50);not null"`
 	TimeSeconds    float64
 	MemoryKB       int
-	Input          string `gorm:"type:text"`
-	ExpectedOutput string `gorm:"type:text"`
+	Input          string `gorm:"type:text"` // the first 64 KiB of the testcase input
+	ExpectedOutput string `gorm:"type:text"` // the first 64 KiB of the expected output
 	ActualOutput   string `gorm:"type:text"`
 }
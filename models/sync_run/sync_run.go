@@ -0,0 +1,51 @@
+package syncRunModel
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type SyncRunStatus string
+
+const (
+	SyncRunStatusRunning   SyncRunStatus = "running"
+	SyncRunStatusSucceeded SyncRunStatus = "succeeded"
+	SyncRunStatusPartial   SyncRunStatus = "partial" // Finished, but some classes still failed after retries
+	SyncRunStatusFailed    SyncRunStatus = "failed"
+)
+
+const (
+	SyncTriggerScheduled = "scheduled"
+	SyncTriggerManual    = "manual"
+)
+
+// SyncRun is one execution of the Messier sync pipeline (semesters -> classes -> students -> assistants).
+// The partial unique index allows a single running run across all replicas.
+type SyncRun struct {
+	ID          uuid.UUID     `gorm:"primaryKey;type:uuid;"`
+	Trigger     string        `gorm:"type:varchar(20);not null"`
+	TriggeredBy *uuid.UUID    `gorm:"type:uuid"` // Admin who pressed "run now", nil for scheduled runs
+	Status      SyncRunStatus `gorm:"type:varchar(20);not null;uniqueIndex:idx_sync_runs_single_running,where:status = 'running'"`
+	SemesterID  string        `gorm:"type:varchar(50)"`
+	Error       string        `gorm:"type:text"`
+	StartedAt   time.Time     `gorm:"not null;index"`
+	FinishedAt  *time.Time
+	Steps       []SyncRunStep `gorm:"foreignKey:SyncRunID;constraint:OnDelete:CASCADE;"`
+}
+
+// SyncRunStep records the counts and errors of one pipeline step, per course where relevant.
+type SyncRunStep struct {
+	ID              uuid.UUID     `gorm:"primaryKey;type:uuid;"`
+	SyncRunID       uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Position        int           `gorm:"not null"`
+	Name            string        `gorm:"type:varchar(50);not null"` // semesters, classes, students, assistants
+	CourseOutlineID string        `gorm:"type:varchar(50)"`
+	Status          SyncRunStatus `gorm:"type:varchar(20);not null"`
+	Attempts        int           `gorm:"not null;default:0"`
+	Processed       int           `gorm:"not null;default:0"`
+	Synced          int           `gorm:"not null;default:0"`
+	Failed          int           `gorm:"not null;default:0"`
+	Errors          string        `gorm:"type:text"` // One error per line
+	StartedAt       time.Time     `gorm:"not null"`
+	FinishedAt      *time.Time
+}
@@ -10,5 +10,6 @@ type TestCase struct {
 	Number    int       `gorm:"primaryKey;type:int;"`
 	InputUrl  string    `gorm:"type:varchar(255);not null"`
 	OutputUrl string    `gorm:"type:varchar(255);not null"`
+	IsSample  bool      `gorm:"not null;default:false"` // Shown in the statement to everyone who can see the case
 	CreatedAt time.Time `gorm:"autoCreateTime;not null"`
 }
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocalCredential is the bcrypt password of a local account.
+type LocalCredential struct {
+	UserID            uuid.UUID `gorm:"primaryKey;type:uuid"`
+	User              User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	PasswordHash      string    `gorm:"not null"`
+	PasswordChangedAt time.Time `gorm:"not null"`
+}
+
+// PasswordResetToken lets a local account holder set a new password once. Only the SHA-256 hash of the
+// token is stored; the token itself is shown to the admin who issued it.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User      User       `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	TokenHash string     `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // Set once the token was used or superseded by a newer one
+	CreatedBy *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time
+}
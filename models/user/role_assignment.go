@@ -0,0 +1,45 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoleAssignment grants a user a role on top of the one their auth provider gives them. Lecturer grants
+// may be limited to one class; Admin and Assistant grants are always global.
+type RoleAssignment struct {
+	ID                 uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	UserID             uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User               User       `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	Role               Role       `gorm:"type:varchar(20);not null"`
+	ClassTransactionID *uuid.UUID `gorm:"type:uuid;index"` // nil for a global grant
+	GrantedBy          *uuid.UUID `gorm:"type:uuid"`
+	GrantedAt          time.Time  `gorm:"not null"`
+}
+
+// Global reports whether the assignment applies to every class.
+func (a RoleAssignment) Global() bool {
+	return a.ClassTransactionID == nil
+}
+
+type RoleAuditAction string
+
+const (
+	RoleAuditGrant  RoleAuditAction = "grant"
+	RoleAuditRevoke RoleAuditAction = "revoke"
+)
+
+// RoleAuditEntry records one grant or revoke. Entries are never updated or deleted, and keep the
+// assignment's details so they still read correctly after it is gone.
+type RoleAuditEntry struct {
+	ID                 uuid.UUID       `gorm:"primaryKey;type:uuid"`
+	AssignmentID       uuid.UUID       `gorm:"type:uuid;not null;index"`
+	UserID             uuid.UUID       `gorm:"type:uuid;not null;index"`
+	Role               Role            `gorm:"type:varchar(20);not null"`
+	ClassTransactionID *uuid.UUID      `gorm:"type:uuid"`
+	Action             RoleAuditAction `gorm:"type:varchar(10);not null"`
+	ActorID            *uuid.UUID      `gorm:"type:uuid;index"`
+	Reason             string
+	CreatedAt          time.Time `gorm:"not null;index"`
+}
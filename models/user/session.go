@@ -1,9 +1,46 @@
 package user
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type MessierToken struct {
 	UserID              string    `json:"id" gorm:"primaryKey;type:uuid"`
 	MessierAccessToken  string    `json:"token" gorm:"uniqueIndex;not null"`
 	MessierTokenExpires time.Time `json:"expires_at" gorm:"not null"`
 }
+
+// Session is one login of a user on one device. Access tokens carry its ID and stop working once it is
+// revoked. The refresh token rotates on every use; only hashes of the current and the previous one are
+// kept, so a replayed old token can be recognised and the session revoked.
+type Session struct {
+	ID                       uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	UserID                   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User                     User       `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	RefreshTokenHash         string     `gorm:"uniqueIndex;not null"`
+	PreviousRefreshTokenHash string     `gorm:"index"`
+	RotatedAt                *time.Time // When PreviousRefreshTokenHash was replaced
+	UserAgent                string
+	IPAddress                string
+	CreatedAt                time.Time
+	LastUsedAt               time.Time `gorm:"not null"`
+	ExpiresAt                time.Time `gorm:"not null;index"` // Moves forward on every refresh
+	RevokedAt                *time.Time
+	RevokedReason            string
+	// RefreshRequiredAt rejects access tokens issued before it, so a role change reaches the next request
+	// through a refresh instead of waiting for the token to expire.
+	RefreshRequiredAt *time.Time
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s Session) Active(at time.Time) bool {
+	return s.RevokedAt == nil && at.Before(s.ExpiresAt)
+}
+
+// AcceptsAccessToken reports whether an access token issued at issuedAt is still good for the session.
+// Token times only have second precision, so a token from the same second is accepted.
+func (s Session) AcceptsAccessToken(issuedAt time.Time) bool {
+	return s.RefreshRequiredAt == nil || !issuedAt.Before(s.RefreshRequiredAt.Truncate(time.Second))
+}
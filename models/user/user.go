@@ -1,7 +1,10 @@
 package user
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"strings"
 	"time"
 )
 
@@ -11,8 +14,11 @@ const (
 	RoleStudent   Role = "Student"
 	RoleAssistant Role = "Assistant"
 	RoleAdmin     Role = "Admin"
+	RoleLecturer  Role = "Lecturer" // Staff who manage the classes they are assigned to
 )
 
+var ErrUnknownRole = errors.New("unknown role")
+
 func (r Role) String() string {
 	switch r {
 	case RoleStudent:
@@ -21,16 +27,56 @@ func (r Role) String() string {
 		return "Assistant"
 	case RoleAdmin:
 		return "Admin"
+	case RoleLecturer:
+		return "Lecturer"
 	}
 
 	return "Unknown"
 }
 
+// ParseRole reads a role name case-insensitively. Anything that is not one of the known roles is
+// rejected instead of being cast, so a typo or an unexpected Messier value cannot become a role.
+func ParseRole(value string) (Role, error) {
+	for _, role := range []Role{RoleStudent, RoleAssistant, RoleLecturer, RoleAdmin} {
+		if strings.EqualFold(strings.TrimSpace(value), string(role)) {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownRole, value)
+}
+
+// Rank orders roles by how much they may do, so the strongest of several roles can be picked.
+func (r Role) Rank() int {
+	switch r {
+	case RoleAssistant:
+		return 1
+	case RoleLecturer:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// AuthProvider is who checks the password of an account at login.
+type AuthProvider string
+
+const (
+	AuthProviderMessier AuthProvider = "messier" // Binus accounts, checked against Messier
+	AuthProviderLocal   AuthProvider = "local"   // Accounts created by an admin, with a password stored here
+)
+
 type User struct {
-	ID        uuid.UUID `gorm:"primaryKey;type:uuid"`
-	Username  string    `gorm:"uniqueIndex"`
-	Name      string    `gorm:"not null"`
-	Role      Role      `gorm:"not null"` // Assistant, SubDev, Student, Lecturer
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uuid.UUID    `gorm:"primaryKey;type:uuid"`
+	Username     string       `gorm:"uniqueIndex"`
+	Name         string       `gorm:"not null"`
+	Role         Role         `gorm:"not null"` // Role from the auth provider; granted roles are added on top at login
+	AuthProvider AuthProvider `gorm:"type:varchar(20);not null;default:messier"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// IsLocal reports whether the account logs in with a password stored by Neptune.
+func (u User) IsLocal() bool {
+	return u.AuthProvider == AuthProviderLocal
 }
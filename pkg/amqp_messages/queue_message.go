@@ -14,4 +14,6 @@ type ResultQueueMessage struct {
 	FinalStatus  submissionModel.SubmissionStatus   `json:"final_status" binding:"required"`
 	Score        int                                `json:"score" binding:"required"`
 	Results      []submissionModel.SubmissionResult `json:"results" binding:"required"`
+	WorkerID     string                             `json:"worker_id"`
+	Attempt      int                                `json:"attempt"` // Judging attempt that produced this result
 }
@@ -0,0 +1,159 @@
+// Package audit records mutating admin requests. Middleware writes one entry per request after the
+// handler ran; handlers fill in what they changed with Describe, Before and After.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log"
+	auditModel "neptune/backend/models/audit"
+	"net/http"
+	"time"
+)
+
+const (
+	entryKey        = "audit_entry"
+	maxSnapshotSize = 64 << 10
+)
+
+// Recorder stores finished entries.
+type Recorder interface {
+	Record(ctx context.Context, entry *auditModel.AuditLog) error
+}
+
+var recorder Recorder
+
+// UseRecorder sets where Middleware stores entries. It is set once at startup.
+func UseRecorder(r Recorder) {
+	recorder = r
+}
+
+type entry struct {
+	action     string
+	targetType string
+	targetID   string
+	before     interface{}
+	after      interface{}
+	forced     bool
+	reason     string
+}
+
+// Middleware records every POST, PUT, PATCH and DELETE of the routes it guards. It must run after
+// RequireAuth so the actor is known. Entries are written before the response finishes, so a failing
+// recorder is logged but never hides the outcome from the client.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if recorder == nil {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		e := &entry{}
+		c.Set(entryKey, e)
+		c.Next()
+
+		record := &auditModel.AuditLog{
+			ActorID:       optionalUUID(c.GetString("user_id")),
+			ActorUsername: c.GetString("username"),
+			ActorRole:     c.GetString("role"),
+			TokenID:       optionalUUID(c.GetString("token_id")),
+			Action:        e.action,
+			TargetType:    e.targetType,
+			TargetID:      e.targetID,
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Status:        c.Writer.Status(),
+			Before:        snapshot(e.before),
+			After:         snapshot(e.after),
+			IPAddress:     c.ClientIP(),
+			Forced:        e.forced,
+			ForceReason:   e.reason,
+			CreatedAt:     time.Now(),
+		}
+		if record.Action == "" {
+			record.Action = c.Request.Method + " " + c.FullPath()
+		}
+
+		// The request context may already be cancelled by the time the handler returns.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := recorder.Record(ctx, record); err != nil {
+			log.Printf("Failed to record audit entry %s %s: %v", record.Action, record.TargetID, err)
+		}
+	}
+}
+
+// Describe names the action, e.g. "contest.update", and the entity it targets.
+func Describe(c *gin.Context, action, targetType, targetID string) {
+	if e := current(c); e != nil {
+		e.action, e.targetType, e.targetID = action, targetType, targetID
+	}
+}
+
+// Before keeps the state of the target as it was before the change.
+func Before(c *gin.Context, snapshot interface{}) {
+	if e := current(c); e != nil {
+		e.before = snapshot
+	}
+}
+
+// After keeps the state of the target after the change, or what the change produced.
+func After(c *gin.Context, snapshot interface{}) {
+	if e := current(c); e != nil {
+		e.after = snapshot
+	}
+}
+
+// Forced marks the entry as a change that overrode a guard, with the reason the actor gave.
+func Forced(c *gin.Context, reason string) {
+	if e := current(c); e != nil {
+		e.forced, e.reason = true, reason
+	}
+}
+
+// SetTargetID fills in the target once it is known, e.g. the ID of a created entity.
+func SetTargetID(c *gin.Context, targetID string) {
+	if e := current(c); e != nil {
+		e.targetID = targetID
+	}
+}
+
+func current(c *gin.Context) *entry {
+	value, ok := c.Get(entryKey)
+	if !ok {
+		return nil
+	}
+	e, _ := value.(*entry)
+	return e
+}
+
+func snapshot(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	if len(data) > maxSnapshotSize {
+		return fmt.Sprintf(`{"truncated":true,"size":%d}`, len(data))
+	}
+	return string(data)
+}
+
+func optionalUUID(value string) *uuid.UUID {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -1,36 +1,70 @@
 package container
 
 import (
+	"context"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"log"
+	accessTokenHand "neptune/backend/handlers/access_token"
+	announcementHand "neptune/backend/handlers/announcement"
+	auditHand "neptune/backend/handlers/audit"
 	caseHandler "neptune/backend/handlers/case"
+	clarificationHand "neptune/backend/handlers/clarification"
 	classHand "neptune/backend/handlers/class"
 	contestHandler "neptune/backend/handlers/contest"
+	courseHand "neptune/backend/handlers/course"
+	fileHand "neptune/backend/handlers/file"
 	"neptune/backend/handlers/language"
 	leaderboardHand "neptune/backend/handlers/leaderboard"
+	localAccountHand "neptune/backend/handlers/local_account"
+	roleHand "neptune/backend/handlers/role"
 	"neptune/backend/handlers/semester"
 	submissionHand "neptune/backend/handlers/submission"
+	syncRunHand "neptune/backend/handlers/sync_run"
 	"neptune/backend/handlers/test_case"
 	userHand "neptune/backend/handlers/user"
 	websocketHand "neptune/backend/handlers/websocket"
+	"neptune/backend/messier"
 	"neptune/backend/messier/auth/log_on"
 	"neptune/backend/messier/auth/me"
 	externalClass "neptune/backend/messier/class"
 	externalSemester "neptune/backend/messier/semester"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/database"
+	"neptune/backend/pkg/middleware"
+	"neptune/backend/pkg/storage"
+	accessTokenRepo "neptune/backend/repositories/access_token"
+	announcementRepo "neptune/backend/repositories/announcement"
+	auditRepo "neptune/backend/repositories/audit"
 	caseRepository "neptune/backend/repositories/case"
+	clarificationRepo "neptune/backend/repositories/clarification"
 	internalClassRepo "neptune/backend/repositories/class"
 	contestRepository "neptune/backend/repositories/contest"
+	courseRepo "neptune/backend/repositories/course"
+	localCredentialRepo "neptune/backend/repositories/local_credential"
 	"neptune/backend/repositories/messier_token"
+	roleRepo "neptune/backend/repositories/role"
 	internalSemesterRepo "neptune/backend/repositories/semester"
+	sessionRepo "neptune/backend/repositories/session"
 	submissionRepo "neptune/backend/repositories/submission"
+	syncRunRepo "neptune/backend/repositories/sync_run"
 	testCaseRepo "neptune/backend/repositories/test_case"
 	userRepo "neptune/backend/repositories/user"
+	accessTokenServ "neptune/backend/services/access_token"
+	announcementServ "neptune/backend/services/announcement"
+	auditServ "neptune/backend/services/audit"
 	caseService "neptune/backend/services/case"
+	clarificationServ "neptune/backend/services/clarification"
 	contestService "neptune/backend/services/contest"
+	courseServ "neptune/backend/services/course"
+	fileServ "neptune/backend/services/file"
 	"neptune/backend/services/internal_class"
 	"neptune/backend/services/internal_semester"
 	judgeServ "neptune/backend/services/judge0"
 	leaderboardServ "neptune/backend/services/leaderboard"
+	localAccountServ "neptune/backend/services/local_account"
+	messierSync "neptune/backend/services/messier_sync"
+	roleServ "neptune/backend/services/role"
+	sessionServ "neptune/backend/services/session"
 	submissionServ "neptune/backend/services/submission"
 	testCaseServ "neptune/backend/services/test_case"
 	userService "neptune/backend/services/user"
@@ -52,19 +86,28 @@ type HandlerContainer struct {
 	LanguageHandler         language.LanguageHandler
 	LeaderboardHandler      leaderboardHand.LeaderboardHandler
 	SubmissionReviewHandler submissionHand.SubmissionReviewHandler
+	FileHandler             fileHand.FileHandler
+	SyncRunHandler          syncRunHand.SyncRunHandler
+	CourseHandler           courseHand.CourseHandler
+	LocalAccountHandler     localAccountHand.LocalAccountHandler
+	RoleHandler             roleHand.RoleHandler
+	AccessTokenHandler      accessTokenHand.AccessTokenHandler
+	AuditHandler            auditHand.AuditHandler
+	ClarificationHandler    clarificationHand.ClarificationHandler
+	AnnouncementHandler     announcementHand.AnnouncementHandler
 }
 
 func NewHandlerContainer(db *gorm.DB) *HandlerContainer {
 	// Messier
-	logOnService := log_on.NewLogOnService()
-	meService := me.NewMeService()
-	messierSemesterService := externalSemester.NewExternalSemesterService()
-	messierClassService := externalClass.NewMessierClassService()
+	messierClient := messier.NewClientFromEnv()
+	logOnService := log_on.NewLogOnService(messierClient)
+	meService := me.NewMeService(messierClient)
+	messierSemesterService := externalSemester.NewExternalSemesterService(messierClient)
+	messierClassService := externalClass.NewMessierClassService(messierClient)
 
 	// Core
 	judge0client := judgeServ.NewJudge0Client()
 	webSocketServ := webSocketService.NewWebSocketService()
-	webSocketHandler := websocketHand.NewWebSocketHandler(webSocketServ)
 
 	rabbitConnection, err := amqp.Dial(os.Getenv("RABBITMQ_URL"))
 	if err != nil {
@@ -75,6 +118,11 @@ func NewHandlerContainer(db *gorm.DB) *HandlerContainer {
 	if err != nil {
 		panic("Failed to open a channel: " + err.Error())
 	}
+	blobStore, err := storage.NewBlobStoreFromEnv()
+	if err != nil {
+		panic("Failed to initialise blob store: " + err.Error())
+	}
+
 	// repo
 	messierTokenRepository := messier_token.NewMessierTokenRepository(db)
 	semesterRepository := internalSemesterRepo.NewSemesterRepository(db)
@@ -84,33 +132,79 @@ func NewHandlerContainer(db *gorm.DB) *HandlerContainer {
 	contestRepo := contestRepository.NewContestRepository(db)
 	testCaseRepository := testCaseRepo.NewTestCaseRepository(db)
 	submissionRepository := submissionRepo.NewSubmissionRepository(db)
+	syncRunRepository := syncRunRepo.NewSyncRunRepository(db)
+	courseRepository := courseRepo.NewCourseRepository(db)
+	localCredentialRepository := localCredentialRepo.NewLocalCredentialRepository(db)
+	sessionRepository := sessionRepo.NewSessionRepository(db)
+	roleRepository := roleRepo.NewRoleRepository(db)
+	accessTokenRepository := accessTokenRepo.NewAccessTokenRepository(db)
+	auditRepository := auditRepo.NewAuditRepository(db)
+	clarificationRepository := clarificationRepo.NewClarificationRepository(db)
+	announcementRepository := announcementRepo.NewAnnouncementRepository(db)
+	txManager := database.NewTransactionManager(db)
+
+	// audit
+	auditService := auditServ.NewAuditService(auditRepository)
+	auditHandler := auditHand.NewAuditHandler(auditService)
+	audit.UseRecorder(auditService)
+
+	// course
+	courseService := courseServ.NewCourseService(courseRepository, semesterRepository, txManager)
+	courseHandler := courseHand.NewCourseHandler(courseService)
+	if err := courseService.SeedDefaultCourses(context.Background()); err != nil {
+		log.Printf("Failed to seed default courses: %v", err)
+	}
 
 	// semester
 	semesterService := internal_semester.NewSemesterService(semesterRepository, messierSemesterService, messierTokenRepository)
 	internalSemesterHandler := semester.NewSemesterHandler(semesterService)
 	// class
-	classService := internal_class.NewClassService(messierClassService, classRepo, userRepository, messierTokenRepository)
+	classService := internal_class.NewClassService(messierClassService, classRepo, userRepository, messierTokenRepository, txManager, courseRepository)
 	classHandler := classHand.NewClassHandler(classService)
+	// scheduled sync
+	syncService := messierSync.NewSyncService(syncRunRepository, semesterRepository, courseRepository, semesterService, classService, logOnService, messierTokenRepository)
+	syncRunHandler := syncRunHand.NewSyncRunHandler(syncService)
 
 	// user
-	userServ := userService.NewUserService(userRepository, logOnService, meService, messierTokenRepository, classRepo, semesterRepository)
-	userHandler := userHand.NewUserHandler(userServ)
-
-	// case
-	caseServ := caseService.NewCaseService(caseRepo)
-	caseHand := caseHandler.NewCaseHandler(caseServ)
+	roleService := roleServ.NewRoleService(roleRepository, userRepository, classRepo, sessionRepository, txManager)
+	roleHandler := roleHand.NewRoleHandler(roleService)
+	middleware.UseClassAccessChecker(roleService)
+	sessionService := sessionServ.NewSessionService(sessionRepository, userRepository, roleService)
+	middleware.UseSessionChecker(sessionService)
+	accessTokenService := accessTokenServ.NewAccessTokenService(accessTokenRepository, userRepository, roleService)
+	accessTokenHandler := accessTokenHand.NewAccessTokenHandler(accessTokenService)
+	middleware.UseTokenAuthenticator(accessTokenService)
+	userServ := userService.NewUserService(userRepository, logOnService, meService, messierTokenRepository, classRepo, semesterRepository, localCredentialRepository, sessionService)
+	userHandler := userHand.NewUserHandler(userServ, sessionService)
+	localAccountService := localAccountServ.NewLocalAccountService(userRepository, localCredentialRepository, txManager, sessionService)
+	localAccountHandler := localAccountHand.NewLocalAccountHandler(localAccountService)
+	if err := localAccountService.EnsureBootstrapAdmin(context.Background()); err != nil {
+		log.Printf("Failed to create local admin account: %v", err)
+	}
 
 	// contest
-	contestServ := contestService.NewContestService(contestRepo, caseRepo)
+	contestServ := contestService.NewContestService(contestRepo, caseRepo, courseRepository, classRepo, submissionRepository, txManager)
 	contestHand := contestHandler.NewContestHandler(contestServ)
 
+	// case
+	caseServ := caseService.NewCaseService(caseRepo, testCaseRepository, blobStore, contestServ, txManager)
+	caseHand := caseHandler.NewCaseHandler(caseServ, blobStore)
+
+	clarificationService := clarificationServ.NewClarificationService(clarificationRepository, contestRepo, classRepo, roleRepository, contestServ, webSocketServ)
+	clarificationHandler := clarificationHand.NewClarificationHandler(clarificationService)
+	webSocketHandler := websocketHand.NewWebSocketHandler(webSocketServ, clarificationService)
+	announcementService := announcementServ.NewAnnouncementService(announcementRepository, contestRepo, clarificationService, webSocketServ, txManager)
+	announcementHandler := announcementHand.NewAnnouncementHandler(announcementService)
+
 	// test_case
-	testCaseService := testCaseServ.NewTestCaseService(testCaseRepository, caseRepo)
+	testCaseService := testCaseServ.NewTestCaseService(testCaseRepository, caseRepo, blobStore, contestServ, txManager)
 	testCaseHandler := testCaseHand.NewTestCaseHandler(testCaseService, caseServ)
 
 	// submission
-	submissionService := submissionServ.NewSubmissionService(submissionRepository, testCaseRepository, ch, judge0client, webSocketServ, contestServ, userRepository)
-	sourceCodeService := submissionServ.NewSubmissionReviewService(submissionRepository)
+	submissionService := submissionServ.NewSubmissionService(submissionRepository, testCaseRepository, ch, judge0client, webSocketServ, contestServ, userRepository, txManager, blobStore)
+	fileService := fileServ.NewFileService(blobStore, caseRepo, testCaseRepository, submissionRepository, classRepo, roleRepository, contestServ)
+	fileHandler := fileHand.NewFileHandler(fileService)
+	sourceCodeService := submissionServ.NewSubmissionReviewService(submissionRepository, blobStore, fileService)
 	submissionHandler := submissionHand.NewSubmissionHandler(submissionService)
 	submissionReviewHandler := submissionHand.NewSubmissionReviewHandler(sourceCodeService)
 	// leaderboard
@@ -123,6 +217,10 @@ func NewHandlerContainer(db *gorm.DB) *HandlerContainer {
 		}
 	}()
 
+	go syncService.StartScheduler(context.Background())
+	go sessionService.StartCleanup(context.Background())
+	go announcementService.StartPublisher(context.Background())
+
 	languageHandler := language.NewLanguageHandler()
 
 	return &HandlerContainer{
@@ -137,5 +235,14 @@ func NewHandlerContainer(db *gorm.DB) *HandlerContainer {
 		LanguageHandler:         *languageHandler,
 		LeaderboardHandler:      *leaderboardHandler,
 		SubmissionReviewHandler: *submissionReviewHandler,
+		FileHandler:             *fileHandler,
+		SyncRunHandler:          *syncRunHandler,
+		CourseHandler:           *courseHandler,
+		LocalAccountHandler:     *localAccountHandler,
+		RoleHandler:             *roleHandler,
+		AccessTokenHandler:      *accessTokenHandler,
+		AuditHandler:            *auditHandler,
+		ClarificationHandler:    *clarificationHandler,
+		AnnouncementHandler:     *announcementHandler,
 	}
 }
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+)
+
+// IsUniqueViolation reports whether err comes from a unique index, either translated by gorm or as a
+// Postgres unique violation (SQLSTATE 23505), without depending on the driver.
+func IsUniqueViolation(err error) bool {
+	var sqlState interface{ SQLState() string }
+	return errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &sqlState) && sqlState.SQLState() == "23505")
+}
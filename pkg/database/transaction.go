@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// TransactionManager runs a unit of work in a single database transaction. Repositories called with the
+// context passed to fn take part in the transaction, so services can combine several repository calls
+// atomically without knowing about gorm.
+type TransactionManager interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactionManager struct {
+	db *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) TransactionManager {
+	return &transactionManager{db: db}
+}
+
+// WithTransaction commits when fn returns nil and rolls back otherwise. Nested calls join the outer
+// transaction.
+func (m *transactionManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// Conn returns the transaction bound to ctx, or db scoped to ctx when no transaction is running.
+// Repositories should use it instead of db.WithContext(ctx).
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
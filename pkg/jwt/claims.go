@@ -11,6 +11,8 @@ type Claims struct {
 	Username string    `json:"username"`
 	Name     string    `json:"name"`
 	Role     user.Role `json:"role"`
+	// SessionID ties the access token to a server-side session, so it can be revoked before it expires.
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -6,12 +6,13 @@ import (
 	"time"
 )
 
-func CreateJWT(userID, username string, name string, role model.Role, expire time.Time) (string, error) {
+func CreateJWT(userID, username string, name string, role model.Role, sessionID string, expire time.Time) (string, error) {
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Name:     name,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		Name:      name,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(expire),
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log"
+	"neptune/backend/models/user"
+	"net/http"
+)
+
+// ClassAccessChecker tells whether a user may manage a class.
+type ClassAccessChecker interface {
+	CanManageClass(ctx context.Context, userID uuid.UUID, role user.Role, classTransactionID uuid.UUID) (bool, error)
+}
+
+var classAccessChecker ClassAccessChecker
+
+// UseClassAccessChecker sets who RequireClassAccess asks. It is set once at startup.
+func UseClassAccessChecker(checker ClassAccessChecker) {
+	classAccessChecker = checker
+}
+
+// RequireClassAccess lets through users who may manage the class named by the param route parameter.
+// Without a checker only admins pass. It must run after RequireAuth.
+func RequireClassAccess(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := user.Role(c.GetString("role"))
+		if role == user.RoleAdmin {
+			c.Next()
+			return
+		}
+
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		classID, classErr := uuid.Parse(c.Param(param))
+		if err != nil || classErr != nil || classAccessChecker == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Insufficient role"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := classAccessChecker.CanManageClass(c.Request.Context(), userID, role, classID)
+		if err != nil {
+			log.Printf("Failed to check access of user %s to class %s: %v", userID, classID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check class access"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Not a lecturer of this class"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,14 +1,39 @@
 package middleware
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"log"
 	jwtPkg "neptune/backend/pkg/jwt"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// SessionChecker tells whether the session behind an access token is still active and still accepts
+// tokens issued at issuedAt.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID string, issuedAt time.Time) (bool, error)
+}
+
+var sessionChecker SessionChecker
+
+// UseSessionChecker makes RequireAuth reject access tokens of revoked or expired sessions. It is set once
+// at startup.
+func UseSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// RequireAuth accepts the access token cookie of a session, or a personal access token sent as
+// Authorization: Bearer on routes registered with AllowTokenScope.
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+			requireTokenAuth(c, strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")))
+			return
+		}
+
 		tokenString, err := c.Cookie("token")
 		if err != nil || tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: No token cookie provided"})
@@ -27,12 +52,38 @@ func RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		// Tokens issued before sessions existed carry no session and cannot be revoked.
+		if claims.SessionID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Please log in again"})
+			c.Abort()
+			return
+		}
+		if sessionChecker != nil {
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			active, err := sessionChecker.IsSessionActive(c.Request.Context(), claims.SessionID, issuedAt)
+			if err != nil {
+				log.Printf("Failed to check session %s: %v", claims.SessionID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Session has been revoked or must be refreshed"})
+				c.Abort()
+				return
+			}
+		}
+
 		// Store the claims in Gin context for later use
 		//fmt.Println("Authenticated user:", claims.Name, claims.UserID, claims.Role, claims.Username)
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role.String())
 		c.Set("username", claims.Username)
 		c.Set("name", claims.Name)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
@@ -0,0 +1,7 @@
+package requests
+
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"` // submissions:read, contests:manage, grades:export
+	ExpiresInDays int      `json:"expires_in_days"`           // Defaults to 30
+}
@@ -4,6 +4,22 @@ import "github.com/google/uuid"
 
 type AddCasesToContestRequest struct {
 	Problems []struct {
-		CaseID uuid.UUID `json:"case_id" binding:"required"`
+		CaseID      uuid.UUID `json:"case_id" binding:"required"`
+		ProblemCode string    `json:"problem_code"` // Optional, the next free letter otherwise
+		Points      *int      `json:"points" binding:"omitempty,min=0"`
+		Color       string    `json:"color"` // Optional, e.g. "#FF8800"
 	} `json:"problems" binding:"required,min=1"`
 }
+
+// UpdateContestCaseRequest changes one problem of a contest. Fields left out are kept.
+type UpdateContestCaseRequest struct {
+	ProblemCode *string `json:"problem_code"`
+	Points      *int    `json:"points" binding:"omitempty,min=0"`
+	Color       *string `json:"color"` // An empty string clears it
+}
+
+// ReorderContestCasesRequest lists every case of the contest in its new order.
+type ReorderContestCasesRequest struct {
+	CaseIDs []uuid.UUID `json:"case_ids" binding:"required,min=1"`
+	Relabel bool        `json:"relabel"` // Give the problems the codes A, B, C... in the new order
+}
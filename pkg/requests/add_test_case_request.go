@@ -26,3 +26,8 @@ func (r *AddTestCaseRequest) ParseFormData(c *gin.Context) error {
 
 	return nil
 }
+
+// SetSampleTestCasesRequest lists the testcase numbers to show as samples; an empty list hides them all.
+type SetSampleTestCasesRequest struct {
+	Numbers []int `json:"numbers" binding:"dive,min=1"`
+}
@@ -0,0 +1,13 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type AnnouncementRequest struct {
+	Title               string      `json:"title" binding:"required,max=200"`
+	Body                string      `json:"body" binding:"max=10000"`
+	ClassTransactionIDs []uuid.UUID `json:"class_transaction_ids"` // Empty reaches every class and the global contest
+	PublishAt           *time.Time  `json:"publish_at"`            // Omitted publishes right away
+}
@@ -0,0 +1,28 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"mime/multipart"
+)
+
+type AddCaseAttachmentRequest struct {
+	CaseID uuid.UUID
+	File   *multipart.FileHeader
+}
+
+func (r *AddCaseAttachmentRequest) ParseFormData(c *gin.Context) error {
+	caseID, err := uuid.Parse(c.Param("case_id"))
+	if err != nil {
+		return err
+	}
+	r.CaseID = caseID
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return err
+	}
+	r.File = file
+
+	return nil
+}
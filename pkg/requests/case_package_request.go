@@ -0,0 +1,22 @@
+package requests
+
+import (
+	"github.com/gin-gonic/gin"
+	"mime/multipart"
+)
+
+type ImportCasePackageRequest struct {
+	File   *multipart.FileHeader
+	Format string // "neptune", "polygon", "kattis", or empty to detect it
+}
+
+func (r *ImportCasePackageRequest) ParseFormData(c *gin.Context) error {
+	file, err := c.FormFile("package")
+	if err != nil {
+		return err
+	}
+	r.File = file
+	r.Format = c.PostForm("format")
+
+	return nil
+}
@@ -5,6 +5,8 @@ type CreateCaseRequest struct {
 	Description   string `json:"description"`
 	TimeLimitMs   int    `json:"time_limit_ms" binding:"required,min=1"`
 	MemoryLimitMb int    `json:"memory_limit_mb" binding:"required,min=1"`
+	CaseStatement
+	CaseMetadata
 }
 
 type UpdateCaseRequest struct {
@@ -12,4 +14,22 @@ type UpdateCaseRequest struct {
 	Description   string `json:"description"`
 	TimeLimitMs   int    `json:"time_limit_ms" binding:"required,min=1"`
 	MemoryLimitMb int    `json:"memory_limit_mb" binding:"required,min=1"`
+	CaseStatement
+	CaseMetadata
+}
+
+// CaseStatement is the structured statement of a case, written in Markdown with $...$ math.
+type CaseStatement struct {
+	Statement    string `json:"statement"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Constraints  string `json:"constraints"`
+}
+
+// CaseMetadata describes a case in the problem bank.
+type CaseMetadata struct {
+	Tags       []string `json:"tags" binding:"max=20,dive,min=1,max=50"`
+	Difficulty string   `json:"difficulty" binding:"omitempty,oneof=easy medium hard"`
+	Author     string   `json:"author" binding:"max=100"`
+	Source     string   `json:"source" binding:"max=255"`
 }
@@ -0,0 +1,14 @@
+package requests
+
+import "github.com/google/uuid"
+
+type AskClarificationRequest struct {
+	ClassTransactionID *uuid.UUID `json:"class_transaction_id"` // Omitted for a global contest
+	CaseID             *uuid.UUID `json:"case_id"`              // Omitted for a general question
+	Question           string     `json:"question" binding:"required,max=2000"`
+}
+
+type AnswerClarificationRequest struct {
+	Answer     string `json:"answer" binding:"required,max=4000"`
+	Visibility string `json:"visibility"` // private (default) or broadcast
+}
@@ -0,0 +1,28 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// CloneContestRequest copies a contest and its cases. Empty fields keep the values of the original.
+type CloneContestRequest struct {
+	Name        string     `json:"name"`        // Defaults to "<original> (copy)"
+	Description *string    `json:"description"` // Optional
+	CourseID    *uuid.UUID `json:"course_id"`   // Optional, moves the copy to another course
+	StartTime   *time.Time `json:"start_time"`  // Global contests only
+	EndTime     *time.Time `json:"end_time"`    // Global contests only
+}
+
+// BulkAssignContestRequest assigns a contest to many classes at once: the listed classes, or every class
+// of a course in a semester.
+type BulkAssignContestRequest struct {
+	ClassTransactionIDs []uuid.UUID `json:"class_transaction_ids"`
+	CourseID            *uuid.UUID  `json:"course_id"`   // With semester_id, instead of class_transaction_ids
+	SemesterID          *uuid.UUID  `json:"semester_id"` // With course_id
+	StartTime           time.Time   `json:"start_time" binding:"required"`
+	EndTime             time.Time   `json:"end_time" binding:"required"`
+	// StaggerMinutes shifts each class after the first this much later than the one before it, keeping
+	// the duration. Zero gives every class the same schedule.
+	StaggerMinutes int `json:"stagger_minutes" binding:"min=0,max=10080"`
+}
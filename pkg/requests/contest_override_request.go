@@ -0,0 +1,10 @@
+package requests
+
+import "time"
+
+type ContestOverrideRequest struct {
+	StartTime    *time.Time `json:"start_time"`                              // Optional, replaces the class start
+	EndTime      *time.Time `json:"end_time"`                                // Optional, replaces the class end
+	ExtraMinutes int        `json:"extra_minutes" binding:"min=0,max=10080"` // Added to the end, at most a week
+	Reason       string     `json:"reason" binding:"max=500"`
+}
@@ -1,17 +1,41 @@
 package requests
 
-import "time"
+import (
+	"github.com/google/uuid"
+	"time"
+)
 
 type CreateContestRequest struct {
 	Name        string     `json:"name" binding:"required"`
 	Description string     `json:"description"`
 	Scope       string     `json:"scope" binding:"required"` // e.g., "public", "class"
+	CourseID    *uuid.UUID `json:"course_id"`
 	StartTime   *time.Time `json:"start_time"`
 	EndTime     *time.Time `json:"end_time"`
+	Visibility  string     `json:"visibility" binding:"omitempty,oneof=public unlisted hidden"` // public by default
+	AccessCode  string     `json:"access_code" binding:"omitempty,max=72"`                      // Global contests only
 }
 
 type UpdateContestRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Scope       string `json:"scope" binding:"required"` // e.g., "public", "class"
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	Scope       string     `json:"scope" binding:"required"` // e.g., "public", "class"
+	CourseID    *uuid.UUID `json:"course_id"`
+	StartTime   *time.Time `json:"start_time"` // Global contests only, keeps the current time when left out
+	EndTime     *time.Time `json:"end_time"`   // Global contests only, keeps the current time when left out
+	// Visibility keeps the current visibility when left out
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted hidden"`
+	// AccessCode keeps the current code when left out; "" removes it. Global contests only.
+	AccessCode *string `json:"access_code" binding:"omitempty,max=72"`
+}
+
+// JoinContestRequest carries the access code of a global contest.
+type JoinContestRequest struct {
+	AccessCode string `json:"access_code" binding:"required"`
+}
+
+// ContestStatusRequest holds a contest as draft or archived, or with an empty status lets its schedule
+// decide again.
+type ContestStatusRequest struct {
+	Status string `json:"status" binding:"omitempty,oneof=draft archived"`
 }
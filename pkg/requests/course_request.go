@@ -0,0 +1,9 @@
+package requests
+
+type CourseRequest struct {
+	CourseOutlineID string   `json:"course_outline_id" binding:"required,uuid"`
+	Code            string   `json:"code" binding:"required"`
+	Name            string   `json:"name" binding:"required"`
+	Active          *bool    `json:"active"`       // Defaults to true
+	SemesterIDs     []string `json:"semester_ids"` // Empty means every semester
+}
@@ -0,0 +1,29 @@
+package requests
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// ForceRequest is read from the query of routes that refuse destructive changes to running contests or
+// contests with submissions: ?force=true&reason=... overrides the guard.
+type ForceRequest struct {
+	Force  bool   `form:"force"`
+	Reason string `form:"reason"`
+}
+
+// ParseAndValidate reads the query and requires a reason whenever force is set.
+func (r *ForceRequest) ParseAndValidate(c *gin.Context) error {
+	if err := c.ShouldBindQuery(r); err != nil {
+		return fmt.Errorf("invalid force parameters: %w", err)
+	}
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Force && r.Reason == "" {
+		return fmt.Errorf("reason is required when force is true")
+	}
+	if len(r.Reason) > 500 {
+		return fmt.Errorf("reason must be at most 500 characters")
+	}
+	return nil
+}
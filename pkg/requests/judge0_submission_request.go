@@ -1,8 +1,10 @@
 package requests
 
+// Judge0SubmissionRequest is the body of a Judge0 submission without its stdin, which the client streams
+// after these fields so large testcases are never held in memory. It is sent with base64_encoded=true, so
+// the text fields hold base64.
 type Judge0SubmissionRequest struct {
 	SourceCode     string `json:"source_code"`
 	LanguageID     int    `json:"language_id"`
-	Stdin          string `json:"stdin"`
 	ExpectedOutput string `json:"expected_output,omitempty"`
 }
@@ -0,0 +1,13 @@
+package requests
+
+type LocalAccountRequest struct {
+	Username string `json:"username" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Role     string `json:"role"`     // Student (default) or Assistant
+	Password string `json:"password"` // Optional, a reset token is issued instead when empty
+}
+
+type PasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
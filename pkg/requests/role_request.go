@@ -0,0 +1,11 @@
+package requests
+
+type GrantRoleRequest struct {
+	Role               string `json:"role" binding:"required"` // Admin, Assistant or Lecturer
+	ClassTransactionID string `json:"class_transaction_id"`    // Optional, Lecturer only; empty grants every class
+	Reason             string `json:"reason"`
+}
+
+type RevokeRoleRequest struct {
+	Reason string `json:"reason"`
+}
@@ -27,6 +27,8 @@ type SubmitCodeRequest struct {
 	LanguageID         int
 	ContestID          uuid.UUID
 	ClassTransactionID *uuid.UUID
+	// Mode is official, virtual or upsolve; empty picks whichever the contest window allows
+	Mode string
 
 	// Internally populated fields after parsing
 	SourceCodeBytes []byte
@@ -42,6 +44,7 @@ func (r *SubmitCodeRequest) ParseAndValidate(c *gin.Context) error {
 	contestIDStr := c.PostForm("contest_id")
 	classIDStr := c.PostForm("class_transaction_id")
 	sourceCodeStr := c.PostForm("source_code")
+	r.Mode = c.PostForm("mode")
 
 	if classIDStr == "" {
 		// If class_id is not provided, we don't set it in the request
@@ -0,0 +1,7 @@
+package requests
+
+import "github.com/google/uuid"
+
+type StartVirtualParticipationRequest struct {
+	ClassTransactionID *uuid.UUID `json:"class_transaction_id"` // Omitted for a global contest
+}
@@ -0,0 +1,19 @@
+package responses
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type AnnouncementResponse struct {
+	ID                  uuid.UUID   `json:"id"`
+	ContestID           uuid.UUID   `json:"contest_id"`
+	Title               string      `json:"title"`
+	Body                string      `json:"body"`
+	AllClasses          bool        `json:"all_classes"`
+	ClassTransactionIDs []uuid.UUID `json:"class_transaction_ids,omitempty"`
+	PublishAt           time.Time   `json:"publish_at"`
+	Published           bool        `json:"published"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+}
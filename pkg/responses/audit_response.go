@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogResponse struct {
+	ID            uint            `json:"id"`
+	ActorID       string          `json:"actor_id,omitempty"`
+	ActorUsername string          `json:"actor_username,omitempty"`
+	ActorRole     string          `json:"actor_role,omitempty"`
+	TokenID       string          `json:"token_id,omitempty"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type,omitempty"`
+	TargetID      string          `json:"target_id,omitempty"`
+	Method        string          `json:"method"`
+	Path          string          `json:"path"`
+	Status        int             `json:"status"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	IPAddress     string          `json:"ip_address"`
+	Forced        bool            `json:"forced,omitempty"`
+	ForceReason   string          `json:"force_reason,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	Entries []AuditLogResponse `json:"entries"`
+	Total   int64              `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+}
@@ -14,4 +14,121 @@ type CaseResponse struct {
 	MemoryLimitMb int       `json:"memory_limit_mb"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	CaseStatementFields
+	// StatementRevision is the number of the latest statement revision
+	StatementRevision int `json:"statement_revision"`
+	// Problem bank metadata
+	Tags       []string            `json:"tags"`
+	Difficulty string              `json:"difficulty"`
+	Author     string              `json:"author"`
+	Source     string              `json:"source"`
+	Usage      []CaseUsageResponse `json:"usage"` // Contests using the case, newest first
+}
+
+type CaseListResponse struct {
+	Cases  []CaseResponse `json:"cases"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+type CaseUsageResponse struct {
+	ContestID   uuid.UUID `json:"contest_id"`
+	ContestName string    `json:"contest_name"`
+	ProblemCode string    `json:"problem_code"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+type CaseTagResponse struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// CaseStatementFields is the structured statement, Markdown with $...$ math.
+type CaseStatementFields struct {
+	Statement    string `json:"statement"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Constraints  string `json:"constraints"`
+}
+
+// CaseStatementResponse is everything a contestant needs to read a problem.
+type CaseStatementResponse struct {
+	CaseID        uuid.UUID `json:"case_id"`
+	Name          string    `json:"name"`
+	Revision      int       `json:"revision"`
+	TimeLimitMs   int       `json:"time_limit_ms"`
+	MemoryLimitMb int       `json:"memory_limit_mb"`
+	PDFFileUrl    string    `json:"pdf_file_url,omitempty"`
+	CaseStatementFields
+	Samples     []CaseSampleResponse     `json:"samples"`
+	Attachments []CaseAttachmentResponse `json:"attachments"`
+}
+
+type CaseSampleResponse struct {
+	Number    int    `json:"number"`
+	Input     string `json:"input"`
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"` // the sample is too long to show in full
+}
+
+type CaseAttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CaseRevisionResponse struct {
+	CaseID   uuid.UUID `json:"case_id"`
+	Revision int       `json:"revision"`
+	Name     string    `json:"name"`
+	CaseStatementFields
+	EditedBy  *uuid.UUID `json:"edited_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CaseRevisionDiffResponse lists the statement fields that differ between two revisions, line by line.
+type CaseRevisionDiffResponse struct {
+	CaseID uuid.UUID               `json:"case_id"`
+	From   int                     `json:"from"`
+	To     int                     `json:"to"`
+	Fields []CaseFieldDiffResponse `json:"fields"`
+}
+
+type CaseFieldDiffResponse struct {
+	Field string             `json:"field"`
+	Lines []DiffLineResponse `json:"lines"`
+}
+
+// DiffLineResponse is one line of a diff. Op is " " for an unchanged line, "-" for a removed one and "+"
+// for an added one.
+type DiffLineResponse struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// CasePDFURL is the authorised download route for a case statement. Stored blob paths are never exposed.
+func CasePDFURL(caseID uuid.UUID, storedPath string) string {
+	if storedPath == "" {
+		return ""
+	}
+	return "/api/cases/" + caseID.String() + "/pdf"
+}
+
+// CaseAttachmentURL is the authorised download route for a case attachment.
+func CaseAttachmentURL(caseID, attachmentID uuid.UUID) string {
+	return "/api/cases/" + caseID.String() + "/attachments/" + attachmentID.String()
+}
+
+// CasePackageImportResponse describes the case created from a problem package.
+type CasePackageImportResponse struct {
+	Case          CaseResponse `json:"case"`
+	Format        string       `json:"format"`
+	TestCaseCount int          `json:"test_case_count"`
+	SampleCount   int          `json:"sample_count"`
+	Attachments   int          `json:"attachments"`
+	Warnings      []string     `json:"warnings"` // Parts of the package that were skipped or guessed
 }
@@ -0,0 +1,21 @@
+package responses
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ClarificationResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	ContestID          uuid.UUID  `json:"contest_id"`
+	ClassTransactionID *uuid.UUID `json:"class_transaction_id,omitempty"`
+	CaseID             *uuid.UUID `json:"case_id,omitempty"`
+	ProblemCode        string     `json:"problem_code,omitempty"`
+	AskedBy            *uuid.UUID `json:"asked_by,omitempty"`   // only shown to staff and the asker
+	AskerName          string     `json:"asker_name,omitempty"` // only shown to staff
+	Question           string     `json:"question"`
+	Answer             string     `json:"answer,omitempty"`
+	AnsweredAt         *time.Time `json:"answered_at,omitempty"`
+	Visibility         string     `json:"visibility"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
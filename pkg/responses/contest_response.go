@@ -6,28 +6,48 @@ import (
 )
 
 type ContestResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Scope       string    `json:"scope"` // e.g., "public", "class"
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Scope       string     `json:"scope"` // e.g., "public", "class"
+	CourseID    *uuid.UUID `json:"course_id,omitempty"`
+	Visibility  string     `json:"visibility"` // public, unlisted or hidden
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// RequiresAccessCode is set on global contests users must join with an access code
+	RequiresAccessCode bool `json:"requires_access_code,omitempty"`
 }
 
 type ClassContestAssignmentResponse struct {
 	ClassTransactionID uuid.UUID `json:"class_transaction_id"`
 	ContestID          uuid.UUID `json:"contest_id"`
-	StartTime          time.Time `json:"start_time"`
+	StartTime          time.Time `json:"start_time"` // the viewer's own window when they have an override
 	EndTime            time.Time `json:"end_time"`
+	PersonalWindow     bool      `json:"personal_window,omitempty"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 
+	// Set by bulk assignment: "created", "updated" or "unchanged". An updated assignment keeps the window
+	// it replaced.
+	Assignment        string     `json:"assignment,omitempty"`
+	PreviousStartTime *time.Time `json:"previous_start_time,omitempty"`
+	PreviousEndTime   *time.Time `json:"previous_end_time,omitempty"`
+
 	Contest ContestResponse `json:"contest"`
 }
 
+const (
+	AssignmentCreated   = "created"
+	AssignmentUpdated   = "updated"
+	AssignmentUnchanged = "unchanged"
+)
+
 type ContestCaseProblemResponse struct {
 	CaseID        uuid.UUID `json:"case_id"`
 	ProblemCode   string    `json:"problem_code"`
+	Position      int       `json:"position"`
+	Points        int       `json:"points"`
+	Color         string    `json:"color,omitempty"`
 	Description   string    `json:"description"`
 	Name          string    `json:"name"`
 	TimeLimitMs   int       `json:"time_limit_ms"`
@@ -40,14 +60,24 @@ type ContestDetailResponse struct {
 	Name        string                       `json:"name"`
 	Description string                       `json:"description"`
 	Scope       string                       `json:"scope"` // e.g., "public", "class"
+	CourseID    *uuid.UUID                   `json:"course_id,omitempty"`
+	Status      string                       `json:"status"` // draft, scheduled, running, ended or archived
+	Visibility  string                       `json:"visibility"`
 	CreatedAt   time.Time                    `json:"created_at"`
 	Cases       []ContestCaseProblemResponse `json:"cases"`
+	// RequiresAccessCode is set on global contests users must join with an access code
+	RequiresAccessCode bool `json:"requires_access_code,omitempty"`
+	// ProblemsVisibleAt is when the viewer gets to see the problems; Cases stays empty until then
+	ProblemsVisibleAt *time.Time `json:"problems_visible_at,omitempty"`
 }
 
 type ContestCaseResponse struct {
 	CaseID   uuid.UUID `json:"case_id"`
 	CaseCode string    `json:"case_code"`
 	CaseName string    `json:"case_name"`
+	Position int       `json:"position"`
+	Points   int       `json:"points"`
+	Color    string    `json:"color,omitempty"`
 }
 
 type GlobalContestResponse struct {
@@ -57,6 +87,8 @@ type GlobalContestResponse struct {
 	Scope       string    `json:"scope"` // e.g., "public", "class"
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
+	// RequiresAccessCode is set when users must join the contest with an access code
+	RequiresAccessCode bool `json:"requires_access_code,omitempty"`
 }
 
 type GlobalContestDetailResponse struct {
@@ -68,3 +100,38 @@ type GlobalContestDetailResponse struct {
 	EndTime     time.Time                    `json:"end_time"`
 	Cases       []ContestCaseProblemResponse `json:"cases"`
 }
+
+type ContestOverrideResponse struct {
+	ClassTransactionID uuid.UUID  `json:"class_transaction_id"`
+	ContestID          uuid.UUID  `json:"contest_id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	Username           string     `json:"username,omitempty"`
+	Name               string     `json:"name,omitempty"`
+	StartTime          *time.Time `json:"start_time,omitempty"`
+	EndTime            *time.Time `json:"end_time,omitempty"`
+	ExtraMinutes       int        `json:"extra_minutes"`
+	Reason             string     `json:"reason,omitempty"`
+	EffectiveStartTime time.Time  `json:"effective_start_time"` // the window the student ends up with
+	EffectiveEndTime   time.Time  `json:"effective_end_time"`
+	GrantedBy          *uuid.UUID `json:"granted_by,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type VirtualParticipationResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	ContestID          uuid.UUID  `json:"contest_id"`
+	ClassTransactionID *uuid.UUID `json:"class_transaction_id,omitempty"`
+	StartedAt          time.Time  `json:"started_at"`
+	EndsAt             time.Time  `json:"ends_at"`
+	Active             bool       `json:"active"`
+}
+
+type ContestLifecycleResponse struct {
+	ContestID      uuid.UUID  `json:"contest_id"`
+	Status         string     `json:"status"`
+	StatusOverride string     `json:"status_override,omitempty"` // draft or archived when set by an admin
+	StartTime      *time.Time `json:"start_time,omitempty"`      // earliest start over every window
+	EndTime        *time.Time `json:"end_time,omitempty"`        // latest end over every window
+	Submissions    int64      `json:"submissions"`
+	Locked         bool       `json:"locked"` // destructive changes need force=true
+}
@@ -0,0 +1,17 @@
+package responses
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type CourseResponse struct {
+	ID              uuid.UUID `json:"id"`
+	CourseOutlineID uuid.UUID `json:"course_outline_id"`
+	Code            string    `json:"code"`
+	Name            string    `json:"name"`
+	Active          bool      `json:"active"`
+	SemesterIDs     []string  `json:"semester_ids"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
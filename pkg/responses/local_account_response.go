@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"time"
+)
+
+type LocalAccountResponse struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	// Set when the account was created without a password; hand it to the account holder.
+	ResetToken          string     `json:"reset_token,omitempty"`
+	ResetTokenExpiresAt *time.Time `json:"reset_token_expires_at,omitempty"`
+}
+
+type LocalAccountImportError struct {
+	Row      int    `json:"row"` // 1-based line in the CSV, the header is row 1
+	Username string `json:"username,omitempty"`
+	Error    string `json:"error"`
+}
+
+type LocalAccountImportResponse struct {
+	Created  int                       `json:"created"`
+	Accounts []LocalAccountResponse    `json:"accounts"`
+	Errors   []LocalAccountImportError `json:"errors"`
+}
+
+type PasswordResetTokenResponse struct {
+	UserID    string    `json:"user_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,41 @@
+package responses
+
+import "time"
+
+type RoleAssignmentResponse struct {
+	ID                 string    `json:"id"`
+	UserID             string    `json:"user_id"`
+	Role               string    `json:"role"`
+	ClassTransactionID string    `json:"class_transaction_id,omitempty"`
+	GrantedBy          string    `json:"granted_by,omitempty"`
+	GrantedAt          time.Time `json:"granted_at"`
+}
+
+// UserRolesResponse shows the role from the auth provider next to the grants on top of it, and the role
+// that ends up in the user's access token.
+type UserRolesResponse struct {
+	UserID        string                   `json:"user_id"`
+	Username      string                   `json:"username"`
+	BaseRole      string                   `json:"base_role"`
+	EffectiveRole string                   `json:"effective_role"`
+	Assignments   []RoleAssignmentResponse `json:"assignments"`
+}
+
+type RoleAuditEntryResponse struct {
+	ID                 string    `json:"id"`
+	AssignmentID       string    `json:"assignment_id"`
+	UserID             string    `json:"user_id"`
+	Role               string    `json:"role"`
+	ClassTransactionID string    `json:"class_transaction_id,omitempty"`
+	Action             string    `json:"action"`
+	ActorID            string    `json:"actor_id,omitempty"`
+	Reason             string    `json:"reason,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type RoleAuditListResponse struct {
+	Entries []RoleAuditEntryResponse `json:"entries"`
+	Total   int64                    `json:"total"`
+	Limit   int                      `json:"limit"`
+	Offset  int                      `json:"offset"`
+}
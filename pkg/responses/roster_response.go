@@ -0,0 +1,60 @@
+package responses
+
+import "time"
+
+// RosterDiffResponse is the difference between the roster of a class and the one in Messier. Applied is
+// false for dry runs and for diffs that were refused.
+type RosterDiffResponse struct {
+	ClassTransactionID string                 `json:"class_transaction_id"`
+	ClassCode          string                 `json:"class_code"`
+	MemberType         string                 `json:"member_type"`
+	Added              []RosterMemberResponse `json:"added"`
+	Removed            []RosterMemberResponse `json:"removed"`
+	Renamed            []RosterRenameResponse `json:"renamed"`
+	Unchanged          int                    `json:"unchanged"`
+	Applied            bool                   `json:"applied"`
+	Error              string                 `json:"error,omitempty"`
+}
+
+type RosterMemberResponse struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+type RosterRenameResponse struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	OldName  string `json:"old_name"`
+	NewName  string `json:"new_name"`
+}
+
+type RosterSyncResponse struct {
+	Message        string               `json:"message"`
+	DryRun         bool                 `json:"dry_run"`
+	Processed      int                  `json:"processed"`
+	Synced         int                  `json:"synced"`
+	FailedClassIDs []string             `json:"failed_class_ids,omitempty"`
+	Errors         []string             `json:"errors,omitempty"`
+	Diffs          []RosterDiffResponse `json:"diffs"`
+}
+
+type RosterChangeResponse struct {
+	ID                 string    `json:"id"`
+	BatchID            string    `json:"batch_id"`
+	ClassTransactionID string    `json:"class_transaction_id"`
+	MemberType         string    `json:"member_type"`
+	Change             string    `json:"change"`
+	UserID             string    `json:"user_id"`
+	Username           string    `json:"username"`
+	OldName            string    `json:"old_name,omitempty"`
+	NewName            string    `json:"new_name,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type RosterChangeListResponse struct {
+	Changes []RosterChangeResponse `json:"changes"`
+	Total   int64                  `json:"total"`
+	Limit   int                    `json:"limit"`
+	Offset  int                    `json:"offset"`
+}
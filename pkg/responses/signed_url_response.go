@@ -0,0 +1,9 @@
+package responses
+
+import "time"
+
+// SignedURLResponse is a short-lived link to a protected file that works without the session cookie.
+type SignedURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -12,6 +12,7 @@ type GetSubmissionPerContestResponse struct {
 	Score        int    `json:"score"`
 	SubmitTime   string `json:"submit_time"` // Time when the submission was made
 	LanguageID   int    `json:"language_id"` // Name of the programming language used
+	Mode         string `json:"mode"`        // official, virtual or upsolve
 
 }
 
@@ -27,4 +28,5 @@ type GetUserSubmissionsResponse struct {
 	Score        int    `json:"score"`
 	SubmitTime   string `json:"submit_time"` // Time when the submission was made
 	LanguageID   int    `json:"language_id"` // Name of the programming language used
+	Mode         string `json:"mode"`        // official, virtual or upsolve
 }
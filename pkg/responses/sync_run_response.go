@@ -0,0 +1,38 @@
+package responses
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type SyncRunResponse struct {
+	ID          uuid.UUID             `json:"id"`
+	Trigger     string                `json:"trigger"`
+	TriggeredBy *uuid.UUID            `json:"triggered_by,omitempty"`
+	Status      string                `json:"status"`
+	SemesterID  string                `json:"semester_id,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	StartedAt   time.Time             `json:"started_at"`
+	FinishedAt  *time.Time            `json:"finished_at,omitempty"`
+	Steps       []SyncRunStepResponse `json:"steps,omitempty"`
+}
+
+type SyncRunStepResponse struct {
+	Name            string     `json:"name"`
+	CourseOutlineID string     `json:"course_outline_id,omitempty"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	Processed       int        `json:"processed"`
+	Synced          int        `json:"synced"`
+	Failed          int        `json:"failed"`
+	Errors          []string   `json:"errors,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+type SyncRunListResponse struct {
+	Runs   []SyncRunResponse `json:"runs"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
@@ -1,9 +1,37 @@
 package responses
 
+import "fmt"
+
 // TestCaseResponse represents the response structure for a test case. Only for admin
 type TestCaseResponse struct {
 	CaseID    string `json:"case_id"`
 	Number    int    `json:"number"`
 	InputUrl  string `json:"input_url"`
 	OutputUrl string `json:"output_url"`
+	IsSample  bool   `json:"is_sample"`
+}
+
+// TestCaseFileURL is the admin download route for a testcase input ("input") or expected output ("output").
+func TestCaseFileURL(caseID string, number int, kind string) string {
+	return fmt.Sprintf("/admin/cases/%s/test-cases/%d/%s", caseID, number, kind)
+}
+
+// TestCaseImportReport describes what happened to every file of an uploaded testcase archive.
+type TestCaseImportReport struct {
+	CaseID        string                     `json:"case_id"`
+	Layout        string                     `json:"layout"`
+	Imported      bool                       `json:"imported"` // false when the existing testcases were kept
+	TestCaseCount int                        `json:"test_case_count"`
+	Files         []TestCaseImportFileReport `json:"files"`
+	Errors        []string                   `json:"errors,omitempty"`
+}
+
+// TestCaseImportFileReport is the outcome for one archive entry. Status is "imported", "skipped" or "rejected".
+type TestCaseImportFileReport struct {
+	Path           string `json:"path"`
+	Status         string `json:"status"`
+	Role           string `json:"role,omitempty"` // "input" or "output"
+	TestCaseNumber int    `json:"test_case_number,omitempty"`
+	SizeBytes      int64  `json:"size_bytes"`
+	Message        string `json:"message,omitempty"`
 }
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrBlobNotFound is returned by Get when no blob is stored under the key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores files (testcases, case PDFs, submission sources) under slash separated keys such as
+// "private/test_case/<caseId>/1/t001.in". All replicas of the API and the judge workers must share the
+// same store.
+type BlobStore interface {
+	// Put streams r into the blob at key, replacing any existing blob. size may be -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob at key for streaming. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every blob whose key starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// KeyFromURL turns a stored path such as "/private/test_case/<caseId>/1/t001.in" into its blob key.
+func KeyFromURL(url string) string {
+	return strings.TrimPrefix(url, "/")
+}
+
+// ReadAll reads a whole blob into memory. Only use it for small blobs such as source code.
+func ReadAll(ctx context.Context, store BlobStore, key string) ([]byte, error) {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// NewBlobStoreFromEnv builds the store selected by BLOB_STORE ("local" by default, or "s3").
+func NewBlobStoreFromEnv() (BlobStore, error) {
+	switch strings.ToLower(os.Getenv("BLOB_STORE")) {
+	case "", "local":
+		root := os.Getenv("BLOB_LOCAL_ROOT")
+		if root == "" {
+			root = "."
+		}
+		return NewLocalBlobStore(root), nil
+	case "s3":
+		return NewS3BlobStore(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    strings.ToLower(os.Getenv("S3_USE_SSL")) == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q, expected \"local\" or \"s3\"", os.Getenv("BLOB_STORE"))
+	}
+}
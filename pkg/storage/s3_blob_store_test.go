@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory stand-in for the part of the S3 API the store uses: path style bucket and object
+// requests and ListObjectsV2. Signatures are not checked.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, bucketExists := f.buckets[bucket]
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !bucketExists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case key == "" && r.Method == http.MethodPut:
+		f.buckets[bucket] = map[string][]byte{}
+	case !bucketExists:
+		s3Error(w, http.StatusNotFound, "NoSuchBucket")
+	case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		prefix := r.URL.Query().Get("prefix")
+		result := struct {
+			XMLName     xml.Name `xml:"ListBucketResult"`
+			Name        string
+			Prefix      string
+			KeyCount    int
+			MaxKeys     int
+			IsTruncated bool
+			Contents    []struct {
+				Key          string
+				Size         int
+				LastModified string
+			}
+		}{Name: bucket, Prefix: prefix, MaxKeys: 1000}
+		var keys []string
+		for k := range objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			result.Contents = append(result.Contents, struct {
+				Key          string
+				Size         int
+				LastModified string
+			}{k, len(objects[k]), time.Now().UTC().Format(time.RFC3339)})
+		}
+		result.KeyCount = len(keys)
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err == nil && strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data, err = decodeAWSChunked(data)
+		}
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		objects[key] = data
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// decodeAWSChunked strips the chunk framing minio-go uses for signed uploads over plain HTTP:
+// "<hex size>;chunk-signature=...\r\n<data>\r\n", ending with a chunk of size 0.
+func decodeAWSChunked(body []byte) ([]byte, error) {
+	var data []byte
+	for {
+		header, rest, ok := strings.Cut(string(body), "\r\n")
+		if !ok {
+			return nil, errors.New("truncated chunk header")
+		}
+		sizeHex, _, _ := strings.Cut(header, ";")
+		var size int
+		if _, err := fmt.Sscanf(sizeHex, "%x", &size); err != nil {
+			return nil, fmt.Errorf("bad chunk size %q", sizeHex)
+		}
+		if size == 0 {
+			return data, nil
+		}
+		if len(rest) < size+2 {
+			return nil, errors.New("truncated chunk")
+		}
+		data = append(data, rest[:size]...)
+		body = []byte(rest[size+2:])
+	}
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+// newTestS3BlobStore connects to the MinIO given by S3_TEST_ENDPOINT, or to a fake S3 when it is unset.
+func newTestS3BlobStore(t *testing.T) BlobStore {
+	t.Helper()
+	cfg := S3Config{
+		Endpoint:  os.Getenv("S3_TEST_ENDPOINT"),
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+		Bucket:    fmt.Sprintf("neptune-test-%d", time.Now().UnixNano()),
+		Region:    "us-east-1",
+	}
+	if cfg.Endpoint == "" {
+		server := httptest.NewServer(&fakeS3{buckets: map[string]map[string][]byte{}})
+		t.Cleanup(server.Close)
+		cfg.Endpoint = strings.TrimPrefix(server.URL, "http://")
+		cfg.AccessKey, cfg.SecretKey = "test", "testtest"
+	}
+	store, err := NewS3BlobStore(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DeletePrefix(context.Background(), "private") })
+	return store
+}
+
+func TestS3BlobStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestS3BlobStore(t)
+
+	// A testcase larger than a single read, streamed in and out
+	content := strings.Repeat("1 2 3\n", 200000)
+	if err := store.Put(ctx, "private/test_case/c1/1/t001.in", strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, "private/test_case/c1/1/t001.out", strings.NewReader("6\n"), 2, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, "private/test_case/c10/1/t001.in", strings.NewReader("x"), 1, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := store.Get(ctx, "private/test_case/c1/1/t001.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(read) != content {
+		t.Fatalf("Get read %d bytes (%v), want %d", len(read), err, len(content))
+	}
+
+	if exists, err := store.Exists(ctx, "private/test_case/c1/1/t001.out"); err != nil || !exists {
+		t.Errorf("Exists = %v, %v for a stored blob", exists, err)
+	}
+	if exists, err := store.Exists(ctx, "private/test_case/c1/1/t002.out"); err != nil || exists {
+		t.Errorf("Exists = %v, %v for a missing blob", exists, err)
+	}
+	if _, err := store.Get(ctx, "private/test_case/c1/1/t002.out"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get of a missing blob = %v, want ErrBlobNotFound", err)
+	}
+
+	// c1 must not match its sibling c10
+	if err := store.DeletePrefix(ctx, "private/test_case/c1"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := store.Exists(ctx, "private/test_case/c1/1/t001.in"); exists {
+		t.Error("DeletePrefix left a blob below the prefix")
+	}
+	if exists, _ := store.Exists(ctx, "private/test_case/c10/1/t001.in"); !exists {
+		t.Error("DeletePrefix removed a blob outside the prefix")
+	}
+
+	if err := store.Delete(ctx, "private/test_case/c10/1/t001.in"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := store.Exists(ctx, "private/test_case/c10/1/t001.in"); exists {
+		t.Error("Delete left the blob")
+	}
+}
@@ -1,12 +1,13 @@
 package utils
 
+// GenerateAlphabetCode turns a 0-based index into a spreadsheet-style code: 0 is "A", 25 is "Z", 26 is "AA".
 func GenerateAlphabetCode(n int) string {
 	result := ""
 	n += 1 // Since A starts from 1 (not 0)
 
 	for n > 0 {
 		n-- // Adjust for 0-based indexing
-		letter := 'A' + (n % 26)
+		letter := rune('A' + (n % 26))
 		result = string(letter) + result
 		n /= 26
 	}
@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"regexp"
+	"strings"
 )
 
+var naturalChunkPattern = regexp.MustCompile(`\d+|\D+`)
+
 func saveZipEntry(f *zip.File, targetPath string) error {
 	outFile, err := os.Create(targetPath)
 	if err != nil {
@@ -26,3 +31,60 @@ func saveZipEntry(f *zip.File, targetPath string) error {
 	}
 	return nil
 }
+
+// NormalizeZipPath cleans a zip entry name and rejects names that would escape the archive root
+// (zip slip) once extracted.
+func NormalizeZipPath(name string) (string, error) {
+	normalized := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(normalized, "/") || (len(normalized) > 1 && normalized[1] == ':') {
+		return "", fmt.Errorf("absolute path %q is not allowed", name)
+	}
+	for _, segment := range strings.Split(normalized, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("path %q escapes the archive root", name)
+		}
+	}
+	cleaned := path.Clean(normalized)
+	if cleaned == "." || cleaned == "" {
+		return "", fmt.Errorf("empty path is not allowed")
+	}
+	return cleaned, nil
+}
+
+// IsArchiveMetadata reports OS metadata that archivers add, such as __MACOSX/ and .DS_Store.
+func IsArchiveMetadata(filePath string) bool {
+	if strings.HasPrefix(filePath, "__MACOSX/") {
+		return true
+	}
+	name := path.Base(filePath)
+	return strings.HasPrefix(name, ".") || name == "Thumbs.db" || name == "desktop.ini"
+}
+
+// NaturalLess compares strings so that embedded numbers sort by value: "2" < "10", "test2" < "test10".
+func NaturalLess(a, b string) bool {
+	chunksA := naturalChunkPattern.FindAllString(a, -1)
+	chunksB := naturalChunkPattern.FindAllString(b, -1)
+	for i := 0; i < len(chunksA) && i < len(chunksB); i++ {
+		ca, cb := chunksA[i], chunksB[i]
+		if ca == cb {
+			continue
+		}
+		if isDigit(ca[0]) && isDigit(cb[0]) {
+			// Compared as digit strings, so numbers too long for an integer still sort by value
+			va, vb := strings.TrimLeft(ca, "0"), strings.TrimLeft(cb, "0")
+			if len(va) != len(vb) {
+				return len(va) < len(vb)
+			}
+			if va != vb {
+				return va < vb
+			}
+			return len(ca) < len(cb) // "01" before "001" when values are equal
+		}
+		return ca < cb
+	}
+	return len(chunksA) < len(chunksB)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
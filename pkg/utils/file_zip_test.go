@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2", "10", true},
+		{"10", "2", false},
+		{"test2", "test10", true},
+		{"test10", "test2", false},
+		{"a", "b", true},
+		{"a", "a", false},
+		{"1", "01", true},
+		{"01", "001", true},
+		{"001", "01", false},
+		{"t1.in", "t1.out", true},
+		{"t9.in", "t10.in", true},
+		{"data/sample/2", "data/secret/1", true},
+		{"1a", "1b", true},
+		{"a1", "1a", false},
+		{"1", "a", true},
+		{"abc", "abc1", true},
+		{"99999999999999999999", "100000000000000000000", true},
+		{"100000000000000000000", "99999999999999999999", false},
+		{"x18446744073709551616", "x18446744073709551617", true},
+	}
+	for _, tt := range tests {
+		if got := NaturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("NaturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNaturalLessSortsTestcases(t *testing.T) {
+	names := []string{"10.in", "1.in", "2.out", "test100", "2.in", "test20", "1.out", "01.in", "test3"}
+	sort.Slice(names, func(i, j int) bool { return NaturalLess(names[i], names[j]) })
+	want := []string{"1.in", "1.out", "01.in", "2.in", "2.out", "10.in", "test3", "test20", "test100"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("sorted = %v, want %v", names, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diffString writes a diff one "<op><text>" entry per line.
+func diffString(diff []DiffLine) string {
+	var lines []string
+	for _, line := range diff {
+		lines = append(lines, string(line.Op)+line.Text)
+	}
+	return strings.Join(lines, "|")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"both empty", "", "", ""},
+		{"added to empty", "", "a\nb", "+a|+b"},
+		{"removed everything", "a\nb", "", "-a|-b"},
+		{"equal", "a\nb", "a\nb", " a| b"},
+		{"CRLF equals LF", "a\r\nb", "a\nb", " a| b"},
+		{"changed line", "a\nb\nc", "a\nx\nc", " a|-b|+x| c"},
+		{"inserted line", "a\nc", "a\nb\nc", " a|+b| c"},
+		{"deleted line", "a\nb\nc", "a\nc", " a|-b| c"},
+		{"trailing newline", "a\n", "a", " a|-"},
+		{"moved line", "a\nb\nc", "b\nc\na", "-a| b| c|+a"},
+		{"repeated lines", "x\nx\ny", "x\ny\nx", " x|-x| y|+x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffString(DiffLines(tt.a, tt.b)); got != tt.want {
+				t.Errorf("DiffLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesRebuildsBothTexts checks that every diff keeps a and b apart from their line endings.
+func TestDiffLinesRebuildsBothTexts(t *testing.T) {
+	pairs := [][2]string{
+		{"1\n2\n3\n4\n5", "0\n2\n3\n5\n6"},
+		{"a\nb\na\nb", "b\na\nb\na"},
+		{strings.Repeat("same\n", 50) + "old", strings.Repeat("same\n", 50) + "new"},
+	}
+	for _, pair := range pairs {
+		var a, b []string
+		for _, line := range DiffLines(pair[0], pair[1]) {
+			if line.Op != DiffInsert {
+				a = append(a, line.Text)
+			}
+			if line.Op != DiffDelete {
+				b = append(b, line.Text)
+			}
+		}
+		if strings.Join(a, "\n") != pair[0] || strings.Join(b, "\n") != pair[1] {
+			t.Errorf("diff of %q and %q does not rebuild them", pair[0], pair[1])
+		}
+	}
+}
+
+func TestDiffLinesReplacesHugeChanges(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 2100; i++ {
+		a = append(a, "a"+strings.Repeat("x", i%7))
+		b = append(b, "b"+strings.Repeat("x", i%7))
+	}
+	diff := DiffLines("head\n"+strings.Join(a, "\n")+"\ntail", "head\n"+strings.Join(b, "\n")+"\ntail")
+	if len(diff) != 2+len(a)+len(b) {
+		t.Fatalf("got %d lines, want %d", len(diff), 2+len(a)+len(b))
+	}
+	ops := []DiffOp{diff[0].Op, diff[1].Op, diff[len(a)].Op, diff[len(a)+1].Op, diff[len(diff)-1].Op}
+	if !reflect.DeepEqual(ops, []DiffOp{DiffEqual, DiffDelete, DiffDelete, DiffInsert, DiffEqual}) {
+		t.Errorf("ops = %v, want the common lines kept and the rest replaced", ops)
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrSignedURLExpired = errors.New("signed url has expired")
+	ErrSignedURLInvalid = errors.New("signed url signature is invalid")
+)
+
+// signedURLSecret is the HMAC key of signed links, set by LoadSignedURLSecret at startup.
+var signedURLSecret []byte
+
+// LoadSignedURLSecret reads FILE_URL_SECRET. Signed links get a key of their own, so it must be set and
+// must not be the JWT secret. Call it after .env is loaded and before serving.
+func LoadSignedURLSecret() error {
+	secret := os.Getenv("FILE_URL_SECRET")
+	if secret == "" {
+		return errors.New("FILE_URL_SECRET environment variable is not set")
+	}
+	if secret == os.Getenv("JWT_SECRET") {
+		return errors.New("FILE_URL_SECRET must differ from JWT_SECRET")
+	}
+	signedURLSecret = []byte(secret)
+	return nil
+}
+
+func signPath(path string, expires int64) string {
+	if len(signedURLSecret) == 0 {
+		panic("signed urls used before LoadSignedURLSecret")
+	}
+	mac := hmac.New(sha256.New, signedURLSecret)
+	mac.Write([]byte(path + "\n" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignURLPath appends expires and signature query parameters that authorise a GET of path until expiresAt.
+func SignURLPath(path string, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signPath(path, expires))
+	return path + "?" + query.Encode()
+}
+
+// VerifySignedURLPath checks the expires and signature query parameters produced by SignURLPath.
+func VerifySignedURLPath(path, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad expires parameter", ErrSignedURLInvalid)
+	}
+	if !hmac.Equal([]byte(signPath(path, expiresAt)), []byte(signature)) {
+		return ErrSignedURLInvalid
+	}
+	if time.Now().Unix() > expiresAt {
+		return ErrSignedURLExpired
+	}
+	return nil
+}
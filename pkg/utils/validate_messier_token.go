@@ -2,22 +2,27 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	messierTokenRepo "neptune/backend/repositories/messier_token"
 	"time"
 )
 
+// ErrMessierTokenUnavailable means the user has no stored Messier token or it has expired, so they have to
+// log in again before anything can be fetched from Messier on their behalf.
+var ErrMessierTokenUnavailable = errors.New("messier token unavailable")
+
 func GetAndValidateMessierToken(ctx context.Context, userID string, repository messierTokenRepo.MessierTokenRepository) (token string, err error) {
 	authToken, err := repository.GetMessierTokenByUserID(ctx, userID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get auth token for user %s: %w", userID, err)
 	}
 	if authToken == nil || authToken.MessierAccessToken == "" {
-		return "", fmt.Errorf("no valid auth token found for user %s", userID)
+		return "", fmt.Errorf("%w: no valid auth token found for user %s", ErrMessierTokenUnavailable, userID)
 	}
 
 	if authToken.MessierTokenExpires.Before(time.Now()) {
-		return "", fmt.Errorf("auth token for user %s has expired", userID)
+		return "", fmt.Errorf("%w: auth token for user %s has expired", ErrMessierTokenUnavailable, userID)
 	}
 
 	return authToken.MessierAccessToken, nil
@@ -0,0 +1,21 @@
+package accessTokenRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	model "neptune/backend/models/user"
+	"time"
+)
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *model.PersonalAccessToken) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.PersonalAccessToken, error)
+	FindByHash(ctx context.Context, hash string) (*model.PersonalAccessToken, error)
+	// FindByUserID lists every token of the user, revoked and expired ones included, newest first.
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.PersonalAccessToken, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedBy *uuid.UUID, at time.Time) error
+
+	// SaveUsage stores a request made with the token and moves its LastUsedAt forward.
+	SaveUsage(ctx context.Context, usage *model.AccessTokenUsage) error
+	FindUsage(ctx context.Context, tokenID uuid.UUID, limit, offset int) ([]model.AccessTokenUsage, int64, error)
+}
@@ -0,0 +1,101 @@
+package accessTokenRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+	"time"
+)
+
+type accessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &accessTokenRepository{db: db}
+}
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *model.PersonalAccessToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Omit("User").Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create access token: %w", err)
+	}
+	return nil
+}
+
+func (r *accessTokenRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.PersonalAccessToken, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *accessTokenRepository) FindByHash(ctx context.Context, hash string) (*model.PersonalAccessToken, error) {
+	return r.findOne(ctx, "token_hash = ?", hash)
+}
+
+func (r *accessTokenRepository) findOne(ctx context.Context, query string, arg interface{}) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	err := database.Conn(ctx, r.db).Where(query, arg).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find access token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	err := database.Conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find access tokens of user %s: %w", userID, err)
+	}
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedBy *uuid.UUID, at time.Time) error {
+	err := database.Conn(ctx, r.db).Model(&model.PersonalAccessToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_by": revokedBy}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *accessTokenRepository) SaveUsage(ctx context.Context, usage *model.AccessTokenUsage) error {
+	conn := database.Conn(ctx, r.db)
+	if err := conn.Create(usage).Error; err != nil {
+		return fmt.Errorf("failed to save usage of access token %s: %w", usage.TokenID, err)
+	}
+	err := conn.Model(&model.PersonalAccessToken{}).
+		Where("id = ?", usage.TokenID).
+		Update("last_used_at", usage.CreatedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to update last use of access token %s: %w", usage.TokenID, err)
+	}
+	return nil
+}
+
+func (r *accessTokenRepository) FindUsage(ctx context.Context, tokenID uuid.UUID, limit, offset int) ([]model.AccessTokenUsage, int64, error) {
+	query := database.Conn(ctx, r.db).Model(&model.AccessTokenUsage{}).Where("token_id = ?", tokenID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count usage of access token %s: %w", tokenID, err)
+	}
+
+	var usage []model.AccessTokenUsage
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&usage).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find usage of access token %s: %w", tokenID, err)
+	}
+	return usage, total, nil
+}
@@ -0,0 +1,23 @@
+package announcementRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	contestModel "neptune/backend/models/contest"
+	"time"
+)
+
+type AnnouncementRepository interface {
+	// Save creates or updates the announcement and replaces its targets.
+	Save(ctx context.Context, announcement *contestModel.Announcement) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*contestModel.Announcement, error)
+	// FindByContest lists a contest's announcements newest first, only those published by now when
+	// publishedBy is not nil.
+	FindByContest(ctx context.Context, contestID uuid.UUID, publishedBy *time.Time) ([]contestModel.Announcement, error)
+	// FindDue lists the announcements whose publish time has passed but were not pushed yet.
+	FindDue(ctx context.Context, now time.Time) ([]contestModel.Announcement, error)
+	// MarkPublished records that the announcement was pushed. It reports false when another instance
+	// already did.
+	MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
@@ -0,0 +1,101 @@
+package announcementRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	contestModel "neptune/backend/models/contest"
+	"neptune/backend/pkg/database"
+	"time"
+)
+
+type announcementRepository struct {
+	db *gorm.DB
+}
+
+func NewAnnouncementRepository(db *gorm.DB) AnnouncementRepository {
+	return &announcementRepository{db: db}
+}
+
+func (r *announcementRepository) Save(ctx context.Context, announcement *contestModel.Announcement) error {
+	if announcement.ID == uuid.Nil {
+		announcement.ID = uuid.New()
+	}
+	conn := database.Conn(ctx, r.db)
+	if err := conn.Omit("Targets").Save(announcement).Error; err != nil {
+		return fmt.Errorf("failed to save announcement %s: %w", announcement.ID, err)
+	}
+	if err := conn.Where("announcement_id = ?", announcement.ID).Delete(&contestModel.AnnouncementTarget{}).Error; err != nil {
+		return fmt.Errorf("failed to clear targets of announcement %s: %w", announcement.ID, err)
+	}
+	if len(announcement.Targets) == 0 {
+		return nil
+	}
+	for i := range announcement.Targets {
+		announcement.Targets[i].AnnouncementID = announcement.ID
+	}
+	if err := conn.Create(&announcement.Targets).Error; err != nil {
+		return fmt.Errorf("failed to save targets of announcement %s: %w", announcement.ID, err)
+	}
+	return nil
+}
+
+func (r *announcementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	conn := database.Conn(ctx, r.db)
+	if err := conn.Where("announcement_id = ?", id).Delete(&contestModel.AnnouncementTarget{}).Error; err != nil {
+		return fmt.Errorf("failed to delete targets of announcement %s: %w", id, err)
+	}
+	if err := conn.Delete(&contestModel.Announcement{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete announcement %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *announcementRepository) FindByID(ctx context.Context, id uuid.UUID) (*contestModel.Announcement, error) {
+	var announcement contestModel.Announcement
+	err := database.Conn(ctx, r.db).Preload("Targets").Where("id = ?", id).First(&announcement).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find announcement %s: %w", id, err)
+	}
+	return &announcement, nil
+}
+
+func (r *announcementRepository) FindByContest(ctx context.Context, contestID uuid.UUID, publishedBy *time.Time) ([]contestModel.Announcement, error) {
+	query := database.Conn(ctx, r.db).Preload("Targets").Where("contest_id = ?", contestID)
+	if publishedBy != nil {
+		query = query.Where("publish_at <= ?", *publishedBy)
+	}
+
+	var announcements []contestModel.Announcement
+	if err := query.Order("publish_at DESC").Find(&announcements).Error; err != nil {
+		return nil, fmt.Errorf("failed to find announcements of contest %s: %w", contestID, err)
+	}
+	return announcements, nil
+}
+
+func (r *announcementRepository) FindDue(ctx context.Context, now time.Time) ([]contestModel.Announcement, error) {
+	var announcements []contestModel.Announcement
+	err := database.Conn(ctx, r.db).Preload("Targets").
+		Where("publish_at <= ? AND published_at IS NULL", now).
+		Order("publish_at ASC").
+		Find(&announcements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due announcements: %w", err)
+	}
+	return announcements, nil
+}
+
+func (r *announcementRepository) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&contestModel.Announcement{}).
+		Where("id = ? AND published_at IS NULL", id).
+		Update("published_at", at)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark announcement %s as published: %w", id, result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
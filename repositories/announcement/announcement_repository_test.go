@@ -0,0 +1,102 @@
+package announcementRepo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	contestModel "neptune/backend/models/contest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingPool is a gorm connection that records every statement and reports no affected rows, so Save
+// takes the insert path it takes for a new announcement.
+type recordingPool struct {
+	statements []recordedStatement
+}
+
+type recordedStatement struct {
+	query string
+	args  []interface{}
+}
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.statements = append(p.statements, recordedStatement{query: query, args: args})
+	return driver.RowsAffected(0), nil
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+// insertedValue returns the value written to column by the first INSERT into table.
+func (p *recordingPool) insertedValue(t *testing.T, table, column string) interface{} {
+	t.Helper()
+	prefix := `INSERT INTO "` + table + `" (`
+	for _, statement := range p.statements {
+		if !strings.HasPrefix(statement.query, prefix) {
+			continue
+		}
+		columns := strings.Split(statement.query[len(prefix):strings.Index(statement.query, ")")], ",")
+		for i, c := range columns {
+			if strings.Trim(c, `" `) == column {
+				return statement.args[i]
+			}
+		}
+		t.Fatalf("INSERT INTO %s does not write %s: %s", table, column, statement.query)
+	}
+	t.Fatalf("no INSERT INTO %s was run", table)
+	return nil
+}
+
+func TestSaveKeepsClassTargetedAnnouncement(t *testing.T) {
+	pool := &recordingPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewAnnouncementRepository(db)
+
+	classID := uuid.New()
+	announcement := &contestModel.Announcement{
+		ID:         uuid.New(),
+		ContestID:  uuid.New(),
+		Title:      "Problem B clarified",
+		AllClasses: false,
+		PublishAt:  time.Now(),
+		Targets:    []contestModel.AnnouncementTarget{{ClassTransactionID: classID}},
+	}
+	if err := repo.Save(context.Background(), announcement); err != nil {
+		t.Fatal(err)
+	}
+
+	if announcement.AllClasses {
+		t.Error("Save set AllClasses on an announcement targeted at one class")
+	}
+	if got := pool.insertedValue(t, "announcements", "all_classes"); got != false {
+		t.Errorf("all_classes was inserted as %v, want false", got)
+	}
+	if got := pool.insertedValue(t, "announcement_targets", "class_transaction_id"); got != classID {
+		t.Errorf("class_transaction_id was inserted as %v, want %s", got, classID)
+	}
+	if !announcement.Reaches(&classID) {
+		t.Error("the announcement does not reach its target class")
+	}
+	other := uuid.New()
+	if announcement.Reaches(&other) || announcement.Reaches(nil) {
+		t.Error("the announcement reaches a class it does not target")
+	}
+}
@@ -0,0 +1,28 @@
+package auditRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	auditModel "neptune/backend/models/audit"
+	"time"
+)
+
+// Filter narrows audit entries down; zero fields match everything.
+type Filter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	ForcedOnly bool
+}
+
+type AuditRepository interface {
+	Save(ctx context.Context, entry *auditModel.AuditLog) error
+	// Find lists matching entries newest first.
+	Find(ctx context.Context, filter Filter, limit, offset int) ([]auditModel.AuditLog, int64, error)
+	// FindBefore lists up to limit matching entries with an ID below beforeID (0 for the newest), newest
+	// first, so large exports can be read in batches.
+	FindBefore(ctx context.Context, filter Filter, beforeID uint, limit int) ([]auditModel.AuditLog, error)
+}
@@ -0,0 +1,78 @@
+package auditRepo
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	auditModel "neptune/backend/models/audit"
+	"neptune/backend/pkg/database"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Save(ctx context.Context, entry *auditModel.AuditLog) error {
+	if err := database.Conn(ctx, r.db).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *auditRepository) Find(ctx context.Context, filter Filter, limit, offset int) ([]auditModel.AuditLog, int64, error) {
+	query := r.filtered(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	var entries []auditModel.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+func (r *auditRepository) FindBefore(ctx context.Context, filter Filter, beforeID uint, limit int) ([]auditModel.AuditLog, error) {
+	query := r.filtered(ctx, filter)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var entries []auditModel.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *auditRepository) filtered(ctx context.Context, filter Filter) *gorm.DB {
+	query := database.Conn(ctx, r.db).Model(&auditModel.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.ForcedOnly {
+		query = query.Where("forced = ?", true)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -4,11 +4,64 @@ import (
 	"context"
 	"github.com/google/uuid"
 	caseModel "neptune/backend/models/contest"
+	"time"
 )
 
+// CaseSort orders the problem bank.
+type CaseSort string
+
+const (
+	SortNewest CaseSort = "newest"
+	SortOldest CaseSort = "oldest"
+	SortName   CaseSort = "name"
+)
+
+// Filter narrows the problem bank down; zero fields match everything.
+type Filter struct {
+	Query      string   // Matched against name, description, author and source, case insensitive
+	Tags       []string // A case must carry every tag
+	Difficulty caseModel.CaseDifficulty
+	Author     string
+	Source     string
+	Used       *bool // Whether the case is a problem in any contest
+	Sort       CaseSort
+}
+
+// TagCount is a tag of the bank and the number of cases carrying it.
+type TagCount struct {
+	Tag   string
+	Count int64
+}
+
+// CaseUsage is one contest a case is a problem in.
+type CaseUsage struct {
+	CaseID      uuid.UUID
+	ContestID   uuid.UUID
+	ContestName string
+	ProblemCode string
+	AddedAt     time.Time
+}
+
 type CaseRepository interface {
 	SaveCase(ctx context.Context, problemCase *caseModel.Case) error
 	FindCaseByID(ctx context.Context, caseID uuid.UUID) (*caseModel.Case, error)
-	FindAllCases(ctx context.Context) ([]caseModel.Case, error)
+	// FindCases returns one page of the cases matching filter, with their tags, and the number of matches.
+	FindCases(ctx context.Context, filter Filter, limit, offset int) ([]caseModel.Case, int64, error)
 	DeleteCase(ctx context.Context, caseID uuid.UUID) error // Soft delete
+
+	// Problem bank
+	ReplaceTags(ctx context.Context, caseID uuid.UUID, tags []string) error
+	FindTags(ctx context.Context) ([]TagCount, error)                        // Most used first
+	FindUsage(ctx context.Context, caseIDs []uuid.UUID) ([]CaseUsage, error) // Newest first, deleted contests left out
+
+	// Statement revisions
+	SaveStatementRevision(ctx context.Context, revision *caseModel.CaseStatementRevision) error
+	FindStatementRevisions(ctx context.Context, caseID uuid.UUID) ([]caseModel.CaseStatementRevision, error) // Newest first
+	FindStatementRevision(ctx context.Context, caseID uuid.UUID, revision int) (*caseModel.CaseStatementRevision, error)
+
+	// Attachments
+	SaveAttachment(ctx context.Context, attachment *caseModel.CaseAttachment) error
+	FindAttachments(ctx context.Context, caseID uuid.UUID) ([]caseModel.CaseAttachment, error)
+	FindAttachment(ctx context.Context, caseID, attachmentID uuid.UUID) (*caseModel.CaseAttachment, error)
+	DeleteAttachment(ctx context.Context, attachmentID uuid.UUID) error
 }
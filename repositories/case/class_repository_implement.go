@@ -7,6 +7,8 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	caseModel "neptune/backend/models/contest"
+	"neptune/backend/pkg/database"
+	"strings"
 	"time"
 )
 
@@ -23,15 +25,24 @@ func (r *caseRepositoryImpl) SaveCase(ctx context.Context, problemCase *caseMode
 	if problemCase.ID == uuid.Nil {
 		problemCase.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	// Tags are kept by ReplaceTags
+	return database.Conn(ctx, r.db).Omit("Tags").Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "id"}}, // Conflict on primary key (ID)
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"name":            problemCase.Name,
-			"description":     problemCase.Description,
-			"pdf_file_url":    problemCase.PDFFileUrl,
-			"time_limit_ms":   problemCase.TimeLimitMs,
-			"memory_limit_mb": problemCase.MemoryLimitMb,
-			"updated_at":      time.Now(),
+			"name":               problemCase.Name,
+			"description":        problemCase.Description,
+			"pdf_file_url":       problemCase.PDFFileUrl,
+			"time_limit_ms":      problemCase.TimeLimitMs,
+			"memory_limit_mb":    problemCase.MemoryLimitMb,
+			"statement":          problemCase.Statement,
+			"input_format":       problemCase.InputFormat,
+			"output_format":      problemCase.OutputFormat,
+			"constraints":        problemCase.Constraints,
+			"statement_revision": problemCase.StatementRevision,
+			"difficulty":         problemCase.Difficulty,
+			"author":             problemCase.Author,
+			"source":             problemCase.Source,
+			"updated_at":         time.Now(),
 		}),
 	}).Create(problemCase).Error
 }
@@ -39,7 +50,7 @@ func (r *caseRepositoryImpl) SaveCase(ctx context.Context, problemCase *caseMode
 // FindCaseByID retrieves a Case.
 func (r *caseRepositoryImpl) FindCaseByID(ctx context.Context, caseID uuid.UUID) (*caseModel.Case, error) {
 	var problemCase caseModel.Case
-	result := r.db.WithContext(ctx).Where("id = ?", caseID).First(&problemCase)
+	result := database.Conn(ctx, r.db).Preload("Tags").Where("id = ?", caseID).First(&problemCase)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -49,17 +60,184 @@ func (r *caseRepositoryImpl) FindCaseByID(ctx context.Context, caseID uuid.UUID)
 	return &problemCase, nil
 }
 
-// FindAllCases retrieves all Cases.
-func (r *caseRepositoryImpl) FindAllCases(ctx context.Context) ([]caseModel.Case, error) {
+// FindCases retrieves one page of the problem bank.
+func (r *caseRepositoryImpl) FindCases(ctx context.Context, filter Filter, limit, offset int) ([]caseModel.Case, int64, error) {
+	query := r.filtered(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count cases: %w", err)
+	}
+
+	order := "created_at DESC"
+	switch filter.Sort {
+	case SortOldest:
+		order = "created_at ASC"
+	case SortName:
+		order = "LOWER(name) ASC"
+	}
 	var cases []caseModel.Case
-	result := r.db.WithContext(ctx).Find(&cases)
+	if err := query.Preload("Tags").Order(order).Order("id").Limit(limit).Offset(offset).Find(&cases).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find cases: %w", err)
+	}
+	return cases, total, nil
+}
+
+// usedCase matches cases that are a problem in a contest that has not been deleted.
+const usedCase = `EXISTS (SELECT 1 FROM contest_cases JOIN contests ON contests.id = contest_cases.contest_id
+	WHERE contest_cases.case_id = cases.id AND contests.deleted_at IS NULL)`
+
+func (r *caseRepositoryImpl) filtered(ctx context.Context, filter Filter) *gorm.DB {
+	query := database.Conn(ctx, r.db).Model(&caseModel.Case{})
+	if filter.Query != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ? OR author ILIKE ? OR source ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where("EXISTS (SELECT 1 FROM case_tags WHERE case_tags.case_id = cases.id AND case_tags.tag = ?)", tag)
+	}
+	if filter.Difficulty != "" {
+		query = query.Where("difficulty = ?", filter.Difficulty)
+	}
+	if filter.Author != "" {
+		query = query.Where("author ILIKE ?", likeEscaper.Replace(filter.Author))
+	}
+	if filter.Source != "" {
+		query = query.Where("source ILIKE ?", "%"+likeEscaper.Replace(filter.Source)+"%")
+	}
+	if filter.Used != nil {
+		if *filter.Used {
+			query = query.Where(usedCase)
+		} else {
+			query = query.Where("NOT " + usedCase)
+		}
+	}
+	return query
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ReplaceTags sets the tags of a case to exactly tags.
+func (r *caseRepositoryImpl) ReplaceTags(ctx context.Context, caseID uuid.UUID, tags []string) error {
+	conn := database.Conn(ctx, r.db)
+	if err := conn.Where("case_id = ?", caseID).Delete(&caseModel.CaseTag{}).Error; err != nil {
+		return fmt.Errorf("failed to clear tags of case %s: %w", caseID.String(), err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]caseModel.CaseTag, len(tags))
+	for i, tag := range tags {
+		rows[i] = caseModel.CaseTag{CaseID: caseID, Tag: tag}
+	}
+	if err := conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to save tags of case %s: %w", caseID.String(), err)
+	}
+	return nil
+}
+
+func (r *caseRepositoryImpl) FindTags(ctx context.Context) ([]TagCount, error) {
+	var tags []TagCount
+	result := database.Conn(ctx, r.db).
+		Model(&caseModel.CaseTag{}).
+		Select("case_tags.tag AS tag, COUNT(*) AS count").
+		Joins("JOIN cases ON cases.id = case_tags.case_id AND cases.deleted_at IS NULL").
+		Group("case_tags.tag").
+		Order("count DESC, tag").
+		Scan(&tags)
 	if result.Error != nil {
-		return nil, fmt.Errorf("failed to find all cases: %w", result.Error)
+		return nil, fmt.Errorf("failed to find case tags: %w", result.Error)
 	}
-	return cases, nil
+	return tags, nil
+}
+
+func (r *caseRepositoryImpl) FindUsage(ctx context.Context, caseIDs []uuid.UUID) ([]CaseUsage, error) {
+	var usage []CaseUsage
+	if len(caseIDs) == 0 {
+		return usage, nil
+	}
+	result := database.Conn(ctx, r.db).
+		Model(&caseModel.ContestCase{}).
+		Select("contest_cases.case_id, contest_cases.contest_id, contests.name AS contest_name, "+
+			"contest_cases.problem_code, contest_cases.created_at AS added_at").
+		Joins("JOIN contests ON contests.id = contest_cases.contest_id AND contests.deleted_at IS NULL").
+		Where("contest_cases.case_id IN ?", caseIDs).
+		Order("contest_cases.created_at DESC").
+		Scan(&usage)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find contests using cases: %w", result.Error)
+	}
+	return usage, nil
 }
 
 // DeleteCase soft deletes a case.
 func (r *caseRepositoryImpl) DeleteCase(ctx context.Context, caseID uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&caseModel.Case{}, caseID).Error
+	return database.Conn(ctx, r.db).Delete(&caseModel.Case{}, caseID).Error
+}
+
+func (r *caseRepositoryImpl) SaveStatementRevision(ctx context.Context, revision *caseModel.CaseStatementRevision) error {
+	if revision.ID == uuid.Nil {
+		revision.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Create(revision).Error; err != nil {
+		return fmt.Errorf("failed to save revision %d of case %s: %w", revision.Revision, revision.CaseID.String(), err)
+	}
+	return nil
+}
+
+func (r *caseRepositoryImpl) FindStatementRevisions(ctx context.Context, caseID uuid.UUID) ([]caseModel.CaseStatementRevision, error) {
+	var revisions []caseModel.CaseStatementRevision
+	result := database.Conn(ctx, r.db).Where("case_id = ?", caseID).Order("revision desc").Find(&revisions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find revisions of case %s: %w", caseID.String(), result.Error)
+	}
+	return revisions, nil
+}
+
+func (r *caseRepositoryImpl) FindStatementRevision(ctx context.Context, caseID uuid.UUID, revision int) (*caseModel.CaseStatementRevision, error) {
+	var found caseModel.CaseStatementRevision
+	result := database.Conn(ctx, r.db).Where("case_id = ? AND revision = ?", caseID, revision).First(&found)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find revision %d of case %s: %w", revision, caseID.String(), result.Error)
+	}
+	return &found, nil
+}
+
+func (r *caseRepositoryImpl) SaveAttachment(ctx context.Context, attachment *caseModel.CaseAttachment) error {
+	if attachment.ID == uuid.Nil {
+		attachment.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Create(attachment).Error; err != nil {
+		return fmt.Errorf("failed to save attachment of case %s: %w", attachment.CaseID.String(), err)
+	}
+	return nil
+}
+
+func (r *caseRepositoryImpl) FindAttachments(ctx context.Context, caseID uuid.UUID) ([]caseModel.CaseAttachment, error) {
+	var attachments []caseModel.CaseAttachment
+	result := database.Conn(ctx, r.db).Where("case_id = ?", caseID).Order("file_name").Find(&attachments)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find attachments of case %s: %w", caseID.String(), result.Error)
+	}
+	return attachments, nil
+}
+
+func (r *caseRepositoryImpl) FindAttachment(ctx context.Context, caseID, attachmentID uuid.UUID) (*caseModel.CaseAttachment, error) {
+	var attachment caseModel.CaseAttachment
+	result := database.Conn(ctx, r.db).Where("id = ? AND case_id = ?", attachmentID, caseID).First(&attachment)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find attachment %s: %w", attachmentID.String(), result.Error)
+	}
+	return &attachment, nil
+}
+
+func (r *caseRepositoryImpl) DeleteAttachment(ctx context.Context, attachmentID uuid.UUID) error {
+	return database.Conn(ctx, r.db).Delete(&caseModel.CaseAttachment{}, attachmentID).Error
 }
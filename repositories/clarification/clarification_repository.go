@@ -0,0 +1,24 @@
+package clarificationRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	contestModel "neptune/backend/models/contest"
+)
+
+// Scope picks the clarifications of one class contest, or of the global contest when
+// ClassTransactionID is nil.
+type Scope struct {
+	ContestID          uuid.UUID
+	ClassTransactionID *uuid.UUID
+}
+
+type ClarificationRepository interface {
+	Create(ctx context.Context, clarification *contestModel.Clarification) error
+	SaveAnswer(ctx context.Context, clarification *contestModel.Clarification) error
+	FindByID(ctx context.Context, id uuid.UUID) (*contestModel.Clarification, error)
+	// FindAll lists every clarification in the scope, newest first.
+	FindAll(ctx context.Context, scope Scope) ([]contestModel.Clarification, error)
+	// FindVisibleTo lists the clarifications the user asked and the broadcast ones, newest first.
+	FindVisibleTo(ctx context.Context, scope Scope, userID uuid.UUID) ([]contestModel.Clarification, error)
+}
@@ -0,0 +1,86 @@
+package clarificationRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	contestModel "neptune/backend/models/contest"
+	"neptune/backend/pkg/database"
+)
+
+type clarificationRepository struct {
+	db *gorm.DB
+}
+
+func NewClarificationRepository(db *gorm.DB) ClarificationRepository {
+	return &clarificationRepository{db: db}
+}
+
+func (r *clarificationRepository) Create(ctx context.Context, clarification *contestModel.Clarification) error {
+	if clarification.ID == uuid.Nil {
+		clarification.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Omit("Asker").Create(clarification).Error; err != nil {
+		return fmt.Errorf("failed to create clarification: %w", err)
+	}
+	return nil
+}
+
+func (r *clarificationRepository) SaveAnswer(ctx context.Context, clarification *contestModel.Clarification) error {
+	err := database.Conn(ctx, r.db).Model(&contestModel.Clarification{}).
+		Where("id = ?", clarification.ID).
+		Updates(map[string]interface{}{
+			"answer":      clarification.Answer,
+			"answered_by": clarification.AnsweredBy,
+			"answered_at": clarification.AnsweredAt,
+			"visibility":  clarification.Visibility,
+			"updated_at":  clarification.UpdatedAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save answer of clarification %s: %w", clarification.ID, err)
+	}
+	return nil
+}
+
+func (r *clarificationRepository) FindByID(ctx context.Context, id uuid.UUID) (*contestModel.Clarification, error) {
+	var clarification contestModel.Clarification
+	err := database.Conn(ctx, r.db).Preload("Asker").Where("id = ?", id).First(&clarification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find clarification %s: %w", id, err)
+	}
+	return &clarification, nil
+}
+
+func (r *clarificationRepository) FindAll(ctx context.Context, scope Scope) ([]contestModel.Clarification, error) {
+	var clarifications []contestModel.Clarification
+	if err := r.scoped(ctx, scope).Find(&clarifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to find clarifications of contest %s: %w", scope.ContestID, err)
+	}
+	return clarifications, nil
+}
+
+func (r *clarificationRepository) FindVisibleTo(ctx context.Context, scope Scope, userID uuid.UUID) ([]contestModel.Clarification, error) {
+	var clarifications []contestModel.Clarification
+	err := r.scoped(ctx, scope).
+		Where("(asked_by = ? OR visibility = ?)", userID, contestModel.ClarificationBroadcast).
+		Find(&clarifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find clarifications of contest %s for user %s: %w", scope.ContestID, userID, err)
+	}
+	return clarifications, nil
+}
+
+func (r *clarificationRepository) scoped(ctx context.Context, scope Scope) *gorm.DB {
+	query := database.Conn(ctx, r.db).Preload("Asker").Where("contest_id = ?", scope.ContestID)
+	if scope.ClassTransactionID == nil {
+		query = query.Where("class_transaction_id IS NULL")
+	} else {
+		query = query.Where("class_transaction_id = ?", *scope.ClassTransactionID)
+	}
+	return query.Order("created_at DESC")
+}
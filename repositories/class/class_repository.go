@@ -13,6 +13,9 @@ type ClassRepository interface {
 	AddClassAssistants(ctx context.Context, classTransactionID string, assistantUserIDs []uuid.UUID) error
 	ClearClassStudents(ctx context.Context, classTransactionID string) error
 	ClearClassAssistants(ctx context.Context, classTransactionID string) error
+	RemoveClassStudents(ctx context.Context, classTransactionID string, studentUserIDs []uuid.UUID) error
+	RemoveClassAssistants(ctx context.Context, classTransactionID string, assistantUserIDs []uuid.UUID) error
+	SaveRosterChanges(ctx context.Context, changes []models.RosterChange) error
 
 	FindAllClassesBySemesterAndCourse(ctx context.Context, semesterID, courseOutlineID string) ([]models.Class, error)
 	FindFirstStudentByClassTransactionID(ctx context.Context, classTransactionID string) (*models.ClassStudent, error)
@@ -20,4 +23,9 @@ type ClassRepository interface {
 	FindClassBasicInfoBySemesterAndCourse(ctx context.Context, semesterID, courseOutlineID string) ([]models.Class, error)
 	FindClassBySemesterCourseAndStudent(ctx context.Context, semesterID, courseOutlineID, userID string) ([]models.Class, error)
 	FindClassesByUserID(ctx context.Context, userID uuid.UUID) ([]models.ClassStudent, error)
+	FindClassStudents(ctx context.Context, classTransactionID string) ([]models.ClassStudent, error)
+	FindClassAssistants(ctx context.Context, classTransactionID string) ([]models.ClassAssistant, error)
+	FindRosterChanges(ctx context.Context, classTransactionID string, limit, offset int) ([]models.RosterChange, int64, error)
+	IsClassAssistant(ctx context.Context, classTransactionID uuid.UUID, userID uuid.UUID) (bool, error)
+	IsClassStudent(ctx context.Context, classTransactionID uuid.UUID, userID uuid.UUID) (bool, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	models "neptune/backend/models/class"
+	"neptune/backend/pkg/database"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -29,7 +30,7 @@ func (c *classRepositoryImplement) AddClassStudents(ctx context.Context, classTr
 			UserID:             userID,
 		})
 	}
-	return c.db.WithContext(ctx).Create(&classStudents).Error
+	return database.Conn(ctx, c.db).Create(&classStudents).Error
 }
 
 func (c *classRepositoryImplement) AddClassAssistants(ctx context.Context, classTransactionID string, assistantUserIDs []uuid.UUID) error {
@@ -47,20 +48,20 @@ func (c *classRepositoryImplement) AddClassAssistants(ctx context.Context, class
 			UserID:             userID,
 		})
 	}
-	return c.db.WithContext(ctx).Create(&classAssistants).Error
+	return database.Conn(ctx, c.db).Create(&classAssistants).Error
 }
 
 func (c *classRepositoryImplement) ClearClassStudents(ctx context.Context, classTransactionID string) error {
-	return c.db.WithContext(ctx).Where("class_transaction_id = ?", classTransactionID).Delete(&models.ClassStudent{}).Error
+	return database.Conn(ctx, c.db).Where("class_transaction_id = ?", classTransactionID).Delete(&models.ClassStudent{}).Error
 }
 
 func (c *classRepositoryImplement) ClearClassAssistants(ctx context.Context, classTransactionID string) error {
-	return c.db.WithContext(ctx).Where("class_transaction_id = ?", classTransactionID).Delete(&models.ClassAssistant{}).Error
+	return database.Conn(ctx, c.db).Where("class_transaction_id = ?", classTransactionID).Delete(&models.ClassAssistant{}).Error
 }
 
 func (c *classRepositoryImplement) FindClassByTransactionID(ctx context.Context, classTransactionID string) (*models.Class, error) {
 	var class models.Class
-	result := c.db.WithContext(ctx).
+	result := database.Conn(ctx, c.db).
 		Preload("Students.User").
 		Preload("Assistants.User").
 		Where("class_transaction_id = ?", classTransactionID).
@@ -76,7 +77,7 @@ func (c *classRepositoryImplement) FindClassByTransactionID(ctx context.Context,
 
 func (c *classRepositoryImplement) FindClassBasicInfoBySemesterAndCourse(ctx context.Context, semesterID, courseOutlineID string) ([]models.Class, error) {
 	var classes []models.Class
-	result := c.db.WithContext(ctx).
+	result := database.Conn(ctx, c.db).
 		Select("class_transaction_id", "semester_id", "course_outline_id", "class_code").
 		Where("semester_id = ?", semesterID).
 		Where("course_outline_id = ?", courseOutlineID).
@@ -88,7 +89,7 @@ func (c *classRepositoryImplement) FindClassBasicInfoBySemesterAndCourse(ctx con
 }
 
 func (c *classRepositoryImplement) SaveClass(ctx context.Context, class *models.Class) error {
-	return c.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, c.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "class_transaction_id"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"semester_id":       class.SemesterID,
@@ -100,7 +101,7 @@ func (c *classRepositoryImplement) SaveClass(ctx context.Context, class *models.
 
 func (c *classRepositoryImplement) FindAllClassesBySemesterAndCourse(ctx context.Context, semesterId string, courseId string) ([]models.Class, error) {
 	var classes []models.Class
-	result := c.db.WithContext(ctx).
+	result := database.Conn(ctx, c.db).
 		Preload("Students.User").
 		Preload("Assistants.User").
 		Where("semester_id = ?", semesterId).
@@ -114,7 +115,7 @@ func (c *classRepositoryImplement) FindAllClassesBySemesterAndCourse(ctx context
 
 func (c *classRepositoryImplement) FindFirstStudentByClassTransactionID(ctx context.Context, classTransactionID string) (*models.ClassStudent, error) {
 	var classStudent models.ClassStudent
-	result := c.db.WithContext(ctx).
+	result := database.Conn(ctx, c.db).
 		Where("class_transaction_id = ?", classTransactionID).
 		Preload("User"). // <-- Add the field name here
 		First(&classStudent)
@@ -129,7 +130,7 @@ func (c *classRepositoryImplement) FindFirstStudentByClassTransactionID(ctx cont
 
 func (c *classRepositoryImplement) FindClassBySemesterCourseAndStudent(ctx context.Context, semesterID, courseOutlineID, userID string) ([]models.Class, error) {
 	var classes []models.Class
-	result := c.db.WithContext(ctx).
+	result := database.Conn(ctx, c.db).
 		Preload("Students.User").
 		Preload("Assistants.User").
 		Joins("JOIN class_students ON class_students.class_transaction_id = classes.class_transaction_id").
@@ -145,7 +146,7 @@ func (c *classRepositoryImplement) FindClassBySemesterCourseAndStudent(ctx conte
 
 func (c *classRepositoryImplement) FindClassesByUserID(ctx context.Context, userID uuid.UUID) ([]models.ClassStudent, error) {
 	var classStudents []models.ClassStudent
-	result := c.db.WithContext(ctx).
+	result := database.Conn(ctx, c.db).
 		Preload("Class"). // Crucially preload the associated Class model
 		Where("user_id = ?", userID).
 		Find(&classStudents)
@@ -156,6 +157,104 @@ func (c *classRepositoryImplement) FindClassesByUserID(ctx context.Context, user
 	return classStudents, nil
 }
 
+func (c *classRepositoryImplement) RemoveClassStudents(ctx context.Context, classTransactionID string, studentUserIDs []uuid.UUID) error {
+	if len(studentUserIDs) == 0 {
+		return nil
+	}
+	return database.Conn(ctx, c.db).
+		Where("class_transaction_id = ? AND user_id IN ?", classTransactionID, studentUserIDs).
+		Delete(&models.ClassStudent{}).Error
+}
+
+func (c *classRepositoryImplement) RemoveClassAssistants(ctx context.Context, classTransactionID string, assistantUserIDs []uuid.UUID) error {
+	if len(assistantUserIDs) == 0 {
+		return nil
+	}
+	return database.Conn(ctx, c.db).
+		Where("class_transaction_id = ? AND user_id IN ?", classTransactionID, assistantUserIDs).
+		Delete(&models.ClassAssistant{}).Error
+}
+
+func (c *classRepositoryImplement) SaveRosterChanges(ctx context.Context, changes []models.RosterChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return database.Conn(ctx, c.db).Create(&changes).Error
+}
+
+func (c *classRepositoryImplement) FindClassStudents(ctx context.Context, classTransactionID string) ([]models.ClassStudent, error) {
+	var students []models.ClassStudent
+	result := database.Conn(ctx, c.db).
+		Preload("User").
+		Where("class_transaction_id = ?", classTransactionID).
+		Find(&students)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find students of class %s: %w", classTransactionID, result.Error)
+	}
+	return students, nil
+}
+
+func (c *classRepositoryImplement) FindClassAssistants(ctx context.Context, classTransactionID string) ([]models.ClassAssistant, error) {
+	var assistants []models.ClassAssistant
+	result := database.Conn(ctx, c.db).
+		Preload("User").
+		Where("class_transaction_id = ?", classTransactionID).
+		Find(&assistants)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find assistants of class %s: %w", classTransactionID, result.Error)
+	}
+	return assistants, nil
+}
+
+// FindRosterChanges returns the roster change log of a class, newest first, and the total number of changes.
+func (c *classRepositoryImplement) FindRosterChanges(ctx context.Context, classTransactionID string, limit, offset int) ([]models.RosterChange, int64, error) {
+	var total int64
+	if err := database.Conn(ctx, c.db).
+		Model(&models.RosterChange{}).
+		Where("class_transaction_id = ?", classTransactionID).
+		Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count roster changes of class %s: %w", classTransactionID, err)
+	}
+
+	var changes []models.RosterChange
+	result := database.Conn(ctx, c.db).
+		Where("class_transaction_id = ?", classTransactionID).
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&changes)
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("failed to find roster changes of class %s: %w", classTransactionID, result.Error)
+	}
+	return changes, total, nil
+}
+
+// IsClassAssistant reports whether the user is an assistant of the class.
+func (c *classRepositoryImplement) IsClassAssistant(ctx context.Context, classTransactionID uuid.UUID, userID uuid.UUID) (bool, error) {
+	var count int64
+	result := database.Conn(ctx, c.db).
+		Model(&models.ClassAssistant{}).
+		Where("class_transaction_id = ? AND user_id = ?", classTransactionID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check assistant %s of class %s: %w", userID.String(), classTransactionID.String(), result.Error)
+	}
+	return count > 0, nil
+}
+
+// IsClassStudent reports whether the user is a student of the class.
+func (c *classRepositoryImplement) IsClassStudent(ctx context.Context, classTransactionID uuid.UUID, userID uuid.UUID) (bool, error) {
+	var count int64
+	result := database.Conn(ctx, c.db).
+		Model(&models.ClassStudent{}).
+		Where("class_transaction_id = ? AND user_id = ?", classTransactionID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check student %s of class %s: %w", userID.String(), classTransactionID.String(), result.Error)
+	}
+	return count > 0, nil
+}
+
 func NewClassRepository(db *gorm.DB) ClassRepository {
 	return &classRepositoryImplement{
 		db: db,
@@ -2,19 +2,24 @@ package contestRepository
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	contestModel "neptune/backend/models/contest"
 )
 
+// ErrProblemCodeTaken is returned when a problem code is already used by another problem of the contest.
+var ErrProblemCodeTaken = errors.New("problem code is already used in this contest")
+
 type ContestRepository interface {
 	// Global Contest Management
 	SaveGlobalContestDetail(ctx context.Context, detail *contestModel.GlobalContestDetail) error
+	DeleteGlobalContestDetail(ctx context.Context, contestID uuid.UUID) error
 	FindAllActiveGlobalContests(ctx context.Context) ([]contestModel.Contest, error)
 
 	SaveContest(ctx context.Context, contest *contestModel.Contest) error
 	FindContestByID(ctx context.Context, contestID uuid.UUID) (*contestModel.Contest, error)
-	FindAllContests(ctx context.Context) ([]contestModel.Contest, error)
-	DeleteContest(ctx context.Context, contestID uuid.UUID) error // Soft delete
+	FindAllContests(ctx context.Context, courseID *uuid.UUID) ([]contestModel.Contest, error) // courseID nil lists every contest
+	DeleteContest(ctx context.Context, contestID uuid.UUID) error                             // Soft delete
 
 	// ContestCase (Problems in a Contest) Management
 	AddCasesToContest(ctx context.Context, contestID uuid.UUID, cases []contestModel.ContestCase) error
@@ -22,10 +27,34 @@ type ContestRepository interface {
 	FindContestCases(ctx context.Context, contestID uuid.UUID) ([]contestModel.ContestCase, error)
 	GetCaseCountInContest(ctx context.Context, contestID uuid.UUID) (int, error)
 	GetContestCaseByCaseID(ctx context.Context, contestID, caseID uuid.UUID) (*contestModel.ContestCase, error)
+	FindContestIDsByCase(ctx context.Context, caseID uuid.UUID) ([]uuid.UUID, error)
+	UpdateContestCase(ctx context.Context, contestCase *contestModel.ContestCase) error // Saves code, position, points and color
+	ReleaseProblemCodes(ctx context.Context, contestID uuid.UUID) error                 // Placeholder codes, so a transaction can swap codes
+	RemoveCaseFromContest(ctx context.Context, contestID, caseID uuid.UUID) error
 
 	// ClassContest (Contest Assignment to Class) Management
 	AssignContestToClass(ctx context.Context, classContest *contestModel.ClassContest) error
 	FindContestsByClassTransactionID(ctx context.Context, classTransactionID uuid.UUID) ([]contestModel.ClassContest, error)
 	FindClassContestByIDs(ctx context.Context, classTransactionID, contestID uuid.UUID) (*contestModel.ClassContest, error)
+	FindClassContestsByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContest, error)
 	RemoveContestFromClass(ctx context.Context, classTransactionID, contestID uuid.UUID) error
+
+	// ClassContestOverride (per-student windows) Management
+	SaveOverride(ctx context.Context, override *contestModel.ClassContestOverride) error // Upserts on class, contest and user
+	DeleteOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID) error
+	FindOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID) (*contestModel.ClassContestOverride, error)
+	FindOverrides(ctx context.Context, classTransactionID, contestID uuid.UUID) ([]contestModel.ClassContestOverride, error)
+	FindOverridesByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContestOverride, error)
+	FindUserOverridesInClass(ctx context.Context, classTransactionID, userID uuid.UUID) ([]contestModel.ClassContestOverride, error)
+
+	// VirtualParticipation Management
+	CreateVirtualParticipation(ctx context.Context, participation *contestModel.VirtualParticipation) error
+	FindVirtualParticipationByID(ctx context.Context, id uuid.UUID) (*contestModel.VirtualParticipation, error)
+	// FindVirtualParticipation looks up the user's run of the class contest, or of the global contest when
+	// classTransactionID is nil.
+	FindVirtualParticipation(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*contestModel.VirtualParticipation, error)
+
+	// ContestAccessGrant Management
+	SaveAccessGrant(ctx context.Context, grant *contestModel.ContestAccessGrant) error // Keeps the first grant
+	HasAccessGrant(ctx context.Context, contestID, userID uuid.UUID) (bool, error)
 }
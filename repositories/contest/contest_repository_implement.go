@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	contestModel "neptune/backend/models/contest"
+	"neptune/backend/pkg/database"
 
 	"time"
 )
@@ -19,22 +20,22 @@ func NewContestRepository(db *gorm.DB) ContestRepository {
 	return &contestRepositoryImpl{db: db}
 }
 
-//func (r *contestRepositoryImpl) WithTransaction(f func(tx *gorm.DB) error) error {
-//	return r.db.Transaction(f)
-//}
-
 // SaveContest creates or updates a Contest.
 func (r *contestRepositoryImpl) SaveContest(ctx context.Context, contest *contestModel.Contest) error {
 	if contest.ID == uuid.Nil {
 		contest.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "id"}}, // Conflict on primary key (ID)
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"name":        contest.Name,
-			"scope":       contest.Scope,
-			"description": contest.Description,
-			"updated_at":  time.Now(),
+			"name":             contest.Name,
+			"scope":            contest.Scope,
+			"description":      contest.Description,
+			"course_id":        contest.CourseID,
+			"status_override":  contest.StatusOverride,
+			"visibility":       contest.Visibility,
+			"access_code_hash": contest.AccessCodeHash,
+			"updated_at":       time.Now(),
 		}),
 	}).Create(contest).Error
 }
@@ -43,7 +44,7 @@ func (r *contestRepositoryImpl) SaveGlobalContestDetail(ctx context.Context, det
 	if detail.ContestID == uuid.Nil {
 		return fmt.Errorf("contest ID cannot be nil")
 	}
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "contest_id"}}, // Conflict on primary key (ContestID)
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"start_time": detail.StartTime,
@@ -53,10 +54,19 @@ func (r *contestRepositoryImpl) SaveGlobalContestDetail(ctx context.Context, det
 
 }
 
+// DeleteGlobalContestDetail removes the global schedule of a contest that is no longer global.
+func (r *contestRepositoryImpl) DeleteGlobalContestDetail(ctx context.Context, contestID uuid.UUID) error {
+	result := database.Conn(ctx, r.db).Where("contest_id = ?", contestID).Delete(&contestModel.GlobalContestDetail{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete global contest detail %s: %w", contestID.String(), result.Error)
+	}
+	return nil
+}
+
 // FindAllGlobalContests retrieves all global contest details.
 func (r *contestRepositoryImpl) FindAllActiveGlobalContests(ctx context.Context) ([]contestModel.Contest, error) {
 	var details []contestModel.Contest
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Preload("GlobalContestDetail").
 		Joins("JOIN global_contest_details ON global_contest_details.contest_id = contests.id").
 		Where("global_contest_details.start_time <= ? AND global_contest_details.end_time >= ?", time.Now(), time.Now()).
@@ -69,7 +79,7 @@ func (r *contestRepositoryImpl) FindAllActiveGlobalContests(ctx context.Context)
 
 func (r *contestRepositoryImpl) GetContestCaseByCaseID(ctx context.Context, contestID, caseID uuid.UUID) (*contestModel.ContestCase, error) {
 	var contestCase contestModel.ContestCase
-	result := r.db.WithContext(ctx).Preload("Case").Where("contest_id", contestID).Where("case_id", caseID).Find(&contestCase)
+	result := database.Conn(ctx, r.db).Preload("Case").Where("contest_id", contestID).Where("case_id", caseID).Find(&contestCase)
 	if result.Error != nil {
 		if result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
@@ -85,8 +95,11 @@ func (r *contestRepositoryImpl) GetContestCaseByCaseID(ctx context.Context, cont
 // FindContestByID retrieves a Contest with its associated Cases.
 func (r *contestRepositoryImpl) FindContestByID(ctx context.Context, contestID uuid.UUID) (*contestModel.Contest, error) {
 	var contest contestModel.Contest
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Preload("GlobalContestDetail").
+		Preload("ContestCases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, problem_code")
+		}).
 		Preload("ContestCases.Case"). // Preload join table, then the Case itself
 		Where("id = ?", contestID).
 		First(&contest)
@@ -99,10 +112,14 @@ func (r *contestRepositoryImpl) FindContestByID(ctx context.Context, contestID u
 	return &contest, nil
 }
 
-// FindAllContests retrieves all Contests (basic info).
-func (r *contestRepositoryImpl) FindAllContests(ctx context.Context) ([]contestModel.Contest, error) {
+// FindAllContests retrieves all Contests (basic info), optionally only those of a course.
+func (r *contestRepositoryImpl) FindAllContests(ctx context.Context, courseID *uuid.UUID) ([]contestModel.Contest, error) {
 	var contests []contestModel.Contest
-	result := r.db.WithContext(ctx).Find(&contests)
+	query := database.Conn(ctx, r.db)
+	if courseID != nil {
+		query = query.Where("course_id = ?", *courseID)
+	}
+	result := query.Find(&contests)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to find all contests: %w", result.Error)
 	}
@@ -111,13 +128,13 @@ func (r *contestRepositoryImpl) FindAllContests(ctx context.Context) ([]contestM
 
 // DeleteContest soft deletes a contest.
 func (r *contestRepositoryImpl) DeleteContest(ctx context.Context, contestID uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&contestModel.Contest{}, contestID).Error
+	return database.Conn(ctx, r.db).Delete(&contestModel.Contest{}, contestID).Error
 }
 
 // AddCasesToContest adds multiple cases to a contest (via ContestCase join table).
 // It clears existing assignments for the given contest before adding new ones.
 func (r *contestRepositoryImpl) AddCasesToContest(ctx context.Context, contestID uuid.UUID, contestCases []contestModel.ContestCase) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		if len(contestCases) == 0 {
 			return nil
 		}
@@ -146,6 +163,9 @@ func (r *contestRepositoryImpl) AddCasesToContest(ctx context.Context, contestID
 
 		if len(filtered) > 0 {
 			if err := tx.Create(&filtered).Error; err != nil {
+				if database.IsUniqueViolation(err) {
+					return ErrProblemCodeTaken
+				}
 				return fmt.Errorf("failed to add filtered cases: %w", err)
 			}
 		}
@@ -165,13 +185,13 @@ func getCaseIDs(contestCases []contestModel.ContestCase) []uuid.UUID {
 
 // ClearContestCases removes all cases from a contest.
 func (r *contestRepositoryImpl) ClearContestCases(ctx context.Context, contestID uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("contest_id = ?", contestID).Delete(&contestModel.ContestCase{}).Error
+	return database.Conn(ctx, r.db).Where("contest_id = ?", contestID).Delete(&contestModel.ContestCase{}).Error
 }
 
 // AssignContestToClass assigns a contest to a class with specific start/end times.
 // It upserts the ClassContest record.
 func (r *contestRepositoryImpl) AssignContestToClass(ctx context.Context, classContest *contestModel.ClassContest) error {
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{
 			{Name: "class_transaction_id"},
 			{Name: "contest_id"},
@@ -187,7 +207,7 @@ func (r *contestRepositoryImpl) AssignContestToClass(ctx context.Context, classC
 // FindContestsByClassTransactionID retrieves all contests assigned to a specific class, with their durations.
 func (r *contestRepositoryImpl) FindContestsByClassTransactionID(ctx context.Context, classTransactionID uuid.UUID) ([]contestModel.ClassContest, error) {
 	var classContests []contestModel.ClassContest
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Preload("Contest").                   // Preload the Contest details
 		Preload("Contest.ContestCases.Case"). // Further preload Cases within the Contest
 		Where("class_transaction_id = ?", classTransactionID).
@@ -201,7 +221,7 @@ func (r *contestRepositoryImpl) FindContestsByClassTransactionID(ctx context.Con
 // FindClassContestByIDs finds a specific ClassContest entry.
 func (r *contestRepositoryImpl) FindClassContestByIDs(ctx context.Context, classTransactionID, contestID uuid.UUID) (*contestModel.ClassContest, error) {
 	var classContest contestModel.ClassContest
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Where("class_transaction_id = ?", classTransactionID).
 		Where("contest_id = ?", contestID).
 		First(&classContest)
@@ -214,13 +234,38 @@ func (r *contestRepositoryImpl) FindClassContestByIDs(ctx context.Context, class
 	return &classContest, nil
 }
 
+// FindClassContestsByContest lists every class the contest is assigned to, with its window.
+func (r *contestRepositoryImpl) FindClassContestsByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContest, error) {
+	var classContests []contestModel.ClassContest
+	result := database.Conn(ctx, r.db).
+		Where("contest_id = ?", contestID).
+		Find(&classContests)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find classes of contest %s: %w", contestID.String(), result.Error)
+	}
+	return classContests, nil
+}
+
+// FindContestIDsByCase lists the contests the case is a problem of.
+func (r *contestRepositoryImpl) FindContestIDsByCase(ctx context.Context, caseID uuid.UUID) ([]uuid.UUID, error) {
+	var contestIDs []uuid.UUID
+	result := database.Conn(ctx, r.db).
+		Model(&contestModel.ContestCase{}).
+		Where("case_id = ?", caseID).
+		Pluck("contest_id", &contestIDs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find contests of case %s: %w", caseID.String(), result.Error)
+	}
+	return contestIDs, nil
+}
+
 // FindContestCases retrieves all cases for a specific contest.
 func (r *contestRepositoryImpl) FindContestCases(ctx context.Context, contestID uuid.UUID) ([]contestModel.ContestCase, error) {
 	var cases []contestModel.ContestCase
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Preload("Case"). // Preload the Case details
 		Where("contest_id = ?", contestID).
-		Order("problem_code").
+		Order("position, problem_code").
 		Find(&cases)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to find cases for contest %s: %w", contestID.String(), result.Error)
@@ -228,9 +273,54 @@ func (r *contestRepositoryImpl) FindContestCases(ctx context.Context, contestID
 	return cases, nil
 }
 
+// UpdateContestCase saves the problem code, position, points and color of a problem in a contest.
+func (r *contestRepositoryImpl) UpdateContestCase(ctx context.Context, contestCase *contestModel.ContestCase) error {
+	result := database.Conn(ctx, r.db).
+		Model(&contestModel.ContestCase{}).
+		Where("contest_id = ? AND case_id = ?", contestCase.ContestID, contestCase.CaseID).
+		Updates(map[string]interface{}{
+			"problem_code": contestCase.ProblemCode,
+			"position":     contestCase.Position,
+			"points":       contestCase.Points,
+			"color":        contestCase.Color,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return ErrProblemCodeTaken
+		}
+		return fmt.Errorf("failed to update case %s in contest %s: %w", contestCase.CaseID.String(), contestCase.ContestID.String(), result.Error)
+	}
+	return nil
+}
+
+// ReleaseProblemCodes gives every problem of the contest a placeholder code ("~1", "~2", ...), so problems
+// saved one by one afterwards in the same transaction can swap codes without tripping the unique index.
+func (r *contestRepositoryImpl) ReleaseProblemCodes(ctx context.Context, contestID uuid.UUID) error {
+	result := database.Conn(ctx, r.db).Exec(`
+		UPDATE contest_cases SET problem_code = '~' || numbered.n
+		FROM (SELECT case_id, row_number() OVER (ORDER BY case_id) AS n FROM contest_cases WHERE contest_id = ?) numbered
+		WHERE contest_cases.contest_id = ? AND contest_cases.case_id = numbered.case_id`, contestID, contestID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to release problem codes of contest %s: %w", contestID.String(), result.Error)
+	}
+	return nil
+}
+
+// RemoveCaseFromContest takes one problem out of a contest. Submissions to it are kept.
+func (r *contestRepositoryImpl) RemoveCaseFromContest(ctx context.Context, contestID, caseID uuid.UUID) error {
+	result := database.Conn(ctx, r.db).
+		Where("contest_id = ? AND case_id = ?", contestID, caseID).
+		Delete(&contestModel.ContestCase{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove case %s from contest %s: %w", caseID.String(), contestID.String(), result.Error)
+	}
+	return nil
+}
+
 func (r *contestRepositoryImpl) GetCaseCountInContest(ctx context.Context, contestID uuid.UUID) (int, error) {
 	var count int64
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Model(&contestModel.ContestCase{}).
 		Where("contest_id = ?", contestID).
 		Count(&count)
@@ -240,9 +330,16 @@ func (r *contestRepositoryImpl) GetCaseCountInContest(ctx context.Context, conte
 	return int(count), nil
 }
 
-// RemoveContestFromClass deletes a contest assignment from a class.
+// RemoveContestFromClass deletes a contest assignment from a class, along with its per-student windows.
 func (r *contestRepositoryImpl) RemoveContestFromClass(ctx context.Context, classTransactionID, contestID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
+		Where("class_transaction_id = ?", classTransactionID).
+		Where("contest_id = ?", contestID).
+		Delete(&contestModel.ClassContestOverride{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove overrides of contest %s in class %s: %w", contestID.String(), classTransactionID.String(), result.Error)
+	}
+	result = database.Conn(ctx, r.db).
 		Where("class_transaction_id = ?", classTransactionID).
 		Where("contest_id = ?", contestID).
 		Delete(&contestModel.ClassContest{})
@@ -251,3 +348,146 @@ func (r *contestRepositoryImpl) RemoveContestFromClass(ctx context.Context, clas
 	}
 	return nil
 }
+
+// SaveOverride creates or replaces a student's window for a class contest.
+func (r *contestRepositoryImpl) SaveOverride(ctx context.Context, override *contestModel.ClassContestOverride) error {
+	if override.ID == uuid.Nil {
+		override.ID = uuid.New()
+	}
+	result := database.Conn(ctx, r.db).Omit("User").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "class_transaction_id"}, {Name: "contest_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"start_time":    override.StartTime,
+			"end_time":      override.EndTime,
+			"extra_minutes": override.ExtraMinutes,
+			"reason":        override.Reason,
+			"granted_by":    override.GrantedBy,
+			"updated_at":    time.Now(),
+		}),
+	}).Create(override)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save override of user %s in contest %s: %w", override.UserID.String(), override.ContestID.String(), result.Error)
+	}
+	return nil
+}
+
+func (r *contestRepositoryImpl) DeleteOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID) error {
+	result := database.Conn(ctx, r.db).
+		Where("class_transaction_id = ? AND contest_id = ? AND user_id = ?", classTransactionID, contestID, userID).
+		Delete(&contestModel.ClassContestOverride{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete override of user %s in contest %s: %w", userID.String(), contestID.String(), result.Error)
+	}
+	return nil
+}
+
+func (r *contestRepositoryImpl) FindOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID) (*contestModel.ClassContestOverride, error) {
+	var override contestModel.ClassContestOverride
+	result := database.Conn(ctx, r.db).
+		Preload("User").
+		Where("class_transaction_id = ? AND contest_id = ? AND user_id = ?", classTransactionID, contestID, userID).
+		First(&override)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find override of user %s in contest %s: %w", userID.String(), contestID.String(), result.Error)
+	}
+	return &override, nil
+}
+
+func (r *contestRepositoryImpl) FindOverrides(ctx context.Context, classTransactionID, contestID uuid.UUID) ([]contestModel.ClassContestOverride, error) {
+	var overrides []contestModel.ClassContestOverride
+	result := database.Conn(ctx, r.db).
+		Preload("User").
+		Where("class_transaction_id = ? AND contest_id = ?", classTransactionID, contestID).
+		Order("created_at asc").
+		Find(&overrides)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find overrides of contest %s in class %s: %w", contestID.String(), classTransactionID.String(), result.Error)
+	}
+	return overrides, nil
+}
+
+// FindOverridesByContest lists the per-student windows of the contest across all its classes.
+func (r *contestRepositoryImpl) FindOverridesByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContestOverride, error) {
+	var overrides []contestModel.ClassContestOverride
+	result := database.Conn(ctx, r.db).
+		Where("contest_id = ?", contestID).
+		Find(&overrides)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find overrides of contest %s: %w", contestID.String(), result.Error)
+	}
+	return overrides, nil
+}
+
+func (r *contestRepositoryImpl) FindUserOverridesInClass(ctx context.Context, classTransactionID, userID uuid.UUID) ([]contestModel.ClassContestOverride, error) {
+	var overrides []contestModel.ClassContestOverride
+	result := database.Conn(ctx, r.db).
+		Where("class_transaction_id = ? AND user_id = ?", classTransactionID, userID).
+		Find(&overrides)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find overrides of user %s in class %s: %w", userID.String(), classTransactionID.String(), result.Error)
+	}
+	return overrides, nil
+}
+
+func (r *contestRepositoryImpl) CreateVirtualParticipation(ctx context.Context, participation *contestModel.VirtualParticipation) error {
+	if participation.ID == uuid.Nil {
+		participation.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Create(participation).Error; err != nil {
+		return fmt.Errorf("failed to create virtual participation in contest %s: %w", participation.ContestID.String(), err)
+	}
+	return nil
+}
+
+func (r *contestRepositoryImpl) FindVirtualParticipationByID(ctx context.Context, id uuid.UUID) (*contestModel.VirtualParticipation, error) {
+	var participation contestModel.VirtualParticipation
+	result := database.Conn(ctx, r.db).Where("id = ?", id).First(&participation)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find virtual participation %s: %w", id.String(), result.Error)
+	}
+	return &participation, nil
+}
+
+func (r *contestRepositoryImpl) FindVirtualParticipation(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*contestModel.VirtualParticipation, error) {
+	query := database.Conn(ctx, r.db).Where("contest_id = ? AND user_id = ?", contestID, userID)
+	if classTransactionID == nil {
+		query = query.Where("class_transaction_id IS NULL")
+	} else {
+		query = query.Where("class_transaction_id = ?", *classTransactionID)
+	}
+
+	var participation contestModel.VirtualParticipation
+	result := query.Order("started_at desc").First(&participation)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find virtual participation of user %s in contest %s: %w", userID.String(), contestID.String(), result.Error)
+	}
+	return &participation, nil
+}
+
+func (r *contestRepositoryImpl) SaveAccessGrant(ctx context.Context, grant *contestModel.ContestAccessGrant) error {
+	result := database.Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(grant)
+	if result.Error != nil {
+		return fmt.Errorf("failed to grant user %s access to contest %s: %w", grant.UserID.String(), grant.ContestID.String(), result.Error)
+	}
+	return nil
+}
+
+func (r *contestRepositoryImpl) HasAccessGrant(ctx context.Context, contestID, userID uuid.UUID) (bool, error) {
+	var count int64
+	result := database.Conn(ctx, r.db).Model(&contestModel.ContestAccessGrant{}).
+		Where("contest_id = ? AND user_id = ?", contestID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check access of user %s to contest %s: %w", userID.String(), contestID.String(), result.Error)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,21 @@
+package courseRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	courseModel "neptune/backend/models/course"
+)
+
+type CourseRepository interface {
+	// SaveCourse creates or updates a course and replaces its semesters. It runs several statements, so
+	// callers wrap it in a transaction.
+	SaveCourse(ctx context.Context, course *courseModel.Course) error
+	DeleteCourse(ctx context.Context, courseID uuid.UUID) error
+	FindCourseByID(ctx context.Context, courseID uuid.UUID) (*courseModel.Course, error)
+	FindCourseByOutlineID(ctx context.Context, courseOutlineID uuid.UUID) (*courseModel.Course, error)
+	FindCourseByCode(ctx context.Context, code string) (*courseModel.Course, error)
+	FindAllCourses(ctx context.Context, activeOnly bool) ([]courseModel.Course, error)
+	// FindActiveCoursesForSemester returns the active courses that run in the semester.
+	FindActiveCoursesForSemester(ctx context.Context, semesterID string) ([]courseModel.Course, error)
+	CountCourses(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,102 @@
+package courseRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	courseModel "neptune/backend/models/course"
+	"neptune/backend/pkg/database"
+)
+
+type courseRepository struct {
+	db *gorm.DB
+}
+
+func (r *courseRepository) SaveCourse(ctx context.Context, course *courseModel.Course) error {
+	db := database.Conn(ctx, r.db)
+	if err := db.Omit("Semesters").Save(course).Error; err != nil {
+		return fmt.Errorf("failed to save course %s: %w", course.Code, err)
+	}
+	if err := db.Where("course_id = ?", course.ID).Delete(&courseModel.CourseSemester{}).Error; err != nil {
+		return fmt.Errorf("failed to clear semesters of course %s: %w", course.Code, err)
+	}
+	if len(course.Semesters) == 0 {
+		return nil
+	}
+	for i := range course.Semesters {
+		course.Semesters[i].CourseID = course.ID
+	}
+	if err := db.Create(&course.Semesters).Error; err != nil {
+		return fmt.Errorf("failed to save semesters of course %s: %w", course.Code, err)
+	}
+	return nil
+}
+
+func (r *courseRepository) DeleteCourse(ctx context.Context, courseID uuid.UUID) error {
+	return database.Conn(ctx, r.db).Delete(&courseModel.Course{}, courseID).Error
+}
+
+func (r *courseRepository) FindCourseByID(ctx context.Context, courseID uuid.UUID) (*courseModel.Course, error) {
+	return r.findOne(ctx, "id = ?", courseID)
+}
+
+func (r *courseRepository) FindCourseByOutlineID(ctx context.Context, courseOutlineID uuid.UUID) (*courseModel.Course, error) {
+	return r.findOne(ctx, "course_outline_id = ?", courseOutlineID)
+}
+
+func (r *courseRepository) FindCourseByCode(ctx context.Context, code string) (*courseModel.Course, error) {
+	return r.findOne(ctx, "code = ?", code)
+}
+
+func (r *courseRepository) findOne(ctx context.Context, query string, args ...interface{}) (*courseModel.Course, error) {
+	var course courseModel.Course
+	err := database.Conn(ctx, r.db).Preload("Semesters").Where(query, args...).First(&course).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find course: %w", err)
+	}
+	return &course, nil
+}
+
+func (r *courseRepository) FindAllCourses(ctx context.Context, activeOnly bool) ([]courseModel.Course, error) {
+	var courses []courseModel.Course
+	query := database.Conn(ctx, r.db).Preload("Semesters").Order("code asc")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	if err := query.Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("failed to find courses: %w", err)
+	}
+	return courses, nil
+}
+
+func (r *courseRepository) FindActiveCoursesForSemester(ctx context.Context, semesterID string) ([]courseModel.Course, error) {
+	var courses []courseModel.Course
+	err := database.Conn(ctx, r.db).
+		Preload("Semesters").
+		Where("active = ?", true).
+		Where(`(NOT EXISTS (SELECT 1 FROM course_semesters cs WHERE cs.course_id = courses.id)
+			OR EXISTS (SELECT 1 FROM course_semesters cs WHERE cs.course_id = courses.id AND cs.semester_id = ?))`, semesterID).
+		Order("code asc").
+		Find(&courses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find courses for semester %s: %w", semesterID, err)
+	}
+	return courses, nil
+}
+
+func (r *courseRepository) CountCourses(ctx context.Context) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, r.db).Model(&courseModel.Course{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count courses: %w", err)
+	}
+	return count, nil
+}
+
+func NewCourseRepository(db *gorm.DB) CourseRepository {
+	return &courseRepository{db: db}
+}
@@ -0,0 +1,78 @@
+package courseRepo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	courseModel "neptune/backend/models/course"
+	"strings"
+	"testing"
+)
+
+// recordingPool is a gorm connection that records every statement and reports no affected rows, so Save
+// takes the insert path it takes for a new course.
+type recordingPool struct {
+	statements []string
+	args       [][]interface{}
+}
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.statements = append(p.statements, query)
+	p.args = append(p.args, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestSaveCourseKeepsInactiveCourse(t *testing.T) {
+	pool := &recordingPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	course := &courseModel.Course{
+		ID:              uuid.New(),
+		CourseOutlineID: uuid.New(),
+		Code:            "COMP6047001",
+		Name:            "Algorithm and Programming",
+		Active:          false,
+	}
+	if err := NewCourseRepository(db).SaveCourse(context.Background(), course); err != nil {
+		t.Fatal(err)
+	}
+	if course.Active {
+		t.Error("SaveCourse activated an inactive course")
+	}
+
+	const prefix = `INSERT INTO "courses" (`
+	for i, query := range pool.statements {
+		if !strings.HasPrefix(query, prefix) {
+			continue
+		}
+		columns := strings.Split(query[len(prefix):strings.Index(query, ")")], ",")
+		for j, column := range columns {
+			if strings.Trim(column, `" `) == "active" {
+				if pool.args[i][j] != false {
+					t.Errorf("active was inserted as %v, want false", pool.args[i][j])
+				}
+				return
+			}
+		}
+		t.Fatalf("INSERT INTO courses does not write active: %s", query)
+	}
+	t.Fatal("no INSERT INTO courses was run")
+}
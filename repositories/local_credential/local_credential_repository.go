@@ -0,0 +1,20 @@
+package local_credential
+
+import (
+	"context"
+	model "neptune/backend/models/user"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LocalCredentialRepository interface {
+	SaveCredential(ctx context.Context, credential *model.LocalCredential) error
+	GetCredentialByUserID(ctx context.Context, userID uuid.UUID) (*model.LocalCredential, error)
+	CreateResetToken(ctx context.Context, token *model.PasswordResetToken) error
+	GetResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	// UseResetToken marks an unused token as used and reports whether it was still unused.
+	UseResetToken(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error)
+	// RevokeResetTokens marks every unused token of the user as used.
+	RevokeResetTokens(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,90 @@
+package local_credential
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type localCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewLocalCredentialRepository(db *gorm.DB) LocalCredentialRepository {
+	return &localCredentialRepository{db: db}
+}
+
+func (r *localCredentialRepository) SaveCredential(ctx context.Context, credential *model.LocalCredential) error {
+	err := database.Conn(ctx, r.db).Omit("User").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"password_hash":       credential.PasswordHash,
+			"password_changed_at": credential.PasswordChangedAt,
+		}),
+	}).Create(credential).Error
+	if err != nil {
+		return fmt.Errorf("failed to save credential of user %s: %w", credential.UserID, err)
+	}
+	return nil
+}
+
+func (r *localCredentialRepository) GetCredentialByUserID(ctx context.Context, userID uuid.UUID) (*model.LocalCredential, error) {
+	var credential model.LocalCredential
+	err := database.Conn(ctx, r.db).Where("user_id = ?", userID).First(&credential).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find credential of user %s: %w", userID, err)
+	}
+	return &credential, nil
+}
+
+func (r *localCredentialRepository) CreateResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Omit("User").Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+	return nil
+}
+
+func (r *localCredentialRepository) GetResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	err := database.Conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find password reset token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *localCredentialRepository) UseResetToken(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use password reset token %s: %w", id, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *localCredentialRepository) RevokeResetTokens(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	err := database.Conn(ctx, r.db).Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke password reset tokens of user %s: %w", userID, err)
+	}
+	return nil
+}
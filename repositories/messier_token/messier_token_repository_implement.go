@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
 )
 
 type messierTokenRepositoryImplement struct {
@@ -14,7 +15,7 @@ type messierTokenRepositoryImplement struct {
 
 func (m messierTokenRepositoryImplement) Save(ctx context.Context, token *model.MessierToken) error {
 	// GORM's Upsert for PostgreSQL/SQLite
-	err := m.db.WithContext(ctx).Clauses(clause.OnConflict{
+	err := database.Conn(ctx, m.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "user_id"}}, // Conflict on UserID
 		DoUpdates: clause.Assignments(map[string]interface{}{ // Update these fields on conflict
 			"messier_access_token":  token.MessierAccessToken,
@@ -30,7 +31,7 @@ func (m messierTokenRepositoryImplement) Save(ctx context.Context, token *model.
 
 func (m messierTokenRepositoryImplement) GetMessierTokenByUserID(ctx context.Context, userID string) (*model.MessierToken, error) {
 	var token model.MessierToken
-	result := m.db.WithContext(ctx).Where("user_id = ?", userID).First(&token)
+	result := database.Conn(ctx, m.db).Where("user_id = ?", userID).First(&token)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil // Not found is not an error, just return nil
@@ -41,7 +42,7 @@ func (m messierTokenRepositoryImplement) GetMessierTokenByUserID(ctx context.Con
 }
 
 func (m messierTokenRepositoryImplement) DeleteByUserID(ctx context.Context, userID string) error {
-	result := m.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.MessierToken{})
+	result := database.Conn(ctx, m.db).Where("user_id = ?", userID).Delete(&model.MessierToken{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete external token for user ID %s: %w", userID, result.Error)
 	}
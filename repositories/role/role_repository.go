@@ -0,0 +1,23 @@
+package roleRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	model "neptune/backend/models/user"
+)
+
+type RoleRepository interface {
+	CreateAssignment(ctx context.Context, assignment *model.RoleAssignment) error
+	DeleteAssignment(ctx context.Context, id uuid.UUID) error
+	FindAssignmentByID(ctx context.Context, id uuid.UUID) (*model.RoleAssignment, error)
+	FindAssignmentsByUserID(ctx context.Context, userID uuid.UUID) ([]model.RoleAssignment, error)
+	// FindAssignment looks up the grant of role to the user for one class, or the global one when
+	// classTransactionID is nil.
+	FindAssignment(ctx context.Context, userID uuid.UUID, role model.Role, classTransactionID *uuid.UUID) (*model.RoleAssignment, error)
+	// HasClassRole reports whether the user holds role globally or for the class.
+	HasClassRole(ctx context.Context, userID uuid.UUID, role model.Role, classTransactionID uuid.UUID) (bool, error)
+
+	SaveAuditEntry(ctx context.Context, entry *model.RoleAuditEntry) error
+	// FindAuditEntries lists entries newest first, only those of userID when it is not nil.
+	FindAuditEntries(ctx context.Context, userID *uuid.UUID, limit, offset int) ([]model.RoleAuditEntry, int64, error)
+}
@@ -0,0 +1,117 @@
+package roleRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) CreateAssignment(ctx context.Context, assignment *model.RoleAssignment) error {
+	if assignment.ID == uuid.Nil {
+		assignment.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Omit("User").Create(assignment).Error; err != nil {
+		return fmt.Errorf("failed to create role assignment: %w", err)
+	}
+	return nil
+}
+
+func (r *roleRepository) DeleteAssignment(ctx context.Context, id uuid.UUID) error {
+	if err := database.Conn(ctx, r.db).Delete(&model.RoleAssignment{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete role assignment %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *roleRepository) FindAssignmentByID(ctx context.Context, id uuid.UUID) (*model.RoleAssignment, error) {
+	var assignment model.RoleAssignment
+	err := database.Conn(ctx, r.db).Where("id = ?", id).First(&assignment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find role assignment %s: %w", id, err)
+	}
+	return &assignment, nil
+}
+
+func (r *roleRepository) FindAssignmentsByUserID(ctx context.Context, userID uuid.UUID) ([]model.RoleAssignment, error) {
+	var assignments []model.RoleAssignment
+	err := database.Conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("granted_at ASC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find role assignments of user %s: %w", userID, err)
+	}
+	return assignments, nil
+}
+
+func (r *roleRepository) FindAssignment(ctx context.Context, userID uuid.UUID, role model.Role, classTransactionID *uuid.UUID) (*model.RoleAssignment, error) {
+	query := database.Conn(ctx, r.db).Where("user_id = ? AND role = ?", userID, role)
+	if classTransactionID == nil {
+		query = query.Where("class_transaction_id IS NULL")
+	} else {
+		query = query.Where("class_transaction_id = ?", *classTransactionID)
+	}
+
+	var assignment model.RoleAssignment
+	if err := query.First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find %s assignment of user %s: %w", role, userID, err)
+	}
+	return &assignment, nil
+}
+
+func (r *roleRepository) HasClassRole(ctx context.Context, userID uuid.UUID, role model.Role, classTransactionID uuid.UUID) (bool, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).Model(&model.RoleAssignment{}).
+		Where("user_id = ? AND role = ? AND (class_transaction_id IS NULL OR class_transaction_id = ?)", userID, role, classTransactionID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s assignment of user %s: %w", role, userID, err)
+	}
+	return count > 0, nil
+}
+
+func (r *roleRepository) SaveAuditEntry(ctx context.Context, entry *model.RoleAuditEntry) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to save role audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *roleRepository) FindAuditEntries(ctx context.Context, userID *uuid.UUID, limit, offset int) ([]model.RoleAuditEntry, int64, error) {
+	query := database.Conn(ctx, r.db).Model(&model.RoleAuditEntry{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count role audit entries: %w", err)
+	}
+
+	var entries []model.RoleAuditEntry
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find role audit entries: %w", err)
+	}
+	return entries, total, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	model "neptune/backend/models/semester"
+	"neptune/backend/pkg/database"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,7 +17,7 @@ type semesterRepository struct {
 
 func (s *semesterRepository) Save(ctx context.Context, semester *model.Semester) error {
 	// Use Upsert logic
-	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+	err := database.Conn(ctx, s.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "id"}}, // Conflict on external ID
 		DoUpdates: clause.Assignments(map[string]interface{}{ // Update these fields on conflict
 			"description": semester.Description,
@@ -33,7 +34,7 @@ func (s *semesterRepository) Save(ctx context.Context, semester *model.Semester)
 
 func (s *semesterRepository) FindAll(ctx context.Context) ([]model.Semester, error) {
 	var semesters []model.Semester
-	result := s.db.WithContext(ctx).Find(&semesters)
+	result := database.Conn(ctx, s.db).Find(&semesters)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to retrieve all semesters: %w", result.Error)
 	}
@@ -42,7 +43,7 @@ func (s *semesterRepository) FindAll(ctx context.Context) ([]model.Semester, err
 
 func (s *semesterRepository) GetSemesterByID(ctx context.Context, semesterID string) (model.Semester, error) {
 	var semester model.Semester
-	result := s.db.WithContext(ctx).Where("id = ?", semesterID).First(&semester)
+	result := database.Conn(ctx, s.db).Where("id = ?", semesterID).First(&semester)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return model.Semester{}, fmt.Errorf("internal_semester with ID %s not found", semesterID)
@@ -56,7 +57,7 @@ func (s *semesterRepository) FindCurrentSemester(ctx context.Context) (*model.Se
 	var currentSemester model.Semester
 	now := time.Now()
 
-	result := s.db.WithContext(ctx).
+	result := database.Conn(ctx, s.db).
 		Where("start <= ? AND (\"end\" IS NULL OR \"end\" >= ?)", now, now).
 		Order("start DESC").
 		First(&currentSemester)
@@ -0,0 +1,25 @@
+package sessionRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	model "neptune/backend/models/user"
+	"time"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Session, error)
+	FindByRefreshTokenHash(ctx context.Context, hash string) (*model.Session, error)
+	FindByPreviousRefreshTokenHash(ctx context.Context, hash string) (*model.Session, error)
+	// Rotate swaps the refresh token hash if it still is oldHash and reports whether it did, so two
+	// concurrent refreshes with the same token cannot both succeed.
+	Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, now, expiresAt time.Time) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, reason string, at time.Time) error
+	// RevokeAllForUser revokes every active session of the user except keep (uuid.Nil keeps none).
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, keep uuid.UUID, reason string, at time.Time) ([]uuid.UUID, error)
+	// RequireRefresh makes every active session of the user reject access tokens issued before at.
+	RequireRefresh(ctx context.Context, userID uuid.UUID, at time.Time) error
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID, at time.Time) ([]model.Session, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
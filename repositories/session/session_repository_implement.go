@@ -0,0 +1,132 @@
+package sessionRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+	"time"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *model.Session) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	if err := database.Conn(ctx, r.db).Omit("User").Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Session, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *sessionRepository) FindByRefreshTokenHash(ctx context.Context, hash string) (*model.Session, error) {
+	return r.findOne(ctx, "refresh_token_hash = ?", hash)
+}
+
+func (r *sessionRepository) FindByPreviousRefreshTokenHash(ctx context.Context, hash string) (*model.Session, error) {
+	return r.findOne(ctx, "previous_refresh_token_hash = ?", hash)
+}
+
+func (r *sessionRepository) findOne(ctx context.Context, query string, arg interface{}) (*model.Session, error) {
+	var session model.Session
+	err := database.Conn(ctx, r.db).Where(query, arg).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, now, expiresAt time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&model.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":          newHash,
+			"previous_refresh_token_hash": oldHash,
+			"rotated_at":                  now,
+			"last_used_at":                now,
+			"expires_at":                  expiresAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to rotate session %s: %w", id, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, reason string, at time.Time) error {
+	err := database.Conn(ctx, r.db).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke session %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, keep uuid.UUID, reason string, at time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := database.Conn(ctx, r.db).Model(&model.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keep).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions of user %s: %w", userID, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	err = database.Conn(ctx, r.db).Model(&model.Session{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions of user %s: %w", userID, err)
+	}
+	return ids, nil
+}
+
+func (r *sessionRepository) RequireRefresh(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	err := database.Conn(ctx, r.db).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("refresh_required_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to require refresh of sessions of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID, at time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	err := database.Conn(ctx, r.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, at).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions of user %s: %w", userID, err)
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&model.Session{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
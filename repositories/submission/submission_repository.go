@@ -12,8 +12,19 @@ type SubmissionRepository interface {
 	FindByID(ctx context.Context, id string) (*submissionModel.Submission, error)
 	Update(ctx context.Context, submission *submissionModel.Submission) error
 	SaveResultsBatch(ctx context.Context, results []submissionModel.SubmissionResult) error
+	// FindAllForContest lists the official submissions made since the contest started, oldest first.
 	FindAllForContest(ctx context.Context, contestId uuid.UUID, classId *uuid.UUID, contestStartTime time.Time) ([]submissionModel.Submission, error)
+	FindByVirtualParticipation(ctx context.Context, participationID uuid.UUID) ([]submissionModel.Submission, error)
 	FindByUserInContest(ctx context.Context, contestID uuid.UUID, userID uuid.UUID, classID *uuid.UUID) ([]submissionModel.Submission, error)
 	FindClassSubmissions(ctx context.Context, classID uuid.UUID, contestID uuid.UUID) ([]submissionModel.Submission, error)
 	FindByStatus(ctx context.Context, status submissionModel.SubmissionStatus) ([]submissionModel.Submission, error)
+	// CountForContest counts the submissions of any mode made to the contest, or to one of its cases.
+	CountForContest(ctx context.Context, contestID uuid.UUID, caseID *uuid.UUID) (int64, error)
+
+	// Judging lease management
+	AcquireJudgingLease(ctx context.Context, submissionID uuid.UUID, workerID string, leaseTTL time.Duration) (*submissionModel.Submission, error)
+	RenewJudgingLease(ctx context.Context, submissionID uuid.UUID, workerID string, attempt int) (bool, error)
+	FindExpiredJudgingLeases(ctx context.Context, heartbeatBefore time.Time, queuedBefore time.Time) ([]submissionModel.Submission, error)
+	ReleaseExpiredJudgingLease(ctx context.Context, submission *submissionModel.Submission) (bool, error)
+	CompleteJudging(ctx context.Context, submission *submissionModel.Submission, attempt int) (bool, error)
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	submissionModel "neptune/backend/models/submission"
+	"neptune/backend/pkg/database"
 	"time"
 )
 
@@ -14,47 +16,65 @@ type submissionRepository struct {
 }
 
 func (r *submissionRepository) Save(ctx context.Context, submission *submissionModel.Submission) error {
-	return r.db.WithContext(ctx).Create(submission).Error
+	return database.Conn(ctx, r.db).Create(submission).Error
 }
 
 func (r *submissionRepository) FindByID(ctx context.Context, id string) (*submissionModel.Submission, error) {
 	var submission submissionModel.Submission
-	err := r.db.WithContext(ctx).Preload("SubmissionResults").First(&submission, "id = ?", id).Error
+	err := database.Conn(ctx, r.db).Preload("SubmissionResults").First(&submission, "id = ?", id).Error
 	return &submission, err
 }
 
 func (r *submissionRepository) Update(ctx context.Context, submission *submissionModel.Submission) error {
-	return r.db.WithContext(ctx).Save(submission).Error
+	return database.Conn(ctx, r.db).Save(submission).Error
 }
 
 func (r *submissionRepository) SaveResultsBatch(ctx context.Context, results []submissionModel.SubmissionResult) error {
 	if len(results) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).Create(&results).Error
+	// Upsert so a redelivered result message overwrites instead of failing on the primary key.
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "submission_id"}, {Name: "testcase_number"}},
+		UpdateAll: true,
+	}).Create(&results).Error
 }
 
 func (r *submissionRepository) FindAllForContest(ctx context.Context, contestId uuid.UUID, classId *uuid.UUID, contestStartTime time.Time) ([]submissionModel.Submission, error) {
 	var submissions []submissionModel.Submission
 	if classId == nil {
 		// If classId is nil, we want to find all submissions for the contest regardless of class
-		err := r.db.WithContext(ctx).
+		err := database.Conn(ctx, r.db).
 			Where("contest_id = ?", contestId).
 			Where("class_transaction_id IS NULL").
+			Where("mode = ?", submissionModel.SubmissionModeOfficial).
 			Where("created_at >= ?", contestStartTime).
 			Order("created_at asc"). // IMPORTANT: Sort by time to process chronologically
 			Find(&submissions).Error
 		return submissions, err
 	}
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("contest_id = ?", contestId).
 		Where("class_transaction_id = ?", classId).
+		Where("mode = ?", submissionModel.SubmissionModeOfficial).
 		Where("created_at >= ?", contestStartTime).
 		Order("created_at asc"). // IMPORTANT: Sort by time to process chronologically
 		Find(&submissions).Error
 	return submissions, err
 }
 
+func (r *submissionRepository) FindByVirtualParticipation(ctx context.Context, participationID uuid.UUID) ([]submissionModel.Submission, error) {
+	var submissions []submissionModel.Submission
+	err := database.Conn(ctx, r.db).
+		Where("virtual_participation_id = ?", participationID).
+		Order("created_at asc").
+		Find(&submissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find submissions of virtual participation %s: %w", participationID, err)
+	}
+	return submissions, nil
+}
+
 func (r *submissionRepository) FindByUserInContest(ctx context.Context, contestID uuid.UUID, userID uuid.UUID, classID *uuid.UUID) ([]submissionModel.Submission, error) {
 	var submissions []submissionModel.Submission
 	classQuery := "class_transaction_id IS NOT NULL"
@@ -62,7 +82,7 @@ func (r *submissionRepository) FindByUserInContest(ctx context.Context, contestI
 		classQuery = "class_transaction_id IS NULL"
 	}
 	fmt.Println(contestID, userID, classID)
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("contest_id = ?", contestID).
 		Where("user_id = ?", userID).Where(classQuery).Find(&submissions).Error
 	return submissions, err
@@ -70,7 +90,7 @@ func (r *submissionRepository) FindByUserInContest(ctx context.Context, contestI
 
 func (r *submissionRepository) FindClassSubmissions(ctx context.Context, classID uuid.UUID, contestID uuid.UUID) ([]submissionModel.Submission, error) {
 	var submissions []submissionModel.Submission
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("class_transaction_id = ?", classID).
 		Where("contest_id = ?", contestID).
 		Order("created_at asc"). // Sort by time to process chronologically
@@ -82,11 +102,127 @@ func (r *submissionRepository) FindClassSubmissions(ctx context.Context, classID
 	return submissions, nil
 }
 
+func (r *submissionRepository) CountForContest(ctx context.Context, contestID uuid.UUID, caseID *uuid.UUID) (int64, error) {
+	var count int64
+	query := database.Conn(ctx, r.db).Model(&submissionModel.Submission{}).Where("contest_id = ?", contestID)
+	if caseID != nil {
+		query = query.Where("case_id = ?", *caseID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count submissions of contest %s: %w", contestID.String(), err)
+	}
+	return count, nil
+}
+
 func (r *submissionRepository) FindByStatus(ctx context.Context, status submissionModel.SubmissionStatus) ([]submissionModel.Submission, error) {
 	var submissions []submissionModel.Submission
-	err := r.db.WithContext(ctx).Where("status = ?", status).Find(&submissions).Error
+	err := database.Conn(ctx, r.db).Where("status = ?", status).Find(&submissions).Error
 	return submissions, err
 }
+
+// AcquireJudgingLease claims a submission for the given worker. The lease is only granted when the
+// submission is still judging and nobody holds a live lease on it. It returns nil when the lease is held
+// elsewhere or the submission already has a final verdict.
+func (r *submissionRepository) AcquireJudgingLease(ctx context.Context, submissionID uuid.UUID, workerID string, leaseTTL time.Duration) (*submissionModel.Submission, error) {
+	now := time.Now()
+	var submission submissionModel.Submission
+	result := database.Conn(ctx, r.db).
+		Model(&submission).
+		Clauses(clause.Returning{}).
+		Where("id = ?", submissionID).
+		Where("status = ?", submissionModel.SubmissionStatusJudging).
+		Where("judging_heartbeat_at IS NULL OR judging_heartbeat_at < ?", now.Add(-leaseTTL)).
+		UpdateColumns(map[string]interface{}{
+			"judging_worker_id":    workerID,
+			"judging_heartbeat_at": now,
+			"judging_attempt":      gorm.Expr("judging_attempt + 1"),
+			"updated_at":           now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to acquire judging lease for submission %s: %w", submissionID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &submission, nil
+}
+
+// RenewJudgingLease refreshes the heartbeat of a lease. It returns false when the lease was lost.
+func (r *submissionRepository) RenewJudgingLease(ctx context.Context, submissionID uuid.UUID, workerID string, attempt int) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&submissionModel.Submission{}).
+		Where("id = ?", submissionID).
+		Where("status = ?", submissionModel.SubmissionStatusJudging).
+		Where("judging_worker_id = ? AND judging_attempt = ?", workerID, attempt).
+		UpdateColumn("judging_heartbeat_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to renew judging lease for submission %s: %w", submissionID, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindExpiredJudgingLeases returns judging submissions whose worker stopped sending heartbeats, and
+// submissions that were never picked up by any worker since queuedBefore.
+func (r *submissionRepository) FindExpiredJudgingLeases(ctx context.Context, heartbeatBefore time.Time, queuedBefore time.Time) ([]submissionModel.Submission, error) {
+	var submissions []submissionModel.Submission
+	err := database.Conn(ctx, r.db).
+		Where("status = ?", submissionModel.SubmissionStatusJudging).
+		Where(r.db.Where("judging_heartbeat_at IS NOT NULL AND judging_heartbeat_at < ?", heartbeatBefore).
+			Or("judging_heartbeat_at IS NULL AND updated_at < ?", queuedBefore)).
+		Order("created_at asc").
+		Find(&submissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired judging leases: %w", err)
+	}
+	return submissions, nil
+}
+
+// ReleaseExpiredJudgingLease clears an expired lease so the submission can be requeued. The update is
+// guarded by the values the caller observed, so when several replicas reap at once only one wins.
+func (r *submissionRepository) ReleaseExpiredJudgingLease(ctx context.Context, submission *submissionModel.Submission) (bool, error) {
+	query := database.Conn(ctx, r.db).
+		Model(&submissionModel.Submission{}).
+		Where("id = ?", submission.ID).
+		Where("status = ?", submissionModel.SubmissionStatusJudging).
+		Where("judging_attempt = ?", submission.JudgingAttempt)
+	if submission.JudgingHeartbeatAt != nil {
+		query = query.Where("judging_heartbeat_at = ?", *submission.JudgingHeartbeatAt)
+	} else {
+		query = query.Where("judging_heartbeat_at IS NULL AND updated_at = ?", submission.UpdatedAt)
+	}
+
+	result := query.UpdateColumns(map[string]interface{}{
+		"judging_worker_id":    "",
+		"judging_heartbeat_at": nil,
+		"updated_at":           time.Now(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to release judging lease for submission %s: %w", submission.ID, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CompleteJudging writes the final verdict of a judging attempt. The write only happens while the
+// submission is still judging under the same attempt, so late or duplicate results are ignored.
+func (r *submissionRepository) CompleteJudging(ctx context.Context, submission *submissionModel.Submission, attempt int) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&submissionModel.Submission{}).
+		Where("id = ?", submission.ID).
+		Where("status = ?", submissionModel.SubmissionStatusJudging).
+		Where("judging_attempt = ?", attempt).
+		UpdateColumns(map[string]interface{}{
+			"status":               submission.Status,
+			"score":                submission.Score,
+			"judging_worker_id":    "",
+			"judging_heartbeat_at": nil,
+			"updated_at":           submission.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to complete judging for submission %s: %w", submission.ID, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func NewSubmissionRepository(db *gorm.DB) SubmissionRepository {
 	return &submissionRepository{db: db}
 }
@@ -0,0 +1,18 @@
+package syncRunRepo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	syncRunModel "neptune/backend/models/sync_run"
+	"time"
+)
+
+type SyncRunRepository interface {
+	// CreateRunning inserts a running run. It returns ErrSyncRunInProgress when another run is active.
+	CreateRunning(ctx context.Context, run *syncRunModel.SyncRun) error
+	Finish(ctx context.Context, run *syncRunModel.SyncRun) error
+	SaveStep(ctx context.Context, step *syncRunModel.SyncRunStep) error
+	FindAll(ctx context.Context, limit, offset int) ([]syncRunModel.SyncRun, int64, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*syncRunModel.SyncRun, error)
+	FailStaleRuns(ctx context.Context, startedBefore time.Time) (int64, error)
+}
@@ -0,0 +1,107 @@
+package syncRunRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	syncRunModel "neptune/backend/models/sync_run"
+	"neptune/backend/pkg/database"
+	"time"
+)
+
+var ErrSyncRunInProgress = errors.New("a sync run is already in progress")
+
+type syncRunRepository struct {
+	db *gorm.DB
+}
+
+func (r *syncRunRepository) CreateRunning(ctx context.Context, run *syncRunModel.SyncRun) error {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	run.Status = syncRunModel.SyncRunStatusRunning
+	if err := database.Conn(ctx, r.db).Create(run).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrSyncRunInProgress
+		}
+		return fmt.Errorf("failed to create sync run: %w", err)
+	}
+	return nil
+}
+
+func (r *syncRunRepository) Finish(ctx context.Context, run *syncRunModel.SyncRun) error {
+	return database.Conn(ctx, r.db).
+		Model(&syncRunModel.SyncRun{}).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"error":       run.Error,
+			"semester_id": run.SemesterID,
+			"finished_at": run.FinishedAt,
+		}).Error
+}
+
+func (r *syncRunRepository) SaveStep(ctx context.Context, step *syncRunModel.SyncRunStep) error {
+	if step.ID == uuid.Nil {
+		step.ID = uuid.New()
+	}
+	return database.Conn(ctx, r.db).Save(step).Error
+}
+
+// FindAll returns runs newest first, without their steps, and the total number of runs.
+func (r *syncRunRepository) FindAll(ctx context.Context, limit, offset int) ([]syncRunModel.SyncRun, int64, error) {
+	var total int64
+	if err := database.Conn(ctx, r.db).Model(&syncRunModel.SyncRun{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count sync runs: %w", err)
+	}
+
+	var runs []syncRunModel.SyncRun
+	err := database.Conn(ctx, r.db).
+		Order("started_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find sync runs: %w", err)
+	}
+	return runs, total, nil
+}
+
+func (r *syncRunRepository) FindByID(ctx context.Context, id uuid.UUID) (*syncRunModel.SyncRun, error) {
+	var run syncRunModel.SyncRun
+	err := database.Conn(ctx, r.db).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") }).
+		Where("id = ?", id).
+		First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find sync run %s: %w", id, err)
+	}
+	return &run, nil
+}
+
+// FailStaleRuns marks runs that have been running since before startedBefore as failed, e.g. after the
+// process running them crashed, so the next run is not blocked forever.
+func (r *syncRunRepository) FailStaleRuns(ctx context.Context, startedBefore time.Time) (int64, error) {
+	now := time.Now()
+	result := database.Conn(ctx, r.db).
+		Model(&syncRunModel.SyncRun{}).
+		Where("status = ? AND started_at < ?", syncRunModel.SyncRunStatusRunning, startedBefore).
+		Updates(map[string]interface{}{
+			"status":      syncRunModel.SyncRunStatusFailed,
+			"error":       "run did not finish (process stopped or timed out)",
+			"finished_at": now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail stale sync runs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func NewSyncRunRepository(db *gorm.DB) SyncRunRepository {
+	return &syncRunRepository{db: db}
+}
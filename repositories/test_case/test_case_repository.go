@@ -2,6 +2,7 @@ package testCaseRepo
 
 import (
 	"context"
+	"github.com/google/uuid"
 	testCaseModel "neptune/backend/models/test_case"
 )
 
@@ -9,5 +10,9 @@ type TestCaseRepository interface {
 	SaveTestCase(ctx context.Context, testCase *testCaseModel.TestCase) error
 	SaveTestCaseBatch(ctx context.Context, testCases []testCaseModel.TestCase) error
 	FindTestCaseByCaseID(ctx context.Context, caseID string) ([]testCaseModel.TestCase, error)
+	FindTestCaseByCaseIDAndNumber(ctx context.Context, caseID uuid.UUID, number int) (*testCaseModel.TestCase, error)
 	DeleteTestCaseByCaseID(ctx context.Context, caseID string) error // Hard Delete
+	// SetSamples marks the listed testcases of the case as samples and every other one as hidden
+	SetSamples(ctx context.Context, caseID uuid.UUID, numbers []int) error
+	FindSampleTestCases(ctx context.Context, caseID uuid.UUID) ([]testCaseModel.TestCase, error)
 }
@@ -2,10 +2,13 @@ package testCaseRepo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	testCaseModel "neptune/backend/models/test_case"
+	"neptune/backend/pkg/database"
 	"time"
 )
 
@@ -14,7 +17,7 @@ type testCaseRepository struct {
 }
 
 func (t *testCaseRepository) SaveTestCase(ctx context.Context, testCase *testCaseModel.TestCase) error {
-	return t.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, t.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "case_id"}, {Name: "number"}}, // Conflict on composite primary key
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"input_url":  testCase.InputUrl,
@@ -28,11 +31,12 @@ func (t *testCaseRepository) SaveTestCaseBatch(ctx context.Context, testCases []
 	if len(testCases) == 0 {
 		return nil
 	}
-	return t.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, t.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "case_id"}, {Name: "number"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"input_url":  gorm.Expr("EXCLUDED.input_url"),
 			"output_url": gorm.Expr("EXCLUDED.output_url"),
+			"is_sample":  gorm.Expr("EXCLUDED.is_sample"),
 			"created_at": gorm.Expr("EXCLUDED.created_at"),
 		}),
 	}).CreateInBatches(testCases, 100).Error // Batch size 100
@@ -40,15 +44,50 @@ func (t *testCaseRepository) SaveTestCaseBatch(ctx context.Context, testCases []
 
 func (t *testCaseRepository) FindTestCaseByCaseID(ctx context.Context, caseID string) ([]testCaseModel.TestCase, error) {
 	var testcases []testCaseModel.TestCase
-	result := t.db.WithContext(ctx).Where("case_id = ?", caseID).Order("number").Find(&testcases)
+	result := database.Conn(ctx, t.db).Where("case_id = ?", caseID).Order("number").Find(&testcases)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to find testcases for case ID %s: %w", caseID, result.Error)
 	}
 	return testcases, nil
 }
 
+func (t *testCaseRepository) FindTestCaseByCaseIDAndNumber(ctx context.Context, caseID uuid.UUID, number int) (*testCaseModel.TestCase, error) {
+	var testcase testCaseModel.TestCase
+	result := database.Conn(ctx, t.db).Where("case_id = ? AND number = ?", caseID, number).First(&testcase)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find testcase %d for case ID %s: %w", number, caseID.String(), result.Error)
+	}
+	return &testcase, nil
+}
+
 func (t *testCaseRepository) DeleteTestCaseByCaseID(ctx context.Context, caseID string) error {
-	return t.db.WithContext(ctx).Unscoped().Where("case_id = ?", caseID).Delete(&testCaseModel.TestCase{}).Error
+	return database.Conn(ctx, t.db).Unscoped().Where("case_id = ?", caseID).Delete(&testCaseModel.TestCase{}).Error
+}
+
+func (t *testCaseRepository) SetSamples(ctx context.Context, caseID uuid.UUID, numbers []int) error {
+	conn := database.Conn(ctx, t.db)
+	if err := conn.Model(&testCaseModel.TestCase{}).Where("case_id = ?", caseID).Update("is_sample", false).Error; err != nil {
+		return fmt.Errorf("failed to clear samples of case %s: %w", caseID.String(), err)
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+	if err := conn.Model(&testCaseModel.TestCase{}).Where("case_id = ? AND number IN ?", caseID, numbers).Update("is_sample", true).Error; err != nil {
+		return fmt.Errorf("failed to mark samples of case %s: %w", caseID.String(), err)
+	}
+	return nil
+}
+
+func (t *testCaseRepository) FindSampleTestCases(ctx context.Context, caseID uuid.UUID) ([]testCaseModel.TestCase, error) {
+	var testcases []testCaseModel.TestCase
+	result := database.Conn(ctx, t.db).Where("case_id = ? AND is_sample = ?", caseID, true).Order("number").Find(&testcases)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find samples of case %s: %w", caseID.String(), result.Error)
+	}
+	return testcases, nil
 }
 
 // NewTestCaseRepository creates a new instance of TestCaseRepository
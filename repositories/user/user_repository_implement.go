@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
 	"time"
 )
 
@@ -25,7 +26,7 @@ func (r *userRepository) Save(ctx context.Context, user *model.User) error {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "id"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"name":       user.Name,
@@ -40,7 +41,7 @@ func (r *userRepository) Save(ctx context.Context, user *model.User) error {
 func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
 
-	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	err := database.Conn(ctx, r.db).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // Return nil, nil when user doesn't exist
@@ -58,19 +59,19 @@ func (r *userRepository) CreateUser(ctx context.Context, user *model.User) error
 		user.ID = uuid.New()
 	}
 
-	return r.db.WithContext(ctx).Create(user).Error
+	return database.Conn(ctx, r.db).Create(user).Error
 }
 
 // UpdateUser updates an existing user in the database
 func (r *userRepository) UpdateUser(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return database.Conn(ctx, r.db).Save(user).Error
 }
 
 // GetUserByID retrieves a user by their ID
 func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	var user model.User
 
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	err := database.Conn(ctx, r.db).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // Return nil, nil when user doesn't exist
@@ -1,17 +1,27 @@
 package router
 
 import (
+	accessTokenHand "neptune/backend/handlers/access_token"
+	announcementHand "neptune/backend/handlers/announcement"
+	auditHand "neptune/backend/handlers/audit"
 	caseHandler "neptune/backend/handlers/case"
+	clarificationHand "neptune/backend/handlers/clarification"
 	"neptune/backend/handlers/class"
 	contestHandler "neptune/backend/handlers/contest"
+	courseHand "neptune/backend/handlers/course"
+	fileHand "neptune/backend/handlers/file"
 	"neptune/backend/handlers/language"
 	leaderboardHand "neptune/backend/handlers/leaderboard"
+	localAccountHand "neptune/backend/handlers/local_account"
+	roleHand "neptune/backend/handlers/role"
 	"neptune/backend/handlers/semester"
 	submissionHand "neptune/backend/handlers/submission"
+	syncRunHand "neptune/backend/handlers/sync_run"
 	testCaseHand "neptune/backend/handlers/test_case"
 	userHand "neptune/backend/handlers/user"
 	websocketHand "neptune/backend/handlers/websocket"
 	"neptune/backend/models/user"
+	"neptune/backend/pkg/audit"
 	"neptune/backend/pkg/middleware"
 
 	"github.com/gin-contrib/cors"
@@ -29,6 +39,15 @@ func NewRouter(userHandler *userHand.UserHandler,
 	languageHandler *language.LanguageHandler,
 	leaderboardHandler *leaderboardHand.LeaderboardHandler,
 	sourceCodeHandler *submissionHand.SubmissionReviewHandler,
+	fileHandler *fileHand.FileHandler,
+	syncRunHandler *syncRunHand.SyncRunHandler,
+	courseHandler *courseHand.CourseHandler,
+	localAccountHandler *localAccountHand.LocalAccountHandler,
+	roleHandler *roleHand.RoleHandler,
+	accessTokenHandler *accessTokenHand.AccessTokenHandler,
+	auditHandler *auditHand.AuditHandler,
+	clarificationHandler *clarificationHand.ClarificationHandler,
+	announcementHandler *announcementHand.AnnouncementHandler,
 ) *gin.Engine {
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
@@ -46,8 +65,15 @@ func NewRouter(userHandler *userHand.UserHandler,
 		})
 	})
 
-	r.Static("/public/case_file", "./public/case_file") // Serve static files from the public directory
-	// Serve private test cases
+	// Signed, short-lived download links issued by the */link routes below. No session cookie needed.
+	signedFileGroup := r.Group("/files")
+	signedFileGroup.Use(middleware.RequireSignedURL())
+	{
+		signedFileGroup.GET("/cases/:caseId/pdf", fileHandler.CasePDF)
+		signedFileGroup.GET("/cases/:caseId/attachments/:attachmentId", fileHandler.CaseAttachment)
+		signedFileGroup.GET("/cases/:caseId/test-cases/:number/:kind", fileHandler.TestCaseFile)
+		signedFileGroup.GET("/submissions/:submissionId/code", fileHandler.SubmissionSource)
+	}
 
 	// Public auth routes
 	authGroup := r.Group("/auth")
@@ -55,6 +81,15 @@ func NewRouter(userHandler *userHand.UserHandler,
 		authGroup.POST("/login", userHandler.LoginHandler)
 		authGroup.POST("logout", middleware.RequireAuth(), userHandler.LogOutHandler)
 		authGroup.GET("/me", middleware.RequireAuth(), userHandler.MeHandler)
+		authGroup.POST("/password-reset", localAccountHandler.ResetPassword)
+		authGroup.POST("/refresh", userHandler.RefreshHandler)
+		authGroup.GET("/sessions", middleware.RequireAuth(), userHandler.GetSessionsHandler)
+		authGroup.DELETE("/sessions", middleware.RequireAuth(), userHandler.RevokeOtherSessionsHandler)
+		authGroup.DELETE("/sessions/:sessionId", middleware.RequireAuth(), userHandler.RevokeSessionHandler)
+		authGroup.POST("/tokens", middleware.RequireAuth(), accessTokenHandler.CreateToken)
+		authGroup.GET("/tokens", middleware.RequireAuth(), accessTokenHandler.ListTokens)
+		authGroup.DELETE("/tokens/:tokenId", middleware.RequireAuth(), accessTokenHandler.RevokeToken)
+		authGroup.GET("/tokens/:tokenId/usage", middleware.RequireAuth(), accessTokenHandler.GetUsage)
 	}
 
 	authRestrictedGroup := r.Group("/api")
@@ -70,15 +105,33 @@ func NewRouter(userHandler *userHand.UserHandler,
 		authRestrictedGroup.GET("/class-detail", classHandler.GetClassDetailByTransactionIDHandler)       // General class detail by ID
 
 		// Contest routes
+		authRestrictedGroup.GET("/courses", courseHandler.GetActiveCourses)
 		authRestrictedGroup.GET("/contests", contestHandler.GetAllContests)
 		authRestrictedGroup.GET("/contests/:contestId", contestHandler.GetContestByID)
+		authRestrictedGroup.POST("/contests/:contestId/join", contestHandler.JoinContest)
 		authRestrictedGroup.GET("/contests/global-detail", contestHandler.GetAllGlobalContestDetail)
 		authRestrictedGroup.GET("/contests/global", contestHandler.GetAllGlobalContestWithoutDetail)
 		authRestrictedGroup.GET("/classes/:classTransactionId/contests", contestHandler.GetContestsForClass) // Get contests assigned to a class
+		authRestrictedGroup.POST("/contests/:contestId/virtual-participations", contestHandler.StartVirtualParticipation)
+		authRestrictedGroup.GET("/contests/:contestId/virtual-participations", contestHandler.GetVirtualParticipation)
+
+		// Clarification routes
+		authRestrictedGroup.GET("/contests/:contestId/clarifications", clarificationHandler.GetClarifications)
+		authRestrictedGroup.POST("/contests/:contestId/clarifications", clarificationHandler.AskQuestion)
+		authRestrictedGroup.POST("/clarifications/:clarificationId/answer", clarificationHandler.AnswerClarification)
+		authRestrictedGroup.GET("/contests/:contestId/announcements", announcementHandler.GetPublishedAnnouncements)
+		authRestrictedGroup.GET("/ws/contests/:contestId", webSocketHandler.HandleContestConnection)
 
 		// Case routes
-		authRestrictedGroup.GET("/cases", caseHandler.GetAllCases)
-		authRestrictedGroup.GET("/cases/:caseId", caseHandler.GetCaseByID)
+		// Students reach problems through their contests; browsing the case bank is for staff
+		authRestrictedGroup.GET("/cases", middleware.RequireRole(user.RoleAdmin, user.RoleLecturer, user.RoleAssistant), caseHandler.GetAllCases)
+		authRestrictedGroup.GET("/cases/tags", middleware.RequireRole(user.RoleAdmin, user.RoleLecturer, user.RoleAssistant), caseHandler.GetTags)
+		authRestrictedGroup.GET("/cases/:caseId", middleware.RequireRole(user.RoleAdmin, user.RoleLecturer, user.RoleAssistant), caseHandler.GetCaseByID)
+		authRestrictedGroup.GET("/cases/:caseId/pdf", fileHandler.CasePDF)
+		authRestrictedGroup.GET("/cases/:caseId/pdf/link", fileHandler.CasePDFLink)
+		authRestrictedGroup.GET("/cases/:caseId/statement", caseHandler.GetStatement)
+		authRestrictedGroup.GET("/cases/:caseId/attachments/:attachmentId", fileHandler.CaseAttachment)
+		authRestrictedGroup.GET("/cases/:caseId/attachments/:attachmentId/link", fileHandler.CaseAttachmentLink)
 
 		// Submission routes
 		authRestrictedGroup.POST("/submissions", submissionHandler.SubmitCode)
@@ -87,41 +140,147 @@ func NewRouter(userHandler *userHand.UserHandler,
 		authRestrictedGroup.GET("/submission/all/:contestId", submissionHandler.GetClassContestSubmissions)
 		authRestrictedGroup.GET("/submissions/:submissionId/code", sourceCodeHandler.ViewCode)
 		authRestrictedGroup.GET("/submissions/:submissionId/download", sourceCodeHandler.DownloadCode)
+		authRestrictedGroup.GET("/submissions/:submissionId/code/link", fileHandler.SubmissionSourceLink)
 
 		authRestrictedGroup.GET("/languages", languageHandler.GetSupportedLanguages)
 
 		// Leaderboard routes
 		authRestrictedGroup.GET("/classes/:classTransactionId/contests/:contestId/leaderboard", leaderboardHandler.GetClassContestLeaderboard)
 		authRestrictedGroup.GET("/contests/:contestId/leaderboard", leaderboardHandler.GetGlobalContestLeaderboard)
+		authRestrictedGroup.GET("/virtual-participations/:participationId/standing", leaderboardHandler.GetVirtualStanding)
 
 	}
 
 	adminGroup := r.Group("/admin")
-	adminGroup.Use(middleware.RequireAuth(), middleware.RequireRole(user.RoleAdmin))
+	adminGroup.Use(middleware.RequireAuth(), middleware.RequireRole(user.RoleAdmin), audit.Middleware())
 	{
 		// TODO: Implement admin-specific routes
 		adminGroup.POST("/sync-semester", semesterHandler.SyncSemestersHandler)
 		adminGroup.POST("/sync-classes", classHandler.SyncClassesHandler)
 		adminGroup.POST("/sync-class-students", classHandler.SyncClassStudentsHandler)
 		adminGroup.POST("/sync-class-assistants", classHandler.SyncClassAssistantsHandler)
+		adminGroup.GET("/sync-runs", syncRunHandler.GetSyncRuns)
+		adminGroup.GET("/sync-runs/:runId", syncRunHandler.GetSyncRun)
+		adminGroup.POST("/sync-runs", syncRunHandler.RunSyncNow)
+
+		adminGroup.POST("/users/local", localAccountHandler.CreateAccount)
+		adminGroup.POST("/users/local/import", localAccountHandler.ImportAccounts)
+		adminGroup.POST("/users/:userId/password-reset", localAccountHandler.IssueResetToken)
+		adminGroup.DELETE("/users/:userId/sessions", userHandler.RevokeUserSessionsHandler)
+		adminGroup.GET("/users/:userId/roles", roleHandler.GetUserRoles)
+		adminGroup.POST("/users/:userId/roles", roleHandler.GrantRole)
+		adminGroup.DELETE("/users/:userId/roles/:assignmentId", roleHandler.RevokeRole)
+		adminGroup.GET("/role-audit", roleHandler.GetAuditTrail)
+		adminGroup.GET("/audit", auditHandler.GetAuditLogs)
+		adminGroup.GET("/audit/export", auditHandler.ExportAuditLogs)
+		adminGroup.GET("/users/:userId/tokens", accessTokenHandler.ListUserTokens)
+		adminGroup.DELETE("/users/:userId/tokens/:tokenId", accessTokenHandler.RevokeUserToken)
+		adminGroup.GET("/users/:userId/tokens/:tokenId/usage", accessTokenHandler.GetUserTokenUsage)
+
+		adminGroup.GET("/courses", courseHandler.GetAllCourses)
+		adminGroup.GET("/courses/:courseId", courseHandler.GetCourseByID)
+		adminGroup.POST("/courses", courseHandler.CreateCourse)
+		adminGroup.PUT("/courses/:courseId", courseHandler.UpdateCourse)
+		adminGroup.DELETE("/courses/:courseId", courseHandler.DeleteCourse)
 
 		adminGroup.POST("/contests", contestHandler.CreateContest)
 		adminGroup.PUT("/contests/:contestId", contestHandler.UpdateContest)
 		adminGroup.DELETE("/contests/:contestId", contestHandler.DeleteContest)
+		adminGroup.GET("/contests/:contestId/status", contestHandler.GetLifecycle)
+		adminGroup.PUT("/contests/:contestId/status", contestHandler.SetStatusOverride)
 		adminGroup.POST("/contests/:contestId/cases", contestHandler.AddCasesToContest)
-
-		adminGroup.POST("/classes/:classTransactionId/assign-contest", contestHandler.AssignContestToClass)
-		adminGroup.DELETE("/classes/:classTransactionId/contests/:contestId", contestHandler.RemoveContestFromClass)
+		adminGroup.PUT("/contests/:contestId/cases/order", contestHandler.ReorderContestCases)
+		adminGroup.PUT("/contests/:contestId/cases/:caseId", contestHandler.UpdateContestCase)
+		adminGroup.DELETE("/contests/:contestId/cases/:caseId", contestHandler.RemoveCaseFromContest)
+		adminGroup.POST("/contests/:contestId/clone", contestHandler.CloneContest)
+		adminGroup.POST("/contests/:contestId/assign-classes", contestHandler.BulkAssignContest)
+		adminGroup.GET("/contests/:contestId/announcements", announcementHandler.GetAnnouncements)
+		adminGroup.POST("/contests/:contestId/announcements", announcementHandler.CreateAnnouncement)
+		adminGroup.PUT("/announcements/:announcementId", announcementHandler.UpdateAnnouncement)
+		adminGroup.DELETE("/announcements/:announcementId", announcementHandler.DeleteAnnouncement)
 
 		adminGroup.POST("/cases", caseHandler.CreateCase)
+		adminGroup.POST("/cases/import", caseHandler.ImportPackage)
+		adminGroup.GET("/cases/:case_id/export", caseHandler.ExportPackage)
 		adminGroup.PUT("/cases/:caseId", caseHandler.UpdateCase)
 		adminGroup.DELETE("/cases/:caseId", caseHandler.DeleteCase)
+		adminGroup.GET("/cases/:case_id/revisions", caseHandler.GetRevisions)
+		adminGroup.GET("/cases/:case_id/revisions/:revision", caseHandler.GetRevision)
+		adminGroup.GET("/cases/:case_id/revision-diff", caseHandler.DiffRevisions)
+		adminGroup.POST("/cases/:case_id/attachments", caseHandler.AddAttachment)
+		adminGroup.DELETE("/cases/:caseId/attachments/:attachmentId", caseHandler.DeleteAttachment)
 
 		adminGroup.POST("/cases/:case_id/test-cases", testCaseHandler.UploadTestCasesHandler)
 		adminGroup.GET("/cases/:case_id/test-cases", testCaseHandler.GetTestCasesByCaseIDHandler)
-		adminGroup.Static("/private/test_case", "./private/test_case")
+		adminGroup.GET("/cases/:case_id/test-cases/:number/:kind", fileHandler.TestCaseFile)
+		adminGroup.GET("/cases/:case_id/test-cases/:number/:kind/link", fileHandler.TestCaseFileLink)
+		adminGroup.PUT("/cases/:caseId/test-cases/samples", testCaseHandler.SetSamplesHandler)
 
 	}
 
+	// Class management, open to admins and to lecturers of the class
+	classStaffGroup := r.Group("/admin/classes/:classTransactionId")
+	classStaffGroup.Use(middleware.RequireAuth(), middleware.RequireRole(user.RoleAdmin, user.RoleLecturer), middleware.RequireClassAccess("classTransactionId"), audit.Middleware())
+	{
+		classStaffGroup.POST("/assign-contest", contestHandler.AssignContestToClass)
+		classStaffGroup.DELETE("/contests/:contestId", contestHandler.RemoveContestFromClass)
+		classStaffGroup.GET("/contests/:contestId/overrides", contestHandler.GetOverrides)
+		classStaffGroup.PUT("/contests/:contestId/overrides/:userId", contestHandler.SetOverride)
+		classStaffGroup.DELETE("/contests/:contestId/overrides/:userId", contestHandler.RemoveOverride)
+		classStaffGroup.GET("/roster-changes", classHandler.GetRosterChangesHandler)
+	}
+
+	// Routes personal access tokens may call, by scope. Every other route needs the session cookie.
+	middleware.AllowTokenScope(user.ScopeSubmissionsRead,
+		"GET /api/classes",
+		"GET /api/class-detail",
+		"GET /api/contests",
+		"GET /api/contests/:contestId",
+		"GET /api/classes/:classTransactionId/contests",
+		"GET /api/submission/:contestId",
+		"GET /api/submission/all/:contestId",
+		"GET /api/submissions/:submissionId/code",
+		"GET /api/submissions/:submissionId/download",
+		"GET /api/submissions/:submissionId/code/link",
+	)
+	middleware.AllowTokenScope(user.ScopeContestsManage,
+		"GET /api/contests",
+		"GET /api/contests/:contestId",
+		"GET /api/classes/:classTransactionId/contests",
+		"GET /api/cases",
+		"GET /api/cases/tags",
+		"GET /api/cases/:caseId",
+		"GET /api/cases/:caseId/statement",
+		"POST /admin/contests",
+		"PUT /admin/contests/:contestId",
+		"DELETE /admin/contests/:contestId",
+		"GET /admin/contests/:contestId/status",
+		"PUT /admin/contests/:contestId/status",
+		"POST /admin/contests/:contestId/cases",
+		"PUT /admin/contests/:contestId/cases/order",
+		"PUT /admin/contests/:contestId/cases/:caseId",
+		"DELETE /admin/contests/:contestId/cases/:caseId",
+		"POST /admin/contests/:contestId/clone",
+		"POST /admin/contests/:contestId/assign-classes",
+		"GET /admin/contests/:contestId/announcements",
+		"POST /admin/contests/:contestId/announcements",
+		"PUT /admin/announcements/:announcementId",
+		"DELETE /admin/announcements/:announcementId",
+		"POST /admin/classes/:classTransactionId/assign-contest",
+		"DELETE /admin/classes/:classTransactionId/contests/:contestId",
+		"GET /admin/classes/:classTransactionId/contests/:contestId/overrides",
+		"PUT /admin/classes/:classTransactionId/contests/:contestId/overrides/:userId",
+		"DELETE /admin/classes/:classTransactionId/contests/:contestId/overrides/:userId",
+	)
+	middleware.AllowTokenScope(user.ScopeGradesExport,
+		"GET /api/classes",
+		"GET /api/class-detail",
+		"GET /api/contests",
+		"GET /api/contests/:contestId",
+		"GET /api/classes/:classTransactionId/contests",
+		"GET /api/classes/:classTransactionId/contests/:contestId/leaderboard",
+		"GET /api/contests/:contestId/leaderboard",
+	)
+
 	return r
 }
@@ -0,0 +1,35 @@
+package accessTokenServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/middleware"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+)
+
+var (
+	ErrTokenNotFound    = errors.New("access token not found")
+	ErrInvalidTokenName = errors.New("token name is required")
+	ErrInvalidScope     = errors.New("scopes must be submissions:read, contests:manage or grades:export")
+	ErrInvalidExpiry    = errors.New("expires_in_days is out of range")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+// RoleResolver works out the role a token acts with.
+type RoleResolver interface {
+	EffectiveRole(ctx context.Context, user *model.User) (model.Role, error)
+}
+
+type AccessTokenService interface {
+	middleware.TokenAuthenticator
+
+	// CreateToken returns the token itself once; only its hash is kept.
+	CreateToken(ctx context.Context, userID uuid.UUID, req requests.CreateAccessTokenRequest) (*responses.AccessTokenResponse, error)
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]responses.AccessTokenResponse, error)
+	// RevokeToken revokes a token of userID; tokens of other users are reported as not found.
+	RevokeToken(ctx context.Context, userID, tokenID uuid.UUID, revokedBy uuid.UUID) error
+	GetUsage(ctx context.Context, userID, tokenID uuid.UUID, limit, offset int) (*responses.AccessTokenUsageListResponse, error)
+}
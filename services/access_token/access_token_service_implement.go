@@ -0,0 +1,315 @@
+package accessTokenServ
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/middleware"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	accessTokenRepo "neptune/backend/repositories/access_token"
+	userRepo "neptune/backend/repositories/user"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenPrefix          = "npt_"
+	displayPrefixLength  = 12
+	defaultExpiresInDays = 30
+	defaultMaxTTLDays    = 365
+	// Lookups are cached briefly so scripts hammering the API do not hit the database on every call.
+	// Revocations on this instance apply at once, elsewhere within this time.
+	tokenCheckTTL   = 15 * time.Second
+	maxCachedTokens = 10000
+)
+
+type tokenCheck struct {
+	identity  *middleware.TokenIdentity
+	checkedAt time.Time
+}
+
+type accessTokenService struct {
+	tokenRepo  accessTokenRepo.AccessTokenRepository
+	userRepo   userRepo.UserRepository
+	roles      RoleResolver
+	maxTTLDays int
+
+	cacheMu sync.Mutex
+	cache   map[string]tokenCheck // by token hash
+}
+
+func NewAccessTokenService(tokenRepo accessTokenRepo.AccessTokenRepository, userRepo userRepo.UserRepository, roles RoleResolver) AccessTokenService {
+	maxTTLDays := defaultMaxTTLDays
+	if raw := os.Getenv("API_TOKEN_MAX_TTL_DAYS"); raw != "" {
+		if days, err := strconv.Atoi(raw); err == nil && days > 0 {
+			maxTTLDays = days
+		} else {
+			log.Printf("Invalid value %q for API_TOKEN_MAX_TTL_DAYS, using default %d", raw, maxTTLDays)
+		}
+	}
+	return &accessTokenService{
+		tokenRepo:  tokenRepo,
+		userRepo:   userRepo,
+		roles:      roles,
+		maxTTLDays: maxTTLDays,
+		cache:      make(map[string]tokenCheck),
+	}
+}
+
+func (s *accessTokenService) CreateToken(ctx context.Context, userID uuid.UUID, req requests.CreateAccessTokenRequest) (*responses.AccessTokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidTokenName
+	}
+	scopes, err := parseScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultExpiresInDays
+	}
+	if days < 1 || days > s.maxTTLDays {
+		return nil, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidExpiry, s.maxTTLDays)
+	}
+
+	owner, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, ErrUserNotFound
+	}
+
+	raw, hash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	token := &user.PersonalAccessToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hash,
+		Prefix:    raw[:displayPrefixLength],
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, days),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	log.Printf("User %s created access token %s (%s) with scopes %v", owner.Username, token.ID, token.Prefix, scopes)
+	resp := toTokenResponse(*token)
+	resp.Token = raw
+	return &resp, nil
+}
+
+func (s *accessTokenService) ListTokens(ctx context.Context, userID uuid.UUID) ([]responses.AccessTokenResponse, error) {
+	tokens, err := s.tokenRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]responses.AccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, toTokenResponse(token))
+	}
+	return result, nil
+}
+
+func (s *accessTokenService) RevokeToken(ctx context.Context, userID, tokenID uuid.UUID, revokedBy uuid.UUID) error {
+	token, err := s.findOwnedToken(ctx, userID, tokenID)
+	if err != nil {
+		return err
+	}
+	var by *uuid.UUID
+	if revokedBy != uuid.Nil {
+		by = &revokedBy
+	}
+	if err := s.tokenRepo.Revoke(ctx, token.ID, by, time.Now()); err != nil {
+		return err
+	}
+
+	s.cacheMu.Lock()
+	delete(s.cache, token.TokenHash)
+	s.cacheMu.Unlock()
+	log.Printf("Access token %s of user %s revoked by %s", token.ID, userID, revokedBy)
+	return nil
+}
+
+func (s *accessTokenService) GetUsage(ctx context.Context, userID, tokenID uuid.UUID, limit, offset int) (*responses.AccessTokenUsageListResponse, error) {
+	if _, err := s.findOwnedToken(ctx, userID, tokenID); err != nil {
+		return nil, err
+	}
+	usage, total, err := s.tokenRepo.FindUsage(ctx, tokenID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.AccessTokenUsageListResponse{
+		Usage:  make([]responses.AccessTokenUsageResponse, 0, len(usage)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, u := range usage {
+		resp.Usage = append(resp.Usage, responses.AccessTokenUsageResponse{
+			Method:    u.Method,
+			Path:      u.Path,
+			Status:    u.Status,
+			IPAddress: u.IPAddress,
+			UserAgent: u.UserAgent,
+			CreatedAt: u.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+func (s *accessTokenService) AuthenticateToken(ctx context.Context, raw string) (*middleware.TokenIdentity, error) {
+	if !strings.HasPrefix(raw, tokenPrefix) {
+		return nil, nil
+	}
+	hash := hashToken(raw)
+
+	s.cacheMu.Lock()
+	check, ok := s.cache[hash]
+	s.cacheMu.Unlock()
+	if ok && time.Since(check.checkedAt) < tokenCheckTTL {
+		return check.identity, nil
+	}
+
+	identity, err := s.lookup(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	s.remember(hash, identity)
+	return identity, nil
+}
+
+// lookup resolves the token to its owner with the role they have now, so revoked roles are not kept by
+// old tokens.
+func (s *accessTokenService) lookup(ctx context.Context, hash string) (*middleware.TokenIdentity, error) {
+	token, err := s.tokenRepo.FindByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.Active(time.Now()) {
+		return nil, nil
+	}
+	owner, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, nil
+	}
+	role, err := s.roles.EffectiveRole(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve role of user %s: %w", owner.ID, err)
+	}
+	return &middleware.TokenIdentity{
+		TokenID:  token.ID,
+		UserID:   owner.ID,
+		Username: owner.Username,
+		Name:     owner.Name,
+		Role:     role,
+		Scopes:   token.Scopes,
+	}, nil
+}
+
+func (s *accessTokenService) RecordTokenUsage(ctx context.Context, usage *user.AccessTokenUsage) {
+	if len(usage.Path) > 512 {
+		usage.Path = usage.Path[:512]
+	}
+	if len(usage.UserAgent) > 512 {
+		usage.UserAgent = usage.UserAgent[:512]
+	}
+	if err := s.tokenRepo.SaveUsage(ctx, usage); err != nil {
+		log.Printf("Failed to record usage of access token %s: %v", usage.TokenID, err)
+	}
+}
+
+func (s *accessTokenService) findOwnedToken(ctx context.Context, userID, tokenID uuid.UUID) (*user.PersonalAccessToken, error) {
+	token, err := s.tokenRepo.FindByID(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.UserID != userID {
+		return nil, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *accessTokenService) remember(hash string, identity *middleware.TokenIdentity) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if len(s.cache) >= maxCachedTokens {
+		for key, cached := range s.cache {
+			if time.Since(cached.checkedAt) >= tokenCheckTTL {
+				delete(s.cache, key)
+			}
+		}
+	}
+	s.cache[hash] = tokenCheck{identity: identity, checkedAt: time.Now()}
+}
+
+func parseScopes(values []string) ([]user.TokenScope, error) {
+	if len(values) == 0 {
+		return nil, ErrInvalidScope
+	}
+	scopes := make([]user.TokenScope, 0, len(values))
+	seen := make(map[user.TokenScope]bool)
+	for _, value := range values {
+		scope, err := user.ParseTokenScope(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidScope, err)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func toTokenResponse(token user.PersonalAccessToken) responses.AccessTokenResponse {
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return responses.AccessTokenResponse{
+		ID:         token.ID.String(),
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     scopes,
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		RevokedAt:  token.RevokedAt,
+	}
+}
+
+func newToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	token = tokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package announcementServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+)
+
+var (
+	ErrContestNotFound      = errors.New("contest not found")
+	ErrAnnouncementNotFound = errors.New("announcement not found")
+	ErrClassNotInContest    = errors.New("contest is not assigned to the target class")
+	ErrNotParticipant       = errors.New("you are not taking part in this contest")
+)
+
+// Event types pushed to contest listeners.
+const (
+	EventPublished = "announcement.published"
+	EventUpdated   = "announcement.updated"
+	EventDeleted   = "announcement.deleted"
+)
+
+type AnnouncementService interface {
+	CreateAnnouncement(ctx context.Context, contestID uuid.UUID, req requests.AnnouncementRequest, createdBy uuid.UUID) (*responses.AnnouncementResponse, error)
+	// UpdateAnnouncement replaces the announcement. Moving the publish time into the future withdraws a
+	// published announcement until then; otherwise connected clients receive the new text.
+	UpdateAnnouncement(ctx context.Context, announcementID uuid.UUID, req requests.AnnouncementRequest) (*responses.AnnouncementResponse, error)
+	DeleteAnnouncement(ctx context.Context, announcementID uuid.UUID) error
+	GetAnnouncement(ctx context.Context, announcementID uuid.UUID) (*responses.AnnouncementResponse, error)
+	// ListAnnouncements lists every announcement of a contest, scheduled ones included.
+	ListAnnouncements(ctx context.Context, contestID uuid.UUID) ([]responses.AnnouncementResponse, error)
+	// ListPublished lists the published announcements meant for the class contest, or the global
+	// contest when classTransactionID is nil, to someone taking part in it.
+	ListPublished(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) ([]responses.AnnouncementResponse, error)
+
+	// StartPublisher pushes scheduled announcements to connected clients once they are due, until ctx is
+	// cancelled.
+	StartPublisher(ctx context.Context)
+}
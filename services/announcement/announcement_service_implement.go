@@ -0,0 +1,295 @@
+package announcementServ
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	contestModel "neptune/backend/models/contest"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	announcementRepo "neptune/backend/repositories/announcement"
+	contestRepository "neptune/backend/repositories/contest"
+	webSocketService "neptune/backend/services/web_socket_service"
+	"strings"
+	"time"
+)
+
+// publishInterval is how often scheduled announcements are checked. An announcement reaches connected
+// clients at most this long after its publish time; the list endpoints show it right away.
+const publishInterval = 15 * time.Second
+
+type announcementService struct {
+	announcementRepo announcementRepo.AnnouncementRepository
+	contestRepo      contestRepository.ContestRepository
+	contestAccess    webSocketService.ContestAccess
+	webSocketService webSocketService.WebSocketService
+	txManager        database.TransactionManager
+}
+
+func NewAnnouncementService(
+	announcementRepo announcementRepo.AnnouncementRepository,
+	contestRepo contestRepository.ContestRepository,
+	contestAccess webSocketService.ContestAccess,
+	webSocketService webSocketService.WebSocketService,
+	txManager database.TransactionManager,
+) AnnouncementService {
+	return &announcementService{
+		announcementRepo: announcementRepo,
+		contestRepo:      contestRepo,
+		contestAccess:    contestAccess,
+		webSocketService: webSocketService,
+		txManager:        txManager,
+	}
+}
+
+func (s *announcementService) CreateAnnouncement(ctx context.Context, contestID uuid.UUID, req requests.AnnouncementRequest, createdBy uuid.UUID) (*responses.AnnouncementResponse, error) {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest == nil {
+		return nil, ErrContestNotFound
+	}
+
+	now := time.Now()
+	announcement := &contestModel.Announcement{
+		ID:        uuid.New(),
+		ContestID: contestID,
+		CreatedBy: &createdBy,
+		CreatedAt: now,
+	}
+	if err := s.apply(ctx, announcement, req, now); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, announcement); err != nil {
+		return nil, err
+	}
+	if !announcement.PublishAt.After(now) {
+		s.publish(ctx, announcement, now)
+	}
+	resp := toResponse(*announcement)
+	return &resp, nil
+}
+
+func (s *announcementService) UpdateAnnouncement(ctx context.Context, announcementID uuid.UUID, req requests.AnnouncementRequest) (*responses.AnnouncementResponse, error) {
+	announcement, err := s.announcementRepo.FindByID(ctx, announcementID)
+	if err != nil {
+		return nil, err
+	}
+	if announcement == nil {
+		return nil, ErrAnnouncementNotFound
+	}
+	wasPublished := announcement.PublishedAt != nil
+	before := *announcement
+
+	now := time.Now()
+	if err := s.apply(ctx, announcement, req, now); err != nil {
+		return nil, err
+	}
+	if announcement.PublishAt.After(now) {
+		announcement.PublishedAt = nil
+	}
+	if err := s.save(ctx, announcement); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case announcement.PublishAt.After(now) && wasPublished:
+		s.push(before, webSocketService.ContestEvent{Type: EventDeleted, Data: removedAnnouncement{ID: before.ID}})
+	case !announcement.PublishAt.After(now) && wasPublished:
+		// Classes dropped from the targets are told to remove it; the rest get the new text.
+		s.pushWhere(before, func(l *webSocketService.ContestListener) bool {
+			return !announcement.Reaches(l.ClassTransactionID)
+		}, webSocketService.ContestEvent{Type: EventDeleted, Data: removedAnnouncement{ID: before.ID}})
+		s.push(*announcement, webSocketService.ContestEvent{Type: EventUpdated, Data: toResponse(*announcement)})
+	case !announcement.PublishAt.After(now):
+		s.publish(ctx, announcement, now)
+	}
+	resp := toResponse(*announcement)
+	return &resp, nil
+}
+
+func (s *announcementService) DeleteAnnouncement(ctx context.Context, announcementID uuid.UUID) error {
+	announcement, err := s.announcementRepo.FindByID(ctx, announcementID)
+	if err != nil {
+		return err
+	}
+	if announcement == nil {
+		return ErrAnnouncementNotFound
+	}
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		return s.announcementRepo.Delete(txCtx, announcementID)
+	}); err != nil {
+		return err
+	}
+	if announcement.PublishedAt != nil {
+		s.push(*announcement, webSocketService.ContestEvent{Type: EventDeleted, Data: removedAnnouncement{ID: announcement.ID}})
+	}
+	return nil
+}
+
+func (s *announcementService) GetAnnouncement(ctx context.Context, announcementID uuid.UUID) (*responses.AnnouncementResponse, error) {
+	announcement, err := s.announcementRepo.FindByID(ctx, announcementID)
+	if err != nil {
+		return nil, err
+	}
+	if announcement == nil {
+		return nil, ErrAnnouncementNotFound
+	}
+	resp := toResponse(*announcement)
+	return &resp, nil
+}
+
+func (s *announcementService) ListAnnouncements(ctx context.Context, contestID uuid.UUID) ([]responses.AnnouncementResponse, error) {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest == nil {
+		return nil, ErrContestNotFound
+	}
+	announcements, err := s.announcementRepo.FindByContest(ctx, contestID, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]responses.AnnouncementResponse, 0, len(announcements))
+	for _, announcement := range announcements {
+		resp = append(resp, toResponse(announcement))
+	}
+	return resp, nil
+}
+
+func (s *announcementService) ListPublished(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) ([]responses.AnnouncementResponse, error) {
+	contestRole, err := s.contestAccess.ContestRoleOf(ctx, contestID, classTransactionID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if contestRole == webSocketService.ContestOutsider {
+		return nil, ErrNotParticipant
+	}
+
+	now := time.Now()
+	announcements, err := s.announcementRepo.FindByContest(ctx, contestID, &now)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]responses.AnnouncementResponse, 0, len(announcements))
+	for _, announcement := range announcements {
+		if announcement.Reaches(classTransactionID) {
+			resp = append(resp, toResponse(announcement))
+		}
+	}
+	return resp, nil
+}
+
+func (s *announcementService) StartPublisher(ctx context.Context) {
+	ticker := time.NewTicker(publishInterval)
+	defer ticker.Stop()
+	for {
+		s.publishDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *announcementService) publishDue(ctx context.Context) {
+	now := time.Now()
+	due, err := s.announcementRepo.FindDue(ctx, now)
+	if err != nil {
+		log.Printf("Failed to find due announcements: %v", err)
+		return
+	}
+	for i := range due {
+		s.publish(ctx, &due[i], now)
+	}
+}
+
+// publish marks the announcement as published and pushes it, unless another instance already did.
+func (s *announcementService) publish(ctx context.Context, announcement *contestModel.Announcement, at time.Time) {
+	claimed, err := s.announcementRepo.MarkPublished(ctx, announcement.ID, at)
+	if err != nil {
+		log.Printf("Failed to publish announcement %s: %v", announcement.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	announcement.PublishedAt = &at
+	s.push(*announcement, webSocketService.ContestEvent{Type: EventPublished, Data: toResponse(*announcement)})
+}
+
+func (s *announcementService) push(announcement contestModel.Announcement, event webSocketService.ContestEvent) {
+	s.pushWhere(announcement, func(*webSocketService.ContestListener) bool { return true }, event)
+}
+
+func (s *announcementService) pushWhere(announcement contestModel.Announcement, deliver func(*webSocketService.ContestListener) bool, event webSocketService.ContestEvent) {
+	s.webSocketService.SendToContest(announcement.ContestID, func(l *webSocketService.ContestListener) bool {
+		return announcement.Reaches(l.ClassTransactionID) && deliver(l)
+	}, event)
+}
+
+// apply copies the request onto the announcement after checking every target class runs the contest.
+func (s *announcementService) apply(ctx context.Context, announcement *contestModel.Announcement, req requests.AnnouncementRequest, now time.Time) error {
+	targets := make([]contestModel.AnnouncementTarget, 0, len(req.ClassTransactionIDs))
+	seen := make(map[uuid.UUID]bool, len(req.ClassTransactionIDs))
+	for _, classTransactionID := range req.ClassTransactionIDs {
+		if seen[classTransactionID] {
+			continue
+		}
+		seen[classTransactionID] = true
+		classContest, err := s.contestRepo.FindClassContestByIDs(ctx, classTransactionID, announcement.ContestID)
+		if err != nil {
+			return err
+		}
+		if classContest == nil {
+			return fmt.Errorf("%w: %s", ErrClassNotInContest, classTransactionID)
+		}
+		targets = append(targets, contestModel.AnnouncementTarget{AnnouncementID: announcement.ID, ClassTransactionID: classTransactionID})
+	}
+
+	announcement.Title = strings.TrimSpace(req.Title)
+	announcement.Body = req.Body
+	announcement.AllClasses = len(targets) == 0
+	announcement.Targets = targets
+	announcement.PublishAt = now
+	if req.PublishAt != nil {
+		announcement.PublishAt = *req.PublishAt
+	}
+	announcement.UpdatedAt = now
+	return nil
+}
+
+func (s *announcementService) save(ctx context.Context, announcement *contestModel.Announcement) error {
+	return s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		return s.announcementRepo.Save(txCtx, announcement)
+	})
+}
+
+// removedAnnouncement tells clients to drop an announcement they were shown.
+type removedAnnouncement struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func toResponse(announcement contestModel.Announcement) responses.AnnouncementResponse {
+	resp := responses.AnnouncementResponse{
+		ID:         announcement.ID,
+		ContestID:  announcement.ContestID,
+		Title:      announcement.Title,
+		Body:       announcement.Body,
+		AllClasses: announcement.AllClasses,
+		PublishAt:  announcement.PublishAt,
+		Published:  announcement.PublishedAt != nil,
+		CreatedAt:  announcement.CreatedAt,
+		UpdatedAt:  announcement.UpdatedAt,
+	}
+	for _, target := range announcement.Targets {
+		resp.ClassTransactionIDs = append(resp.ClassTransactionIDs, target.ClassTransactionID)
+	}
+	return resp
+}
@@ -0,0 +1,17 @@
+package auditServ
+
+import (
+	"context"
+	"io"
+	"neptune/backend/pkg/audit"
+	"neptune/backend/pkg/responses"
+	auditRepo "neptune/backend/repositories/audit"
+)
+
+type AuditService interface {
+	audit.Recorder
+
+	ListEntries(ctx context.Context, filter auditRepo.Filter, limit, offset int) (*responses.AuditLogListResponse, error)
+	// ExportCSV writes every matching entry to w, newest first, reading them in batches.
+	ExportCSV(ctx context.Context, filter auditRepo.Filter, w io.Writer) error
+}
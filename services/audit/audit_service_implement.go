@@ -0,0 +1,135 @@
+package auditServ
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	auditModel "neptune/backend/models/audit"
+	"neptune/backend/pkg/responses"
+	auditRepo "neptune/backend/repositories/audit"
+	"strconv"
+	"time"
+)
+
+const exportBatchSize = 500
+
+type auditService struct {
+	auditRepo auditRepo.AuditRepository
+}
+
+func NewAuditService(auditRepo auditRepo.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+func (s *auditService) Record(ctx context.Context, entry *auditModel.AuditLog) error {
+	if len(entry.Path) > 512 {
+		entry.Path = entry.Path[:512]
+	}
+	return s.auditRepo.Save(ctx, entry)
+}
+
+func (s *auditService) ListEntries(ctx context.Context, filter auditRepo.Filter, limit, offset int) (*responses.AuditLogListResponse, error) {
+	entries, total, err := s.auditRepo.Find(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.AuditLogListResponse{
+		Entries: make([]responses.AuditLogResponse, 0, len(entries)),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, toAuditLogResponse(entry))
+	}
+	return resp, nil
+}
+
+func (s *auditService) ExportCSV(ctx context.Context, filter auditRepo.Filter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "created_at", "actor_id", "actor_username", "actor_role", "token_id", "action",
+		"target_type", "target_id", "method", "path", "status", "ip_address", "before", "after", "forced", "force_reason"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write audit CSV: %w", err)
+	}
+
+	var beforeID uint
+	for {
+		entries, err := s.auditRepo.FindBefore(ctx, filter, beforeID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			row := []string{
+				strconv.FormatUint(uint64(entry.ID), 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				optionalID(entry.ActorID),
+				entry.ActorUsername,
+				entry.ActorRole,
+				optionalID(entry.TokenID),
+				entry.Action,
+				entry.TargetType,
+				entry.TargetID,
+				entry.Method,
+				entry.Path,
+				strconv.Itoa(entry.Status),
+				entry.IPAddress,
+				entry.Before,
+				entry.After,
+				strconv.FormatBool(entry.Forced),
+				entry.ForceReason,
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write audit CSV: %w", err)
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write audit CSV: %w", err)
+		}
+		if len(entries) < exportBatchSize {
+			return nil
+		}
+		beforeID = entries[len(entries)-1].ID
+	}
+}
+
+func toAuditLogResponse(entry auditModel.AuditLog) responses.AuditLogResponse {
+	return responses.AuditLogResponse{
+		ID:            entry.ID,
+		ActorID:       optionalID(entry.ActorID),
+		ActorUsername: entry.ActorUsername,
+		ActorRole:     entry.ActorRole,
+		TokenID:       optionalID(entry.TokenID),
+		Action:        entry.Action,
+		TargetType:    entry.TargetType,
+		TargetID:      entry.TargetID,
+		Method:        entry.Method,
+		Path:          entry.Path,
+		Status:        entry.Status,
+		Before:        rawJSON(entry.Before),
+		After:         rawJSON(entry.After),
+		IPAddress:     entry.IPAddress,
+		Forced:        entry.Forced,
+		ForceReason:   entry.ForceReason,
+		CreatedAt:     entry.CreatedAt,
+	}
+}
+
+func rawJSON(value string) json.RawMessage {
+	if value == "" || !json.Valid([]byte(value)) {
+		return nil
+	}
+	return json.RawMessage(value)
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
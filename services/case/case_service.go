@@ -2,15 +2,52 @@ package caseService
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	"io"
+	"neptune/backend/models/user"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
+	caseRepository "neptune/backend/repositories/case"
+)
+
+var (
+	ErrCaseNotFound       = errors.New("case not found")
+	ErrRevisionNotFound   = errors.New("statement revision not found")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrInvalidMetadata    = errors.New("invalid problem bank metadata")
+	ErrInvalidPackage     = errors.New("invalid problem package")
 )
 
 type CaseService interface {
-	CreateCase(ctx context.Context, req requests.CreateCaseRequest, url string) (*responses.CaseResponse, error)
+	// CreateCase creates the case with its first statement revision. url is the stored PDF, or empty.
+	CreateCase(ctx context.Context, req requests.CreateCaseRequest, url string, editorID uuid.UUID) (*responses.CaseResponse, error)
 	GetCaseByID(ctx context.Context, caseID uuid.UUID) (*responses.CaseResponse, error)
-	GetAllCases(ctx context.Context) ([]responses.CaseResponse, error)
-	UpdateCase(ctx context.Context, caseID uuid.UUID, req requests.UpdateCaseRequest) (*responses.CaseResponse, error)
-	DeleteCase(ctx context.Context, caseID uuid.UUID) error // Soft delete
+	// ListCases returns one page of the problem bank, each case with the contests using it.
+	ListCases(ctx context.Context, filter caseRepository.Filter, limit, offset int) (*responses.CaseListResponse, error)
+	GetTags(ctx context.Context) ([]responses.CaseTagResponse, error)
+	// UpdateCase changes the case, saving a new statement revision when the statement changes. Changing
+	// its limits while it is in a running contest, or after it has contest submissions, needs force.
+	UpdateCase(ctx context.Context, caseID uuid.UUID, req requests.UpdateCaseRequest, force bool, editorID uuid.UUID) (*responses.CaseResponse, error)
+	DeleteCase(ctx context.Context, caseID uuid.UUID, force bool) error // Soft delete, guarded like limit changes
+
+	// GetStatement returns the statement with its samples and attachments, to staff and to students the
+	// problem is released to.
+	GetStatement(ctx context.Context, caseID uuid.UUID, viewerID uuid.UUID, role user.Role) (*responses.CaseStatementResponse, error)
+
+	// Statement revisions
+	GetRevisions(ctx context.Context, caseID uuid.UUID) ([]responses.CaseRevisionResponse, error)
+	GetRevision(ctx context.Context, caseID uuid.UUID, revision int) (*responses.CaseRevisionResponse, error)
+	DiffRevisions(ctx context.Context, caseID uuid.UUID, from, to int) (*responses.CaseRevisionDiffResponse, error)
+
+	// Attachments
+	AddAttachment(ctx context.Context, req requests.AddCaseAttachmentRequest) (*responses.CaseAttachmentResponse, error)
+	DeleteAttachment(ctx context.Context, caseID, attachmentID uuid.UUID) error
+
+	// Problem packages, see docs/neptune-package-format.md
+	// ImportPackage creates a case from a Neptune, Polygon or Kattis package.
+	ImportPackage(ctx context.Context, req requests.ImportCasePackageRequest, editorID uuid.UUID) (*responses.CasePackageImportResponse, error)
+	// ExportPackage writes the case to w as a Neptune package.
+	ExportPackage(ctx context.Context, caseID uuid.UUID, w io.Writer) error
 }
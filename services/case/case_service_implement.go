@@ -4,22 +4,58 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"io"
+	"log"
+	"mime"
 	caseModel "neptune/backend/models/contest"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/database"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/storage"
+	"neptune/backend/pkg/utils"
 	caseRepository "neptune/backend/repositories/case"
+	testCaseRepo "neptune/backend/repositories/test_case"
+	contestService "neptune/backend/services/contest"
+	testCaseServ "neptune/backend/services/test_case"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	maxSampleBytes     = 64 << 10 // samples longer than this are cut in the statement
+	maxAttachmentBytes = 20 << 20
+
+	// Problem bank metadata limits, matching the columns
+	maxTags         = 20
+	maxTagLength    = 50
+	maxAuthorLength = 100
+	maxSourceLength = 255
 )
 
 type caseServiceImpl struct {
-	caseRepo caseRepository.CaseRepository
+	caseRepo       caseRepository.CaseRepository
+	testCaseRepo   testCaseRepo.TestCaseRepository
+	blobStore      storage.BlobStore
+	contestService contestService.ContestService
+	txManager      database.TransactionManager
+	packageLimits  testCaseServ.ArchiveLimits // Packages carry testcases, so they share the archive limits
 }
 
-func NewCaseService(caseRepo caseRepository.CaseRepository) CaseService {
-	return &caseServiceImpl{caseRepo: caseRepo}
+func NewCaseService(caseRepo caseRepository.CaseRepository, testCaseRepo testCaseRepo.TestCaseRepository, blobStore storage.BlobStore, contestService contestService.ContestService, txManager database.TransactionManager) CaseService {
+	return &caseServiceImpl{
+		caseRepo:       caseRepo,
+		testCaseRepo:   testCaseRepo,
+		blobStore:      blobStore,
+		contestService: contestService,
+		txManager:      txManager,
+		packageLimits:  testCaseServ.LoadArchiveLimits(),
+	}
 }
 
 // CreateCase creates a new problem case.
-func (s *caseServiceImpl) CreateCase(ctx context.Context, req requests.CreateCaseRequest, url string) (*responses.CaseResponse, error) {
+func (s *caseServiceImpl) CreateCase(ctx context.Context, req requests.CreateCaseRequest, url string, editorID uuid.UUID) (*responses.CaseResponse, error) {
 	problemCase := &caseModel.Case{
 		ID:            uuid.New(),
 		Name:          req.Name,
@@ -28,18 +64,26 @@ func (s *caseServiceImpl) CreateCase(ctx context.Context, req requests.CreateCas
 		TimeLimitMs:   req.TimeLimitMs,
 		MemoryLimitMb: req.MemoryLimitMb,
 	}
-	if err := s.caseRepo.SaveCase(ctx, problemCase); err != nil {
-		return nil, fmt.Errorf("failed to create case: %w", err)
+	setStatement(problemCase, req.CaseStatement)
+	problemCase.StatementRevision = 1
+	tags, err := setMetadata(problemCase, req.CaseMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.caseRepo.SaveCase(txCtx, problemCase); err != nil {
+			return fmt.Errorf("failed to create case: %w", err)
+		}
+		if err := s.caseRepo.ReplaceTags(txCtx, problemCase.ID, tags); err != nil {
+			return err
+		}
+		return s.caseRepo.SaveStatementRevision(txCtx, newRevision(problemCase, editorID))
+	}); err != nil {
+		return nil, err
 	}
-	return &responses.CaseResponse{
-		ID:            problemCase.ID,
-		Name:          problemCase.Name,
-		Description:   problemCase.Description,
-		PDFFileUrl:    problemCase.PDFFileUrl,
-		TimeLimitMs:   problemCase.TimeLimitMs,
-		MemoryLimitMb: problemCase.MemoryLimitMb,
-		CreatedAt:     problemCase.CreatedAt,
-	}, nil
+	resp := toCaseResponse(*problemCase, nil)
+	return &resp, nil
 }
 
 // GetCaseByID retrieves a problem case.
@@ -51,75 +95,463 @@ func (s *caseServiceImpl) GetCaseByID(ctx context.Context, caseID uuid.UUID) (*r
 	if problemCase == nil {
 		return nil, nil // Not found
 	}
-	return &responses.CaseResponse{
-		ID:            problemCase.ID,
-		Name:          problemCase.Name,
-		Description:   problemCase.Description,
-		PDFFileUrl:    problemCase.PDFFileUrl,
-		TimeLimitMs:   problemCase.TimeLimitMs,
-		MemoryLimitMb: problemCase.MemoryLimitMb,
-		CreatedAt:     problemCase.CreatedAt,
-		UpdatedAt:     problemCase.UpdatedAt,
-	}, nil
+	usage, err := s.caseRepo.FindUsage(ctx, []uuid.UUID{caseID})
+	if err != nil {
+		return nil, err
+	}
+	resp := toCaseResponse(*problemCase, usage)
+	return &resp, nil
 }
 
-// GetAllCases retrieves all problem cases.
-func (s *caseServiceImpl) GetAllCases(ctx context.Context) ([]responses.CaseResponse, error) {
-	cases, err := s.caseRepo.FindAllCases(ctx)
+// ListCases retrieves one page of the problem bank.
+func (s *caseServiceImpl) ListCases(ctx context.Context, filter caseRepository.Filter, limit, offset int) (*responses.CaseListResponse, error) {
+	cases, total, err := s.caseRepo.FindCases(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	caseIDs := make([]uuid.UUID, len(cases))
+	for i, c := range cases {
+		caseIDs[i] = c.ID
+	}
+	usage, err := s.caseRepo.FindUsage(ctx, caseIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all cases: %w", err)
+		return nil, err
+	}
+	usageByCase := make(map[uuid.UUID][]caseRepository.CaseUsage, len(cases))
+	for _, u := range usage {
+		usageByCase[u.CaseID] = append(usageByCase[u.CaseID], u)
 	}
 
-	resp := make([]responses.CaseResponse, len(cases))
+	resp := &responses.CaseListResponse{
+		Cases:  make([]responses.CaseResponse, len(cases)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
 	for i, c := range cases {
-		resp[i] = responses.CaseResponse{
-			ID:            c.ID,
-			Name:          c.Name,
-			Description:   c.Description,
-			PDFFileUrl:    c.PDFFileUrl,
-			TimeLimitMs:   c.TimeLimitMs,
-			MemoryLimitMb: c.MemoryLimitMb,
-			CreatedAt:     c.CreatedAt,
-			UpdatedAt:     c.UpdatedAt,
-		}
+		resp.Cases[i] = toCaseResponse(c, usageByCase[c.ID])
+	}
+	return resp, nil
+}
+
+func (s *caseServiceImpl) GetTags(ctx context.Context) ([]responses.CaseTagResponse, error) {
+	tags, err := s.caseRepo.FindTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]responses.CaseTagResponse, len(tags))
+	for i, tag := range tags {
+		resp[i] = responses.CaseTagResponse{Tag: tag.Tag, Count: tag.Count}
 	}
 	return resp, nil
 }
 
 // UpdateCase updates an existing problem case.
-func (s *caseServiceImpl) UpdateCase(ctx context.Context, caseID uuid.UUID, req requests.UpdateCaseRequest) (*responses.CaseResponse, error) {
+func (s *caseServiceImpl) UpdateCase(ctx context.Context, caseID uuid.UUID, req requests.UpdateCaseRequest, force bool, editorID uuid.UUID) (*responses.CaseResponse, error) {
 	problemCase, err := s.caseRepo.FindCaseByID(ctx, caseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find case for update: %w", err)
 	}
 	if problemCase == nil {
-		return nil, fmt.Errorf("case with ID %s not found", caseID.String())
+		return nil, fmt.Errorf("%w: %s", ErrCaseNotFound, caseID.String())
 	}
+	limitsChanged := problemCase.TimeLimitMs != req.TimeLimitMs || problemCase.MemoryLimitMb != req.MemoryLimitMb
+	if limitsChanged && !force {
+		if err := s.contestService.GuardCaseChange(ctx, caseID); err != nil {
+			return nil, err
+		}
+	}
+	statementChanged := problemCase.Name != req.Name || problemCase.Statement != req.Statement ||
+		problemCase.InputFormat != req.InputFormat || problemCase.OutputFormat != req.OutputFormat ||
+		problemCase.Constraints != req.Constraints
 
 	problemCase.Name = req.Name
 	problemCase.Description = req.Description
 	problemCase.TimeLimitMs = req.TimeLimitMs
 	problemCase.MemoryLimitMb = req.MemoryLimitMb
+	setStatement(problemCase, req.CaseStatement)
+	if statementChanged {
+		problemCase.StatementRevision++
+	}
+	tags, err := setMetadata(problemCase, req.CaseMetadata)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.caseRepo.SaveCase(ctx, problemCase); err != nil {
-		return nil, fmt.Errorf("failed to update case: %w", err)
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.caseRepo.SaveCase(txCtx, problemCase); err != nil {
+			return fmt.Errorf("failed to update case: %w", err)
+		}
+		if err := s.caseRepo.ReplaceTags(txCtx, problemCase.ID, tags); err != nil {
+			return err
+		}
+		if statementChanged {
+			return s.caseRepo.SaveStatementRevision(txCtx, newRevision(problemCase, editorID))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return &responses.CaseResponse{
-		ID:            problemCase.ID,
-		Name:          problemCase.Name,
-		Description:   problemCase.Description,
-		PDFFileUrl:    problemCase.PDFFileUrl,
-		TimeLimitMs:   problemCase.TimeLimitMs,
-		MemoryLimitMb: problemCase.MemoryLimitMb,
-		CreatedAt:     problemCase.CreatedAt,
-		UpdatedAt:     problemCase.UpdatedAt,
-	}, nil
+	usage, err := s.caseRepo.FindUsage(ctx, []uuid.UUID{caseID})
+	if err != nil {
+		return nil, err
+	}
+	resp := toCaseResponse(*problemCase, usage)
+	return &resp, nil
 }
 
 // DeleteCase soft deletes a problem case.
-func (s *caseServiceImpl) DeleteCase(ctx context.Context, caseID uuid.UUID) error {
-	// Implement checks if case is part of any active contests before deleting
-	// For now, just soft delete
+func (s *caseServiceImpl) DeleteCase(ctx context.Context, caseID uuid.UUID, force bool) error {
+	if !force {
+		if err := s.contestService.GuardCaseChange(ctx, caseID); err != nil {
+			return err
+		}
+	}
 	return s.caseRepo.DeleteCase(ctx, caseID)
 }
+
+func (s *caseServiceImpl) GetStatement(ctx context.Context, caseID uuid.UUID, viewerID uuid.UUID, role user.Role) (*responses.CaseStatementResponse, error) {
+	if err := s.contestService.AuthorizeCase(ctx, caseID, viewerID, role); err != nil {
+		return nil, err
+	}
+	problemCase, err := s.findCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.CaseStatementResponse{
+		CaseID:              problemCase.ID,
+		Name:                problemCase.Name,
+		Revision:            problemCase.StatementRevision,
+		TimeLimitMs:         problemCase.TimeLimitMs,
+		MemoryLimitMb:       problemCase.MemoryLimitMb,
+		PDFFileUrl:          responses.CasePDFURL(problemCase.ID, problemCase.PDFFileUrl),
+		CaseStatementFields: statementFields(*problemCase),
+		Samples:             []responses.CaseSampleResponse{},
+		Attachments:         []responses.CaseAttachmentResponse{},
+	}
+
+	samples, err := s.testCaseRepo.FindSampleTestCases(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sample := range samples {
+		input, inputCut, err := s.readSample(ctx, sample.InputUrl)
+		if err != nil {
+			return nil, err
+		}
+		output, outputCut, err := s.readSample(ctx, sample.OutputUrl)
+		if err != nil {
+			return nil, err
+		}
+		resp.Samples = append(resp.Samples, responses.CaseSampleResponse{
+			Number:    sample.Number,
+			Input:     input,
+			Output:    output,
+			Truncated: inputCut || outputCut,
+		})
+	}
+
+	attachments, err := s.caseRepo.FindAttachments(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		resp.Attachments = append(resp.Attachments, toAttachmentResponse(attachment))
+	}
+	return resp, nil
+}
+
+func (s *caseServiceImpl) GetRevisions(ctx context.Context, caseID uuid.UUID) ([]responses.CaseRevisionResponse, error) {
+	if _, err := s.findCase(ctx, caseID); err != nil {
+		return nil, err
+	}
+	revisions, err := s.caseRepo.FindStatementRevisions(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]responses.CaseRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		resp[i] = toRevisionResponse(revision)
+	}
+	return resp, nil
+}
+
+func (s *caseServiceImpl) GetRevision(ctx context.Context, caseID uuid.UUID, revision int) (*responses.CaseRevisionResponse, error) {
+	found, err := s.findRevision(ctx, caseID, revision)
+	if err != nil {
+		return nil, err
+	}
+	resp := toRevisionResponse(*found)
+	return &resp, nil
+}
+
+// DiffRevisions compares two statement revisions field by field. Unchanged fields are left out.
+func (s *caseServiceImpl) DiffRevisions(ctx context.Context, caseID uuid.UUID, from, to int) (*responses.CaseRevisionDiffResponse, error) {
+	older, err := s.findRevision(ctx, caseID, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.findRevision(ctx, caseID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.CaseRevisionDiffResponse{CaseID: caseID, From: from, To: to, Fields: []responses.CaseFieldDiffResponse{}}
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"name", older.Name, newer.Name},
+		{"statement", older.Statement, newer.Statement},
+		{"input_format", older.InputFormat, newer.InputFormat},
+		{"output_format", older.OutputFormat, newer.OutputFormat},
+		{"constraints", older.Constraints, newer.Constraints},
+	}
+	for _, field := range fields {
+		if field.before == field.after {
+			continue
+		}
+		lines := utils.DiffLines(field.before, field.after)
+		diff := responses.CaseFieldDiffResponse{Field: field.name, Lines: make([]responses.DiffLineResponse, len(lines))}
+		for i, line := range lines {
+			diff.Lines[i] = responses.DiffLineResponse{Op: string(line.Op), Text: line.Text}
+		}
+		resp.Fields = append(resp.Fields, diff)
+	}
+	return resp, nil
+}
+
+// AddAttachment stores the uploaded file in the blob store and attaches it to the case.
+func (s *caseServiceImpl) AddAttachment(ctx context.Context, req requests.AddCaseAttachmentRequest) (*responses.CaseAttachmentResponse, error) {
+	if _, err := s.findCase(ctx, req.CaseID); err != nil {
+		return nil, err
+	}
+	if req.File.Size > maxAttachmentBytes {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrAttachmentTooLarge, req.File.Size, maxAttachmentBytes)
+	}
+
+	fileName := path.Base(strings.ReplaceAll(req.File.Filename, "\\", "/"))
+	ext := strings.ToLower(filepath.Ext(fileName))
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	attachment := &caseModel.CaseAttachment{
+		ID:          uuid.New(),
+		CaseID:      req.CaseID,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   req.File.Size,
+	}
+	attachment.FileUrl = fmt.Sprintf("/private/case_attachment/%s/%s%s", req.CaseID.String(), attachment.ID.String(), ext)
+	key := storage.KeyFromURL(attachment.FileUrl)
+
+	src, err := req.File.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+	if err := s.blobStore.Put(ctx, key, src, req.File.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := s.caseRepo.SaveAttachment(ctx, attachment); err != nil {
+		if delErr := s.blobStore.Delete(context.Background(), key); delErr != nil {
+			log.Printf("Error removing orphaned attachment %s: %v", key, delErr)
+		}
+		return nil, err
+	}
+	resp := toAttachmentResponse(*attachment)
+	return &resp, nil
+}
+
+func (s *caseServiceImpl) DeleteAttachment(ctx context.Context, caseID, attachmentID uuid.UUID) error {
+	attachment, err := s.caseRepo.FindAttachment(ctx, caseID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return ErrAttachmentNotFound
+	}
+	if err := s.caseRepo.DeleteAttachment(ctx, attachmentID); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if err := s.blobStore.Delete(context.Background(), storage.KeyFromURL(attachment.FileUrl)); err != nil {
+		log.Printf("Failed to delete attachment file %s: %v", attachment.FileUrl, err)
+	}
+	return nil
+}
+
+func (s *caseServiceImpl) findCase(ctx context.Context, caseID uuid.UUID) (*caseModel.Case, error) {
+	problemCase, err := s.caseRepo.FindCaseByID(ctx, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get case: %w", err)
+	}
+	if problemCase == nil {
+		return nil, ErrCaseNotFound
+	}
+	return problemCase, nil
+}
+
+func (s *caseServiceImpl) findRevision(ctx context.Context, caseID uuid.UUID, revision int) (*caseModel.CaseStatementRevision, error) {
+	found, err := s.caseRepo.FindStatementRevision(ctx, caseID, revision)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+	return found, nil
+}
+
+// readSample reads a sample file for the statement, cut at maxSampleBytes.
+func (s *caseServiceImpl) readSample(ctx context.Context, storedPath string) (string, bool, error) {
+	rc, err := s.blobStore.Get(ctx, storage.KeyFromURL(storedPath))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read sample %s: %w", storedPath, err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(io.LimitReader(rc, maxSampleBytes+1))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read sample %s: %w", storedPath, err)
+	}
+	if len(content) > maxSampleBytes {
+		return string(content[:maxSampleBytes]), true, nil
+	}
+	return string(content), false, nil
+}
+
+func setStatement(problemCase *caseModel.Case, statement requests.CaseStatement) {
+	problemCase.Statement = statement.Statement
+	problemCase.InputFormat = statement.InputFormat
+	problemCase.OutputFormat = statement.OutputFormat
+	problemCase.Constraints = statement.Constraints
+}
+
+// setMetadata copies the problem bank metadata onto the case and returns its normalised tags: lower case,
+// trimmed and without duplicates.
+func setMetadata(problemCase *caseModel.Case, metadata requests.CaseMetadata) ([]string, error) {
+	difficulty := caseModel.CaseDifficulty(strings.ToLower(strings.TrimSpace(metadata.Difficulty)))
+	if !difficulty.Valid() {
+		return nil, fmt.Errorf("%w: unknown difficulty %q", ErrInvalidMetadata, metadata.Difficulty)
+	}
+	author := strings.TrimSpace(metadata.Author)
+	source := strings.TrimSpace(metadata.Source)
+	if len(author) > maxAuthorLength || len(source) > maxSourceLength {
+		return nil, fmt.Errorf("%w: author or source is too long", ErrInvalidMetadata)
+	}
+
+	tags := make([]string, 0, len(metadata.Tags))
+	seen := make(map[string]bool, len(metadata.Tags))
+	for _, raw := range metadata.Tags {
+		tag := strings.ToLower(strings.TrimSpace(raw))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidMetadata, tag, maxTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidMetadata, maxTags)
+	}
+
+	problemCase.Difficulty = difficulty
+	problemCase.Author = author
+	problemCase.Source = source
+	problemCase.Tags = make([]caseModel.CaseTag, len(tags))
+	for i, tag := range tags {
+		problemCase.Tags[i] = caseModel.CaseTag{CaseID: problemCase.ID, Tag: tag}
+	}
+	return tags, nil
+}
+
+// newRevision snapshots the current statement of the case as its StatementRevision.
+func newRevision(problemCase *caseModel.Case, editorID uuid.UUID) *caseModel.CaseStatementRevision {
+	revision := &caseModel.CaseStatementRevision{
+		ID:           uuid.New(),
+		CaseID:       problemCase.ID,
+		Revision:     problemCase.StatementRevision,
+		Name:         problemCase.Name,
+		Statement:    problemCase.Statement,
+		InputFormat:  problemCase.InputFormat,
+		OutputFormat: problemCase.OutputFormat,
+		Constraints:  problemCase.Constraints,
+	}
+	if editorID != uuid.Nil {
+		revision.EditedBy = &editorID
+	}
+	return revision
+}
+
+func statementFields(problemCase caseModel.Case) responses.CaseStatementFields {
+	return responses.CaseStatementFields{
+		Statement:    problemCase.Statement,
+		InputFormat:  problemCase.InputFormat,
+		OutputFormat: problemCase.OutputFormat,
+		Constraints:  problemCase.Constraints,
+	}
+}
+
+func toCaseResponse(problemCase caseModel.Case, usage []caseRepository.CaseUsage) responses.CaseResponse {
+	tags := make([]string, len(problemCase.Tags))
+	for i, tag := range problemCase.Tags {
+		tags[i] = tag.Tag
+	}
+	usageResp := make([]responses.CaseUsageResponse, len(usage))
+	for i, u := range usage {
+		usageResp[i] = responses.CaseUsageResponse{
+			ContestID:   u.ContestID,
+			ContestName: u.ContestName,
+			ProblemCode: u.ProblemCode,
+			AddedAt:     u.AddedAt,
+		}
+	}
+	return responses.CaseResponse{
+		ID:                  problemCase.ID,
+		Name:                problemCase.Name,
+		Description:         problemCase.Description,
+		PDFFileUrl:          responses.CasePDFURL(problemCase.ID, problemCase.PDFFileUrl),
+		TimeLimitMs:         problemCase.TimeLimitMs,
+		MemoryLimitMb:       problemCase.MemoryLimitMb,
+		CreatedAt:           problemCase.CreatedAt,
+		UpdatedAt:           problemCase.UpdatedAt,
+		CaseStatementFields: statementFields(problemCase),
+		StatementRevision:   problemCase.StatementRevision,
+		Tags:                tags,
+		Difficulty:          string(problemCase.Difficulty),
+		Author:              problemCase.Author,
+		Source:              problemCase.Source,
+		Usage:               usageResp,
+	}
+}
+
+func toRevisionResponse(revision caseModel.CaseStatementRevision) responses.CaseRevisionResponse {
+	return responses.CaseRevisionResponse{
+		CaseID:   revision.CaseID,
+		Revision: revision.Revision,
+		Name:     revision.Name,
+		CaseStatementFields: responses.CaseStatementFields{
+			Statement:    revision.Statement,
+			InputFormat:  revision.InputFormat,
+			OutputFormat: revision.OutputFormat,
+			Constraints:  revision.Constraints,
+		},
+		EditedBy:  revision.EditedBy,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func toAttachmentResponse(attachment caseModel.CaseAttachment) responses.CaseAttachmentResponse {
+	return responses.CaseAttachmentResponse{
+		ID:          attachment.ID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		URL:         responses.CaseAttachmentURL(attachment.CaseID, attachment.ID),
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package caseService
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"neptune/backend/pkg/storage"
+	"path"
+	"strings"
+)
+
+// ExportPackage writes the case as a Neptune package. Nothing is written when the case does not exist;
+// once writing has started an error leaves w with a partial zip.
+func (s *caseServiceImpl) ExportPackage(ctx context.Context, caseID uuid.UUID, w io.Writer) error {
+	problemCase, err := s.findCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	testcases, err := s.testCaseRepo.FindTestCaseByCaseID(ctx, caseID.String())
+	if err != nil {
+		return fmt.Errorf("failed to find testcases of case %s: %w", caseID.String(), err)
+	}
+	attachments, err := s.caseRepo.FindAttachments(ctx, caseID)
+	if err != nil {
+		return err
+	}
+
+	manifest := neptuneManifest{
+		FormatVersion: neptuneFormatVersion,
+		Name:          problemCase.Name,
+		Description:   problemCase.Description,
+		TimeLimitMs:   problemCase.TimeLimitMs,
+		MemoryLimitMb: problemCase.MemoryLimitMb,
+		Difficulty:    string(problemCase.Difficulty),
+		Author:        problemCase.Author,
+		Source:        problemCase.Source,
+	}
+	for _, tag := range problemCase.Tags {
+		manifest.Tags = append(manifest.Tags, tag.Tag)
+	}
+	// Tests are renumbered from 1 in case the stored numbers have gaps
+	for i, tc := range testcases {
+		if tc.IsSample {
+			manifest.Samples = append(manifest.Samples, i+1)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode problem.json: %w", err)
+	}
+	if err := writeZipText(zw, packageDescriptors[PackageNeptune], string(manifestJSON)+"\n"); err != nil {
+		return err
+	}
+	for file, text := range map[string]string{
+		neptuneStatementFile:    problemCase.Statement,
+		neptuneInputFormatFile:  problemCase.InputFormat,
+		neptuneOutputFormatFile: problemCase.OutputFormat,
+		neptuneConstraintsFile:  problemCase.Constraints,
+	} {
+		if text == "" {
+			continue
+		}
+		if err := writeZipText(zw, file, text+"\n"); err != nil {
+			return err
+		}
+	}
+	if problemCase.PDFFileUrl != "" {
+		if err := s.copyBlob(ctx, zw, neptunePDFFile, problemCase.PDFFileUrl); err != nil {
+			return err
+		}
+	}
+	for i, tc := range testcases {
+		if err := s.copyBlob(ctx, zw, neptuneTestPath(i+1, ".in"), tc.InputUrl); err != nil {
+			return err
+		}
+		if err := s.copyBlob(ctx, zw, neptuneTestPath(i+1, ".out"), tc.OutputUrl); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(attachments))
+	for _, attachment := range attachments {
+		name := attachment.FileName
+		if names[name] {
+			// Two attachments may share a file name; keep both
+			ext := path.Ext(name)
+			name = strings.TrimSuffix(name, ext) + "-" + attachment.ID.String()[:8] + ext
+		}
+		names[name] = true
+		if err := s.copyBlob(ctx, zw, "attachments/"+name, attachment.FileUrl); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish package: %w", err)
+	}
+	return nil
+}
+
+func (s *caseServiceImpl) copyBlob(ctx context.Context, zw *zip.Writer, name, storedPath string) error {
+	rc, err := s.blobStore.Get(ctx, storage.KeyFromURL(storedPath))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", storedPath, err)
+	}
+	defer rc.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeZipText(zw *zip.Writer, name, text string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
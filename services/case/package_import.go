@@ -0,0 +1,433 @@
+package caseService
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"mime"
+	caseModel "neptune/backend/models/contest"
+	testCaseModel "neptune/backend/models/test_case"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/storage"
+	"neptune/backend/pkg/utils"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PackageFormat names a problem package format the importer understands.
+type PackageFormat string
+
+const (
+	PackageNeptune PackageFormat = "neptune" // docs/neptune-package-format.md
+	PackagePolygon PackageFormat = "polygon" // Polygon package, problem.xml
+	PackageKattis  PackageFormat = "kattis"  // Kattis problem format, problem.yaml
+)
+
+// Descriptor files that mark the root of a package, by format.
+var packageDescriptors = map[PackageFormat]string{
+	PackageNeptune: "problem.json",
+	PackagePolygon: "problem.xml",
+	PackageKattis:  "problem.yaml",
+}
+
+const (
+	maxPackageTextBytes = 1 << 20 // statements and descriptors
+	defaultTimeLimitMs  = 1000
+)
+
+// problemPackage is what a parser found in a package, whatever its format.
+type problemPackage struct {
+	Format        PackageFormat
+	Name          string
+	Description   string
+	TimeLimitMs   int
+	MemoryLimitMb int
+	Statement     requests.CaseStatement
+	Metadata      requests.CaseMetadata
+	PDF           *zip.File
+	Tests         []packageTest
+	Attachments   []*packageFile
+	Warnings      []string
+
+	// Checker describes how the package judges output when that is not an exact comparison, e.g. "the
+	// testlib checker files/check.cpp". Such packages are refused until checkers can be run.
+	Checker string
+}
+
+type packageTest struct {
+	Input  *zip.File
+	Output *zip.File
+	Sample bool
+}
+
+// packageFile is an entry of the package stored under its own name.
+type packageFile struct {
+	Name  string
+	Entry *zip.File
+}
+
+func (p *problemPackage) warn(format string, args ...any) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
+
+// packageArchive holds the entries of a package by their path below the package root, so packages
+// zipped with or without their top folder read the same.
+type packageArchive struct {
+	files map[string]*zip.File
+}
+
+func (a *packageArchive) file(filePath string) *zip.File {
+	return a.files[filePath]
+}
+
+// readText reads a text file of the package. A missing file reads as empty.
+func (a *packageArchive) readText(filePath string) (string, error) {
+	f := a.files[filePath]
+	if f == nil {
+		return "", nil
+	}
+	if f.UncompressedSize64 > maxPackageTextBytes {
+		return "", fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidPackage, filePath, maxPackageTextBytes)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("%w: cannot read %s: %v", ErrInvalidPackage, filePath, err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("%w: cannot read %s: %v", ErrInvalidPackage, filePath, err)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(content), "\r\n", "\n")), nil
+}
+
+// list returns the files below dir in natural order. With recursive unset only direct children count.
+func (a *packageArchive) list(dir string, recursive bool) []string {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	var found []string
+	for filePath := range a.files {
+		if !strings.HasPrefix(filePath, prefix) {
+			continue
+		}
+		if !recursive && strings.Contains(strings.TrimPrefix(filePath, prefix), "/") {
+			continue
+		}
+		found = append(found, filePath)
+	}
+	sort.Slice(found, func(i, j int) bool { return utils.NaturalLess(found[i], found[j]) })
+	return found
+}
+
+// openPackage checks every entry against the archive limits and finds the package root, the shallowest
+// folder holding a descriptor. It returns the archive and the format of that descriptor.
+func (s *caseServiceImpl) openPackage(zipReader *zip.Reader) (*packageArchive, PackageFormat, error) {
+	if len(zipReader.File) > s.packageLimits.MaxFiles {
+		return nil, "", fmt.Errorf("%w: package has %d entries, the limit is %d", ErrInvalidPackage, len(zipReader.File), s.packageLimits.MaxFiles)
+	}
+
+	entries := make(map[string]*zip.File, len(zipReader.File))
+	var totalBytes uint64
+	var format PackageFormat
+	root, rootDepth := "", -1
+	for _, f := range zipReader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		filePath, err := utils.NormalizeZipPath(f.Name)
+		if err == nil {
+			err = s.packageLimits.Check(f)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %s: %v", ErrInvalidPackage, f.Name, err)
+		}
+		// Kattis keeps the time limit in .timelimit, which would pass for OS metadata
+		if utils.IsArchiveMetadata(filePath) && path.Base(filePath) != ".timelimit" {
+			continue
+		}
+		totalBytes += f.UncompressedSize64
+		entries[filePath] = f
+
+		for candidate, descriptor := range packageDescriptors {
+			if path.Base(filePath) != descriptor {
+				continue
+			}
+			depth := strings.Count(filePath, "/")
+			if rootDepth == -1 || depth < rootDepth {
+				format, rootDepth = candidate, depth
+				root = strings.TrimSuffix(path.Dir(filePath), ".")
+			}
+		}
+	}
+	if totalBytes > s.packageLimits.MaxTotalBytes {
+		return nil, "", fmt.Errorf("%w: package expands to %d bytes, the limit is %d", ErrInvalidPackage, totalBytes, s.packageLimits.MaxTotalBytes)
+	}
+	if rootDepth == -1 {
+		return nil, "", fmt.Errorf("%w: no problem.json, problem.xml or problem.yaml found", ErrInvalidPackage)
+	}
+
+	archive := &packageArchive{files: make(map[string]*zip.File, len(entries))}
+	for filePath, f := range entries {
+		if root == "" {
+			archive.files[filePath] = f
+		} else if strings.HasPrefix(filePath, root+"/") {
+			archive.files[strings.TrimPrefix(filePath, root+"/")] = f
+		}
+	}
+	return archive, format, nil
+}
+
+// ImportPackage creates a new case from a problem package. Files are stored before the case is saved, and
+// removed again when saving fails, so a rejected package leaves nothing behind.
+func (s *caseServiceImpl) ImportPackage(ctx context.Context, req requests.ImportCasePackageRequest, editorID uuid.UUID) (*responses.CasePackageImportResponse, error) {
+	src, err := req.File.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded package: %w", err)
+	}
+	defer src.Close()
+	zipReader, err := zip.NewReader(src, req.File.Size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a readable zip file: %v", ErrInvalidPackage, err)
+	}
+
+	archive, detected, err := s.openPackage(zipReader)
+	if err != nil {
+		return nil, err
+	}
+	if req.Format != "" && PackageFormat(req.Format) != detected {
+		return nil, fmt.Errorf("%w: expected a %s package but found %s", ErrInvalidPackage, req.Format, packageDescriptors[detected])
+	}
+
+	var pkg *problemPackage
+	switch detected {
+	case PackageNeptune:
+		pkg, err = parseNeptunePackage(archive)
+	case PackagePolygon:
+		pkg, err = parsePolygonPackage(archive)
+	case PackageKattis:
+		pkg, err = parseKattisPackage(archive)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := pkg.finish(); err != nil {
+		return nil, err
+	}
+
+	problemCase := &caseModel.Case{
+		ID:                uuid.New(),
+		Name:              pkg.Name,
+		Description:       pkg.Description,
+		TimeLimitMs:       pkg.TimeLimitMs,
+		MemoryLimitMb:     pkg.MemoryLimitMb,
+		StatementRevision: 1,
+	}
+	setStatement(problemCase, pkg.Statement)
+	tags, err := setMetadata(problemCase, pkg.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &storedPackage{}
+	rows, err := s.storePackage(ctx, problemCase, pkg, stored)
+	if err != nil {
+		s.discardPackage(stored)
+		return nil, err
+	}
+
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.caseRepo.SaveCase(txCtx, problemCase); err != nil {
+			return fmt.Errorf("failed to create case: %w", err)
+		}
+		if err := s.caseRepo.ReplaceTags(txCtx, problemCase.ID, tags); err != nil {
+			return err
+		}
+		if err := s.caseRepo.SaveStatementRevision(txCtx, newRevision(problemCase, editorID)); err != nil {
+			return err
+		}
+		if err := s.testCaseRepo.SaveTestCaseBatch(txCtx, rows.testcases); err != nil {
+			return fmt.Errorf("failed to save testcases: %w", err)
+		}
+		for i := range rows.attachments {
+			if err := s.caseRepo.SaveAttachment(txCtx, &rows.attachments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.discardPackage(stored)
+		return nil, err
+	}
+
+	resp := &responses.CasePackageImportResponse{
+		Case:          toCaseResponse(*problemCase, nil),
+		Format:        string(pkg.Format),
+		TestCaseCount: len(rows.testcases),
+		Attachments:   len(rows.attachments),
+		Warnings:      pkg.Warnings,
+	}
+	for _, test := range pkg.Tests {
+		if test.Sample {
+			resp.SampleCount++
+		}
+	}
+	if resp.Warnings == nil {
+		resp.Warnings = []string{}
+	}
+	return resp, nil
+}
+
+// finish checks what every case needs and fills in defaults, noting each guess as a warning.
+func (p *problemPackage) finish() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("%w: the package does not name the problem", ErrInvalidPackage)
+	}
+	if len(p.Tests) == 0 {
+		return fmt.Errorf("%w: the package has no testcases", ErrInvalidPackage)
+	}
+	if p.TimeLimitMs <= 0 {
+		p.TimeLimitMs = defaultTimeLimitMs
+		p.warn("no time limit found, using %d ms", defaultTimeLimitMs)
+	}
+	if p.MemoryLimitMb <= 0 {
+		p.MemoryLimitMb = 256
+		p.warn("no memory limit found, using 256 MB")
+	}
+	if p.Checker != "" {
+		// Judging compares output exactly, so a special judge problem would be misjudged
+		return fmt.Errorf("%w: the package judges output with %s, but submissions are judged by comparing "+
+			"output exactly and special checkers are not supported yet", ErrInvalidPackage, p.Checker)
+	}
+
+	// Metadata from other systems is kept where it fits and dropped otherwise, rather than failing the import
+	if !caseModel.CaseDifficulty(strings.ToLower(p.Metadata.Difficulty)).Valid() {
+		p.warn("unknown difficulty %q dropped", p.Metadata.Difficulty)
+		p.Metadata.Difficulty = ""
+	}
+	var tags []string
+	for _, tag := range p.Metadata.Tags {
+		tag = strings.TrimSpace(tag)
+		if len(tag) > maxTagLength {
+			p.warn("tag %q is too long and was dropped", tag)
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		p.warn("only the first %d of %d tags were kept", maxTags, len(tags))
+		tags = tags[:maxTags]
+	}
+	p.Metadata.Tags = tags
+	if len(p.Metadata.Author) > maxAuthorLength {
+		p.Metadata.Author = p.Metadata.Author[:maxAuthorLength]
+		p.warn("author was shortened to %d characters", maxAuthorLength)
+	}
+	if len(p.Metadata.Source) > maxSourceLength {
+		p.Metadata.Source = p.Metadata.Source[:maxSourceLength]
+		p.warn("source was shortened to %d characters", maxSourceLength)
+	}
+	return nil
+}
+
+// storedPackage tracks the blobs written for an import so a failed import can remove them.
+type storedPackage struct {
+	keys     []string
+	prefixes []string
+}
+
+type packageRows struct {
+	testcases   []testCaseModel.TestCase
+	attachments []caseModel.CaseAttachment
+}
+
+// storePackage writes the PDF, testcases and attachments of the package to the blob store and
+// points problemCase at them. It returns the rows to save with the case.
+func (s *caseServiceImpl) storePackage(ctx context.Context, problemCase *caseModel.Case, pkg *problemPackage, stored *storedPackage) (*packageRows, error) {
+	rows := &packageRows{}
+
+	if pkg.PDF != nil {
+		problemCase.PDFFileUrl = "/private/case_file/" + uuid.New().String() + ".pdf"
+		if err := s.storeEntry(ctx, pkg.PDF, problemCase.PDFFileUrl, "application/pdf", stored); err != nil {
+			return nil, err
+		}
+	}
+
+	stagePrefix := fmt.Sprintf("private/test_case/%s/%s", problemCase.ID.String(), uuid.New().String())
+	stored.prefixes = append(stored.prefixes, stagePrefix)
+	for i, test := range pkg.Tests {
+		number := i + 1
+		inputURL := fmt.Sprintf("/%s/%d/t%03d.in", stagePrefix, number, number)
+		outputURL := fmt.Sprintf("/%s/%d/t%03d.out", stagePrefix, number, number)
+		if err := s.storeEntry(ctx, test.Input, inputURL, "text/plain", stored); err != nil {
+			return nil, err
+		}
+		if err := s.storeEntry(ctx, test.Output, outputURL, "text/plain", stored); err != nil {
+			return nil, err
+		}
+		rows.testcases = append(rows.testcases, testCaseModel.TestCase{
+			CaseID:    problemCase.ID,
+			Number:    number,
+			InputUrl:  inputURL,
+			OutputUrl: outputURL,
+			IsSample:  test.Sample,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	for _, file := range pkg.Attachments {
+		fileName := file.Name
+		ext := strings.ToLower(path.Ext(fileName))
+		contentType := mime.TypeByExtension(ext)
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		attachment := caseModel.CaseAttachment{
+			ID:          uuid.New(),
+			CaseID:      problemCase.ID,
+			FileName:    fileName,
+			ContentType: contentType,
+			SizeBytes:   int64(file.Entry.UncompressedSize64),
+		}
+		attachment.FileUrl = fmt.Sprintf("/private/case_attachment/%s/%s%s", problemCase.ID.String(), attachment.ID.String(), ext)
+		if err := s.storeEntry(ctx, file.Entry, attachment.FileUrl, contentType, stored); err != nil {
+			return nil, err
+		}
+		rows.attachments = append(rows.attachments, attachment)
+	}
+	return rows, nil
+}
+
+func (s *caseServiceImpl) storeEntry(ctx context.Context, f *zip.File, storedPath, contentType string, stored *storedPackage) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: cannot read %s: %v", ErrInvalidPackage, f.Name, err)
+	}
+	defer rc.Close()
+
+	key := storage.KeyFromURL(storedPath)
+	stored.keys = append(stored.keys, key)
+	if err := s.blobStore.Put(ctx, key, rc, int64(f.UncompressedSize64), contentType); err != nil {
+		return fmt.Errorf("failed to store %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+func (s *caseServiceImpl) discardPackage(stored *storedPackage) {
+	for _, key := range stored.keys {
+		if err := s.blobStore.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to clean up imported file %s: %v", key, err)
+		}
+	}
+	for _, prefix := range stored.prefixes {
+		if err := s.blobStore.DeletePrefix(context.Background(), prefix); err != nil {
+			log.Printf("Failed to clean up imported testcases %s: %v", prefix, err)
+		}
+	}
+}
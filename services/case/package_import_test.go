@@ -0,0 +1,190 @@
+package caseService
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	testCaseServ "neptune/backend/services/test_case"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// zipOf builds a zip reader holding the given files.
+func zipOf(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+func testPackageService() *caseServiceImpl {
+	return &caseServiceImpl{packageLimits: testCaseServ.ArchiveLimits{
+		MaxFiles:         100,
+		MaxFileBytes:     1 << 20,
+		MaxTotalBytes:    1 << 20,
+		MaxCompressRatio: 200,
+	}}
+}
+
+func TestOpenPackageFindsRoot(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		format PackageFormat
+		paths  []string
+	}{
+		{
+			name:   "flat",
+			files:  map[string]string{"problem.json": "{}", "tests/1.in": "1", "tests/1.out": "1"},
+			format: PackageNeptune,
+			paths:  []string{"problem.json", "tests/1.in", "tests/1.out"},
+		},
+		{
+			name:   "top folder",
+			files:  map[string]string{"aplusb/problem.xml": "<problem/>", "aplusb/tests/01": "1"},
+			format: PackagePolygon,
+			paths:  []string{"problem.xml", "tests/01"},
+		},
+		{
+			name: "shallowest descriptor wins",
+			files: map[string]string{
+				"sum/problem.yaml":                    "name: Sum",
+				"sum/data/secret/1.in":                "1",
+				"sum/attachments/other/problem.json":  "{}",
+				"sum/output_validators/v/problem.xml": "<problem/>",
+				"unrelated/readme.txt":                "outside the root",
+			},
+			format: PackageKattis,
+			paths: []string{
+				"attachments/other/problem.json", "data/secret/1.in",
+				"output_validators/v/problem.xml", "problem.yaml",
+			},
+		},
+		{
+			name: "metadata skipped but .timelimit kept",
+			files: map[string]string{
+				"p/problem.yaml":            "name: P",
+				"p/.timelimit":              "2",
+				"__MACOSX/p/._problem.yaml": "",
+				"p/.DS_Store":               "",
+			},
+			format: PackageKattis,
+			paths:  []string{".timelimit", "problem.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, format, err := testPackageService().openPackage(zipOf(t, tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.format {
+				t.Errorf("format = %q, want %q", format, tt.format)
+			}
+			var paths []string
+			for filePath := range archive.files {
+				paths = append(paths, filePath)
+			}
+			sort.Strings(paths)
+			if strings.Join(paths, ",") != strings.Join(tt.paths, ",") {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestOpenPackageRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"no descriptor", map[string]string{"tests/1.in": "1"}},
+		{"path escapes the root", map[string]string{"problem.json": "{}", "../evil": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := testPackageService().openPackage(zipOf(t, tt.files))
+			if !errors.Is(err, ErrInvalidPackage) {
+				t.Errorf("err = %v, want ErrInvalidPackage", err)
+			}
+		})
+	}
+}
+
+// TestImportRefusesSpecialCheckers checks that packages judged by anything but an exact comparison are
+// refused, since judging would ignore their checker.
+func TestImportRefusesSpecialCheckers(t *testing.T) {
+	polygon := func(checker string) map[string]string {
+		return map[string]string{
+			"problem.xml": `<problem><names><name language="english" value="Sum"/></names>
+<judging><testset name="tests"><time-limit>1000</time-limit><memory-limit>268435456</memory-limit>
+<test-count>1</test-count><input-path-pattern>tests/%02d</input-path-pattern>
+<answer-path-pattern>tests/%02d.a</answer-path-pattern></testset></judging>
+<assets>` + checker + `</assets></problem>`,
+			"tests/01": "1 2", "tests/01.a": "3", "files/check.cpp": "",
+		}
+	}
+	kattis := func(yaml string) map[string]string {
+		return map[string]string{"problem.yaml": "name: Sum\n" + yaml, "data/secret/1.in": "1 2", "data/secret/1.ans": "3"}
+	}
+	tests := []struct {
+		name    string
+		files   map[string]string
+		refused bool
+	}{
+		{"polygon standard checker", polygon(`<checker name="std::wcmp.cpp" type="testlib"><source path="files/check.cpp"/></checker>`), false},
+		{"polygon float checker", polygon(`<checker name="std::rcmp6.cpp" type="testlib"><source path="files/check.cpp"/></checker>`), true},
+		{"polygon custom checker", polygon(`<checker type="testlib"><source path="files/check.cpp"/></checker>`), true},
+		{"kattis default", kattis(""), false},
+		{"kattis custom validator", kattis("validation: custom\n"), true},
+		{"kattis float tolerance", kattis("validator_flags: float_tolerance 1e-6\n"), true},
+		{"neptune checker", map[string]string{
+			"problem.json": `{"format_version": 1, "name": "Sum", "checker": {"type": "testlib", "file": "checker/check.cpp"}}`,
+			"tests/1.in":   "1 2", "tests/1.out": "3", "checker/check.cpp": "",
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, format, err := testPackageService().openPackage(zipOf(t, tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pkg *problemPackage
+			switch format {
+			case PackageNeptune:
+				pkg, err = parseNeptunePackage(archive)
+			case PackagePolygon:
+				pkg, err = parsePolygonPackage(archive)
+			case PackageKattis:
+				pkg, err = parseKattisPackage(archive)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pkg.finish()
+			if tt.refused && !errors.Is(err, ErrInvalidPackage) {
+				t.Errorf("finish() = %v, want ErrInvalidPackage", err)
+			}
+			if !tt.refused && err != nil {
+				t.Errorf("finish() = %v, want nil", err)
+			}
+		})
+	}
+}
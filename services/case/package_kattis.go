@@ -0,0 +1,200 @@
+package caseService
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// kattisDefaultMemoryMb is the memory limit of the Kattis problem format when problem.yaml sets none.
+const kattisDefaultMemoryMb = 2048
+
+// kattisProblem is the part of problem.yaml the importer reads. It covers both the legacy format and the
+// 2023-07 draft, where name may be a map of languages and the time limit moved into limits.
+type kattisProblem struct {
+	Name           any    `yaml:"name"`
+	Author         string `yaml:"author"`
+	Source         string `yaml:"source"`
+	Validation     string `yaml:"validation"`
+	ValidatorFlags string `yaml:"validator_flags"`
+	Keywords       any    `yaml:"keywords"`
+	Limits         struct {
+		Memory    int     `yaml:"memory"`     // MiB
+		TimeLimit float64 `yaml:"time_limit"` // Seconds
+	} `yaml:"limits"`
+}
+
+var (
+	kattisProblemNamePattern = regexp.MustCompile(`\\problemname\{([^}]*)\}`)
+	kattisTexSectionPattern  = regexp.MustCompile(`(?m)^\s*\\section\*?\{(Input|Output)\}\s*$`)
+	kattisMdSectionPattern   = regexp.MustCompile(`(?mi)^#{1,3}\s*(Input|Output)\s*$`)
+)
+
+// Statement files in the order they are looked for; the draft format renamed problem_statement/.
+var kattisStatementFiles = []string{
+	"problem_statement/problem.en.md", "problem_statement/problem.md",
+	"statement/problem.en.md", "statement/problem.md",
+	"problem_statement/problem.en.tex", "problem_statement/problem.tex",
+	"statement/problem.en.tex", "statement/problem.tex",
+}
+
+func parseKattisPackage(archive *packageArchive) (*problemPackage, error) {
+	raw, err := archive.readText(packageDescriptors[PackageKattis])
+	if err != nil {
+		return nil, err
+	}
+	var problem kattisProblem
+	if err := yaml.Unmarshal([]byte(raw), &problem); err != nil {
+		return nil, fmt.Errorf("%w: problem.yaml: %v", ErrInvalidPackage, err)
+	}
+	pkg := &problemPackage{Format: PackageKattis, Name: kattisName(problem.Name)}
+	pkg.Metadata.Author = problem.Author
+	pkg.Metadata.Source = problem.Source
+	pkg.Metadata.Tags = kattisKeywords(problem.Keywords)
+
+	if err := readKattisStatement(archive, pkg); err != nil {
+		return nil, err
+	}
+
+	pkg.MemoryLimitMb = problem.Limits.Memory
+	if pkg.MemoryLimitMb <= 0 {
+		pkg.MemoryLimitMb = kattisDefaultMemoryMb
+	}
+	seconds := problem.Limits.TimeLimit
+	if seconds <= 0 {
+		// DOMjudge and problemtools write the computed limit to .timelimit
+		text, err := archive.readText(".timelimit")
+		if err != nil {
+			return nil, err
+		}
+		if text != "" {
+			if seconds, err = strconv.ParseFloat(text, 64); err != nil {
+				pkg.warn(".timelimit holds %q, which is not a number of seconds", text)
+			}
+		}
+	}
+	pkg.TimeLimitMs = int(seconds * 1000)
+
+	for _, group := range []struct {
+		dir    string
+		sample bool
+	}{{"data/sample", true}, {"data/secret", false}} {
+		for _, filePath := range archive.list(group.dir, true) {
+			if path.Ext(filePath) != ".in" {
+				continue
+			}
+			answerPath := strings.TrimSuffix(filePath, ".in") + ".ans"
+			answer := archive.file(answerPath)
+			if answer == nil {
+				pkg.warn("%s has no %s and was skipped", filePath, path.Base(answerPath))
+				continue
+			}
+			pkg.Tests = append(pkg.Tests, packageTest{Input: archive.file(filePath), Output: answer, Sample: group.sample})
+		}
+	}
+
+	validation := strings.Fields(problem.Validation)
+	for _, flag := range validation {
+		if flag == "interactive" {
+			pkg.warn("interactive problems are not supported; the problem was imported as a regular one")
+		}
+	}
+	switch {
+	case len(validation) > 0 && validation[0] == "custom":
+		pkg.Checker = "a custom output validator"
+	case strings.Contains(problem.ValidatorFlags, "float_"):
+		pkg.Checker = fmt.Sprintf("the default output validator with %q", problem.ValidatorFlags)
+	}
+	return pkg, nil
+}
+
+// kattisName picks the English name from a name that is a string or a map of languages.
+func kattisName(name any) string {
+	switch value := name.(type) {
+	case string:
+		return value
+	case map[string]any:
+		if english, ok := value["en"].(string); ok {
+			return english
+		}
+		for _, other := range value {
+			if s, ok := other.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// kattisKeywords reads keywords given as a space separated string or as a list.
+func kattisKeywords(keywords any) []string {
+	switch value := keywords.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		var tags []string
+		for _, keyword := range value {
+			if s, ok := keyword.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		return tags
+	}
+	return nil
+}
+
+// readKattisStatement splits the statement at its Input and Output headings. A name given only through
+// \problemname in the LaTeX statement is used when problem.yaml has none.
+func readKattisStatement(archive *packageArchive, pkg *problemPackage) error {
+	file, text := "", ""
+	for _, candidate := range kattisStatementFiles {
+		if archive.file(candidate) == nil {
+			continue
+		}
+		content, err := archive.readText(candidate)
+		if err != nil {
+			return err
+		}
+		file, text = candidate, content
+		break
+	}
+	if file == "" {
+		pkg.warn("no statement found in problem_statement/")
+		return nil
+	}
+
+	headings := kattisMdSectionPattern
+	if path.Ext(file) == ".tex" {
+		headings = kattisTexSectionPattern
+		if m := kattisProblemNamePattern.FindStringSubmatch(text); m != nil {
+			if pkg.Name == "" {
+				pkg.Name = strings.TrimSpace(m[1])
+			}
+			text = strings.Replace(text, m[0], "", 1)
+		}
+		pkg.warn("the statement was written in LaTeX; check its formatting")
+	}
+
+	sections := headings.FindAllStringSubmatchIndex(text, -1)
+	end := len(text)
+	if len(sections) > 0 {
+		end = sections[0][0]
+	}
+	pkg.Statement.Statement = strings.TrimSpace(text[:end])
+	for i, section := range sections {
+		sectionEnd := len(text)
+		if i+1 < len(sections) {
+			sectionEnd = sections[i+1][0]
+		}
+		body := strings.TrimSpace(text[section[1]:sectionEnd])
+		if strings.EqualFold(text[section[2]:section[3]], "input") {
+			pkg.Statement.InputFormat = body
+		} else {
+			pkg.Statement.OutputFormat = body
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package caseService
+
+import (
+	"encoding/json"
+	"fmt"
+	"neptune/backend/pkg/requests"
+	"path"
+	"strconv"
+)
+
+// neptuneFormatVersion is the version of docs/neptune-package-format.md this build reads and writes.
+const neptuneFormatVersion = 1
+
+// Files of a Neptune package besides problem.json, tests/<n>.in, tests/<n>.out and attachments/.
+const (
+	neptuneStatementFile    = "statement/statement.md"
+	neptuneInputFormatFile  = "statement/input.md"
+	neptuneOutputFormatFile = "statement/output.md"
+	neptuneConstraintsFile  = "statement/constraints.md"
+	neptunePDFFile          = "statement/statement.pdf"
+)
+
+// neptuneManifest is problem.json of a Neptune package.
+type neptuneManifest struct {
+	FormatVersion int             `json:"format_version"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	TimeLimitMs   int             `json:"time_limit_ms"`
+	MemoryLimitMb int             `json:"memory_limit_mb"`
+	Tags          []string        `json:"tags,omitempty"`
+	Difficulty    string          `json:"difficulty,omitempty"`
+	Author        string          `json:"author,omitempty"`
+	Source        string          `json:"source,omitempty"`
+	Samples       []int           `json:"samples,omitempty"` // Numbers of the tests shown in the statement
+	Checker       *neptuneChecker `json:"checker,omitempty"`
+}
+
+type neptuneChecker struct {
+	Type string `json:"type"` // "testlib" or "kattis"; only read to refuse the package
+	File string `json:"file"` // Path inside the package, below checker/
+}
+
+func parseNeptunePackage(archive *packageArchive) (*problemPackage, error) {
+	raw, err := archive.readText(packageDescriptors[PackageNeptune])
+	if err != nil {
+		return nil, err
+	}
+	var manifest neptuneManifest
+	if err := json.Unmarshal([]byte(raw), &manifest); err != nil {
+		return nil, fmt.Errorf("%w: problem.json: %v", ErrInvalidPackage, err)
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > neptuneFormatVersion {
+		return nil, fmt.Errorf("%w: problem.json has format_version %d, this server reads up to %d", ErrInvalidPackage, manifest.FormatVersion, neptuneFormatVersion)
+	}
+
+	pkg := &problemPackage{
+		Format:        PackageNeptune,
+		Name:          manifest.Name,
+		Description:   manifest.Description,
+		TimeLimitMs:   manifest.TimeLimitMs,
+		MemoryLimitMb: manifest.MemoryLimitMb,
+		Metadata: requests.CaseMetadata{
+			Tags:       manifest.Tags,
+			Difficulty: manifest.Difficulty,
+			Author:     manifest.Author,
+			Source:     manifest.Source,
+		},
+		PDF: archive.file(neptunePDFFile),
+	}
+	for target, file := range map[*string]string{
+		&pkg.Statement.Statement:    neptuneStatementFile,
+		&pkg.Statement.InputFormat:  neptuneInputFormatFile,
+		&pkg.Statement.OutputFormat: neptuneOutputFormatFile,
+		&pkg.Statement.Constraints:  neptuneConstraintsFile,
+	} {
+		if *target, err = archive.readText(file); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tests are numbered from 1 without gaps
+	for number := 1; ; number++ {
+		input := archive.file(neptuneTestPath(number, ".in"))
+		output := archive.file(neptuneTestPath(number, ".out"))
+		if input == nil && output == nil {
+			break
+		}
+		if input == nil || output == nil {
+			return nil, fmt.Errorf("%w: test %d needs both tests/%d.in and tests/%d.out", ErrInvalidPackage, number, number, number)
+		}
+		pkg.Tests = append(pkg.Tests, packageTest{Input: input, Output: output})
+	}
+	if extra := len(archive.list("tests", true)) - 2*len(pkg.Tests); extra > 0 {
+		pkg.warn("%d files in tests/ do not follow the <n>.in / <n>.out numbering and were skipped", extra)
+	}
+	for _, number := range manifest.Samples {
+		if number < 1 || number > len(pkg.Tests) {
+			return nil, fmt.Errorf("%w: sample %d is not a test of the package", ErrInvalidPackage, number)
+		}
+		pkg.Tests[number-1].Sample = true
+	}
+
+	if manifest.Checker != nil {
+		pkg.Checker = fmt.Sprintf("the %s checker %s", manifest.Checker.Type, manifest.Checker.File)
+	}
+
+	for _, filePath := range archive.list("attachments", false) {
+		pkg.Attachments = append(pkg.Attachments, &packageFile{Name: path.Base(filePath), Entry: archive.file(filePath)})
+	}
+	return pkg, nil
+}
+
+// neptuneTestPath is where test number n is stored in a Neptune package.
+func neptuneTestPath(number int, ext string) string {
+	return "tests/" + strconv.Itoa(number) + ext
+}
@@ -0,0 +1,183 @@
+package caseService
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// polygonProblem is the part of problem.xml in a Polygon package the importer reads.
+type polygonProblem struct {
+	ShortName string `xml:"short-name,attr"`
+	Names     []struct {
+		Language string `xml:"language,attr"`
+		Value    string `xml:"value,attr"`
+	} `xml:"names>name"`
+	Statements []struct {
+		Language string `xml:"language,attr"`
+		Path     string `xml:"path,attr"`
+		Type     string `xml:"type,attr"`
+	} `xml:"statements>statement"`
+	Testsets []polygonTestset `xml:"judging>testset"`
+	Checker  *struct {
+		Name   string `xml:"name,attr"` // e.g. "std::wcmp.cpp" for a standard checker
+		Type   string `xml:"type,attr"`
+		Source struct {
+			Path string `xml:"path,attr"`
+		} `xml:"source"`
+	} `xml:"assets>checker"`
+	Tags []struct {
+		Value string `xml:"value,attr"`
+	} `xml:"tags>tag"`
+}
+
+type polygonTestset struct {
+	Name          string `xml:"name,attr"`
+	TimeLimit     int    `xml:"time-limit"`   // Milliseconds
+	MemoryLimit   int64  `xml:"memory-limit"` // Bytes
+	TestCount     int    `xml:"test-count"`
+	InputPattern  string `xml:"input-path-pattern"`  // e.g. "tests/%02d"
+	AnswerPattern string `xml:"answer-path-pattern"` // e.g. "tests/%02d.a"
+	Tests         []struct {
+		Sample bool `xml:"sample,attr"`
+	} `xml:"tests>test"`
+}
+
+// polygonProperties is statements/<language>/problem-properties.json, which Polygon writes with the
+// statement sections of each language.
+type polygonProperties struct {
+	Name   string `json:"name"`
+	Legend string `json:"legend"`
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	Notes  string `json:"notes"`
+}
+
+// polygonExactCheckers are the standard checkers that accept what an exact comparison of the jury's output
+// accepts, give or take whitespace, so packages using them import without their checker.
+var polygonExactCheckers = map[string]bool{
+	"std::fcmp.cpp": true, // lines, exactly
+	"std::hcmp.cpp": true, // a huge integer
+	"std::lcmp.cpp": true, // lines of tokens
+	"std::ncmp.cpp": true, // integers
+	"std::wcmp.cpp": true, // tokens
+}
+
+func parsePolygonPackage(archive *packageArchive) (*problemPackage, error) {
+	raw, err := archive.readText(packageDescriptors[PackagePolygon])
+	if err != nil {
+		return nil, err
+	}
+	var problem polygonProblem
+	if err := xml.Unmarshal([]byte(raw), &problem); err != nil {
+		return nil, fmt.Errorf("%w: problem.xml: %v", ErrInvalidPackage, err)
+	}
+	pkg := &problemPackage{Format: PackagePolygon}
+
+	// Prefer the English statement, like most campuses, and fall back to the first language
+	language := ""
+	for _, name := range problem.Names {
+		if language == "" || name.Language == "english" {
+			language, pkg.Name = name.Language, name.Value
+		}
+	}
+	if pkg.Name == "" {
+		pkg.Name = problem.ShortName
+	}
+	if err := readPolygonStatement(archive, language, pkg); err != nil {
+		return nil, err
+	}
+	for _, statement := range problem.Statements {
+		if statement.Type == "application/pdf" && (statement.Language == language || pkg.PDF == nil) {
+			if f := archive.file(statement.Path); f != nil {
+				pkg.PDF = f
+			}
+		}
+	}
+	for _, tag := range problem.Tags {
+		pkg.Metadata.Tags = append(pkg.Metadata.Tags, tag.Value)
+	}
+
+	if len(problem.Testsets) == 0 {
+		return nil, fmt.Errorf("%w: problem.xml has no testset", ErrInvalidPackage)
+	}
+	testset := problem.Testsets[0]
+	for _, candidate := range problem.Testsets {
+		if candidate.Name == "tests" {
+			testset = candidate
+		}
+	}
+	pkg.TimeLimitMs = testset.TimeLimit
+	pkg.MemoryLimitMb = int(testset.MemoryLimit >> 20)
+	if testset.TestCount > len(archive.files) {
+		return nil, fmt.Errorf("%w: testset declares %d tests but the package has fewer files", ErrInvalidPackage, testset.TestCount)
+	}
+	for number := 1; number <= testset.TestCount; number++ {
+		inputPath := fmt.Sprintf(testset.InputPattern, number)
+		answerPath := fmt.Sprintf(testset.AnswerPattern, number)
+		input, answer := archive.file(inputPath), archive.file(answerPath)
+		if input == nil || answer == nil {
+			return nil, fmt.Errorf("%w: test %d is missing (%s, %s); Polygon only includes generated tests "+
+				"in full packages", ErrInvalidPackage, number, inputPath, answerPath)
+		}
+		test := packageTest{Input: input, Output: answer}
+		if number <= len(testset.Tests) {
+			test.Sample = testset.Tests[number-1].Sample
+		}
+		pkg.Tests = append(pkg.Tests, test)
+	}
+
+	if checker := problem.Checker; checker != nil && checker.Source.Path != "" {
+		if polygonExactCheckers[checker.Name] {
+			pkg.warn("the standard checker %s was replaced by comparing output exactly", checker.Name)
+		} else {
+			name := checker.Name
+			if name == "" {
+				name = checker.Source.Path
+			}
+			pkg.Checker = "the checker " + name
+		}
+	}
+	return pkg, nil
+}
+
+// readPolygonStatement reads the statement sections of one language, from problem-properties.json when
+// the package has it and from statement-sections/ otherwise. Polygon writes them in LaTeX; inline math
+// carries over, other commands are kept as they are.
+func readPolygonStatement(archive *packageArchive, language string, pkg *problemPackage) error {
+	var properties polygonProperties
+	raw, err := archive.readText(path.Join("statements", language, "problem-properties.json"))
+	if err != nil {
+		return err
+	}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &properties); err != nil {
+			return fmt.Errorf("%w: problem-properties.json: %v", ErrInvalidPackage, err)
+		}
+	} else {
+		sections := path.Join("statement-sections", language)
+		for target, file := range map[*string]string{
+			&properties.Legend: "legend.tex",
+			&properties.Input:  "input.tex",
+			&properties.Output: "output.tex",
+			&properties.Notes:  "notes.tex",
+		} {
+			if *target, err = archive.readText(path.Join(sections, file)); err != nil {
+				return err
+			}
+		}
+	}
+
+	pkg.Statement.Statement = strings.TrimSpace(properties.Legend)
+	if notes := strings.TrimSpace(properties.Notes); notes != "" {
+		pkg.Statement.Statement += "\n\n### Notes\n\n" + notes
+	}
+	pkg.Statement.InputFormat = strings.TrimSpace(properties.Input)
+	pkg.Statement.OutputFormat = strings.TrimSpace(properties.Output)
+	if pkg.Statement.Statement != "" {
+		pkg.warn("the statement was written in LaTeX; check its formatting")
+	}
+	return nil
+}
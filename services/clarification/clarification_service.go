@@ -0,0 +1,44 @@
+package clarificationServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	webSocketService "neptune/backend/services/web_socket_service"
+)
+
+var (
+	ErrContestNotFound       = errors.New("contest not found")
+	ErrClarificationNotFound = errors.New("clarification not found")
+	ErrCaseNotInContest      = errors.New("case is not part of this contest")
+	ErrNotParticipant        = errors.New("you are not taking part in this contest")
+	ErrNotContestStaff       = errors.New("only staff of this contest can answer clarifications")
+	ErrContestNotRunning     = errors.New("questions can only be asked while the contest is running")
+	ErrInvalidVisibility     = errors.New("visibility must be private or broadcast")
+	ErrBlankQuestion         = errors.New("question must not be blank")
+	ErrBlankAnswer           = errors.New("answer must not be blank")
+)
+
+// Event types pushed to contest listeners.
+const (
+	EventAsked    = "clarification.asked"
+	EventAnswered = "clarification.answered"
+)
+
+type ClarificationService interface {
+	// ContestRoleOf tells whether the user takes part in the class contest, or the global contest when
+	// classTransactionID is nil, and whether as staff. Admins are staff everywhere; assistants and
+	// lecturers of the class are staff of its contests, and every assistant of a global contest.
+	webSocketService.ContestAccess
+
+	AskQuestion(ctx context.Context, contestID uuid.UUID, userID uuid.UUID, role user.Role, req requests.AskClarificationRequest) (*responses.ClarificationResponse, error)
+	// ListClarifications shows staff every question in the scope, and students their own and the
+	// broadcast ones.
+	ListClarifications(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) ([]responses.ClarificationResponse, error)
+	// AnswerClarification answers a question, or replaces an earlier answer, and pushes it to the asker,
+	// or to everyone in the contest when broadcast.
+	AnswerClarification(ctx context.Context, clarificationID uuid.UUID, userID uuid.UUID, role user.Role, req requests.AnswerClarificationRequest) (*responses.ClarificationResponse, error)
+}
@@ -0,0 +1,362 @@
+package clarificationServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	contestModel "neptune/backend/models/contest"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	clarificationRepo "neptune/backend/repositories/clarification"
+	internalClassRepo "neptune/backend/repositories/class"
+	contestRepository "neptune/backend/repositories/contest"
+	roleRepo "neptune/backend/repositories/role"
+	contestService "neptune/backend/services/contest"
+	webSocketService "neptune/backend/services/web_socket_service"
+	"strings"
+	"time"
+)
+
+type clarificationService struct {
+	clarificationRepo clarificationRepo.ClarificationRepository
+	contestRepo       contestRepository.ContestRepository
+	classRepo         internalClassRepo.ClassRepository
+	roleRepo          roleRepo.RoleRepository
+	contestService    contestService.ContestService
+	webSocketService  webSocketService.WebSocketService
+}
+
+func NewClarificationService(
+	clarificationRepo clarificationRepo.ClarificationRepository,
+	contestRepo contestRepository.ContestRepository,
+	classRepo internalClassRepo.ClassRepository,
+	roleRepo roleRepo.RoleRepository,
+	contestService contestService.ContestService,
+	webSocketService webSocketService.WebSocketService,
+) ClarificationService {
+	return &clarificationService{
+		clarificationRepo: clarificationRepo,
+		contestRepo:       contestRepo,
+		classRepo:         classRepo,
+		roleRepo:          roleRepo,
+		contestService:    contestService,
+		webSocketService:  webSocketService,
+	}
+}
+
+// contestWindow is a contest as seen from one class, or as the global contest.
+type contestWindow struct {
+	contest      *contestModel.Contest
+	classContest *contestModel.ClassContest // nil for the global contest
+	startTime    time.Time
+	endTime      time.Time
+}
+
+// findWindow loads the class contest, or the global contest when classTransactionID is nil. It returns
+// nil when the contest is not run in that scope.
+func (s *clarificationService) findWindow(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID) (*contestWindow, error) {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest == nil {
+		return nil, nil
+	}
+	if classTransactionID == nil {
+		if contest.GlobalContestDetail == nil {
+			return nil, nil
+		}
+		return &contestWindow{contest: contest, startTime: contest.GlobalContestDetail.StartTime, endTime: contest.GlobalContestDetail.EndTime}, nil
+	}
+	classContest, err := s.contestRepo.FindClassContestByIDs(ctx, *classTransactionID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if classContest == nil {
+		return nil, nil
+	}
+	return &contestWindow{contest: contest, classContest: classContest, startTime: classContest.StartTime, endTime: classContest.EndTime}, nil
+}
+
+func (s *clarificationService) ContestRoleOf(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) (webSocketService.ContestRole, error) {
+	window, err := s.findWindow(ctx, contestID, classTransactionID)
+	if err != nil {
+		return webSocketService.ContestOutsider, err
+	}
+	if window == nil {
+		return webSocketService.ContestOutsider, nil
+	}
+	return s.roleIn(ctx, contestID, classTransactionID, userID, role)
+}
+
+// roleIn is the user's role in the contest as run in the class, or in the global contest. Participants
+// must also be let into the contest itself, so hidden, draft and access code protected contests stay
+// closed to them.
+func (s *clarificationService) roleIn(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) (webSocketService.ContestRole, error) {
+	contestRole, err := s.scopeRole(ctx, classTransactionID, userID, role)
+	if err != nil || contestRole != webSocketService.ContestParticipant {
+		return contestRole, err
+	}
+	if err := s.contestService.AuthorizeContest(ctx, contestID, userID, role); err != nil {
+		if errors.Is(err, contestService.ErrContestNotFound) || errors.Is(err, contestService.ErrAccessCodeRequired) ||
+			errors.Is(err, contestService.ErrNotEnrolled) {
+			return webSocketService.ContestOutsider, nil
+		}
+		return webSocketService.ContestOutsider, err
+	}
+	return contestRole, nil
+}
+
+// scopeRole is the user's role in the class, or in the global contest when classTransactionID is nil.
+func (s *clarificationService) scopeRole(ctx context.Context, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) (webSocketService.ContestRole, error) {
+	if role == user.RoleAdmin {
+		return webSocketService.ContestStaff, nil
+	}
+	if classTransactionID == nil {
+		if role.Rank() >= user.RoleAssistant.Rank() {
+			return webSocketService.ContestStaff, nil
+		}
+		return webSocketService.ContestParticipant, nil
+	}
+
+	isAssistant, err := s.classRepo.IsClassAssistant(ctx, *classTransactionID, userID)
+	if err != nil {
+		return webSocketService.ContestOutsider, err
+	}
+	if isAssistant {
+		return webSocketService.ContestStaff, nil
+	}
+	if role == user.RoleLecturer {
+		isLecturer, err := s.roleRepo.HasClassRole(ctx, userID, user.RoleLecturer, *classTransactionID)
+		if err != nil {
+			return webSocketService.ContestOutsider, err
+		}
+		if isLecturer {
+			return webSocketService.ContestStaff, nil
+		}
+	}
+	isStudent, err := s.classRepo.IsClassStudent(ctx, *classTransactionID, userID)
+	if err != nil {
+		return webSocketService.ContestOutsider, err
+	}
+	if isStudent {
+		return webSocketService.ContestParticipant, nil
+	}
+	return webSocketService.ContestOutsider, nil
+}
+
+func (s *clarificationService) AskQuestion(ctx context.Context, contestID uuid.UUID, userID uuid.UUID, role user.Role, req requests.AskClarificationRequest) (*responses.ClarificationResponse, error) {
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, ErrBlankQuestion
+	}
+	window, err := s.findWindow(ctx, contestID, req.ClassTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, ErrContestNotFound
+	}
+	contestRole, err := s.roleIn(ctx, contestID, req.ClassTransactionID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if contestRole == webSocketService.ContestOutsider {
+		return nil, ErrNotParticipant
+	}
+	startTime, endTime := window.startTime, window.endTime
+	if window.classContest != nil {
+		override, err := s.contestRepo.FindOverride(ctx, *req.ClassTransactionID, contestID, userID)
+		if err != nil {
+			return nil, err
+		}
+		startTime, endTime = window.classContest.WindowFor(override)
+	}
+	now := time.Now()
+	if now.Before(startTime) || now.After(endTime) {
+		return nil, ErrContestNotRunning
+	}
+	codes := problemCodes(window.contest)
+	if req.CaseID != nil {
+		if _, ok := codes[*req.CaseID]; !ok {
+			return nil, ErrCaseNotInContest
+		}
+	}
+
+	clarification := &contestModel.Clarification{
+		ID:                 uuid.New(),
+		ContestID:          contestID,
+		ClassTransactionID: req.ClassTransactionID,
+		CaseID:             req.CaseID,
+		AskedBy:            userID,
+		Question:           question,
+		Visibility:         contestModel.ClarificationPrivate,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := s.clarificationRepo.Create(ctx, clarification); err != nil {
+		return nil, err
+	}
+	// Reload for the asker's name, which staff see.
+	saved, err := s.clarificationRepo.FindByID(ctx, clarification.ID)
+	if err != nil || saved == nil {
+		saved = clarification
+	}
+
+	staffView := toResponse(*saved, codes, true)
+	s.webSocketService.SendToContest(contestID, func(l *webSocketService.ContestListener) bool {
+		return l.Staff && l.InScope(saved.ClassTransactionID)
+	}, webSocketService.ContestEvent{Type: EventAsked, Data: staffView})
+
+	resp := toResponse(*saved, codes, false)
+	resp.AskedBy = &saved.AskedBy
+	return &resp, nil
+}
+
+func (s *clarificationService) ListClarifications(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) ([]responses.ClarificationResponse, error) {
+	window, err := s.findWindow(ctx, contestID, classTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, ErrContestNotFound
+	}
+	contestRole, err := s.roleIn(ctx, contestID, classTransactionID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := clarificationRepo.Scope{ContestID: contestID, ClassTransactionID: classTransactionID}
+	var clarifications []contestModel.Clarification
+	switch contestRole {
+	case webSocketService.ContestStaff:
+		clarifications, err = s.clarificationRepo.FindAll(ctx, scope)
+	case webSocketService.ContestParticipant:
+		clarifications, err = s.clarificationRepo.FindVisibleTo(ctx, scope, userID)
+	default:
+		return nil, ErrNotParticipant
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	codes := problemCodes(window.contest)
+	staff := contestRole == webSocketService.ContestStaff
+	resp := make([]responses.ClarificationResponse, 0, len(clarifications))
+	for _, clarification := range clarifications {
+		item := toResponse(clarification, codes, staff)
+		if clarification.AskedBy == userID {
+			askedBy := clarification.AskedBy
+			item.AskedBy = &askedBy
+		}
+		resp = append(resp, item)
+	}
+	return resp, nil
+}
+
+func (s *clarificationService) AnswerClarification(ctx context.Context, clarificationID uuid.UUID, userID uuid.UUID, role user.Role, req requests.AnswerClarificationRequest) (*responses.ClarificationResponse, error) {
+	answer := strings.TrimSpace(req.Answer)
+	if answer == "" {
+		return nil, ErrBlankAnswer
+	}
+	visibility := contestModel.ClarificationPrivate
+	switch strings.ToLower(strings.TrimSpace(req.Visibility)) {
+	case "", string(contestModel.ClarificationPrivate):
+	case string(contestModel.ClarificationBroadcast):
+		visibility = contestModel.ClarificationBroadcast
+	default:
+		return nil, ErrInvalidVisibility
+	}
+
+	clarification, err := s.clarificationRepo.FindByID(ctx, clarificationID)
+	if err != nil {
+		return nil, err
+	}
+	if clarification == nil {
+		return nil, ErrClarificationNotFound
+	}
+	contestRole, err := s.roleIn(ctx, clarification.ContestID, clarification.ClassTransactionID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if contestRole != webSocketService.ContestStaff {
+		return nil, ErrNotContestStaff
+	}
+	window, err := s.findWindow(ctx, clarification.ContestID, clarification.ClassTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, ErrContestNotFound
+	}
+
+	now := time.Now()
+	clarification.Answer = answer
+	clarification.AnsweredBy = &userID
+	clarification.AnsweredAt = &now
+	clarification.Visibility = visibility
+	clarification.UpdatedAt = now
+	if err := s.clarificationRepo.SaveAnswer(ctx, clarification); err != nil {
+		return nil, err
+	}
+
+	s.pushAnswer(*clarification, problemCodes(window.contest))
+	resp := toResponse(*clarification, problemCodes(window.contest), true)
+	return &resp, nil
+}
+
+// pushAnswer sends the answer to staff and the asker, and to every other student when it is broadcast.
+// Students other than the asker never learn who asked.
+func (s *clarificationService) pushAnswer(clarification contestModel.Clarification, codes map[uuid.UUID]string) {
+	scope := clarification.ClassTransactionID
+	staffView := toResponse(clarification, codes, true)
+	s.webSocketService.SendToContest(clarification.ContestID, func(l *webSocketService.ContestListener) bool {
+		return l.Staff && l.InScope(scope)
+	}, webSocketService.ContestEvent{Type: EventAnswered, Data: staffView})
+
+	askerView := toResponse(clarification, codes, false)
+	askerView.AskedBy = &clarification.AskedBy
+	s.webSocketService.SendToContest(clarification.ContestID, func(l *webSocketService.ContestListener) bool {
+		return !l.Staff && l.InScope(scope) && l.UserID == clarification.AskedBy
+	}, webSocketService.ContestEvent{Type: EventAnswered, Data: askerView})
+
+	if clarification.Visibility != contestModel.ClarificationBroadcast {
+		return
+	}
+	publicView := toResponse(clarification, codes, false)
+	s.webSocketService.SendToContest(clarification.ContestID, func(l *webSocketService.ContestListener) bool {
+		return !l.Staff && l.InScope(scope) && l.UserID != clarification.AskedBy
+	}, webSocketService.ContestEvent{Type: EventAnswered, Data: publicView})
+}
+
+func problemCodes(contest *contestModel.Contest) map[uuid.UUID]string {
+	codes := make(map[uuid.UUID]string, len(contest.ContestCases))
+	for _, contestCase := range contest.ContestCases {
+		codes[contestCase.CaseID] = contestCase.ProblemCode
+	}
+	return codes
+}
+
+func toResponse(clarification contestModel.Clarification, codes map[uuid.UUID]string, staff bool) responses.ClarificationResponse {
+	resp := responses.ClarificationResponse{
+		ID:                 clarification.ID,
+		ContestID:          clarification.ContestID,
+		ClassTransactionID: clarification.ClassTransactionID,
+		CaseID:             clarification.CaseID,
+		Question:           clarification.Question,
+		Answer:             clarification.Answer,
+		AnsweredAt:         clarification.AnsweredAt,
+		Visibility:         string(clarification.Visibility),
+		CreatedAt:          clarification.CreatedAt,
+	}
+	if clarification.CaseID != nil {
+		resp.ProblemCode = codes[*clarification.CaseID]
+	}
+	if staff {
+		askedBy := clarification.AskedBy
+		resp.AskedBy = &askedBy
+		resp.AskerName = clarification.Asker.Name
+	}
+	return resp
+}
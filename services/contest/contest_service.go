@@ -2,30 +2,143 @@ package contestService
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	submissionModel "neptune/backend/models/submission"
+	"neptune/backend/models/user"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
+	"time"
 )
 
+// ErrContestCourseNotFound is returned when a contest is linked to a course that does not exist.
+var ErrContestCourseNotFound = errors.New("course not found")
+
+var (
+	ErrContestNotFound      = errors.New("contest not found")
+	ErrClassContestNotFound = errors.New("contest is not assigned to this class")
+	ErrNotClassStudent      = errors.New("user is not a student of this class")
+	ErrInvalidOverride      = errors.New("an override needs a start time, end time or extra minutes, and must end after it starts")
+	ErrContestNotOpen       = errors.New("contest is not open for submissions")
+	ErrContestNotFinished   = errors.New("contest has not finished yet")
+	ErrVirtualStarted       = errors.New("you already started a virtual participation of this contest")
+	ErrVirtualNotFound      = errors.New("virtual participation not found")
+	ErrInvalidMode          = errors.New("mode must be official, virtual or upsolve")
+	ErrInvalidSchedule      = errors.New("end time must be after start time")
+	ErrNoClassesSelected    = errors.New("give class_transaction_ids, or course_id with semester_id")
+	ErrClassNotFound        = errors.New("class not found")
+	ErrCaseNotFound         = errors.New("case not found")
+	ErrCaseNotInContest     = errors.New("case is not part of this contest")
+	ErrInvalidProblemCode   = errors.New("problem code must be 1 to 10 letters or digits")
+	ErrDuplicateProblemCode = errors.New("problem code is already used in this contest")
+	ErrInvalidColor         = errors.New("color must look like #RRGGBB")
+	ErrInvalidOrder         = errors.New("order must list every case of the contest exactly once")
+	ErrContestLocked        = errors.New("contest is running or already has submissions; repeat with force=true and a reason to change it anyway")
+	ErrInvalidStatus        = errors.New("status must be draft, archived or empty")
+	ErrAccessCodeRequired   = errors.New("this contest needs an access code, join it first")
+	ErrInvalidAccessCode    = errors.New("access code is not correct")
+	ErrAccessCodeNotGlobal  = errors.New("only global contests can have an access code")
+	ErrNotEnrolled          = errors.New("you are not a student of a class taking this contest")
+	ErrCaseHidden           = errors.New("case is not visible to you")
+)
+
+// Admission is how a submission is let into a contest.
+type Admission struct {
+	Mode                   submissionModel.SubmissionMode
+	VirtualParticipationID *uuid.UUID
+}
+
+// Window is when a user may take part in a contest.
+type Window struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Personal  bool // the user has their own window in this class contest
+}
+
+// Contains reports whether t falls inside the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.StartTime) && !t.After(w.EndTime)
+}
+
 type ContestService interface {
-	// Global Contest Management
-	FindAllActiveGlobalContests(ctx context.Context) ([]responses.GlobalContestResponse, error)
-	FindAllActiveGlobalContestsDetail(ctx context.Context) ([]responses.GlobalContestDetailResponse, error)
+	// Global Contest Management. Students only get public contests listed.
+	FindAllActiveGlobalContests(ctx context.Context, role user.Role) ([]responses.GlobalContestResponse, error)
+	FindAllActiveGlobalContestsDetail(ctx context.Context, role user.Role) ([]responses.GlobalContestDetailResponse, error)
 
 	// Contest Management
 	CreateContest(ctx context.Context, req requests.CreateContestRequest) (*responses.ContestResponse, error)
-	GetContestByID(ctx context.Context, contestID uuid.UUID) (*responses.ContestDetailResponse, error)
-	GetAllContests(ctx context.Context) ([]responses.ContestResponse, error)
-	UpdateContest(ctx context.Context, contestID uuid.UUID, req requests.UpdateContestRequest) (*responses.ContestResponse, error)
-	DeleteContest(ctx context.Context, contestID uuid.UUID) error
+	// GetContestByID returns the contest as the viewer may see it. Students get ErrContestNotFound for
+	// hidden and draft contests, ErrAccessCodeRequired or ErrNotEnrolled when they are not let in, and no
+	// problems before their window starts.
+	GetContestByID(ctx context.Context, contestID uuid.UUID, viewerID uuid.UUID, role user.Role) (*responses.ContestDetailResponse, error)
+	GetAllContests(ctx context.Context, courseID *uuid.UUID, role user.Role) ([]responses.ContestResponse, error)
+	// JoinContest checks the access code of a global contest and lets the user in for good.
+	JoinContest(ctx context.Context, contestID uuid.UUID, req requests.JoinContestRequest, viewerID uuid.UUID, role user.Role) (*responses.ContestDetailResponse, error)
+	// AuthorizeContest fails with ErrContestNotFound, ErrAccessCodeRequired or ErrNotEnrolled unless the
+	// viewer may see the contest, by the same rules as GetContestByID.
+	AuthorizeContest(ctx context.Context, contestID uuid.UUID, viewerID uuid.UUID, role user.Role) error
+	// AuthorizeContestProblems fails like AuthorizeContest, and reports whether the problems are released
+	// to the viewer yet.
+	AuthorizeContestProblems(ctx context.Context, contestID uuid.UUID, viewerID uuid.UUID, role user.Role) (bool, error)
+	// AuthorizeCase fails with ErrCaseHidden unless the viewer is staff, or the case is a released problem
+	// of a contest the viewer can see.
+	AuthorizeCase(ctx context.Context, caseID uuid.UUID, viewerID uuid.UUID, role user.Role) error
+	// UpdateContest changes the contest, including its scope and global times. Changing the scope or the
+	// global times of a locked contest needs force.
+	UpdateContest(ctx context.Context, contestID uuid.UUID, req requests.UpdateContestRequest, force bool) (*responses.ContestResponse, error)
+	// DeleteContest soft deletes the contest. A locked contest needs force.
+	DeleteContest(ctx context.Context, contestID uuid.UUID, force bool) error
+	// CloneContest copies the contest with its cases and problem codes. Class assignments are not copied.
+	CloneContest(ctx context.Context, contestID uuid.UUID, req requests.CloneContestRequest) (*responses.ContestDetailResponse, error)
 
 	// Contest-Case (Problem) Management
-	AddCasesToContest(ctx context.Context, contestID uuid.UUID, req requests.AddCasesToContestRequest) error
+	// AddCasesToContest needs force while the contest is running or has submissions.
+	AddCasesToContest(ctx context.Context, contestID uuid.UUID, req requests.AddCasesToContestRequest, force bool) error
 	GetContestCases(ctx context.Context, contestID uuid.UUID) ([]responses.ContestCaseResponse, error)
 	GetContentCaseByCaseID(ctx context.Context, contestID, caseID uuid.UUID) (*responses.ContestCaseResponse, error)
+	// UpdateContestCase needs force to change the code or points while the contest is running or the
+	// problem has submissions.
+	UpdateContestCase(ctx context.Context, contestID, caseID uuid.UUID, req requests.UpdateContestCaseRequest, force bool) (*responses.ContestCaseResponse, error)
+	// RemoveCaseFromContest needs force while the contest is running or the problem has submissions.
+	RemoveCaseFromContest(ctx context.Context, contestID, caseID uuid.UUID, force bool) error
+	// ReorderContestCases sets the display order of the contest's problems, optionally relabelling them.
+	// Relabelling needs force while the contest is running or has submissions.
+	ReorderContestCases(ctx context.Context, contestID uuid.UUID, req requests.ReorderContestCasesRequest, force bool) ([]responses.ContestCaseResponse, error)
+
+	// Lifecycle
+	GetLifecycle(ctx context.Context, contestID uuid.UUID) (*responses.ContestLifecycleResponse, error)
+	SetStatusOverride(ctx context.Context, contestID uuid.UUID, req requests.ContestStatusRequest) (*responses.ContestLifecycleResponse, error)
+	// GuardCaseChange fails with ErrContestLocked when the case is a problem of a running contest, or has
+	// submissions in one of its contests.
+	GuardCaseChange(ctx context.Context, caseID uuid.UUID) error
 
 	// Class-Contest Assignment
 	AssignContestToClass(ctx context.Context, classTransactionID uuid.UUID, req requests.AssignContestToClassRequest) (*responses.ClassContestAssignmentResponse, error)
-	GetContestsForClass(ctx context.Context, classTransactionID uuid.UUID) ([]responses.ClassContestAssignmentResponse, error)
+	// BulkAssignContest assigns the contest to every selected class in one transaction; either all of them
+	// get it or none do. Classes that already have it get the new schedule, which needs force once their
+	// window has started. Each assignment says whether it was created, updated or unchanged.
+	BulkAssignContest(ctx context.Context, contestID uuid.UUID, req requests.BulkAssignContestRequest, force bool) ([]responses.ClassContestAssignmentResponse, error)
+	// GetContestsForClass lists the class's contests with the viewer's own window where they have one.
+	// Students only get public contests listed.
+	GetContestsForClass(ctx context.Context, classTransactionID uuid.UUID, viewerID uuid.UUID, role user.Role) ([]responses.ClassContestAssignmentResponse, error)
 	RemoveContestFromClass(ctx context.Context, classTransactionID, contestID uuid.UUID) error
+
+	// Per-student windows on a class contest
+	GetOverrides(ctx context.Context, classTransactionID, contestID uuid.UUID) ([]responses.ContestOverrideResponse, error)
+	SetOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID, req requests.ContestOverrideRequest, grantedBy uuid.UUID) (*responses.ContestOverrideResponse, error)
+	RemoveOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID) error
+	// WindowFor returns when the user may take part in the class contest, or the global contest when
+	// classTransactionID is nil.
+	WindowFor(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*Window, error)
+
+	// AdmitSubmission decides whether the user may submit now and which standings it counts for. Inside
+	// the window it is official; during a virtual participation, virtual; after the contest only upsolve
+	// when asked for. Draft contests take no student submissions and archived ones only upsolve.
+	// Assistants, lecturers and admins are let in at any time. The case must be a problem of the contest,
+	// and students may only submit once its problems are released to them.
+	AdmitSubmission(ctx context.Context, contestID, caseID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role, requestedMode string) (*Admission, error)
+
+	// Virtual participation of finished contests
+	StartVirtualParticipation(ctx context.Context, contestID uuid.UUID, req requests.StartVirtualParticipationRequest, userID uuid.UUID, role user.Role) (*responses.VirtualParticipationResponse, error)
+	GetVirtualParticipation(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*responses.VirtualParticipationResponse, error)
 }
@@ -2,19 +2,35 @@ package contestService
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 	contestModel "neptune/backend/models/contest"
+	submissionModel "neptune/backend/models/submission"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/database"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
 	"neptune/backend/pkg/utils"
 	caseRepository "neptune/backend/repositories/case"
+	internalClassRepo "neptune/backend/repositories/class"
 	contestRepository "neptune/backend/repositories/contest"
+	courseRepo "neptune/backend/repositories/course"
+	submissionRepository "neptune/backend/repositories/submission"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 )
 
 type contestServiceImpl struct {
-	contestRepo contestRepository.ContestRepository
-	caseRepo    caseRepository.CaseRepository // Need to lookup cases by ID
+	contestRepo    contestRepository.ContestRepository
+	caseRepo       caseRepository.CaseRepository // Need to lookup cases by ID
+	courseRepo     courseRepo.CourseRepository
+	classRepo      internalClassRepo.ClassRepository
+	submissionRepo submissionRepository.SubmissionRepository
+	txManager      database.TransactionManager
 }
 
 func (s *contestServiceImpl) GetContentCaseByCaseID(ctx context.Context, contestID, caseID uuid.UUID) (*responses.ContestCaseResponse, error) {
@@ -25,29 +41,54 @@ func (s *contestServiceImpl) GetContentCaseByCaseID(ctx context.Context, contest
 		return nil, err
 	}
 
-	resp := responses.ContestCaseResponse{
-		CaseID:   contestCase.CaseID,
-		CaseCode: contestCase.ProblemCode,
-		CaseName: contestCase.Case.Name,
-	}
-
+	resp := toContestCaseResponse(*contestCase)
 	return &resp, nil
 }
 
-func NewContestService(contestRepo contestRepository.ContestRepository, caseRepo caseRepository.CaseRepository) ContestService {
+func NewContestService(contestRepo contestRepository.ContestRepository, caseRepo caseRepository.CaseRepository, courseRepo courseRepo.CourseRepository, classRepo internalClassRepo.ClassRepository, submissionRepo submissionRepository.SubmissionRepository, txManager database.TransactionManager) ContestService {
 	return &contestServiceImpl{
-		contestRepo: contestRepo,
-		caseRepo:    caseRepo,
+		contestRepo:    contestRepo,
+		caseRepo:       caseRepo,
+		courseRepo:     courseRepo,
+		classRepo:      classRepo,
+		submissionRepo: submissionRepo,
+		txManager:      txManager,
+	}
+}
+
+// checkCourse verifies that the course a contest is linked to exists.
+func (s *contestServiceImpl) checkCourse(ctx context.Context, courseID *uuid.UUID) error {
+	if courseID == nil {
+		return nil
+	}
+	course, err := s.courseRepo.FindCourseByID(ctx, *courseID)
+	if err != nil {
+		return err
+	}
+	if course == nil {
+		return fmt.Errorf("%w: %s", ErrContestCourseNotFound, courseID.String())
 	}
+	return nil
 }
 
 // CreateContest creates a new contest.
 func (s *contestServiceImpl) CreateContest(ctx context.Context, req requests.CreateContestRequest) (*responses.ContestResponse, error) {
+	if err := s.checkCourse(ctx, req.CourseID); err != nil {
+		return nil, err
+	}
 	contest := &contestModel.Contest{
 		ID:          uuid.New(),
 		Scope:       req.Scope,
 		Name:        req.Name,
 		Description: req.Description,
+		CourseID:    req.CourseID,
+		Visibility:  contestModel.ContestPublic,
+	}
+	if req.Visibility != "" {
+		contest.Visibility = contestModel.ContestVisibility(req.Visibility)
+	}
+	if err := setAccessCode(contest, req.AccessCode); err != nil {
+		return nil, err
 	}
 	if err := s.contestRepo.SaveContest(ctx, contest); err != nil {
 		return nil, fmt.Errorf("failed to create contest: %w", err)
@@ -64,17 +105,12 @@ func (s *contestServiceImpl) CreateContest(ctx context.Context, req requests.Cre
 		}
 	}
 
-	return &responses.ContestResponse{
-		ID:          contest.ID,
-		Name:        contest.Name,
-		Scope:       contest.Scope,
-		Description: contest.Description,
-		CreatedAt:   contest.CreatedAt,
-	}, nil
+	resp := toContestResponse(*contest)
+	return &resp, nil
 }
 
-// GetContestByID retrieves a contest with its associated cases.
-func (s *contestServiceImpl) GetContestByID(ctx context.Context, contestID uuid.UUID) (*responses.ContestDetailResponse, error) {
+// GetContestByID retrieves a contest with its associated cases, as far as the viewer may see them.
+func (s *contestServiceImpl) GetContestByID(ctx context.Context, contestID uuid.UUID, viewerID uuid.UUID, role user.Role) (*responses.ContestDetailResponse, error) {
 	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contest: %w", err)
@@ -82,13 +118,29 @@ func (s *contestServiceImpl) GetContestByID(ctx context.Context, contestID uuid.
 	if contest == nil {
 		return nil, nil // Not found
 	}
+	access, err := s.access(ctx, contest, viewerID, role)
+	if err != nil {
+		return nil, err
+	}
 
 	resp := &responses.ContestDetailResponse{
-		ID:          contest.ID,
-		Name:        contest.Name,
-		Scope:       contest.Scope,
-		Description: contest.Description,
-		CreatedAt:   contest.CreatedAt,
+		ID:                 contest.ID,
+		Name:               contest.Name,
+		Scope:              contest.Scope,
+		Description:        contest.Description,
+		CourseID:           contest.CourseID,
+		Visibility:         string(contest.Visibility),
+		RequiresAccessCode: contest.AccessCodeHash != "",
+		CreatedAt:          contest.CreatedAt,
+	}
+	lifecycle, err := s.lifecycle(ctx, contest, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Status = string(lifecycle.status)
+	if !access.problemsVisible(time.Now()) {
+		resp.ProblemsVisibleAt = access.problemsAt
+		return resp, nil
 	}
 
 	for _, cc := range contest.ContestCases {
@@ -96,136 +148,267 @@ func (s *contestServiceImpl) GetContestByID(ctx context.Context, contestID uuid.
 			resp.Cases = append(resp.Cases, responses.ContestCaseProblemResponse{
 				CaseID:        cc.Case.ID,
 				ProblemCode:   cc.ProblemCode,
+				Position:      cc.Position,
+				Points:        problemPoints(cc),
+				Color:         cc.Color,
 				Name:          cc.Case.Name,
 				Description:   cc.Case.Description,
 				TimeLimitMs:   cc.Case.TimeLimitMs,
 				MemoryLimitMb: cc.Case.MemoryLimitMb,
-				PDFFileUrl:    cc.Case.PDFFileUrl,
+				PDFFileUrl:    responses.CasePDFURL(cc.Case.ID, cc.Case.PDFFileUrl),
 			})
 		}
 	}
 	return resp, nil
 }
 
-// GetAllContests retrieves all contests (basic info).
-func (s *contestServiceImpl) GetAllContests(ctx context.Context) ([]responses.ContestResponse, error) {
-	contests, err := s.contestRepo.FindAllContests(ctx)
+// GetAllContests retrieves all contests (basic info), optionally only those of a course.
+func (s *contestServiceImpl) GetAllContests(ctx context.Context, courseID *uuid.UUID, role user.Role) ([]responses.ContestResponse, error) {
+	contests, err := s.contestRepo.FindAllContests(ctx, courseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all contests: %w", err)
 	}
 
-	resp := make([]responses.ContestResponse, len(contests))
-	for i, c := range contests {
-		resp[i] = responses.ContestResponse{
-			ID:          c.ID,
-			Name:        c.Name,
-			Scope:       c.Scope,
-			Description: c.Description,
-			CreatedAt:   c.CreatedAt,
-			UpdatedAt:   c.UpdatedAt,
+	resp := make([]responses.ContestResponse, 0, len(contests))
+	for _, c := range contests {
+		if listed(c, role) {
+			resp = append(resp, toContestResponse(c))
 		}
 	}
 	return resp, nil
 }
 
-func (s *contestServiceImpl) FindAllActiveGlobalContests(ctx context.Context) ([]responses.GlobalContestResponse, error) {
+func (s *contestServiceImpl) FindAllActiveGlobalContests(ctx context.Context, role user.Role) ([]responses.GlobalContestResponse, error) {
 	contests, err := s.contestRepo.FindAllActiveGlobalContests(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find active global contests: %w", err)
 	}
 
-	resp := make([]responses.GlobalContestResponse, len(contests))
-	for i, c := range contests {
-		resp[i] = responses.GlobalContestResponse{
-			ID:          c.ID,
-			Name:        c.Name,
-			Description: c.Description,
-			StartTime:   c.GlobalContestDetail.StartTime,
-			EndTime:     c.GlobalContestDetail.EndTime,
+	resp := make([]responses.GlobalContestResponse, 0, len(contests))
+	for _, c := range contests {
+		if !listed(c, role) {
+			continue
 		}
+		resp = append(resp, responses.GlobalContestResponse{
+			ID:                 c.ID,
+			Name:               c.Name,
+			Description:        c.Description,
+			StartTime:          c.GlobalContestDetail.StartTime,
+			EndTime:            c.GlobalContestDetail.EndTime,
+			RequiresAccessCode: c.AccessCodeHash != "",
+		})
 	}
 	return resp, nil
 }
 
 // FindAllActiveGlobalContestsDetail retrieves all active global contest details.
-func (s *contestServiceImpl) FindAllActiveGlobalContestsDetail(ctx context.Context) ([]responses.GlobalContestDetailResponse, error) {
+func (s *contestServiceImpl) FindAllActiveGlobalContestsDetail(ctx context.Context, role user.Role) ([]responses.GlobalContestDetailResponse, error) {
 	contests, err := s.contestRepo.FindAllActiveGlobalContests(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find active global contest details: %w", err)
 	}
 
-	resp := make([]responses.GlobalContestDetailResponse, len(contests))
-	for i, c := range contests {
-		resp[i] = responses.GlobalContestDetailResponse{
+	resp := make([]responses.GlobalContestDetailResponse, 0, len(contests))
+	for _, c := range contests {
+		if !listed(c, role) {
+			continue
+		}
+		resp = append(resp, responses.GlobalContestDetailResponse{
 			ID:          c.ID,
 			Name:        c.Name,
 			Description: c.Description,
 			StartTime:   c.GlobalContestDetail.StartTime,
 			EndTime:     c.GlobalContestDetail.EndTime,
-		}
+		})
 	}
 	return resp, nil
 }
 
 // UpdateContest updates an existing contest.
-func (s *contestServiceImpl) UpdateContest(ctx context.Context, contestID uuid.UUID, req requests.UpdateContestRequest) (*responses.ContestResponse, error) {
+func (s *contestServiceImpl) UpdateContest(ctx context.Context, contestID uuid.UUID, req requests.UpdateContestRequest, force bool) (*responses.ContestResponse, error) {
 	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find contest for update: %w", err)
 	}
 	if contest == nil {
-		return nil, fmt.Errorf("contest with ID %s not found", contestID.String())
+		return nil, ErrContestNotFound
+	}
+
+	if err := s.checkCourse(ctx, req.CourseID); err != nil {
+		return nil, err
+	}
+
+	// A global contest keeps its times unless new ones are given; other scopes drop them.
+	var globalDetail *contestModel.GlobalContestDetail
+	if req.Scope == "global" {
+		globalDetail = &contestModel.GlobalContestDetail{ContestID: contest.ID}
+		if contest.GlobalContestDetail != nil {
+			globalDetail.StartTime = contest.GlobalContestDetail.StartTime
+			globalDetail.EndTime = contest.GlobalContestDetail.EndTime
+		}
+		if req.StartTime != nil {
+			globalDetail.StartTime = *req.StartTime
+		}
+		if req.EndTime != nil {
+			globalDetail.EndTime = *req.EndTime
+		}
+		if globalDetail.StartTime.IsZero() || globalDetail.EndTime.IsZero() {
+			return nil, fmt.Errorf("%w: global contests need start_time and end_time", ErrInvalidSchedule)
+		}
+		if !globalDetail.EndTime.After(globalDetail.StartTime) {
+			return nil, ErrInvalidSchedule
+		}
+	}
+	// Moving the window of a running contest, or one with submissions, is as destructive as changing
+	// its scope
+	timesChanged := contest.GlobalContestDetail != nil && globalDetail != nil &&
+		(!globalDetail.StartTime.Equal(contest.GlobalContestDetail.StartTime) || !globalDetail.EndTime.Equal(contest.GlobalContestDetail.EndTime))
+	if (req.Scope != contest.Scope || timesChanged) && !force {
+		if err := s.guardContest(ctx, contest, nil); err != nil {
+			return nil, err
+		}
 	}
 
 	contest.Name = req.Name
 	contest.Description = req.Description
+	contest.Scope = req.Scope
+	contest.CourseID = req.CourseID
+	if req.Visibility != "" {
+		contest.Visibility = contestModel.ContestVisibility(req.Visibility)
+	}
+	if req.AccessCode != nil {
+		if err := setAccessCode(contest, *req.AccessCode); err != nil {
+			return nil, err
+		}
+	} else if globalDetail == nil {
+		contest.AccessCodeHash = "" // Only global contests keep an access code
+	}
 
-	if err := s.contestRepo.SaveContest(ctx, contest); err != nil {
-		return nil, fmt.Errorf("failed to update contest: %w", err)
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.contestRepo.SaveContest(txCtx, contest); err != nil {
+			return fmt.Errorf("failed to update contest: %w", err)
+		}
+		if globalDetail != nil {
+			return s.contestRepo.SaveGlobalContestDetail(txCtx, globalDetail)
+		}
+		if contest.GlobalContestDetail != nil {
+			return s.contestRepo.DeleteGlobalContestDetail(txCtx, contest.ID)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return &responses.ContestResponse{
-		ID:          contest.ID,
-		Name:        contest.Name,
-		Scope:       contest.Scope,
-		Description: contest.Description,
-		CreatedAt:   contest.CreatedAt,
-		UpdatedAt:   contest.UpdatedAt,
-	}, nil
+	resp := toContestResponse(*contest)
+	return &resp, nil
 }
 
-// DeleteContest soft deletes a contest.
-func (s *contestServiceImpl) DeleteContest(ctx context.Context, contestID uuid.UUID) error {
-	// Implement checks if contest is assigned to any active classes before deleting
-	// For now, just soft delete
+// DeleteContest soft deletes a contest, refusing a running contest or one with submissions unless forced.
+func (s *contestServiceImpl) DeleteContest(ctx context.Context, contestID uuid.UUID, force bool) error {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return ErrContestNotFound
+	}
+	if !force {
+		if err := s.guardContest(ctx, contest, nil); err != nil {
+			return err
+		}
+	}
 	return s.contestRepo.DeleteContest(ctx, contestID)
 }
 
-// AddCasesToContest adds/updates problems for a contest.
-func (s *contestServiceImpl) AddCasesToContest(ctx context.Context, contestID uuid.UUID, req requests.AddCasesToContestRequest) error {
+// AddCasesToContest appends problems to a contest. Cases already in it are skipped; the others get their
+// own problem code, or the next free letter when none is given. A locked contest needs force.
+func (s *contestServiceImpl) AddCasesToContest(ctx context.Context, contestID uuid.UUID, req requests.AddCasesToContestRequest, force bool) error {
+	if !force {
+		contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+		if err != nil {
+			return fmt.Errorf("failed to get contest: %w", err)
+		}
+		if contest == nil {
+			return ErrContestNotFound
+		}
+		if err := s.guardContest(ctx, contest, nil); err != nil {
+			return err
+		}
+	}
+	existing, err := s.contestRepo.FindContestCases(ctx, contestID)
+	if err != nil {
+		return fmt.Errorf("failed to get contest cases: %w", err)
+	}
+	usedCodes := make(map[string]bool, len(existing)+len(req.Problems))
+	inContest := make(map[uuid.UUID]bool, len(existing)+len(req.Problems))
+	nextPosition := 0
+	for _, cc := range existing {
+		usedCodes[cc.ProblemCode] = true
+		inContest[cc.CaseID] = true
+		if cc.Position >= nextPosition {
+			nextPosition = cc.Position + 1
+		}
+	}
+
+	// Explicit codes are claimed first so a generated code never takes one asked for later in the request.
 	var contestCases []contestModel.ContestCase
 	for _, problem := range req.Problems {
-		// Cek apakah case nya beneran ada
-		_, err := s.caseRepo.FindCaseByID(ctx, problem.CaseID)
-		if err != nil {
-			return fmt.Errorf("failed to find case by ID %s: %w", problem.CaseID.String(), err)
+		if inContest[problem.CaseID] {
+			continue
 		}
+		inContest[problem.CaseID] = true
 
-		// If a problem code is duplicated, the unique index on (ContestID, CaseID) will prevent it
-		//caseCode := getProblemCodeByContestCaseCount
-		caseCode, err := s.getProblemCodeByContestCaseCount(contestID)
+		problemCase, err := s.caseRepo.FindCaseByID(ctx, problem.CaseID)
 		if err != nil {
-			return fmt.Errorf("failed to generate problem code: %w", err)
+			return fmt.Errorf("failed to find case by ID %s: %w", problem.CaseID.String(), err)
+		}
+		if problemCase == nil {
+			return fmt.Errorf("%w: %s", ErrCaseNotFound, problem.CaseID.String())
 		}
+		points := defaultProblemPoints
+		if problem.Points != nil {
+			points = *problem.Points
+		}
+		contestCase := contestModel.ContestCase{
+			ContestID: contestID,
+			CaseID:    problem.CaseID,
+			Position:  nextPosition,
+			Points:    &points,
+		}
+		nextPosition++
+		if problem.ProblemCode != "" {
+			code, err := normalizeProblemCode(problem.ProblemCode)
+			if err != nil {
+				return err
+			}
+			if usedCodes[code] {
+				return fmt.Errorf("%w: %s", ErrDuplicateProblemCode, code)
+			}
+			usedCodes[code] = true
+			contestCase.ProblemCode = code
+		}
+		if contestCase.Color, err = normalizeColor(problem.Color); err != nil {
+			return err
+		}
+		contestCases = append(contestCases, contestCase)
+	}
 
-		contestCases = append(contestCases, contestModel.ContestCase{
-			ContestID:   contestID,
-			CaseID:      problem.CaseID,
-			ProblemCode: caseCode,
-		})
+	next := len(existing)
+	for i := range contestCases {
+		if contestCases[i].ProblemCode != "" {
+			continue
+		}
+		for usedCodes[utils.GenerateAlphabetCode(next)] {
+			next++
+		}
+		contestCases[i].ProblemCode = utils.GenerateAlphabetCode(next)
+		usedCodes[contestCases[i].ProblemCode] = true
 	}
 
 	if err := s.contestRepo.AddCasesToContest(ctx, contestID, contestCases); err != nil {
+		if errors.Is(err, contestRepository.ErrProblemCodeTaken) {
+			return ErrDuplicateProblemCode // Another request took the code since it was checked
+		}
 		return fmt.Errorf("failed to add cases to contest: %w", err)
 	}
 	return nil
@@ -239,15 +422,152 @@ func (s *contestServiceImpl) GetContestCases(ctx context.Context, contestID uuid
 
 	resp := make([]responses.ContestCaseResponse, len(cases))
 	for i, cc := range cases {
-		resp[i] = responses.ContestCaseResponse{
-			CaseID:   cc.Case.ID,
-			CaseCode: cc.ProblemCode,
-			CaseName: cc.Case.Name,
-		}
+		resp[i] = toContestCaseResponse(cc)
 	}
 	return resp, nil
 }
 
+// UpdateContestCase changes the code, points or color of one problem in a contest. Changing the code or
+// the points needs force while the contest is running or the problem has submissions.
+func (s *contestServiceImpl) UpdateContestCase(ctx context.Context, contestID, caseID uuid.UUID, req requests.UpdateContestCaseRequest, force bool) (*responses.ContestCaseResponse, error) {
+	cases, err := s.contestRepo.FindContestCases(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest cases: %w", err)
+	}
+	var contestCase *contestModel.ContestCase
+	for i := range cases {
+		if cases[i].CaseID == caseID {
+			contestCase = &cases[i]
+		}
+	}
+	if contestCase == nil {
+		return nil, ErrCaseNotInContest
+	}
+
+	// The leaderboard is keyed by problem code and scored by points, so changing either is as
+	// destructive as removing the problem
+	scoringChanged := false
+	if req.ProblemCode != nil {
+		code, err := normalizeProblemCode(*req.ProblemCode)
+		if err != nil {
+			return nil, err
+		}
+		for _, cc := range cases {
+			if cc.CaseID != caseID && cc.ProblemCode == code {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateProblemCode, code)
+			}
+		}
+		scoringChanged = code != contestCase.ProblemCode
+		contestCase.ProblemCode = code
+	}
+	if req.Points != nil {
+		scoringChanged = scoringChanged || problemPoints(*contestCase) != *req.Points
+		contestCase.Points = req.Points
+	}
+	if scoringChanged && !force {
+		if err := s.guardContestByID(ctx, contestID, &caseID); err != nil {
+			return nil, err
+		}
+	}
+	if req.Color != nil {
+		if contestCase.Color, err = normalizeColor(*req.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.contestRepo.UpdateContestCase(ctx, contestCase); err != nil {
+		if errors.Is(err, contestRepository.ErrProblemCodeTaken) {
+			return nil, ErrDuplicateProblemCode
+		}
+		return nil, err
+	}
+	resp := toContestCaseResponse(*contestCase)
+	return &resp, nil
+}
+
+// RemoveCaseFromContest takes a problem out of a contest. The other problems keep their codes.
+func (s *contestServiceImpl) RemoveCaseFromContest(ctx context.Context, contestID, caseID uuid.UUID, force bool) error {
+	contestCase, err := s.findContestCase(ctx, contestID, caseID)
+	if err != nil {
+		return err
+	}
+	if !force {
+		if err := s.guardContestByID(ctx, contestID, &caseID); err != nil {
+			return err
+		}
+	}
+	return s.contestRepo.RemoveCaseFromContest(ctx, contestID, contestCase.CaseID)
+}
+
+// ReorderContestCases sets the display order of the problems. Relabelling them needs force while the
+// contest is running or has submissions.
+func (s *contestServiceImpl) ReorderContestCases(ctx context.Context, contestID uuid.UUID, req requests.ReorderContestCasesRequest, force bool) ([]responses.ContestCaseResponse, error) {
+	cases, err := s.contestRepo.FindContestCases(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest cases: %w", err)
+	}
+	if len(req.CaseIDs) != len(cases) {
+		return nil, ErrInvalidOrder
+	}
+	byCase := make(map[uuid.UUID]*contestModel.ContestCase, len(cases))
+	for i := range cases {
+		byCase[cases[i].CaseID] = &cases[i]
+	}
+
+	ordered := make([]*contestModel.ContestCase, 0, len(req.CaseIDs))
+	relabelled := false
+	for position, caseID := range req.CaseIDs {
+		contestCase, ok := byCase[caseID]
+		if !ok {
+			return nil, ErrInvalidOrder
+		}
+		delete(byCase, caseID) // a case listed twice is no longer found
+		contestCase.Position = position
+		if req.Relabel {
+			code := utils.GenerateAlphabetCode(position)
+			relabelled = relabelled || code != contestCase.ProblemCode
+			contestCase.ProblemCode = code
+		}
+		ordered = append(ordered, contestCase)
+	}
+	// Relabelling re-keys every row of the leaderboard
+	if relabelled && !force {
+		if err := s.guardContestByID(ctx, contestID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if req.Relabel {
+			if err := s.contestRepo.ReleaseProblemCodes(txCtx, contestID); err != nil {
+				return err
+			}
+		}
+		for _, contestCase := range ordered {
+			if err := s.contestRepo.UpdateContestCase(txCtx, contestCase); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetContestCases(ctx, contestID)
+}
+
+func (s *contestServiceImpl) findContestCase(ctx context.Context, contestID, caseID uuid.UUID) (*contestModel.ContestCase, error) {
+	cases, err := s.contestRepo.FindContestCases(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest cases: %w", err)
+	}
+	for i := range cases {
+		if cases[i].CaseID == caseID {
+			return &cases[i], nil
+		}
+	}
+	return nil, ErrCaseNotInContest
+}
+
 // AssignContestToClass assigns a contest to a specific class with a duration.
 func (s *contestServiceImpl) AssignContestToClass(ctx context.Context, classTransactionID uuid.UUID, req requests.AssignContestToClassRequest) (*responses.ClassContestAssignmentResponse, error) {
 	// Optional: Verify ContestID and ClassTransactionID exist before assigning
@@ -277,47 +597,916 @@ func (s *contestServiceImpl) AssignContestToClass(ctx context.Context, classTran
 	}, nil
 }
 
+// CloneContest copies a contest, its global schedule and its cases in one transaction.
+func (s *contestServiceImpl) CloneContest(ctx context.Context, contestID uuid.UUID, req requests.CloneContestRequest) (*responses.ContestDetailResponse, error) {
+	source, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if source == nil {
+		return nil, ErrContestNotFound
+	}
+
+	clone := &contestModel.Contest{
+		ID:             uuid.New(),
+		Name:           strings.TrimSpace(req.Name),
+		Description:    source.Description,
+		Scope:          source.Scope,
+		CourseID:       source.CourseID,
+		Visibility:     source.Visibility,
+		AccessCodeHash: source.AccessCodeHash,
+	}
+	if clone.Name == "" {
+		clone.Name = source.Name + " (copy)"
+	}
+	if req.Description != nil {
+		clone.Description = *req.Description
+	}
+	if req.CourseID != nil {
+		if err := s.checkCourse(ctx, req.CourseID); err != nil {
+			return nil, err
+		}
+		clone.CourseID = req.CourseID
+	}
+
+	var globalDetail *contestModel.GlobalContestDetail
+	if source.GlobalContestDetail != nil {
+		globalDetail = &contestModel.GlobalContestDetail{
+			ContestID: clone.ID,
+			StartTime: source.GlobalContestDetail.StartTime,
+			EndTime:   source.GlobalContestDetail.EndTime,
+		}
+		if req.StartTime != nil {
+			globalDetail.StartTime = *req.StartTime
+		}
+		if req.EndTime != nil {
+			globalDetail.EndTime = *req.EndTime
+		}
+		if !globalDetail.EndTime.After(globalDetail.StartTime) {
+			return nil, ErrInvalidSchedule
+		}
+	}
+
+	contestCases, err := s.contestRepo.FindContestCases(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest cases: %w", err)
+	}
+	clonedCases := make([]contestModel.ContestCase, 0, len(contestCases))
+	for _, cc := range contestCases {
+		clonedCases = append(clonedCases, contestModel.ContestCase{
+			ContestID:   clone.ID,
+			CaseID:      cc.CaseID,
+			ProblemCode: cc.ProblemCode,
+			Position:    cc.Position,
+			Points:      cc.Points,
+			Color:       cc.Color,
+		})
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.contestRepo.SaveContest(txCtx, clone); err != nil {
+			return fmt.Errorf("failed to create contest: %w", err)
+		}
+		if globalDetail != nil {
+			if err := s.contestRepo.SaveGlobalContestDetail(txCtx, globalDetail); err != nil {
+				return fmt.Errorf("failed to create global contest detail: %w", err)
+			}
+		}
+		if err := s.contestRepo.AddCasesToContest(txCtx, clone.ID, clonedCases); err != nil {
+			return fmt.Errorf("failed to add cases to contest: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetContestByID(ctx, clone.ID, uuid.Nil, user.RoleAdmin)
+}
+
+// BulkAssignContest resolves the selected classes, then assigns the contest to all of them in one
+// transaction. With a stagger, classes are scheduled in the order given, or by class code for a course.
+func (s *contestServiceImpl) BulkAssignContest(ctx context.Context, contestID uuid.UUID, req requests.BulkAssignContestRequest, force bool) ([]responses.ClassContestAssignmentResponse, error) {
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidSchedule
+	}
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return nil, ErrContestNotFound
+	}
+	classIDs, err := s.selectClasses(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.contestRepo.FindClassContestsByContest(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[uuid.UUID]contestModel.ClassContest, len(assigned))
+	for _, classContest := range assigned {
+		existing[classContest.ClassTransactionID] = classContest
+	}
+
+	now := time.Now()
+	duration := req.EndTime.Sub(req.StartTime)
+	stagger := time.Duration(req.StaggerMinutes) * time.Minute
+	classContests := make([]*contestModel.ClassContest, len(classIDs))
+	resp := make([]responses.ClassContestAssignmentResponse, len(classIDs))
+	for i, classTransactionID := range classIDs {
+		startTime := req.StartTime.Add(time.Duration(i) * stagger)
+		classContests[i] = &contestModel.ClassContest{
+			ClassTransactionID: classTransactionID,
+			ContestID:          contestID,
+			StartTime:          startTime,
+			EndTime:            startTime.Add(duration),
+		}
+		resp[i].Assignment = responses.AssignmentCreated
+
+		previous, ok := existing[classTransactionID]
+		if !ok {
+			continue
+		}
+		if previous.StartTime.Equal(startTime) && previous.EndTime.Equal(startTime.Add(duration)) {
+			resp[i].Assignment = responses.AssignmentUnchanged
+			continue
+		}
+		// Moving the window of a class that already started the contest changes its standings
+		if !now.Before(previous.StartTime) && !force {
+			return nil, fmt.Errorf("%w: class %s already started it at %s", ErrContestLocked, classTransactionID,
+				previous.StartTime.Format(time.RFC3339))
+		}
+		resp[i].Assignment = responses.AssignmentUpdated
+		resp[i].PreviousStartTime = &previous.StartTime
+		resp[i].PreviousEndTime = &previous.EndTime
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		for i, classContest := range classContests {
+			if resp[i].Assignment == responses.AssignmentUnchanged {
+				continue
+			}
+			if err := s.contestRepo.AssignContestToClass(txCtx, classContest); err != nil {
+				return fmt.Errorf("failed to assign contest to class %s: %w", classContest.ClassTransactionID, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for i, classContest := range classContests {
+		resp[i].ClassTransactionID = classContest.ClassTransactionID
+		resp[i].ContestID = classContest.ContestID
+		resp[i].StartTime = classContest.StartTime
+		resp[i].EndTime = classContest.EndTime
+		resp[i].CreatedAt = classContest.CreatedAt
+		resp[i].UpdatedAt = classContest.UpdatedAt
+	}
+	return resp, nil
+}
+
+// selectClasses returns the listed classes, checking each exists, or every class of the course in the
+// semester ordered by class code.
+func (s *contestServiceImpl) selectClasses(ctx context.Context, req requests.BulkAssignContestRequest) ([]uuid.UUID, error) {
+	if len(req.ClassTransactionIDs) > 0 {
+		if req.CourseID != nil || req.SemesterID != nil {
+			return nil, ErrNoClassesSelected
+		}
+		classIDs := make([]uuid.UUID, 0, len(req.ClassTransactionIDs))
+		seen := make(map[uuid.UUID]bool, len(req.ClassTransactionIDs))
+		for _, classTransactionID := range req.ClassTransactionIDs {
+			if seen[classTransactionID] {
+				continue
+			}
+			seen[classTransactionID] = true
+			class, err := s.classRepo.FindClassByTransactionID(ctx, classTransactionID.String())
+			if err != nil {
+				return nil, err
+			}
+			if class == nil {
+				return nil, fmt.Errorf("%w: %s", ErrClassNotFound, classTransactionID)
+			}
+			classIDs = append(classIDs, classTransactionID)
+		}
+		return classIDs, nil
+	}
+
+	if req.CourseID == nil || req.SemesterID == nil {
+		return nil, ErrNoClassesSelected
+	}
+	course, err := s.courseRepo.FindCourseByID(ctx, *req.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, fmt.Errorf("%w: %s", ErrContestCourseNotFound, req.CourseID.String())
+	}
+	classes, err := s.classRepo.FindClassBasicInfoBySemesterAndCourse(ctx, req.SemesterID.String(), course.CourseOutlineID.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("%w: course %s has no classes in semester %s", ErrClassNotFound, course.Code, req.SemesterID)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].ClassCode < classes[j].ClassCode
+	})
+	classIDs := make([]uuid.UUID, len(classes))
+	for i, class := range classes {
+		classIDs[i] = class.ClassTransactionID
+	}
+	return classIDs, nil
+}
+
 // GetContestsForClass retrieves all contests assigned to a specific class.
-func (s *contestServiceImpl) GetContestsForClass(ctx context.Context, classTransactionID uuid.UUID) ([]responses.ClassContestAssignmentResponse, error) {
+func (s *contestServiceImpl) GetContestsForClass(ctx context.Context, classTransactionID uuid.UUID, viewerID uuid.UUID, role user.Role) ([]responses.ClassContestAssignmentResponse, error) {
 	classContests, err := s.contestRepo.FindContestsByClassTransactionID(ctx, classTransactionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contests for class: %w", err)
 	}
+	overrides, err := s.contestRepo.FindUserOverridesInClass(ctx, classTransactionID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	overrideByContest := make(map[uuid.UUID]*contestModel.ClassContestOverride, len(overrides))
+	for i := range overrides {
+		overrideByContest[overrides[i].ContestID] = &overrides[i]
+	}
 
-	resp := make([]responses.ClassContestAssignmentResponse, len(classContests))
-	for i, cc := range classContests {
+	resp := make([]responses.ClassContestAssignmentResponse, 0, len(classContests))
+	for _, cc := range classContests {
+		if !listed(cc.Contest, role) {
+			continue
+		}
+		override := overrideByContest[cc.ContestID]
+		startTime, endTime := cc.WindowFor(override)
 
-		resp[i] = responses.ClassContestAssignmentResponse{
+		resp = append(resp, responses.ClassContestAssignmentResponse{
 			ClassTransactionID: cc.ClassTransactionID,
 			ContestID:          cc.ContestID,
-			StartTime:          cc.StartTime,
-			EndTime:            cc.EndTime,
+			StartTime:          startTime,
+			EndTime:            endTime,
+			PersonalWindow:     override != nil,
 			CreatedAt:          cc.CreatedAt,
 			UpdatedAt:          cc.UpdatedAt,
-			Contest: responses.ContestResponse{
-				ID:          cc.Contest.ID,
-				Name:        cc.Contest.Name,
-				Scope:       cc.Contest.Scope,
-				Description: cc.Contest.Description,
-				CreatedAt:   cc.Contest.CreatedAt,
-			},
-		}
+			Contest:            toContestResponse(cc.Contest),
+		})
+	}
+	return resp, nil
+}
+
+// RemoveContestFromClass removes a contest assignment from a class.
+func (s *contestServiceImpl) RemoveContestFromClass(ctx context.Context, classTransactionID, contestID uuid.UUID) error {
+	return s.contestRepo.RemoveContestFromClass(ctx, classTransactionID, contestID)
+}
+
+// GetOverrides lists the students of a class who have their own window for the contest.
+func (s *contestServiceImpl) GetOverrides(ctx context.Context, classTransactionID, contestID uuid.UUID) ([]responses.ContestOverrideResponse, error) {
+	classContest, err := s.findClassContest(ctx, classTransactionID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.contestRepo.FindOverrides(ctx, classTransactionID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]responses.ContestOverrideResponse, len(overrides))
+	for i, override := range overrides {
+		resp[i] = toOverrideResponse(*classContest, override)
 	}
 	return resp, nil
 }
 
-func (s *contestServiceImpl) getProblemCodeByContestCaseCount(contestID uuid.UUID) (string, error) {
-	// Get all contest cases for the given contest ID
-	caseCount, err := s.contestRepo.GetCaseCountInContest(context.Background(), contestID)
+// SetOverride gives a student of the class their own window for the contest, replacing any earlier one.
+func (s *contestServiceImpl) SetOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID, req requests.ContestOverrideRequest, grantedBy uuid.UUID) (*responses.ContestOverrideResponse, error) {
+	classContest, err := s.findClassContest(ctx, classTransactionID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	isStudent, err := s.classRepo.IsClassStudent(ctx, classTransactionID, userID)
 	if err != nil {
-		// Handle error (you might want to panic or return a default)
-		return "", err
+		return nil, err
+	}
+	if !isStudent {
+		return nil, ErrNotClassStudent
 	}
 
-	return utils.GenerateAlphabetCode(caseCount), nil
+	override := &contestModel.ClassContestOverride{
+		ClassTransactionID: classTransactionID,
+		ContestID:          contestID,
+		UserID:             userID,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		ExtraMinutes:       req.ExtraMinutes,
+		Reason:             req.Reason,
+		GrantedBy:          &grantedBy,
+	}
+	if req.StartTime == nil && req.EndTime == nil && req.ExtraMinutes == 0 {
+		return nil, ErrInvalidOverride
+	}
+	if startTime, endTime := classContest.WindowFor(override); !endTime.After(startTime) {
+		return nil, ErrInvalidOverride
+	}
+	if err := s.contestRepo.SaveOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.contestRepo.FindOverride(ctx, classTransactionID, contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		saved = override
+	}
+	resp := toOverrideResponse(*classContest, *saved)
+	return &resp, nil
 }
 
-// RemoveContestFromClass removes a contest assignment from a class.
-func (s *contestServiceImpl) RemoveContestFromClass(ctx context.Context, classTransactionID, contestID uuid.UUID) error {
-	return s.contestRepo.RemoveContestFromClass(ctx, classTransactionID, contestID)
+// RemoveOverride puts a student back on the class window.
+func (s *contestServiceImpl) RemoveOverride(ctx context.Context, classTransactionID, contestID, userID uuid.UUID) error {
+	if _, err := s.findClassContest(ctx, classTransactionID, contestID); err != nil {
+		return err
+	}
+	return s.contestRepo.DeleteOverride(ctx, classTransactionID, contestID, userID)
+}
+
+// WindowFor returns the class window, or the student's own one, or the global contest window.
+func (s *contestServiceImpl) WindowFor(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*Window, error) {
+	if classTransactionID == nil {
+		contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+		if err != nil {
+			return nil, err
+		}
+		if contest == nil || contest.GlobalContestDetail == nil {
+			return nil, ErrContestNotFound
+		}
+		return &Window{StartTime: contest.GlobalContestDetail.StartTime, EndTime: contest.GlobalContestDetail.EndTime}, nil
+	}
+
+	classContest, err := s.findClassContest(ctx, *classTransactionID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.contestRepo.FindOverride(ctx, *classTransactionID, contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	startTime, endTime := classContest.WindowFor(override)
+	return &Window{StartTime: startTime, EndTime: endTime, Personal: override != nil}, nil
+}
+
+func (s *contestServiceImpl) findClassContest(ctx context.Context, classTransactionID, contestID uuid.UUID) (*contestModel.ClassContest, error) {
+	classContest, err := s.contestRepo.FindClassContestByIDs(ctx, classTransactionID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if classContest == nil {
+		return nil, ErrClassContestNotFound
+	}
+	return classContest, nil
+}
+
+func toOverrideResponse(classContest contestModel.ClassContest, override contestModel.ClassContestOverride) responses.ContestOverrideResponse {
+	startTime, endTime := classContest.WindowFor(&override)
+	return responses.ContestOverrideResponse{
+		ClassTransactionID: override.ClassTransactionID,
+		ContestID:          override.ContestID,
+		UserID:             override.UserID,
+		Username:           override.User.Username,
+		Name:               override.User.Name,
+		StartTime:          override.StartTime,
+		EndTime:            override.EndTime,
+		ExtraMinutes:       override.ExtraMinutes,
+		Reason:             override.Reason,
+		EffectiveStartTime: startTime,
+		EffectiveEndTime:   endTime,
+		GrantedBy:          override.GrantedBy,
+		UpdatedAt:          override.UpdatedAt,
+	}
+}
+
+func (s *contestServiceImpl) AdmitSubmission(ctx context.Context, contestID, caseID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role, requestedMode string) (*Admission, error) {
+	mode := submissionModel.SubmissionMode(strings.ToLower(strings.TrimSpace(requestedMode)))
+	switch mode {
+	case "", submissionModel.SubmissionModeOfficial, submissionModel.SubmissionModeVirtual, submissionModel.SubmissionModeUpsolve:
+	default:
+		return nil, ErrInvalidMode
+	}
+	staff := isStaff(role)
+	// The window is that of the submitted class, so students may only submit for a class they are in
+	if classTransactionID != nil && !staff {
+		enrolled, err := s.classRepo.IsClassStudent(ctx, *classTransactionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !enrolled {
+			return nil, ErrNotClassStudent
+		}
+	}
+	window, err := s.WindowFor(ctx, contestID, classTransactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	virtual, err := s.contestRepo.FindVirtualParticipation(ctx, contestID, classTransactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest == nil {
+		return nil, ErrContestNotFound
+	}
+	if !hasContestCase(contest, caseID) {
+		return nil, ErrCaseNotInContest
+	}
+	if !staff {
+		switch contest.StatusOverride {
+		case contestModel.ContestDraft:
+			return nil, fmt.Errorf("%w: it is still a draft", ErrContestNotOpen)
+		case contestModel.ContestArchived:
+			if mode != submissionModel.SubmissionModeUpsolve {
+				return nil, fmt.Errorf("%w: it is archived, submit with mode upsolve to practise", ErrContestNotOpen)
+			}
+		}
+		access, err := s.access(ctx, contest, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if !access.problemsVisible(now) {
+			return nil, fmt.Errorf("%w: its problems are not released yet", ErrContestNotOpen)
+		}
+	}
+	switch {
+	case window.Contains(now) && (mode == "" || mode == submissionModel.SubmissionModeOfficial):
+		return &Admission{Mode: submissionModel.SubmissionModeOfficial}, nil
+	case virtual != nil && virtual.Active(now) && (mode == "" || mode == submissionModel.SubmissionModeVirtual):
+		return &Admission{Mode: submissionModel.SubmissionModeVirtual, VirtualParticipationID: &virtual.ID}, nil
+	case now.After(window.EndTime) && (mode == submissionModel.SubmissionModeUpsolve || (staff && mode == "")):
+		return &Admission{Mode: submissionModel.SubmissionModeUpsolve}, nil
+	case staff && (mode == "" || mode == submissionModel.SubmissionModeOfficial):
+		// Staff trying the problems before the contest starts
+		return &Admission{Mode: submissionModel.SubmissionModeOfficial}, nil
+	case now.After(window.EndTime):
+		return nil, fmt.Errorf("%w: it ended at %s, submit with mode upsolve to practise", ErrContestNotOpen, window.EndTime.Format(time.RFC3339))
+	default:
+		return nil, fmt.Errorf("%w: it runs from %s to %s", ErrContestNotOpen, window.StartTime.Format(time.RFC3339), window.EndTime.Format(time.RFC3339))
+	}
+}
+
+// hasContestCase reports whether caseID is one of the problems of the contest.
+func hasContestCase(contest *contestModel.Contest, caseID uuid.UUID) bool {
+	for _, cc := range contest.ContestCases {
+		if cc.CaseID == caseID {
+			return true
+		}
+	}
+	return false
+}
+
+// StartVirtualParticipation starts a personal run of a finished contest, as long as the user's own
+// window was. Each user gets one run per class contest or global contest.
+func (s *contestServiceImpl) StartVirtualParticipation(ctx context.Context, contestID uuid.UUID, req requests.StartVirtualParticipationRequest, userID uuid.UUID, role user.Role) (*responses.VirtualParticipationResponse, error) {
+	window, err := s.WindowFor(ctx, contestID, req.ClassTransactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !now.After(window.EndTime) {
+		return nil, ErrContestNotFinished
+	}
+	if req.ClassTransactionID != nil && role.Rank() < user.RoleAssistant.Rank() {
+		isStudent, err := s.classRepo.IsClassStudent(ctx, *req.ClassTransactionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isStudent {
+			return nil, ErrNotClassStudent
+		}
+	}
+	existing, err := s.contestRepo.FindVirtualParticipation(ctx, contestID, req.ClassTransactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrVirtualStarted
+	}
+
+	participation := &contestModel.VirtualParticipation{
+		ID:                 uuid.New(),
+		ContestID:          contestID,
+		ClassTransactionID: req.ClassTransactionID,
+		UserID:             userID,
+		StartedAt:          now,
+		EndsAt:             now.Add(window.EndTime.Sub(window.StartTime)),
+	}
+	if err := s.contestRepo.CreateVirtualParticipation(ctx, participation); err != nil {
+		return nil, err
+	}
+	resp := toVirtualParticipationResponse(*participation, now)
+	return &resp, nil
+}
+
+func (s *contestServiceImpl) GetVirtualParticipation(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*responses.VirtualParticipationResponse, error) {
+	participation, err := s.contestRepo.FindVirtualParticipation(ctx, contestID, classTransactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if participation == nil {
+		return nil, ErrVirtualNotFound
+	}
+	resp := toVirtualParticipationResponse(*participation, time.Now())
+	return &resp, nil
+}
+
+func toVirtualParticipationResponse(participation contestModel.VirtualParticipation, now time.Time) responses.VirtualParticipationResponse {
+	return responses.VirtualParticipationResponse{
+		ID:                 participation.ID,
+		ContestID:          participation.ContestID,
+		ClassTransactionID: participation.ClassTransactionID,
+		StartedAt:          participation.StartedAt,
+		EndsAt:             participation.EndsAt,
+		Active:             participation.Active(now),
+	}
+}
+
+// defaultProblemPoints is what a problem is worth when it is added without points.
+const defaultProblemPoints = 100
+
+var (
+	problemCodePattern = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+	colorPattern       = regexp.MustCompile(`^#[0-9A-F]{6}$`)
+)
+
+// normalizeProblemCode upper-cases the code and checks it is 1 to 10 letters or digits.
+func normalizeProblemCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !problemCodePattern.MatchString(code) {
+		return "", ErrInvalidProblemCode
+	}
+	return code, nil
+}
+
+// normalizeColor upper-cases a #RRGGBB color. An empty color is allowed and means none.
+func normalizeColor(color string) (string, error) {
+	color = strings.ToUpper(strings.TrimSpace(color))
+	if color != "" && !colorPattern.MatchString(color) {
+		return "", ErrInvalidColor
+	}
+	return color, nil
+}
+
+func toContestCaseResponse(cc contestModel.ContestCase) responses.ContestCaseResponse {
+	return responses.ContestCaseResponse{
+		CaseID:   cc.CaseID,
+		CaseCode: cc.ProblemCode,
+		CaseName: cc.Case.Name,
+		Position: cc.Position,
+		Points:   problemPoints(cc),
+		Color:    cc.Color,
+	}
+}
+
+// problemPoints is what the problem is worth; rows always carry points, the fallback only guards a nil.
+func problemPoints(cc contestModel.ContestCase) int {
+	if cc.Points == nil {
+		return defaultProblemPoints
+	}
+	return *cc.Points
+}
+
+// contestLifecycle is where a contest is, and how much work its submissions represent.
+type contestLifecycle struct {
+	status      contestModel.ContestStatus
+	start, end  *time.Time
+	submissions int64
+}
+
+// locked reports whether destructive changes need force.
+func (l contestLifecycle) locked() bool {
+	return l.status == contestModel.ContestRunning || l.submissions > 0
+}
+
+// lifecycle works out the contest's status from its global window, or the windows of its classes and
+// students. Submissions are counted for the whole contest, or for one case when caseID is given.
+func (s *contestServiceImpl) lifecycle(ctx context.Context, contest *contestModel.Contest, caseID *uuid.UUID) (*contestLifecycle, error) {
+	l := &contestLifecycle{}
+	widen := func(start, end time.Time) {
+		if l.start == nil || start.Before(*l.start) {
+			l.start = &start
+		}
+		if l.end == nil || end.After(*l.end) {
+			l.end = &end
+		}
+	}
+
+	if contest.GlobalContestDetail != nil {
+		widen(contest.GlobalContestDetail.StartTime, contest.GlobalContestDetail.EndTime)
+	} else {
+		classContests, err := s.contestRepo.FindClassContestsByContest(ctx, contest.ID)
+		if err != nil {
+			return nil, err
+		}
+		byClass := make(map[uuid.UUID]*contestModel.ClassContest, len(classContests))
+		for i := range classContests {
+			byClass[classContests[i].ClassTransactionID] = &classContests[i]
+			widen(classContests[i].StartTime, classContests[i].EndTime)
+		}
+		overrides, err := s.contestRepo.FindOverridesByContest(ctx, contest.ID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range overrides {
+			if classContest, ok := byClass[overrides[i].ClassTransactionID]; ok {
+				widen(classContest.WindowFor(&overrides[i]))
+			}
+		}
+	}
+	l.status = contestModel.StatusAt(contest.StatusOverride, l.start, l.end, time.Now())
+
+	submissions, err := s.submissionRepo.CountForContest(ctx, contest.ID, caseID)
+	if err != nil {
+		return nil, err
+	}
+	l.submissions = submissions
+	return l, nil
+}
+
+// guardContest refuses destructive changes to a running contest or one with submissions.
+// guardContestByID loads the contest and guards it like guardContest.
+func (s *contestServiceImpl) guardContestByID(ctx context.Context, contestID uuid.UUID, caseID *uuid.UUID) error {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return ErrContestNotFound
+	}
+	return s.guardContest(ctx, contest, caseID)
+}
+
+func (s *contestServiceImpl) guardContest(ctx context.Context, contest *contestModel.Contest, caseID *uuid.UUID) error {
+	l, err := s.lifecycle(ctx, contest, caseID)
+	if err != nil {
+		return err
+	}
+	if l.locked() {
+		return fmt.Errorf("%w (%s is %s with %d submissions)", ErrContestLocked, contest.Name, l.status, l.submissions)
+	}
+	return nil
+}
+
+func (s *contestServiceImpl) GetLifecycle(ctx context.Context, contestID uuid.UUID) (*responses.ContestLifecycleResponse, error) {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return nil, ErrContestNotFound
+	}
+	l, err := s.lifecycle(ctx, contest, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &responses.ContestLifecycleResponse{
+		ContestID:      contest.ID,
+		Status:         string(l.status),
+		StatusOverride: string(contest.StatusOverride),
+		StartTime:      l.start,
+		EndTime:        l.end,
+		Submissions:    l.submissions,
+		Locked:         l.locked(),
+	}, nil
+}
+
+func (s *contestServiceImpl) SetStatusOverride(ctx context.Context, contestID uuid.UUID, req requests.ContestStatusRequest) (*responses.ContestLifecycleResponse, error) {
+	status := contestModel.ContestStatus(req.Status)
+	if status != "" && status != contestModel.ContestDraft && status != contestModel.ContestArchived {
+		return nil, ErrInvalidStatus
+	}
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return nil, ErrContestNotFound
+	}
+	contest.StatusOverride = status
+	if err := s.contestRepo.SaveContest(ctx, contest); err != nil {
+		return nil, fmt.Errorf("failed to update contest: %w", err)
+	}
+	return s.GetLifecycle(ctx, contestID)
+}
+
+func (s *contestServiceImpl) GuardCaseChange(ctx context.Context, caseID uuid.UUID) error {
+	contestIDs, err := s.contestRepo.FindContestIDsByCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	for _, contestID := range contestIDs {
+		contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+		if err != nil {
+			return fmt.Errorf("failed to get contest: %w", err)
+		}
+		if contest == nil {
+			continue // soft deleted
+		}
+		if err := s.guardContest(ctx, contest, &caseID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JoinContest checks the access code and records the grant, so the user is not asked again.
+func (s *contestServiceImpl) JoinContest(ctx context.Context, contestID uuid.UUID, req requests.JoinContestRequest, viewerID uuid.UUID, role user.Role) (*responses.ContestDetailResponse, error) {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil || (contest.Visibility == contestModel.ContestHidden && !isStaff(role)) {
+		return nil, ErrContestNotFound
+	}
+	if contest.AccessCodeHash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(contest.AccessCodeHash), []byte(req.AccessCode)) != nil {
+			return nil, ErrInvalidAccessCode
+		}
+		grant := &contestModel.ContestAccessGrant{ContestID: contest.ID, UserID: viewerID}
+		if err := s.contestRepo.SaveAccessGrant(ctx, grant); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetContestByID(ctx, contestID, viewerID, role)
+}
+
+func (s *contestServiceImpl) AuthorizeContest(ctx context.Context, contestID uuid.UUID, viewerID uuid.UUID, role user.Role) error {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return ErrContestNotFound
+	}
+	_, err = s.access(ctx, contest, viewerID, role)
+	return err
+}
+
+func (s *contestServiceImpl) AuthorizeContestProblems(ctx context.Context, contestID uuid.UUID, viewerID uuid.UUID, role user.Role) (bool, error) {
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if contest == nil {
+		return false, ErrContestNotFound
+	}
+	access, err := s.access(ctx, contest, viewerID, role)
+	if err != nil {
+		return false, err
+	}
+	return access.problemsVisible(time.Now()), nil
+}
+
+func (s *contestServiceImpl) AuthorizeCase(ctx context.Context, caseID uuid.UUID, viewerID uuid.UUID, role user.Role) error {
+	if isStaff(role) {
+		return nil
+	}
+	contestIDs, err := s.contestRepo.FindContestIDsByCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, contestID := range contestIDs {
+		contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+		if err != nil {
+			return fmt.Errorf("failed to get contest: %w", err)
+		}
+		if contest == nil {
+			continue // soft deleted
+		}
+		access, err := s.access(ctx, contest, viewerID, role)
+		if err != nil {
+			if errors.Is(err, ErrContestNotFound) || errors.Is(err, ErrAccessCodeRequired) || errors.Is(err, ErrNotEnrolled) {
+				continue
+			}
+			return err
+		}
+		if access.problemsVisible(now) {
+			return nil
+		}
+	}
+	return ErrCaseHidden
+}
+
+// contestAccess is how much of a contest a viewer who is let in may see.
+type contestAccess struct {
+	staff      bool
+	problemsAt *time.Time // when the problems are released to the viewer; nil for staff
+}
+
+func (a contestAccess) problemsVisible(now time.Time) bool {
+	return a.staff || (a.problemsAt != nil && !now.Before(*a.problemsAt))
+}
+
+// access decides whether the viewer may see the contest. Staff see every contest. Students do not see
+// hidden or draft contests, need a grant for a global contest with an access code, and must be students
+// of a class taking a class contest. Their problems are released when their own window starts.
+func (s *contestServiceImpl) access(ctx context.Context, contest *contestModel.Contest, viewerID uuid.UUID, role user.Role) (*contestAccess, error) {
+	if isStaff(role) {
+		return &contestAccess{staff: true}, nil
+	}
+	if contest.Visibility == contestModel.ContestHidden || contest.StatusOverride == contestModel.ContestDraft {
+		return nil, ErrContestNotFound
+	}
+
+	if contest.GlobalContestDetail != nil {
+		if contest.AccessCodeHash != "" {
+			granted, err := s.contestRepo.HasAccessGrant(ctx, contest.ID, viewerID)
+			if err != nil {
+				return nil, err
+			}
+			if !granted {
+				return nil, ErrAccessCodeRequired
+			}
+		}
+		startTime := contest.GlobalContestDetail.StartTime
+		return &contestAccess{problemsAt: &startTime}, nil
+	}
+
+	classContests, err := s.contestRepo.FindClassContestsByContest(ctx, contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	var problemsAt *time.Time
+	for _, classContest := range classContests {
+		enrolled, err := s.classRepo.IsClassStudent(ctx, classContest.ClassTransactionID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if !enrolled {
+			continue
+		}
+		override, err := s.contestRepo.FindOverride(ctx, classContest.ClassTransactionID, contest.ID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		startTime, _ := classContest.WindowFor(override)
+		if problemsAt == nil || startTime.Before(*problemsAt) {
+			problemsAt = &startTime
+		}
+	}
+	if problemsAt == nil {
+		return nil, ErrNotEnrolled
+	}
+	return &contestAccess{problemsAt: problemsAt}, nil
+}
+
+// isStaff reports whether the role sees and may enter every contest: assistants, lecturers and admins.
+func isStaff(role user.Role) bool {
+	return role.Rank() >= user.RoleAssistant.Rank()
+}
+
+// listed reports whether the contest shows up in lists for the role. Students only get public contests
+// that are out of draft.
+func listed(contest contestModel.Contest, role user.Role) bool {
+	if isStaff(role) {
+		return true
+	}
+	return contest.Visibility != contestModel.ContestHidden && contest.Visibility != contestModel.ContestUnlisted &&
+		contest.StatusOverride != contestModel.ContestDraft
+}
+
+// setAccessCode hashes the access code onto the contest, or removes it when code is empty.
+func setAccessCode(contest *contestModel.Contest, code string) error {
+	if code == "" {
+		contest.AccessCodeHash = ""
+		return nil
+	}
+	if contest.Scope != "global" {
+		return ErrAccessCodeNotGlobal
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash access code: %w", err)
+	}
+	contest.AccessCodeHash = string(hash)
+	return nil
+}
+
+func toContestResponse(contest contestModel.Contest) responses.ContestResponse {
+	return responses.ContestResponse{
+		ID:                 contest.ID,
+		Name:               contest.Name,
+		Scope:              contest.Scope,
+		Description:        contest.Description,
+		CourseID:           contest.CourseID,
+		Visibility:         string(contest.Visibility),
+		CreatedAt:          contest.CreatedAt,
+		UpdatedAt:          contest.UpdatedAt,
+		RequiresAccessCode: contest.AccessCodeHash != "",
+	}
 }
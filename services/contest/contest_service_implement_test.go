@@ -0,0 +1,331 @@
+package contestService
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	classModel "neptune/backend/models/class"
+	contestModel "neptune/backend/models/contest"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	classRepository "neptune/backend/repositories/class"
+	contestRepository "neptune/backend/repositories/contest"
+	submissionRepository "neptune/backend/repositories/submission"
+	"testing"
+	"time"
+)
+
+// overrideRepo holds the contests of one class and the overrides of one student in memory.
+type overrideRepo struct {
+	contestRepository.ContestRepository
+	classContests []contestModel.ClassContest
+	overrides     []contestModel.ClassContestOverride
+}
+
+func (r *overrideRepo) FindContestsByClassTransactionID(ctx context.Context, classTransactionID uuid.UUID) ([]contestModel.ClassContest, error) {
+	return r.classContests, nil
+}
+
+func (r *overrideRepo) FindClassContestsByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContest, error) {
+	return r.classContests, nil
+}
+
+func (r *overrideRepo) FindOverridesByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContestOverride, error) {
+	return r.overrides, nil
+}
+
+func (r *overrideRepo) FindUserOverridesInClass(ctx context.Context, classTransactionID, userID uuid.UUID) ([]contestModel.ClassContestOverride, error) {
+	return r.overrides, nil
+}
+
+// TestContestsForClassUseTheOverrideOfEachContest checks that a student with overrides for several
+// contests of a class, with overlapping windows, gets each override only on its own contest.
+func TestContestsForClassUseTheOverrideOfEachContest(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	classID, studentID := uuid.New(), uuid.New()
+	quiz, exam, lab := uuid.New(), uuid.New(), uuid.New()
+	classContest := func(contestID uuid.UUID, start, end int) contestModel.ClassContest {
+		return contestModel.ClassContest{
+			ClassTransactionID: classID,
+			ContestID:          contestID,
+			StartTime:          at(start),
+			EndTime:            at(end),
+			Contest:            contestModel.Contest{ID: contestID, Visibility: contestModel.ContestPublic},
+		}
+	}
+	repo := &overrideRepo{
+		classContests: []contestModel.ClassContest{classContest(quiz, 9, 10), classContest(exam, 9, 12), classContest(lab, 13, 15)},
+		overrides: []contestModel.ClassContestOverride{
+			{ClassTransactionID: classID, ContestID: quiz, UserID: studentID, StartTime: ptr(at(11))},
+			{ClassTransactionID: classID, ContestID: exam, UserID: studentID, StartTime: ptr(at(10)), ExtraMinutes: 60},
+		},
+	}
+	s := &contestServiceImpl{contestRepo: repo}
+
+	resp, err := s.GetContestsForClass(context.Background(), classID, studentID, user.RoleStudent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uuid.UUID]struct {
+		start, end time.Time
+		personal   bool
+	}{
+		quiz: {at(11), at(12), true},
+		exam: {at(10), at(14), true},
+		lab:  {at(13), at(15), false},
+	}
+	if len(resp) != len(want) {
+		t.Fatalf("got %d contests, want %d", len(resp), len(want))
+	}
+	for _, got := range resp {
+		w := want[got.ContestID]
+		if !got.StartTime.Equal(w.start) || !got.EndTime.Equal(w.end) || got.PersonalWindow != w.personal {
+			t.Errorf("contest %s: window %s - %s personal %v, want %s - %s personal %v", got.ContestID,
+				got.StartTime.Format("15:04"), got.EndTime.Format("15:04"), got.PersonalWindow,
+				w.start.Format("15:04"), w.end.Format("15:04"), w.personal)
+		}
+	}
+}
+
+// submissionCounter reports a fixed number of submissions for any contest.
+type submissionCounter struct {
+	submissionRepository.SubmissionRepository
+	count int64
+}
+
+func (c submissionCounter) CountForContest(ctx context.Context, contestID uuid.UUID, caseID *uuid.UUID) (int64, error) {
+	return c.count, nil
+}
+
+func TestLifecycleSpansEveryWindow(t *testing.T) {
+	now := time.Now()
+	hours := func(h int) time.Time { return now.Add(time.Duration(h) * time.Hour) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	classA, classB, unassigned := uuid.New(), uuid.New(), uuid.New()
+	windows := []contestModel.ClassContest{
+		{ClassTransactionID: classA, StartTime: hours(-5), EndTime: hours(-4)},
+		{ClassTransactionID: classB, StartTime: hours(-3), EndTime: hours(-1)},
+	}
+
+	tests := []struct {
+		name        string
+		contest     contestModel.Contest
+		classes     []contestModel.ClassContest
+		overrides   []contestModel.ClassContestOverride
+		submissions int64
+		want        contestModel.ContestStatus
+		locked      bool
+	}{
+		{
+			name:    "every class window is over",
+			classes: windows,
+			want:    contestModel.ContestEnded,
+		},
+		{
+			name:      "a late student keeps it running",
+			classes:   windows,
+			overrides: []contestModel.ClassContestOverride{{ClassTransactionID: classB, StartTime: ptr(hours(-1))}},
+			want:      contestModel.ContestRunning,
+			locked:    true,
+		},
+		{
+			name:      "extra time keeps it running",
+			classes:   windows,
+			overrides: []contestModel.ClassContestOverride{{ClassTransactionID: classA, ExtraMinutes: 5 * 60}},
+			want:      contestModel.ContestRunning,
+			locked:    true,
+		},
+		{
+			name:      "overrides of unassigned classes are ignored",
+			classes:   windows,
+			overrides: []contestModel.ClassContestOverride{{ClassTransactionID: unassigned, EndTime: ptr(hours(1))}},
+			want:      contestModel.ContestEnded,
+		},
+		{
+			name:      "an early student starts it",
+			classes:   []contestModel.ClassContest{{ClassTransactionID: classA, StartTime: hours(1), EndTime: hours(2)}},
+			overrides: []contestModel.ClassContestOverride{{ClassTransactionID: classA, StartTime: ptr(hours(-1))}},
+			want:      contestModel.ContestRunning,
+			locked:    true,
+		},
+		{
+			name:    "scheduled without submissions",
+			classes: []contestModel.ClassContest{{ClassTransactionID: classA, StartTime: hours(1), EndTime: hours(2)}},
+			want:    contestModel.ContestScheduled,
+		},
+		{
+			name:        "ended with submissions",
+			classes:     windows,
+			submissions: 3,
+			want:        contestModel.ContestEnded,
+			locked:      true,
+		},
+		{
+			name: "no class yet",
+			want: contestModel.ContestDraft,
+		},
+		{
+			name:    "archived",
+			contest: contestModel.Contest{StatusOverride: contestModel.ContestArchived},
+			classes: windows,
+			want:    contestModel.ContestArchived,
+		},
+		{
+			name:    "global window",
+			contest: contestModel.Contest{GlobalContestDetail: &contestModel.GlobalContestDetail{StartTime: hours(-1), EndTime: hours(1)}},
+			classes: windows,
+			want:    contestModel.ContestRunning,
+			locked:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &contestServiceImpl{
+				contestRepo:    &overrideRepo{classContests: tt.classes, overrides: tt.overrides},
+				submissionRepo: submissionCounter{count: tt.submissions},
+			}
+			l, err := s.lifecycle(context.Background(), &tt.contest, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.status != tt.want || l.locked() != tt.locked {
+				t.Errorf("status %s locked %v, want %s locked %v", l.status, l.locked(), tt.want, tt.locked)
+			}
+		})
+	}
+}
+
+// globalContestRepo holds one global contest in memory.
+type globalContestRepo struct {
+	contestRepository.ContestRepository
+	contest *contestModel.Contest
+}
+
+func (r *globalContestRepo) FindContestByID(ctx context.Context, contestID uuid.UUID) (*contestModel.Contest, error) {
+	return r.contest, nil
+}
+
+func (r *globalContestRepo) FindVirtualParticipation(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID) (*contestModel.VirtualParticipation, error) {
+	return nil, nil
+}
+
+func TestAdmitSubmissionOnlyTakesCasesOfTheContest(t *testing.T) {
+	now := time.Now()
+	running := &contestModel.GlobalContestDetail{StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)}
+	upcoming := &contestModel.GlobalContestDetail{StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)}
+	problem, otherProblem := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		window  *contestModel.GlobalContestDetail
+		caseID  uuid.UUID
+		role    user.Role
+		wantErr error
+	}{
+		{"problem of the running contest", running, problem, user.RoleStudent, nil},
+		{"case of another contest", running, otherProblem, user.RoleStudent, ErrCaseNotInContest},
+		{"staff cannot either", upcoming, otherProblem, user.RoleLecturer, ErrCaseNotInContest},
+		{"problems not released yet", upcoming, problem, user.RoleStudent, ErrContestNotOpen},
+		{"staff before the start", upcoming, problem, user.RoleLecturer, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contest := &contestModel.Contest{
+				ID:                  uuid.New(),
+				Visibility:          contestModel.ContestPublic,
+				GlobalContestDetail: tt.window,
+				ContestCases:        []contestModel.ContestCase{{CaseID: problem}},
+			}
+			s := &contestServiceImpl{contestRepo: &globalContestRepo{contest: contest}}
+			_, err := s.AdmitSubmission(context.Background(), contest.ID, tt.caseID, nil, uuid.New(), tt.role, "")
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// assignmentRepo holds the class assignments of one contest in memory.
+type assignmentRepo struct {
+	contestRepository.ContestRepository
+	classContests []contestModel.ClassContest
+	saved         []uuid.UUID
+}
+
+func (r *assignmentRepo) FindContestByID(ctx context.Context, contestID uuid.UUID) (*contestModel.Contest, error) {
+	return &contestModel.Contest{ID: contestID}, nil
+}
+
+func (r *assignmentRepo) FindClassContestsByContest(ctx context.Context, contestID uuid.UUID) ([]contestModel.ClassContest, error) {
+	return r.classContests, nil
+}
+
+func (r *assignmentRepo) AssignContestToClass(ctx context.Context, classContest *contestModel.ClassContest) error {
+	r.saved = append(r.saved, classContest.ClassTransactionID)
+	return nil
+}
+
+// anyClass finds every class it is asked for.
+type anyClass struct {
+	classRepository.ClassRepository
+}
+
+func (anyClass) FindClassByTransactionID(ctx context.Context, classTransactionID string) (*classModel.Class, error) {
+	return &classModel.Class{}, nil
+}
+
+// noTransaction runs the function directly.
+type noTransaction struct{}
+
+func (noTransaction) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestBulkAssignReportsAndGuardsExistingClasses(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	start, end := now.Add(24*time.Hour), now.Add(26*time.Hour)
+	newClass, sameClass, futureClass, runningClass := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	assigned := []contestModel.ClassContest{
+		{ClassTransactionID: sameClass, StartTime: start, EndTime: end},
+		{ClassTransactionID: futureClass, StartTime: now.Add(time.Hour), EndTime: now.Add(3 * time.Hour)},
+		{ClassTransactionID: runningClass, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		classes []uuid.UUID
+		force   bool
+		want    []string
+		saved   int
+		locked  bool
+	}{
+		{"new, unchanged and moved classes", []uuid.UUID{newClass, sameClass, futureClass}, false,
+			[]string{responses.AssignmentCreated, responses.AssignmentUnchanged, responses.AssignmentUpdated}, 2, false},
+		{"a class that already started", []uuid.UUID{newClass, runningClass}, false, nil, 0, true},
+		{"forced", []uuid.UUID{runningClass}, true, []string{responses.AssignmentUpdated}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &assignmentRepo{classContests: assigned}
+			s := &contestServiceImpl{contestRepo: repo, classRepo: anyClass{}, txManager: noTransaction{}}
+			req := requests.BulkAssignContestRequest{ClassTransactionIDs: tt.classes, StartTime: start, EndTime: end}
+			resp, err := s.BulkAssignContest(context.Background(), uuid.New(), req, tt.force)
+			if tt.locked != errors.Is(err, ErrContestLocked) || (!tt.locked && err != nil) {
+				t.Fatalf("err = %v, locked want %v", err, tt.locked)
+			}
+			if len(repo.saved) != tt.saved {
+				t.Errorf("saved %d assignments, want %d", len(repo.saved), tt.saved)
+			}
+			for i, want := range tt.want {
+				if resp[i].Assignment != want {
+					t.Errorf("class %d is %q, want %q", i, resp[i].Assignment, want)
+				}
+				if (want == responses.AssignmentUpdated) != (resp[i].PreviousStartTime != nil) {
+					t.Errorf("class %d reports previous window %v", i, resp[i].PreviousStartTime)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package courseServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+)
+
+var (
+	ErrCourseNotFound  = errors.New("course not found")
+	ErrCourseConflict  = errors.New("a course with this course outline ID or code already exists")
+	ErrInvalidSemester = errors.New("unknown semester")
+)
+
+type CourseService interface {
+	CreateCourse(ctx context.Context, req requests.CourseRequest) (*responses.CourseResponse, error)
+	UpdateCourse(ctx context.Context, courseID uuid.UUID, req requests.CourseRequest) (*responses.CourseResponse, error)
+	DeleteCourse(ctx context.Context, courseID uuid.UUID) error
+	GetCourseByID(ctx context.Context, courseID uuid.UUID) (*responses.CourseResponse, error)
+	GetCourses(ctx context.Context, activeOnly bool) ([]responses.CourseResponse, error)
+	// SeedDefaultCourses creates the courses that used to be hard-coded when the catalogue is empty.
+	SeedDefaultCourses(ctx context.Context) error
+}
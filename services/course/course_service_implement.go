@@ -0,0 +1,185 @@
+package courseServ
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"neptune/backend/messier/constants"
+	courseModel "neptune/backend/models/course"
+	"neptune/backend/pkg/database"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	courseRepo "neptune/backend/repositories/course"
+	internalSemesterRepo "neptune/backend/repositories/semester"
+	"strings"
+)
+
+// defaultCourses seed an empty catalogue with the courses the backend was built for.
+var defaultCourses = []courseModel.Course{
+	{CourseOutlineID: uuid.MustParse(constants.AlgoprogID1), Code: "COMP6047001", Name: "Algorithm and Programming", Active: true},
+	{CourseOutlineID: uuid.MustParse(constants.AlgoprogID2), Code: "COMP6878051", Name: "Algorithm and Programming", Active: true},
+}
+
+type courseService struct {
+	courseRepo   courseRepo.CourseRepository
+	semesterRepo internalSemesterRepo.SemesterRepository
+	txManager    database.TransactionManager
+}
+
+func NewCourseService(courseRepo courseRepo.CourseRepository, semesterRepo internalSemesterRepo.SemesterRepository, txManager database.TransactionManager) CourseService {
+	return &courseService{
+		courseRepo:   courseRepo,
+		semesterRepo: semesterRepo,
+		txManager:    txManager,
+	}
+}
+
+func (s *courseService) CreateCourse(ctx context.Context, req requests.CourseRequest) (*responses.CourseResponse, error) {
+	course := &courseModel.Course{ID: uuid.New()}
+	if err := s.applyRequest(ctx, course, req); err != nil {
+		return nil, err
+	}
+	if err := s.saveCourse(ctx, course); err != nil {
+		return nil, err
+	}
+	resp := toCourseResponse(*course)
+	return &resp, nil
+}
+
+func (s *courseService) UpdateCourse(ctx context.Context, courseID uuid.UUID, req requests.CourseRequest) (*responses.CourseResponse, error) {
+	course, err := s.courseRepo.FindCourseByID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, ErrCourseNotFound
+	}
+	if err := s.applyRequest(ctx, course, req); err != nil {
+		return nil, err
+	}
+	if err := s.saveCourse(ctx, course); err != nil {
+		return nil, err
+	}
+	resp := toCourseResponse(*course)
+	return &resp, nil
+}
+
+// applyRequest validates req and copies it onto course. The course outline ID and code must stay unique.
+func (s *courseService) applyRequest(ctx context.Context, course *courseModel.Course, req requests.CourseRequest) error {
+	outlineID, err := uuid.Parse(req.CourseOutlineID)
+	if err != nil {
+		return fmt.Errorf("invalid course outline ID %s: %w", req.CourseOutlineID, err)
+	}
+	code := strings.TrimSpace(req.Code)
+
+	if existing, err := s.courseRepo.FindCourseByOutlineID(ctx, outlineID); err != nil {
+		return err
+	} else if existing != nil && existing.ID != course.ID {
+		return ErrCourseConflict
+	}
+	if existing, err := s.courseRepo.FindCourseByCode(ctx, code); err != nil {
+		return err
+	} else if existing != nil && existing.ID != course.ID {
+		return ErrCourseConflict
+	}
+
+	semesters, err := s.semesterRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load semesters: %w", err)
+	}
+	known := make(map[string]bool, len(semesters))
+	for _, sem := range semesters {
+		known[sem.ID] = true
+	}
+	course.Semesters = course.Semesters[:0]
+	for _, semesterID := range req.SemesterIDs {
+		if !known[semesterID] {
+			return fmt.Errorf("%w: %s", ErrInvalidSemester, semesterID)
+		}
+		course.Semesters = append(course.Semesters, courseModel.CourseSemester{CourseID: course.ID, SemesterID: semesterID})
+	}
+
+	course.CourseOutlineID = outlineID
+	course.Code = code
+	course.Name = strings.TrimSpace(req.Name)
+	course.Active = req.Active == nil || *req.Active
+	return nil
+}
+
+func (s *courseService) DeleteCourse(ctx context.Context, courseID uuid.UUID) error {
+	course, err := s.courseRepo.FindCourseByID(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	if course == nil {
+		return ErrCourseNotFound
+	}
+	return s.courseRepo.DeleteCourse(ctx, courseID)
+}
+
+func (s *courseService) GetCourseByID(ctx context.Context, courseID uuid.UUID) (*responses.CourseResponse, error) {
+	course, err := s.courseRepo.FindCourseByID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, ErrCourseNotFound
+	}
+	resp := toCourseResponse(*course)
+	return &resp, nil
+}
+
+func (s *courseService) GetCourses(ctx context.Context, activeOnly bool) ([]responses.CourseResponse, error) {
+	courses, err := s.courseRepo.FindAllCourses(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]responses.CourseResponse, 0, len(courses))
+	for _, course := range courses {
+		resp = append(resp, toCourseResponse(course))
+	}
+	return resp, nil
+}
+
+func (s *courseService) SeedDefaultCourses(ctx context.Context) error {
+	count, err := s.courseRepo.CountCourses(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	for _, course := range defaultCourses {
+		course.ID = uuid.New()
+		if err := s.saveCourse(ctx, &course); err != nil {
+			return err
+		}
+		log.Printf("Seeded course %s (%s)", course.Code, course.CourseOutlineID)
+	}
+	return nil
+}
+
+// saveCourse saves the course together with its semesters, so a failure cannot leave it without them.
+func (s *courseService) saveCourse(ctx context.Context, course *courseModel.Course) error {
+	return s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		return s.courseRepo.SaveCourse(txCtx, course)
+	})
+}
+
+func toCourseResponse(course courseModel.Course) responses.CourseResponse {
+	semesterIDs := make([]string, 0, len(course.Semesters))
+	for _, sem := range course.Semesters {
+		semesterIDs = append(semesterIDs, sem.SemesterID)
+	}
+	return responses.CourseResponse{
+		ID:              course.ID,
+		CourseOutlineID: course.CourseOutlineID,
+		Code:            course.Code,
+		Name:            course.Name,
+		Active:          course.Active,
+		SemesterIDs:     semesterIDs,
+		CreatedAt:       course.CreatedAt,
+		UpdatedAt:       course.UpdatedAt,
+	}
+}
@@ -0,0 +1,64 @@
+package fileServ
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"neptune/backend/models/user"
+	"time"
+)
+
+var (
+	ErrFileNotFound  = errors.New("file not found")
+	ErrFileForbidden = errors.New("not allowed to access this file")
+)
+
+// TestCaseFileKind selects the input or expected output of a testcase.
+type TestCaseFileKind string
+
+const (
+	TestCaseFileInput  TestCaseFileKind = "input"
+	TestCaseFileOutput TestCaseFileKind = "output"
+)
+
+// Viewer is the authenticated user asking for a file.
+type Viewer struct {
+	UserID uuid.UUID
+	Role   user.Role
+}
+
+// NewViewer builds a Viewer from the user_id and role set by the auth middleware.
+func NewViewer(userID string, role string) (Viewer, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return Viewer{}, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+	return Viewer{UserID: id, Role: user.Role(role)}, nil
+}
+
+// FileDownload is an open file ready to be streamed to the client. The caller must close Content.
+type FileDownload struct {
+	Content     io.ReadCloser
+	ContentType string
+	FileName    string
+}
+
+// FileService opens protected files from the blob store and issues short-lived signed links to them.
+// Authorize* methods must be called before opening a file on behalf of a viewer; requests arriving with a
+// valid signed link were authorised when the link was issued.
+type FileService interface {
+	AuthorizeSubmissionSource(ctx context.Context, submissionID uuid.UUID, viewer Viewer) error
+	// AuthorizeCaseFile lets staff read the PDF and attachments of any case, and students only those of
+	// problems released to them in a contest they can see.
+	AuthorizeCaseFile(ctx context.Context, caseID uuid.UUID, viewer Viewer) error
+
+	OpenCasePDF(ctx context.Context, caseID uuid.UUID) (*FileDownload, error)
+	OpenCaseAttachment(ctx context.Context, caseID, attachmentID uuid.UUID) (*FileDownload, error)
+	OpenTestCaseFile(ctx context.Context, caseID uuid.UUID, number int, kind TestCaseFileKind) (*FileDownload, error)
+	OpenSubmissionSource(ctx context.Context, submissionID uuid.UUID) (*FileDownload, error)
+
+	// SignPath returns a signed link to path (e.g. "/files/cases/<id>/pdf") and when it expires.
+	SignPath(path string) (string, time.Time)
+}
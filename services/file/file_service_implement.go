@@ -0,0 +1,195 @@
+package fileServ
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"mime"
+	"neptune/backend/models/user"
+	"neptune/backend/pkg/storage"
+	"neptune/backend/pkg/utils"
+	caseRepository "neptune/backend/repositories/case"
+	internalClassRepo "neptune/backend/repositories/class"
+	roleRepo "neptune/backend/repositories/role"
+	submissionRepo "neptune/backend/repositories/submission"
+	testCaseRepo "neptune/backend/repositories/test_case"
+	contestService "neptune/backend/services/contest"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+const defaultSignedURLTTL = 5 * time.Minute
+
+type fileServiceImpl struct {
+	blobStore      storage.BlobStore
+	caseRepo       caseRepository.CaseRepository
+	testCaseRepo   testCaseRepo.TestCaseRepository
+	submissionRepo submissionRepo.SubmissionRepository
+	classRepo      internalClassRepo.ClassRepository
+	roleRepo       roleRepo.RoleRepository
+	contestService contestService.ContestService
+	signedURLTTL   time.Duration
+}
+
+func NewFileService(blobStore storage.BlobStore,
+	caseRepo caseRepository.CaseRepository,
+	testCaseRepo testCaseRepo.TestCaseRepository,
+	submissionRepo submissionRepo.SubmissionRepository,
+	classRepo internalClassRepo.ClassRepository,
+	roleRepo roleRepo.RoleRepository,
+	contestService contestService.ContestService) FileService {
+	ttl := defaultSignedURLTTL
+	if raw := os.Getenv("FILE_URL_TTL_SECONDS"); raw != "" {
+		if seconds, err := strconv.Atoi(raw); err == nil && seconds > 0 {
+			ttl = time.Duration(seconds) * time.Second
+		} else {
+			log.Printf("Invalid value %q for FILE_URL_TTL_SECONDS, using default %s", raw, ttl)
+		}
+	}
+	return &fileServiceImpl{
+		blobStore:      blobStore,
+		caseRepo:       caseRepo,
+		testCaseRepo:   testCaseRepo,
+		submissionRepo: submissionRepo,
+		classRepo:      classRepo,
+		roleRepo:       roleRepo,
+		contestService: contestService,
+		signedURLTTL:   ttl,
+	}
+}
+
+// AuthorizeSubmissionSource lets the author, admins and the assistants and lecturers of the submission's
+// class read it.
+func (s *fileServiceImpl) AuthorizeSubmissionSource(ctx context.Context, submissionID uuid.UUID, viewer Viewer) error {
+	submission, err := s.submissionRepo.FindByID(ctx, submissionID.String())
+	if err != nil {
+		return fmt.Errorf("%w: submission %s", ErrFileNotFound, submissionID)
+	}
+
+	if submission.UserID == viewer.UserID || viewer.Role == user.RoleAdmin {
+		return nil
+	}
+	if (viewer.Role == user.RoleAssistant || viewer.Role == user.RoleLecturer) && submission.ClassTransactionID != nil {
+		isAssistant, err := s.classRepo.IsClassAssistant(ctx, *submission.ClassTransactionID, viewer.UserID)
+		if err != nil {
+			return err
+		}
+		if isAssistant {
+			return nil
+		}
+	}
+	if viewer.Role == user.RoleLecturer && submission.ClassTransactionID != nil {
+		isLecturer, err := s.roleRepo.HasClassRole(ctx, viewer.UserID, user.RoleLecturer, *submission.ClassTransactionID)
+		if err != nil {
+			return err
+		}
+		if isLecturer {
+			return nil
+		}
+	}
+	return ErrFileForbidden
+}
+
+func (s *fileServiceImpl) AuthorizeCaseFile(ctx context.Context, caseID uuid.UUID, viewer Viewer) error {
+	err := s.contestService.AuthorizeCase(ctx, caseID, viewer.UserID, viewer.Role)
+	if errors.Is(err, contestService.ErrCaseHidden) {
+		return fmt.Errorf("%w: %v", ErrFileForbidden, err)
+	}
+	return err
+}
+
+func (s *fileServiceImpl) OpenCasePDF(ctx context.Context, caseID uuid.UUID) (*FileDownload, error) {
+	problemCase, err := s.caseRepo.FindCaseByID(ctx, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find case %s: %w", caseID, err)
+	}
+	if problemCase == nil || problemCase.PDFFileUrl == "" {
+		return nil, fmt.Errorf("%w: no statement for case %s", ErrFileNotFound, caseID)
+	}
+	return s.open(ctx, problemCase.PDFFileUrl, "application/pdf", problemCase.Name+".pdf")
+}
+
+func (s *fileServiceImpl) OpenCaseAttachment(ctx context.Context, caseID, attachmentID uuid.UUID) (*FileDownload, error) {
+	attachment, err := s.caseRepo.FindAttachment(ctx, caseID, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find attachment %s of case %s: %w", attachmentID, caseID, err)
+	}
+	if attachment == nil {
+		return nil, fmt.Errorf("%w: attachment %s of case %s", ErrFileNotFound, attachmentID, caseID)
+	}
+	return s.open(ctx, attachment.FileUrl, attachment.ContentType, attachment.FileName)
+}
+
+func (s *fileServiceImpl) OpenTestCaseFile(ctx context.Context, caseID uuid.UUID, number int, kind TestCaseFileKind) (*FileDownload, error) {
+	testCase, err := s.testCaseRepo.FindTestCaseByCaseIDAndNumber(ctx, caseID, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find testcase %d of case %s: %w", number, caseID, err)
+	}
+	if testCase == nil {
+		return nil, fmt.Errorf("%w: testcase %d of case %s", ErrFileNotFound, number, caseID)
+	}
+
+	switch kind {
+	case TestCaseFileInput:
+		return s.open(ctx, testCase.InputUrl, "text/plain; charset=utf-8", path.Base(testCase.InputUrl))
+	case TestCaseFileOutput:
+		return s.open(ctx, testCase.OutputUrl, "text/plain; charset=utf-8", path.Base(testCase.OutputUrl))
+	default:
+		return nil, fmt.Errorf("%w: unknown testcase file kind %q", ErrFileNotFound, kind)
+	}
+}
+
+func (s *fileServiceImpl) OpenSubmissionSource(ctx context.Context, submissionID uuid.UUID) (*FileDownload, error) {
+	submission, err := s.submissionRepo.FindByID(ctx, submissionID.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: submission %s", ErrFileNotFound, submissionID)
+	}
+	return s.open(ctx, submission.SourceCodePath, SourceContentType(submission.SourceCodePath), path.Base(submission.SourceCodePath))
+}
+
+func (s *fileServiceImpl) SignPath(urlPath string) (string, time.Time) {
+	expiresAt := time.Now().Add(s.signedURLTTL)
+	return utils.SignURLPath(urlPath, expiresAt), expiresAt
+}
+
+func (s *fileServiceImpl) open(ctx context.Context, storedPath, contentType, fileName string) (*FileDownload, error) {
+	rc, err := s.blobStore.Get(ctx, storage.KeyFromURL(storedPath))
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, fileName)
+		}
+		return nil, err
+	}
+	return &FileDownload{Content: rc, ContentType: contentType, FileName: fileName}, nil
+}
+
+// SourceContentType picks a text content type for a source file so browsers render it instead of
+// executing or sniffing it.
+func SourceContentType(filePath string) string {
+	switch path.Ext(filePath) {
+	case ".py":
+		return "text/x-python; charset=utf-8"
+	case ".go":
+		return "text/x-go; charset=utf-8"
+	case ".c", ".h":
+		return "text/x-c; charset=utf-8"
+	case ".cpp", ".cc", ".hpp":
+		return "text/x-c++; charset=utf-8"
+	case ".java":
+		return "text/x-java; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// ContentDisposition builds an inline or attachment Content-Disposition header with a safely quoted name.
+func ContentDisposition(disposition, fileName string) string {
+	if header := mime.FormatMediaType(disposition, map[string]string{"filename": fileName}); header != "" {
+		return header
+	}
+	return disposition
+}
@@ -2,16 +2,50 @@ package internal_class
 
 import (
 	"context"
+	"fmt"
 	"neptune/backend/pkg/responses"
 )
 
 type ClassService interface {
 	SyncClasses(ctx context.Context, semesterID string, requestMakerID string) error
-	SyncClassStudents(ctx context.Context, semesterID string, courseOutlineID string, requestMakerID string) error
-	SyncClassAssistants(ctx context.Context, semesterID string, courseOutlineID string, requestMakerID string) error
+	SyncClassStudents(ctx context.Context, semesterID string, courseOutlineID string, requestMakerID string, opts RosterSyncOptions) (SyncOutcome, error)
+	SyncClassAssistants(ctx context.Context, semesterID string, courseOutlineID string, requestMakerID string, opts RosterSyncOptions) (SyncOutcome, error)
+
+	// The *WithToken variants are used by the sync scheduler, which brings its own Messier token and needs
+	// per class results to retry failures.
+	SyncClassesWithToken(ctx context.Context, semesterID string, messierToken string) (SyncOutcome, error)
+	SyncClassStudentsWithToken(ctx context.Context, semesterID string, courseOutlineID string, messierToken string, opts RosterSyncOptions) (SyncOutcome, error)
+	SyncClassAssistantsWithToken(ctx context.Context, semesterID string, courseOutlineID string, messierToken string, opts RosterSyncOptions) (SyncOutcome, error)
 
 	GetClassesBySemesterAndCourse(ctx context.Context, semesterID string, courseID string) ([]responses.GetClassWithoutDetailResponse, error)
 	GetClassDetailBySemesterAndCourse(ctx context.Context, semesterID string, courseID string) ([]responses.GetDetailClassResponse, error)
 	GetClassDetailBySemesterCourseAndStudent(ctx context.Context, semesterID, courseID, userID string) ([]responses.GetDetailClassResponse, error)
 	GetClassDetailByTransactionID(ctx context.Context, classTransactionID string) (*responses.GetDetailClassResponse, error) // For future CRUD}
+	GetRosterChanges(ctx context.Context, classTransactionID string, limit, offset int) (*responses.RosterChangeListResponse, error)
+}
+
+// RosterSyncOptions controls how student and assistant rosters are synced.
+type RosterSyncOptions struct {
+	OnlyClassIDs []string // When not empty only these classes are synced, e.g. to retry failures
+	DryRun       bool     // Compute the diffs without changing anything
+	Force        bool     // Apply diffs even when they remove more members than the removal guard allows
+}
+
+// SyncOutcome summarises one sync step. Synced counts classes, students or assistants written depending
+// on the step; FailedClassIDs lists the classes worth retrying.
+type SyncOutcome struct {
+	Processed      int
+	Synced         int
+	FailedClassIDs []string
+	Errors         []string
+	Diffs          []responses.RosterDiffResponse // Roster steps only
+}
+
+func (o *SyncOutcome) addError(message string) {
+	o.Errors = append(o.Errors, message)
+}
+
+func (o *SyncOutcome) addClassFailure(classTransactionID, classCode string, err error) {
+	o.FailedClassIDs = append(o.FailedClassIDs, classTransactionID)
+	o.addError(fmt.Sprintf("class %s (%s): %v", classCode, classTransactionID, err))
 }
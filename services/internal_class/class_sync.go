@@ -0,0 +1,233 @@
+package internal_class
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"neptune/backend/messier"
+	models "neptune/backend/models/class"
+	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/utils"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+var assistantGenerationRegex = regexp.MustCompile(`[A-Z]{2}(\d{2}-\d{1})`)
+
+func (c classService) SyncClasses(ctx context.Context, semesterID string, requestMakerID string) error {
+	authToken, err := utils.GetAndValidateMessierToken(ctx, requestMakerID, c.messierTokenRepo)
+	if err != nil {
+		return err
+	}
+	_, err = c.SyncClassesWithToken(ctx, semesterID, authToken)
+	return err
+}
+
+func (c classService) SyncClassStudents(ctx context.Context, semesterID string, courseOutlineID string, requestMakerID string, opts RosterSyncOptions) (SyncOutcome, error) {
+	messierAccessToken, err := utils.GetAndValidateMessierToken(ctx, requestMakerID, c.messierTokenRepo)
+	if err != nil {
+		return SyncOutcome{}, err
+	}
+	return c.SyncClassStudentsWithToken(ctx, semesterID, courseOutlineID, messierAccessToken, opts)
+}
+
+// SyncClassAssistants : Please make sure to sync the student first
+func (c classService) SyncClassAssistants(ctx context.Context, semesterID string, courseOutlineID, requestMakerID string, opts RosterSyncOptions) (SyncOutcome, error) {
+	authToken, err := utils.GetAndValidateMessierToken(ctx, requestMakerID, c.messierTokenRepo)
+	if err != nil {
+		return SyncOutcome{}, fmt.Errorf("failed to get and validate Messier token: %w", err)
+	}
+	return c.SyncClassAssistantsWithToken(ctx, semesterID, courseOutlineID, authToken, opts)
+}
+
+// SyncClassesWithToken mirrors the classes of every active catalogue course running in the semester.
+func (c classService) SyncClassesWithToken(ctx context.Context, semesterID string, messierToken string) (SyncOutcome, error) {
+	var outcome SyncOutcome
+	semId, err := uuid.Parse(semesterID)
+	if err != nil {
+		return outcome, fmt.Errorf("invalid semester ID %s: %w", semesterID, err)
+	}
+
+	courses, err := c.courseRepo.FindActiveCoursesForSemester(ctx, semesterID)
+	if err != nil {
+		return outcome, err
+	}
+	for _, course := range courses {
+		courseID := course.CourseOutlineID.String()
+		log.Printf("Starting basic class sync for Semester: %s, CourseOutline: %s", semesterID, courseID)
+
+		basicClasses, err := c.messierClassSrv.GetClassesBySemesterAndCourseOutline(ctx, semesterID, courseID, messierToken)
+		if err != nil {
+			log.Printf("Error fetching basic classes for semester %s, course %s: %v", semesterID, courseID, err)
+			if errors.Is(err, messier.ErrUnauthorized) {
+				return outcome, err
+			}
+			outcome.addError(fmt.Sprintf("course %s: %v", courseID, err))
+			continue
+		}
+
+		for _, bc := range basicClasses {
+			outcome.Processed++
+			class := &models.Class{
+				ClassTransactionID: bc.ClassTransactionID,
+				SemesterID:         semId,
+				CourseOutlineID:    bc.CourseOutlineID,
+				ClassCode:          bc.ClassCode,
+			}
+			if err := c.classRepo.SaveClass(ctx, class); err != nil {
+				log.Printf("Error saving basic class %s (%s): %v", bc.ClassTransactionID, bc.ClassCode, err)
+				outcome.addClassFailure(bc.ClassTransactionID.String(), bc.ClassCode, err)
+				continue
+			}
+			outcome.Synced++
+		}
+	}
+	log.Printf("Successfully synced %d basic classes for semester %s.", outcome.Synced, semesterID)
+	return outcome, nil
+}
+
+// SyncClassStudentsWithToken brings the student roster of each class in line with Messier, applying
+// only the differences. See RosterSyncOptions for dry runs, retries and the removal guard.
+func (c classService) SyncClassStudentsWithToken(ctx context.Context, semesterID string, courseOutlineID string, messierToken string, opts RosterSyncOptions) (SyncOutcome, error) {
+	var outcome SyncOutcome
+
+	// 1. Get all basic classes from internal DB that need student syncing
+	classes, err := c.classesToSync(ctx, semesterID, courseOutlineID, opts.OnlyClassIDs)
+	if err != nil {
+		return outcome, fmt.Errorf("failed to retrieve basic classes for student sync: %w", err)
+	}
+
+	for _, cl := range classes {
+		outcome.Processed++
+		diff, err := c.syncStudentsForClass(ctx, cl, messierToken, opts)
+		if diff != nil {
+			outcome.Diffs = append(outcome.Diffs, *diff)
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to sync students for class %s: %v", cl.ClassTransactionID, err)
+			outcome.addClassFailure(cl.ClassTransactionID.String(), cl.ClassCode, err)
+			// Every other class would fail the same way with a rejected token.
+			if errors.Is(err, messier.ErrUnauthorized) {
+				return outcome, err
+			}
+			continue
+		}
+		outcome.Synced += len(diff.Added) + len(diff.Renamed) + diff.Unchanged
+	}
+	log.Printf("Successfully synced students for %d classes. Total students synced: %d", len(classes), outcome.Synced)
+	return outcome, nil
+}
+
+// SyncClassAssistantsWithToken brings the assistants of each class in line with Messier. Students must be
+// synced first, because assistants are looked up through a student of the class.
+func (c classService) SyncClassAssistantsWithToken(ctx context.Context, semesterID string, courseOutlineID string, messierToken string, opts RosterSyncOptions) (SyncOutcome, error) {
+	var outcome SyncOutcome
+
+	classes, err := c.classesToSync(ctx, semesterID, courseOutlineID, opts.OnlyClassIDs)
+	if err != nil {
+		return outcome, fmt.Errorf("failed to retrieve basic classes for assistant sync: %w", err)
+	}
+
+	for _, cl := range classes {
+		outcome.Processed++
+		diff, err := c.syncAssistantsForClass(ctx, cl, semesterID, messierToken, opts)
+		if diff != nil {
+			outcome.Diffs = append(outcome.Diffs, *diff)
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to sync assistants for class %s: %v", cl.ClassTransactionID, err)
+			outcome.addClassFailure(cl.ClassTransactionID.String(), cl.ClassCode, err)
+			// Every other class would fail the same way with a rejected token.
+			if errors.Is(err, messier.ErrUnauthorized) {
+				return outcome, err
+			}
+			continue
+		}
+		outcome.Synced += len(diff.Added) + len(diff.Renamed) + diff.Unchanged
+	}
+	log.Printf("Successfully synced assistants for %d classes. Total assistants synced: %d", len(classes), outcome.Synced)
+	return outcome, nil
+}
+
+func (c classService) classesToSync(ctx context.Context, semesterID, courseOutlineID string, onlyClassIDs []string) ([]models.Class, error) {
+	classes, err := c.classRepo.FindClassBasicInfoBySemesterAndCourse(ctx, semesterID, courseOutlineID)
+	if err != nil || len(onlyClassIDs) == 0 {
+		return classes, err
+	}
+
+	wanted := make(map[string]bool, len(onlyClassIDs))
+	for _, id := range onlyClassIDs {
+		wanted[id] = true
+	}
+	filtered := make([]models.Class, 0, len(onlyClassIDs))
+	for _, cl := range classes {
+		if wanted[cl.ClassTransactionID.String()] {
+			filtered = append(filtered, cl)
+		}
+	}
+	return filtered, nil
+}
+
+func (c classService) syncStudentsForClass(ctx context.Context, cl models.Class, messierToken string, opts RosterSyncOptions) (*responses.RosterDiffResponse, error) {
+	log.Printf("Syncing students for Class: %s (Name: %s)", cl.ClassTransactionID, cl.ClassCode)
+
+	// Fetch students from Messier
+	messierStudents, err := c.messierClassSrv.GetStudentFromClassTransaction(ctx, cl.SemesterID.String(), cl.CourseOutlineID.String(), cl.ClassCode, messierToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch students: %w", err)
+	}
+
+	desired := make([]rosterMember, 0, len(messierStudents))
+	for _, ms := range messierStudents {
+		desired = append(desired, rosterMember{UserID: ms.BinusianID, Username: ms.NIM, Name: ms.Name})
+	}
+	return c.syncRoster(ctx, cl, models.RosterMemberStudent, desired, opts)
+}
+
+func (c classService) syncAssistantsForClass(ctx context.Context, cl models.Class, semesterID string, messierToken string, opts RosterSyncOptions) (*responses.RosterDiffResponse, error) {
+	log.Printf("Syncing assistants for Class: %s (Name: %s)", cl.ClassTransactionID, cl.ClassCode)
+
+	// Fetch initial assistants from Messier
+	firstStudent, err := c.classRepo.FindFirstStudentByClassTransactionID(ctx, cl.ClassTransactionID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find first student: %w", err)
+	}
+	if firstStudent == nil {
+		return nil, fmt.Errorf("class has no students yet, sync students first")
+	}
+
+	assistants, err := c.messierClassSrv.GetAssistantInitialFromStudentTransaction(ctx, firstStudent.User.Username, semesterID, messierToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch assistant initials through student %s: %w", firstStudent.User.Username, err)
+	}
+
+	var classAssistantInitials []string
+	for _, assistant := range assistants {
+		if assistant.ClassTransactionID == cl.ClassTransactionID {
+			classAssistantInitials = assistant.Assistants
+			break
+		}
+	}
+
+	desired := make([]rosterMember, 0, len(classAssistantInitials))
+	log.Printf("Processing %d assistant initials for class %s: %v", len(classAssistantInitials), cl.ClassCode, classAssistantInitials)
+	for _, initial := range classAssistantInitials {
+		matches := assistantGenerationRegex.FindStringSubmatch(initial)
+		if len(matches) < 2 {
+			log.Printf("Warning: Could not extract generation from assistant initial %s for class %s. Skipping.", initial, cl.ClassTransactionID)
+			continue
+		}
+		generation := matches[1]
+
+		// 2. Fetch detailed assistant info. A missing detail would look like a removal, so the class
+		// fails instead and is retried.
+		assistantDetail, err := c.messierClassSrv.GetAssistantDetailFromAssistantInitial(ctx, initial, generation, messierToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch detail for assistant %s (%s): %w", initial, generation, err)
+		}
+		desired = append(desired, rosterMember{UserID: assistantDetail.UserID, Username: assistantDetail.Username, Name: assistantDetail.Name})
+	}
+	return c.syncRoster(ctx, cl, models.RosterMemberAssistant, desired, opts)
+}
@@ -0,0 +1,321 @@
+package internal_class
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	models "neptune/backend/models/class"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/responses"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRosterRemovalGuard is returned when a sync would remove a larger share of a roster than
+// ROSTER_MAX_REMOVAL_RATIO allows, which usually means Messier returned a partial list.
+var ErrRosterRemovalGuard = errors.New("roster sync would remove too many members")
+
+const defaultMaxRosterRemovalRatio = 0.5
+
+// rosterMember is a student or assistant as listed by Messier.
+type rosterMember struct {
+	UserID   uuid.UUID
+	Username string
+	Name     string
+}
+
+type rosterRename struct {
+	User    model.User
+	NewName string
+}
+
+// rosterDiff is what has to change for a class roster to match Messier. Members are matched by username.
+type rosterDiff struct {
+	Added     []rosterMember
+	Removed   []model.User
+	Renamed   []rosterRename
+	Unchanged int
+}
+
+func diffRoster(current []model.User, desired []rosterMember) rosterDiff {
+	var diff rosterDiff
+	currentByUsername := make(map[string]model.User, len(current))
+	for _, u := range current {
+		currentByUsername[u.Username] = u
+	}
+
+	seen := make(map[string]bool, len(desired))
+	for _, member := range desired {
+		if seen[member.Username] {
+			continue
+		}
+		seen[member.Username] = true
+
+		existing, ok := currentByUsername[member.Username]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, member)
+		case member.Name != "" && existing.Name != member.Name:
+			diff.Renamed = append(diff.Renamed, rosterRename{User: existing, NewName: member.Name})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, u := range current {
+		if !seen[u.Username] {
+			diff.Removed = append(diff.Removed, u)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Username < diff.Added[j].Username })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Username < diff.Removed[j].Username })
+	sort.Slice(diff.Renamed, func(i, j int) bool { return diff.Renamed[i].User.Username < diff.Renamed[j].User.Username })
+	return diff
+}
+
+func (d rosterDiff) isEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0
+}
+
+// size is the number of members the roster has once the diff is applied.
+func (d rosterDiff) size() int {
+	return d.Unchanged + len(d.Renamed) + len(d.Added)
+}
+
+func (d rosterDiff) toResponse(cl models.Class, memberType string) responses.RosterDiffResponse {
+	resp := responses.RosterDiffResponse{
+		ClassTransactionID: cl.ClassTransactionID.String(),
+		ClassCode:          cl.ClassCode,
+		MemberType:         memberType,
+		Added:              make([]responses.RosterMemberResponse, 0, len(d.Added)),
+		Removed:            make([]responses.RosterMemberResponse, 0, len(d.Removed)),
+		Renamed:            make([]responses.RosterRenameResponse, 0, len(d.Renamed)),
+		Unchanged:          d.Unchanged,
+	}
+	for _, m := range d.Added {
+		resp.Added = append(resp.Added, responses.RosterMemberResponse{UserID: m.UserID.String(), Username: m.Username, Name: m.Name})
+	}
+	for _, u := range d.Removed {
+		resp.Removed = append(resp.Removed, responses.RosterMemberResponse{UserID: u.ID.String(), Username: u.Username, Name: u.Name})
+	}
+	for _, r := range d.Renamed {
+		resp.Renamed = append(resp.Renamed, responses.RosterRenameResponse{
+			UserID:   r.User.ID.String(),
+			Username: r.User.Username,
+			OldName:  r.User.Name,
+			NewName:  r.NewName,
+		})
+	}
+	return resp
+}
+
+// syncRoster diffs the roster of a class against the members listed by Messier and, unless this is a dry
+// run, applies the diff and its change log in one transaction. The returned diff is set even on error.
+func (c classService) syncRoster(ctx context.Context, cl models.Class, memberType string, desired []rosterMember, opts RosterSyncOptions) (*responses.RosterDiffResponse, error) {
+	current, err := c.currentRoster(ctx, cl, memberType)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffRoster(current, desired)
+	resp := diff.toResponse(cl, memberType)
+
+	if ratio := maxRosterRemovalRatio(); !opts.Force && len(current) > 0 && float64(len(diff.Removed))/float64(len(current)) > ratio {
+		err := fmt.Errorf("%w: %d of %d %ss would be removed (limit %.0f%%), rerun with force to apply",
+			ErrRosterRemovalGuard, len(diff.Removed), len(current), memberType, ratio*100)
+		resp.Error = err.Error()
+		return &resp, err
+	}
+	if opts.DryRun {
+		return &resp, nil
+	}
+	if diff.isEmpty() {
+		resp.Applied = true
+		return &resp, nil
+	}
+
+	if err := c.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		return c.applyRosterDiff(ctx, cl, memberType, diff)
+	}); err != nil {
+		resp.Error = err.Error()
+		return &resp, err
+	}
+	resp.Applied = true
+	log.Printf("Roster of class %s (%ss): %d added, %d removed, %d renamed", cl.ClassCode, memberType, len(diff.Added), len(diff.Removed), len(diff.Renamed))
+	return &resp, nil
+}
+
+func (c classService) currentRoster(ctx context.Context, cl models.Class, memberType string) ([]model.User, error) {
+	var users []model.User
+	if memberType == models.RosterMemberAssistant {
+		assistants, err := c.classRepo.FindClassAssistants(ctx, cl.ClassTransactionID.String())
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range assistants {
+			users = append(users, a.User)
+		}
+		return users, nil
+	}
+
+	students, err := c.classRepo.FindClassStudents(ctx, cl.ClassTransactionID.String())
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range students {
+		users = append(users, s.User)
+	}
+	return users, nil
+}
+
+func (c classService) applyRosterDiff(ctx context.Context, cl models.Class, memberType string, diff rosterDiff) error {
+	role := model.RoleStudent
+	if memberType == models.RosterMemberAssistant {
+		role = model.RoleAssistant
+	}
+
+	batchID := uuid.New()
+	now := time.Now()
+	change := func(changeType models.RosterChangeType, userID uuid.UUID, username, oldName, newName string) models.RosterChange {
+		return models.RosterChange{
+			ID:                 uuid.New(),
+			BatchID:            batchID,
+			ClassTransactionID: cl.ClassTransactionID,
+			MemberType:         memberType,
+			Change:             changeType,
+			UserID:             userID,
+			Username:           username,
+			OldName:            oldName,
+			NewName:            newName,
+			CreatedAt:          now,
+		}
+	}
+
+	var changes []models.RosterChange
+	addedIDs := make([]uuid.UUID, 0, len(diff.Added))
+	for _, member := range diff.Added {
+		user, err := c.ensureRosterUser(ctx, member, role)
+		if err != nil {
+			return err
+		}
+		addedIDs = append(addedIDs, user.ID)
+		changes = append(changes, change(models.RosterChangeAdded, user.ID, user.Username, "", user.Name))
+	}
+
+	for _, rename := range diff.Renamed {
+		user := rename.User
+		user.Name = rename.NewName
+		if err := c.userRepo.UpdateUser(ctx, &user); err != nil {
+			return fmt.Errorf("failed to rename user %s: %w", user.Username, err)
+		}
+		changes = append(changes, change(models.RosterChangeRenamed, user.ID, user.Username, rename.User.Name, rename.NewName))
+	}
+
+	removedIDs := make([]uuid.UUID, 0, len(diff.Removed))
+	for _, user := range diff.Removed {
+		removedIDs = append(removedIDs, user.ID)
+		changes = append(changes, change(models.RosterChangeRemoved, user.ID, user.Username, user.Name, ""))
+	}
+
+	classID := cl.ClassTransactionID.String()
+	if memberType == models.RosterMemberAssistant {
+		if err := c.classRepo.RemoveClassAssistants(ctx, classID, removedIDs); err != nil {
+			return fmt.Errorf("failed to remove assistants: %w", err)
+		}
+		if err := c.classRepo.AddClassAssistants(ctx, classID, addedIDs); err != nil {
+			return fmt.Errorf("failed to add assistants: %w", err)
+		}
+	} else {
+		if err := c.classRepo.RemoveClassStudents(ctx, classID, removedIDs); err != nil {
+			return fmt.Errorf("failed to remove students: %w", err)
+		}
+		if err := c.classRepo.AddClassStudents(ctx, classID, addedIDs); err != nil {
+			return fmt.Errorf("failed to add students: %w", err)
+		}
+	}
+
+	if err := c.classRepo.SaveRosterChanges(ctx, changes); err != nil {
+		return fmt.Errorf("failed to save roster change log: %w", err)
+	}
+	return nil
+}
+
+// ensureRosterUser returns the user of a member joining a class, creating it on first sight.
+func (c classService) ensureRosterUser(ctx context.Context, member rosterMember, role model.Role) (*model.User, error) {
+	user, err := c.userRepo.GetUserByUsername(ctx, member.Username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user %s: %w", member.Username, err)
+	}
+
+	if user == nil {
+		user = &model.User{
+			ID:        member.UserID,
+			Username:  member.Username,
+			Name:      member.Name,
+			Role:      role,
+			CreatedAt: time.Now(),
+		}
+		if err := c.userRepo.CreateUser(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to create user %s: %w", member.Username, err)
+		}
+		log.Printf("Created new %s user: %s (%s)", role, user.Username, user.Name)
+		return user, nil
+	}
+
+	if user.Name != member.Name || user.Role != role {
+		user.Name = member.Name
+		user.Role = role
+		if err := c.userRepo.UpdateUser(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to update user %s: %w", member.Username, err)
+		}
+	}
+	return user, nil
+}
+
+func (c classService) GetRosterChanges(ctx context.Context, classTransactionID string, limit, offset int) (*responses.RosterChangeListResponse, error) {
+	changes, total, err := c.classRepo.FindRosterChanges(ctx, classTransactionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.RosterChangeListResponse{
+		Changes: make([]responses.RosterChangeResponse, 0, len(changes)),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for _, ch := range changes {
+		resp.Changes = append(resp.Changes, responses.RosterChangeResponse{
+			ID:                 ch.ID.String(),
+			BatchID:            ch.BatchID.String(),
+			ClassTransactionID: ch.ClassTransactionID.String(),
+			MemberType:         ch.MemberType,
+			Change:             string(ch.Change),
+			UserID:             ch.UserID.String(),
+			Username:           ch.Username,
+			OldName:            ch.OldName,
+			NewName:            ch.NewName,
+			CreatedAt:          ch.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+// maxRosterRemovalRatio is the largest share of a roster one sync may remove without force.
+func maxRosterRemovalRatio() float64 {
+	raw := os.Getenv("ROSTER_MAX_REMOVAL_RATIO")
+	if raw == "" {
+		return defaultMaxRosterRemovalRatio
+	}
+	ratio, err := strconv.ParseFloat(raw, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		log.Printf("Invalid ROSTER_MAX_REMOVAL_RATIO=%q, using default %.2f", raw, defaultMaxRosterRemovalRatio)
+		return defaultMaxRosterRemovalRatio
+	}
+	return ratio
+}
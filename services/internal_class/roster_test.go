@@ -0,0 +1,277 @@
+package internal_class
+
+import (
+	"context"
+	"errors"
+	models "neptune/backend/models/class"
+	model "neptune/backend/models/user"
+	classRepository "neptune/backend/repositories/class"
+	userRepository "neptune/backend/repositories/user"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func rosterUsers(names ...string) []model.User {
+	users := make([]model.User, 0, len(names))
+	for _, name := range names {
+		username, display, _ := strings.Cut(name, "=")
+		users = append(users, model.User{ID: uuid.New(), Username: username, Name: display})
+	}
+	return users
+}
+
+func rosterMembers(names ...string) []rosterMember {
+	members := make([]rosterMember, 0, len(names))
+	for _, name := range names {
+		username, display, _ := strings.Cut(name, "=")
+		members = append(members, rosterMember{UserID: uuid.New(), Username: username, Name: display})
+	}
+	return members
+}
+
+func TestDiffRoster(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   []model.User
+		desired   []rosterMember
+		added     []string
+		removed   []string
+		renamed   []string
+		unchanged int
+	}{
+		{
+			name:    "empty class",
+			desired: rosterMembers("b=B", "a=A"),
+			added:   []string{"a", "b"},
+		},
+		{
+			name:    "everyone left",
+			current: rosterUsers("a=A", "b=B"),
+			removed: []string{"a", "b"},
+		},
+		{
+			name:      "same roster",
+			current:   rosterUsers("a=A", "b=B"),
+			desired:   rosterMembers("b=B", "a=A"),
+			unchanged: 2,
+		},
+		{
+			name:      "mixed",
+			current:   rosterUsers("a=A", "b=B", "c=C"),
+			desired:   rosterMembers("a=A", "c=Charlie", "d=D"),
+			added:     []string{"d"},
+			removed:   []string{"b"},
+			renamed:   []string{"c"},
+			unchanged: 1,
+		},
+		{
+			name:      "duplicate usernames from Messier count once",
+			current:   rosterUsers("a=A"),
+			desired:   rosterMembers("a=A", "a=Other", "b=B", "b=B"),
+			added:     []string{"b"},
+			unchanged: 1,
+		},
+		{
+			name:      "a missing name is not a rename",
+			current:   rosterUsers("a=A"),
+			desired:   rosterMembers("a="),
+			unchanged: 1,
+		},
+		{
+			name:    "usernames are case sensitive",
+			current: rosterUsers("ab24-1=A"),
+			desired: rosterMembers("AB24-1=A"),
+			added:   []string{"AB24-1"},
+			removed: []string{"ab24-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffRoster(tt.current, tt.desired)
+			var added, removed, renamed []string
+			for _, m := range diff.Added {
+				added = append(added, m.Username)
+			}
+			for _, u := range diff.Removed {
+				removed = append(removed, u.Username)
+			}
+			for _, r := range diff.Renamed {
+				renamed = append(renamed, r.User.Username)
+			}
+			if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(removed, tt.removed) ||
+				!reflect.DeepEqual(renamed, tt.renamed) || diff.Unchanged != tt.unchanged {
+				t.Errorf("diff = added %v, removed %v, renamed %v, unchanged %d; want %v, %v, %v, %d",
+					added, removed, renamed, diff.Unchanged, tt.added, tt.removed, tt.renamed, tt.unchanged)
+			}
+			if want := len(tt.added) + len(tt.renamed) + tt.unchanged; diff.size() != want {
+				t.Errorf("size = %d, want %d", diff.size(), want)
+			}
+		})
+	}
+}
+
+func TestMaxRosterRemovalRatio(t *testing.T) {
+	for raw, want := range map[string]float64{
+		"":     defaultMaxRosterRemovalRatio,
+		"0.25": 0.25,
+		"0":    0,
+		"1":    1,
+		"1.5":  defaultMaxRosterRemovalRatio,
+		"-0.1": defaultMaxRosterRemovalRatio,
+		"half": defaultMaxRosterRemovalRatio,
+	} {
+		t.Setenv("ROSTER_MAX_REMOVAL_RATIO", raw)
+		if got := maxRosterRemovalRatio(); got != want {
+			t.Errorf("ROSTER_MAX_REMOVAL_RATIO=%q gives %v, want %v", raw, got, want)
+		}
+	}
+}
+
+// rosterRepo is a class repository holding one class in memory; the roster code uses no other method.
+type rosterRepo struct {
+	classRepository.ClassRepository
+	students       []model.User
+	added, removed []uuid.UUID
+	changes        []models.RosterChange
+}
+
+func (r *rosterRepo) FindClassStudents(ctx context.Context, classTransactionID string) ([]models.ClassStudent, error) {
+	students := make([]models.ClassStudent, 0, len(r.students))
+	for _, u := range r.students {
+		students = append(students, models.ClassStudent{UserID: u.ID, User: u})
+	}
+	return students, nil
+}
+
+func (r *rosterRepo) AddClassStudents(ctx context.Context, classTransactionID string, ids []uuid.UUID) error {
+	r.added = append(r.added, ids...)
+	return nil
+}
+
+func (r *rosterRepo) RemoveClassStudents(ctx context.Context, classTransactionID string, ids []uuid.UUID) error {
+	r.removed = append(r.removed, ids...)
+	return nil
+}
+
+func (r *rosterRepo) SaveRosterChanges(ctx context.Context, changes []models.RosterChange) error {
+	r.changes = append(r.changes, changes...)
+	return nil
+}
+
+// rosterUserRepo keeps users by username.
+type rosterUserRepo struct {
+	userRepository.UserRepository
+	users map[string]*model.User
+}
+
+func (r *rosterUserRepo) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	if u, ok := r.users[username]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *rosterUserRepo) CreateUser(ctx context.Context, user *model.User) error {
+	r.users[user.Username] = user
+	return nil
+}
+
+func (r *rosterUserRepo) UpdateUser(ctx context.Context, user *model.User) error {
+	copied := *user
+	r.users[user.Username] = &copied
+	return nil
+}
+
+func TestRosterRemovalGuardBoundary(t *testing.T) {
+	tests := []struct {
+		name    string
+		ratio   string
+		current int
+		kept    int
+		force   bool
+		guarded bool
+	}{
+		{"half removed at the limit", "0.5", 4, 2, false, false},
+		{"more than half removed", "0.5", 4, 1, false, true},
+		{"forced", "0.5", 4, 0, true, false},
+		{"one of three under a third", "0.34", 3, 2, false, false},
+		{"zero allows no removal", "0", 3, 2, false, true},
+		{"one allows emptying the class", "1", 3, 0, false, false},
+		{"empty class is never guarded", "0", 0, 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ROSTER_MAX_REMOVAL_RATIO", tt.ratio)
+			var names []string
+			for i := 0; i < tt.current; i++ {
+				names = append(names, string(rune('a'+i)))
+			}
+			repo := &rosterRepo{students: rosterUsers(names...)}
+			c := classService{classRepo: repo}
+
+			resp, err := c.syncRoster(context.Background(), models.Class{ClassCode: "LA01"}, models.RosterMemberStudent,
+				rosterMembers(names[:tt.kept]...), RosterSyncOptions{DryRun: true, Force: tt.force})
+			if tt.guarded != errors.Is(err, ErrRosterRemovalGuard) {
+				t.Fatalf("err = %v, guarded want %v", err, tt.guarded)
+			}
+			if len(resp.Removed) != tt.current-tt.kept {
+				t.Errorf("diff removes %d, want %d", len(resp.Removed), tt.current-tt.kept)
+			}
+			if tt.guarded && resp.Error == "" {
+				t.Error("the guarded diff does not say why")
+			}
+		})
+	}
+}
+
+func TestApplyRosterDiff(t *testing.T) {
+	current := rosterUsers("stay=Stay", "rename=Old", "leave=Leave")
+	existing := &model.User{ID: uuid.New(), Username: "back", Name: "Back", Role: model.RoleAssistant}
+	users := &rosterUserRepo{users: map[string]*model.User{"back": existing}}
+	for i := range current {
+		users.users[current[i].Username] = &current[i]
+	}
+	repo := &rosterRepo{}
+	c := classService{classRepo: repo, userRepo: users}
+
+	diff := diffRoster(current, rosterMembers("stay=Stay", "rename=New", "new=New Student", "back=Back"))
+	classID := uuid.New()
+	if err := c.applyRosterDiff(context.Background(), models.Class{ClassTransactionID: classID}, models.RosterMemberStudent, diff); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.removed) != 1 || repo.removed[0] != current[2].ID {
+		t.Errorf("removed %v, want only %s", repo.removed, current[2].ID)
+	}
+	if len(repo.added) != 2 || repo.added[0] != existing.ID || repo.added[1] != users.users["new"].ID {
+		t.Errorf("added %v, want the existing user and the new one", repo.added)
+	}
+	if users.users["new"] == nil || users.users["new"].Role != model.RoleStudent {
+		t.Error("the new member was not created as a student")
+	}
+	if users.users["back"].Role != model.RoleStudent {
+		t.Errorf("a returning member keeps role %s, want student", users.users["back"].Role)
+	}
+	if users.users["rename"].Name != "New" {
+		t.Errorf("renamed member is called %q, want New", users.users["rename"].Name)
+	}
+
+	counts := map[models.RosterChangeType]int{}
+	batch := repo.changes[0].BatchID
+	for _, change := range repo.changes {
+		counts[change.Change]++
+		if change.BatchID != batch || change.ClassTransactionID != classID || change.MemberType != models.RosterMemberStudent {
+			t.Errorf("change %+v is not in the batch of this sync", change)
+		}
+		if change.Change == models.RosterChangeRenamed && (change.OldName != "Old" || change.NewName != "New") {
+			t.Errorf("rename logged as %q -> %q, want Old -> New", change.OldName, change.NewName)
+		}
+	}
+	if counts[models.RosterChangeAdded] != 2 || counts[models.RosterChangeRemoved] != 1 || counts[models.RosterChangeRenamed] != 1 {
+		t.Errorf("change log counts = %v, want 2 added, 1 removed, 1 renamed", counts)
+	}
+}
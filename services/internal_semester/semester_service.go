@@ -7,6 +7,7 @@ import (
 
 type SemesterService interface {
 	SyncSemester(ctx context.Context, requestMakerID string) error
+	SyncSemesterWithToken(ctx context.Context, messierAccessToken string) (int, error)
 	GetInternalSemesters(ctx context.Context) ([]responses.SemesterResponse, error)
 	GetCurrentSemester(ctx context.Context) (*responses.SemesterResponse, error)
 }
@@ -7,6 +7,7 @@ import (
 	messierSemester "neptune/backend/messier/semester"
 	model "neptune/backend/models/semester"
 	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/utils"
 	messierTokenRepo "neptune/backend/repositories/messier_token"
 	"neptune/backend/repositories/semester"
 	"time"
@@ -21,39 +22,32 @@ type semesterService struct {
 func (s *semesterService) SyncSemester(ctx context.Context, requestMakerID string) error {
 	log.Printf("Starting semester sync for user: %s", requestMakerID)
 
-	adminToken, err := s.messierTokenRepository.GetMessierTokenByUserID(ctx, requestMakerID)
+	adminToken, err := utils.GetAndValidateMessierToken(ctx, requestMakerID, s.messierTokenRepository)
 	if err != nil {
-		log.Printf("Failed to get admin token for user %s: %v", requestMakerID, err)
-		return fmt.Errorf("failed to get admin token: %w", err)
-	}
-
-	log.Printf("Found admin token for user %s", requestMakerID)
-
-	if adminToken == nil || adminToken.MessierAccessToken == "" {
-		log.Printf("Admin token not found or empty for user ID: %s", requestMakerID)
-		return fmt.Errorf("admin token not found or empty for user ID: %s", requestMakerID)
-	}
-
-	if adminToken.MessierTokenExpires.Before(time.Now()) {
-		log.Printf("Admin token has expired for user ID: %s", requestMakerID)
-		return fmt.Errorf("admin token has expired for user ID: %s", requestMakerID)
+		log.Printf("No usable Messier token for user %s: %v", requestMakerID, err)
+		return err
 	}
 
 	log.Printf("Admin token is valid, fetching semesters from external API")
 
-	messierAccessToken := adminToken.MessierAccessToken
+	_, err = s.SyncSemesterWithToken(ctx, adminToken)
+	return err
+}
 
+// SyncSemesterWithToken mirrors all Messier semesters using the given token and returns how many were saved.
+func (s *semesterService) SyncSemesterWithToken(ctx context.Context, messierAccessToken string) (int, error) {
 	externalSemesters, err := s.externalSemesterService.GetSemesters(ctx, messierAccessToken)
 	if err != nil {
 		log.Printf("Failed to get external semesters: %v", err)
-		return fmt.Errorf("failed to get external semesters: %w", err)
+		return 0, fmt.Errorf("failed to get external semesters: %w", err)
 	}
 
 	log.Printf("Successfully fetched %d semesters from external API", len(externalSemesters))
 
+	saved := 0
 	for _, ms := range externalSemesters {
 		if ms.Start.IsZero() { // Check if the parsed Start time is its zero value
-			return fmt.Errorf("received an invalid (zero) Start time for semester ID %s, which is unexpected", ms.SemesterID)
+			return saved, fmt.Errorf("received an invalid (zero) Start time for semester ID %s, which is unexpected", ms.SemesterID)
 		}
 		var endTimePtr *time.Time
 		if !ms.End.IsZero() { // If the parsed End time is NOT its zero value
@@ -73,11 +67,12 @@ func (s *semesterService) SyncSemester(ctx context.Context, requestMakerID strin
 			// You might choose to return an error here or continue processing
 		} else {
 			log.Printf("Successfully saved semester: ID=%s, Description=%s", sem.ID, sem.Description)
+			saved++
 		}
 	}
 
 	log.Printf("Successfully synced %d semesters from Messier API.", len(externalSemesters))
-	return nil
+	return saved, nil
 }
 
 func (s *semesterService) GetInternalSemesters(ctx context.Context) ([]responses.SemesterResponse, error) {
@@ -1,5 +1,10 @@
 package judgeServ
 
+import (
+	"context"
+	"io"
+)
+
 type Judge0Result struct {
 	Stdout        string `json:"stdout"`
 	Stderr        string `json:"stderr"`
@@ -13,5 +18,6 @@ type Judge0Result struct {
 }
 
 type Judge0Client interface {
-	SubmitCode(sourceCode, stdin string, languageID int) (*Judge0Result, error)
+	// SubmitCode runs the source on stdin, which is streamed into the request rather than read up front.
+	SubmitCode(ctx context.Context, sourceCode string, stdin io.Reader, languageID int) (*Judge0Result, error)
 }
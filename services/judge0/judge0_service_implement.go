@@ -3,6 +3,7 @@ package judgeServ
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,26 +18,34 @@ type judge0ClientImpl struct {
 	httpClient *http.Client
 }
 
-func (c judge0ClientImpl) SubmitCode(sourceCode, stdin string, languageID int) (*Judge0Result, error) {
+func (c judge0ClientImpl) SubmitCode(ctx context.Context, sourceCode string, stdin io.Reader, languageID int) (*Judge0Result, error) {
 	if c.apiURL == "" {
 		return nil, fmt.Errorf("JUDGE0_API_URL environment variable not set")
 	}
 
+	// Text travels base64 encoded both ways, so input and output that is not valid UTF-8 survives the JSON
 	reqBody := requests.Judge0SubmissionRequest{
-		SourceCode: sourceCode,
+		SourceCode: base64.StdEncoding.EncodeToString([]byte(sourceCode)),
 		LanguageID: languageID,
-		Stdin:      stdin,
 	}
 
-	bodyBytes, err := json.Marshal(reqBody)
+	head, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal Judge0 request body: %w", err)
 	}
 
+	// The body is written while it is sent, so the testcase input goes from the blob store to Judge0
+	// without being held in memory
+	body, bodyWriter := io.Pipe()
+	go func() {
+		bodyWriter.CloseWithError(writeSubmissionBody(bodyWriter, head, stdin))
+	}()
+	defer body.Close()
+
 	// We add ?wait=true to get the result synchronously
-	reqURL := fmt.Sprintf("%s/submissions?wait=true&base64_encoded=false", c.apiURL)
+	reqURL := fmt.Sprintf("%s/submissions?wait=true&base64_encoded=true", c.apiURL)
 
-	req, err := http.NewRequestWithContext(context.Background(), "POST", reqURL, bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Judge0 request: %w", err)
 	}
@@ -57,10 +66,36 @@ func (c judge0ClientImpl) SubmitCode(sourceCode, stdin string, languageID int) (
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode Judge0 response: %w", err)
 	}
+	for _, field := range []*string{&result.Stdout, &result.Stderr, &result.CompileOutput} {
+		// Judge0 breaks its base64 into lines, which the decoder skips
+		decoded, err := base64.StdEncoding.DecodeString(*field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Judge0 output: %w", err)
+		}
+		*field = string(decoded)
+	}
 
 	return &result, nil
 }
 
+// writeSubmissionBody writes the marshalled fields in head followed by stdin, base64 encoded, as a JSON
+// string. The base64 alphabet needs no escaping inside JSON.
+func writeSubmissionBody(w io.Writer, head []byte, stdin io.Reader) error {
+	head = bytes.TrimSuffix(head, []byte("}"))
+	if _, err := w.Write(append(head, `,"stdin":"`...)); err != nil {
+		return err
+	}
+	encoder := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(encoder, stdin); err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, `"}`)
+	return err
+}
+
 func NewJudge0Client() Judge0Client {
 	return &judge0ClientImpl{
 		apiURL: os.Getenv("JUDGE0_API_URL"), // e.g., "http://localhost:2358"
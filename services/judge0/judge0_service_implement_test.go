@@ -0,0 +1,73 @@
+package judgeServ
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmitCodeStreamsStdin(t *testing.T) {
+	// Latin-1 and raw bytes are not valid UTF-8 and must still arrive unchanged
+	stdin := "1 2\n\"quoted\" \\ tab\t\x01 ünïcode caf\xe9 \xff\xfe\r\n" + strings.Repeat("9", 1<<20)
+	var got map[string]any
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		// Judge0 breaks its base64 output into lines of 60 characters
+		w.Write([]byte(`{"stdout":"Mwo=\n","stderr":"","compile_output":null,"time":"0.01","memory":128,"status":{"id":3,"description":"Accepted"}}`))
+	}))
+	defer server.Close()
+
+	client := judge0ClientImpl{apiURL: server.URL, httpClient: &http.Client{Timeout: 5 * time.Second}}
+	// Reads through a small-chunk reader so encoding is checked across chunk boundaries
+	result, err := client.SubmitCode(context.Background(), "print(sum)", &chunkReader{s: stdin, n: 7}, 71)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stdout != "3\n" || result.Status.ID != 3 {
+		t.Errorf("result = %+v", result)
+	}
+	if !strings.Contains(query, "base64_encoded=true") {
+		t.Errorf("query %q does not ask for base64", query)
+	}
+	if decoded := decodeField(t, got["stdin"]); decoded != stdin {
+		t.Errorf("stdin did not arrive unchanged")
+	}
+	if decodeField(t, got["source_code"]) != "print(sum)" || got["language_id"] != float64(71) {
+		t.Errorf("body = source_code %v, language_id %v", got["source_code"], got["language_id"])
+	}
+}
+
+func decodeField(t *testing.T, field any) string {
+	t.Helper()
+	s, _ := field.(string)
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("field %.20q is not base64: %v", s, err)
+	}
+	return string(decoded)
+}
+
+// chunkReader returns s at most n bytes at a time.
+type chunkReader struct {
+	s string
+	n int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.s == "" {
+		return 0, io.EOF
+	}
+	n := copy(p[:min(len(p), r.n)], r.s)
+	r.s = r.s[n:]
+	return n, nil
+}
@@ -2,10 +2,11 @@ package leaderboardServ
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	contestModel "neptune/backend/models/contest"
 	leaderboardModel "neptune/backend/models/leaderboard"
 	submissionModel "neptune/backend/models/submission"
-	"neptune/backend/models/user"
 	"neptune/backend/repositories/class"
 	contestRepository "neptune/backend/repositories/contest"
 	submissionRepo "neptune/backend/repositories/submission"
@@ -21,6 +22,21 @@ const penaltyPerWrongAttempt = 20
 type Service interface {
 	GetContestLeaderboard(ctx context.Context, classID, contestID uuid.UUID) ([]leaderboardModel.LeaderboardRow, error)
 	GetGlobalContestLeaderboard(ctx context.Context, contestID uuid.UUID) ([]leaderboardModel.LeaderboardRow, error)
+	// GetVirtualStanding ranks the user's virtual run against the official standings as they were the
+	// same time into the contest.
+	GetVirtualStanding(ctx context.Context, participationID, userID uuid.UUID) (*VirtualStanding, error)
+}
+
+var ErrVirtualParticipationNotFound = errors.New("virtual participation not found")
+
+// VirtualStanding is where a virtual participant would have ranked in the official contest.
+type VirtualStanding struct {
+	ParticipationID uuid.UUID                         `json:"participation_id"`
+	ContestID       uuid.UUID                         `json:"contest_id"`
+	Rank            int                               `json:"rank"`
+	ElapsedMinutes  int                               `json:"elapsed_minutes"`
+	Finished        bool                              `json:"finished"`
+	Leaderboard     []leaderboardModel.LeaderboardRow `json:"leaderboard"`
 }
 
 type serviceImpl struct {
@@ -49,7 +65,9 @@ func (s *serviceImpl) GetContestLeaderboard(ctx context.Context, classID, contes
 	if err != nil {
 		return nil, fmt.Errorf("could not find contest assignment for this class: %w", err)
 	}
-	contestStartTime := classContest.StartTime
+	if classContest == nil {
+		return nil, fmt.Errorf("contest %s is not assigned to class %s", contestID, classID)
+	}
 
 	contestCases, err := s.contestRepo.FindContestCases(ctx, contestID)
 	if err != nil {
@@ -59,193 +77,227 @@ func (s *serviceImpl) GetContestLeaderboard(ctx context.Context, classID, contes
 		return []leaderboardModel.LeaderboardRow{}, nil // Return empty leaderboard if no problems
 	}
 
-	classInfo, err := s.classRepo.FindClassByTransactionID(ctx, classID.String())
+	leaderboardRows, err := s.classContestRows(ctx, classContest, contestCases, 0)
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch class details: %w", err)
+		return nil, err
 	}
+	rankRows(leaderboardRows)
+	return leaderboardRows, nil
+}
 
-	// Step 2: Aggregate participants and problem IDs for the main query
-	caseIDs := make([]uuid.UUID, len(contestCases))
-	for i, cc := range contestCases {
-		caseIDs[i] = cc.CaseID
+func (s *serviceImpl) GetGlobalContestLeaderboard(ctx context.Context, contestID uuid.UUID) ([]leaderboardModel.LeaderboardRow, error) {
+	// Step 1: Fetch contest data
+	contestCases, err := s.contestRepo.FindContestCases(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch problems for contest: %w", err)
+	}
+	if len(contestCases) == 0 {
+		return []leaderboardModel.LeaderboardRow{}, nil
 	}
 
-	userIDs := make([]uuid.UUID, len(classInfo.Students))
-	// We also need a quick way to look up user names later
-	userNameMap := make(map[uuid.UUID]struct {
-		Name     string
-		UserName string
-	})
-	for i, student := range classInfo.Students {
-		userIDs[i] = student.UserID
-		userNameMap[student.UserID] = struct {
-			Name     string
-			UserName string
-		}{
-			Name:     student.User.Name,
-			UserName: student.User.Username,
-		}
+	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch contest: %w", err)
 	}
 
-	// Step 3: Fetch all relevant submissions in a single, efficient query
-	allSubmissions, err := s.submissionRepo.FindAllForContest(ctx, contestID, &classID, contestStartTime)
+	leaderboardRows, err := s.globalContestRows(ctx, contest, contestCases, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch submissions: %w", err)
+		return nil, err
 	}
+	rankRows(leaderboardRows)
+	return leaderboardRows, nil
+}
 
-	// Group submissions by user for easier processing
-	submissionsByUser := make(map[uuid.UUID][]submissionModel.Submission)
-	for _, sub := range allSubmissions {
-		submissionsByUser[sub.UserID] = append(submissionsByUser[sub.UserID], sub)
+func (s *serviceImpl) GetVirtualStanding(ctx context.Context, participationID, userID uuid.UUID) (*VirtualStanding, error) {
+	participation, err := s.contestRepo.FindVirtualParticipationByID(ctx, participationID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch virtual participation: %w", err)
+	}
+	if participation == nil || participation.UserID != userID {
+		return nil, ErrVirtualParticipationNotFound
 	}
 
-	// Step 4: Process submissions for each student to calculate results
-	var leaderboardRows []leaderboardModel.LeaderboardRow
-	for _, userID := range userIDs {
-		userSubmissions := submissionsByUser[userID]
+	contestCases, err := s.contestRepo.FindContestCases(ctx, participation.ContestID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch problems for contest: %w", err)
+	}
 
-		row := leaderboardModel.LeaderboardRow{
-			UserID:         userID,
-			Name:           userNameMap[userID].Name,
-			UserName:       userNameMap[userID].UserName,
-			SolvedCount:    0,
-			TotalPenalty:   0,
-			ProblemResults: make(map[string]leaderboardModel.CaseResult),
-		}
+	// Official rows are cut off at the same time into the contest as the virtual run has reached.
+	now := time.Now()
+	elapsed := now.Sub(participation.StartedAt)
+	if now.After(participation.EndsAt) {
+		elapsed = participation.EndsAt.Sub(participation.StartedAt)
+	}
 
-		submissionsByCase := make(map[uuid.UUID][]submissionModel.Submission)
-		for _, sub := range userSubmissions {
-			submissionsByCase[sub.CaseID] = append(submissionsByCase[sub.CaseID], sub)
+	var officialRows []leaderboardModel.LeaderboardRow
+	if participation.ClassTransactionID != nil {
+		classContest, err := s.contestRepo.FindClassContestByIDs(ctx, *participation.ClassTransactionID, participation.ContestID)
+		if err != nil {
+			return nil, fmt.Errorf("could not find contest assignment for this class: %w", err)
 		}
-
-		for _, problem := range contestCases {
-			problemSubmissions := submissionsByCase[problem.CaseID]
-			problemResult := calculateProblemResult(problemSubmissions, contestStartTime)
-			row.ProblemResults[problem.ProblemCode] = problemResult // Use ProblemCode like "A", "B"
-
-			if problemResult.IsSolved {
-
-				row.SolvedCount++
-				row.TotalPenalty += problemResult.SolveTimeMinutes + (problemResult.WrongAttempts * penaltyPerWrongAttempt)
-			}
+		if classContest == nil {
+			return nil, ErrVirtualParticipationNotFound
+		}
+		officialRows, err = s.classContestRows(ctx, classContest, contestCases, elapsed)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		contest, err := s.contestRepo.FindContestByID(ctx, participation.ContestID)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch contest: %w", err)
+		}
+		if contest == nil || contest.GlobalContestDetail == nil {
+			return nil, ErrVirtualParticipationNotFound
+		}
+		officialRows, err = s.globalContestRows(ctx, contest, contestCases, elapsed)
+		if err != nil {
+			return nil, err
 		}
-		leaderboardRows = append(leaderboardRows, row)
 	}
 
-	// Step 5: Sort the leaderboard based on ICPC rules
-	sort.Slice(leaderboardRows, func(i, j int) bool {
-		if leaderboardRows[i].SolvedCount != leaderboardRows[j].SolvedCount {
-			return leaderboardRows[i].SolvedCount > leaderboardRows[j].SolvedCount
+	virtualSubmissions, err := s.submissionRepo.FindByVirtualParticipation(ctx, participationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch submissions: %w", err)
+	}
+	userInfo, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user info for %s: %w", userID.String(), err)
+	}
+	virtualRow := buildRow(userID, userInfo.Name, userInfo.Username, virtualSubmissions, contestCases, participation.StartedAt)
+	virtualRow.Virtual = true
+
+	// The user's own official row would compete with their virtual one, so it is left out.
+	leaderboardRows := []leaderboardModel.LeaderboardRow{virtualRow}
+	for _, row := range officialRows {
+		if row.UserID != userID {
+			leaderboardRows = append(leaderboardRows, row)
 		}
-		if leaderboardRows[i].TotalPenalty != leaderboardRows[j].TotalPenalty {
-			return leaderboardRows[i].TotalPenalty < leaderboardRows[j].TotalPenalty
+	}
+	rankRows(leaderboardRows)
+
+	standing := &VirtualStanding{
+		ParticipationID: participation.ID,
+		ContestID:       participation.ContestID,
+		ElapsedMinutes:  int(elapsed.Minutes()),
+		Finished:        !participation.Active(now),
+		Leaderboard:     leaderboardRows,
+	}
+	for _, row := range leaderboardRows {
+		if row.Virtual {
+			standing.Rank = row.Rank
 		}
-		return leaderboardRows[i].UserName < leaderboardRows[j].UserName
-	})
-
-	// Step 6: Assign final ranks
-	for i := range leaderboardRows {
-		leaderboardRows[i].Rank = i + 1
 	}
-
-	return leaderboardRows, nil
+	return standing, nil
 }
 
-func (s *serviceImpl) GetGlobalContestLeaderboard(ctx context.Context, contestID uuid.UUID) ([]leaderboardModel.LeaderboardRow, error) {
-	// Step 1: Fetch contest data
-	contestCases, err := s.contestRepo.FindContestCases(ctx, contestID)
+// classContestRows builds one unranked row per student of the class. A positive limit only counts
+// submissions made within that long of each student's start.
+func (s *serviceImpl) classContestRows(ctx context.Context, classContest *contestModel.ClassContest, contestCases []contestModel.ContestCase, limit time.Duration) ([]leaderboardModel.LeaderboardRow, error) {
+	// Students with their own window are timed from their own start, and only their submissions inside
+	// that window count.
+	overrides, err := s.contestRepo.FindOverrides(ctx, classContest.ClassTransactionID, classContest.ContestID)
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch problems for contest: %w", err)
+		return nil, fmt.Errorf("could not fetch contest windows: %w", err)
 	}
-	if len(contestCases) == 0 {
-		return []leaderboardModel.LeaderboardRow{}, nil
+	overrideByUser := make(map[uuid.UUID]*contestModel.ClassContestOverride, len(overrides))
+	earliestStartTime := classContest.StartTime
+	for i := range overrides {
+		overrideByUser[overrides[i].UserID] = &overrides[i]
+		if startTime, _ := classContest.WindowFor(&overrides[i]); startTime.Before(earliestStartTime) {
+			earliestStartTime = startTime
+		}
 	}
 
-	contest, err := s.contestRepo.FindContestByID(ctx, contestID)
+	classInfo, err := s.classRepo.FindClassByTransactionID(ctx, classContest.ClassTransactionID.String())
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch contest: %w", err)
+		return nil, fmt.Errorf("could not fetch class details: %w", err)
 	}
-	contestStartTime := contest.GlobalContestDetail.StartTime
 
-	// Step 2: Fetch all submissions for the contest
-	allSubmissions, err := s.submissionRepo.FindAllForContest(ctx, contestID, nil, contestStartTime)
+	// Fetch all relevant submissions in a single, efficient query
+	allSubmissions, err := s.submissionRepo.FindAllForContest(ctx, classContest.ContestID, &classContest.ClassTransactionID, earliestStartTime)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch submissions: %w", err)
 	}
 
-	// Group submissions by user
+	// Group submissions by user for easier processing
 	submissionsByUser := make(map[uuid.UUID][]submissionModel.Submission)
-	userIDsMap := make(map[uuid.UUID]struct{})
-
 	for _, sub := range allSubmissions {
 		submissionsByUser[sub.UserID] = append(submissionsByUser[sub.UserID], sub)
-		userIDsMap[sub.UserID] = struct{}{}
 	}
 
-	// Extract unique user IDs
-	var userIDs []uuid.UUID
-	for uid := range userIDsMap {
-		userIDs = append(userIDs, uid)
+	leaderboardRows := make([]leaderboardModel.LeaderboardRow, 0, len(classInfo.Students))
+	for _, student := range classInfo.Students {
+		userStartTime, userEndTime := classContest.WindowFor(overrideByUser[student.UserID])
+		if limit > 0 && userStartTime.Add(limit).Before(userEndTime) {
+			userEndTime = userStartTime.Add(limit)
+		}
+		userSubmissions := submissionsInWindow(submissionsByUser[student.UserID], userStartTime, userEndTime)
+		leaderboardRows = append(leaderboardRows, buildRow(student.UserID, student.User.Name, student.User.Username, userSubmissions, contestCases, userStartTime))
 	}
-	if len(userIDs) == 0 {
-		return []leaderboardModel.LeaderboardRow{}, nil
+	return leaderboardRows, nil
+}
+
+// globalContestRows builds one unranked row per user who submitted to the global contest. A positive
+// limit only counts submissions made within that long of the contest start.
+func (s *serviceImpl) globalContestRows(ctx context.Context, contest *contestModel.Contest, contestCases []contestModel.ContestCase, limit time.Duration) ([]leaderboardModel.LeaderboardRow, error) {
+	contestStartTime := contest.GlobalContestDetail.StartTime
+
+	// Fetch all submissions for the contest
+	allSubmissions, err := s.submissionRepo.FindAllForContest(ctx, contest.ID, nil, contestStartTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch submissions: %w", err)
+	}
+	if limit > 0 {
+		allSubmissions = submissionsInWindow(allSubmissions, contestStartTime, contestStartTime.Add(limit))
 	}
 
-	// Fetch user info
-	users := make([]user.User, 0, len(userIDs))
-	for uid := range userIDsMap {
-		userInfo, err := s.userRepo.GetUserByID(ctx, uid)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch user info for %s: %w", uid.String(), err)
-		}
-		users = append(users, *userInfo)
+	// Group submissions by user
+	submissionsByUser := make(map[uuid.UUID][]submissionModel.Submission)
+	for _, sub := range allSubmissions {
+		submissionsByUser[sub.UserID] = append(submissionsByUser[sub.UserID], sub)
 	}
 
-	userNameMap := make(map[uuid.UUID]struct {
-		Name     string
-		UserName string
-	})
-	for _, u := range users {
-		userNameMap[u.ID] = struct {
-			Name     string
-			UserName string
-		}{
-			Name:     u.Name,
-			UserName: u.Username,
+	leaderboardRows := make([]leaderboardModel.LeaderboardRow, 0, len(submissionsByUser))
+	for userID, userSubmissions := range submissionsByUser {
+		userInfo, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch user info for %s: %w", userID.String(), err)
 		}
+		leaderboardRows = append(leaderboardRows, buildRow(userID, userInfo.Name, userInfo.Username, userSubmissions, contestCases, contestStartTime))
 	}
+	return leaderboardRows, nil
+}
 
-	// Step 3: Process submissions for each user
-	var leaderboardRows []leaderboardModel.LeaderboardRow
-	for _, userID := range userIDs {
-		userSubmissions := submissionsByUser[userID]
-		row := leaderboardModel.LeaderboardRow{
-			UserID:         userID,
-			Name:           userNameMap[userID].Name,
-			UserName:       userNameMap[userID].UserName,
-			SolvedCount:    0,
-			TotalPenalty:   0,
-			ProblemResults: make(map[string]leaderboardModel.CaseResult),
-		}
+// buildRow scores one participant's submissions, timing every solve from startTime.
+func buildRow(userID uuid.UUID, name, userName string, submissions []submissionModel.Submission, contestCases []contestModel.ContestCase, startTime time.Time) leaderboardModel.LeaderboardRow {
+	row := leaderboardModel.LeaderboardRow{
+		UserID:         userID,
+		Name:           name,
+		UserName:       userName,
+		SolvedCount:    0,
+		TotalPenalty:   0,
+		ProblemResults: make(map[string]leaderboardModel.CaseResult),
+	}
 
-		submissionsByCase := make(map[uuid.UUID][]submissionModel.Submission)
-		for _, sub := range userSubmissions {
-			submissionsByCase[sub.CaseID] = append(submissionsByCase[sub.CaseID], sub)
-		}
+	submissionsByCase := make(map[uuid.UUID][]submissionModel.Submission)
+	for _, sub := range submissions {
+		submissionsByCase[sub.CaseID] = append(submissionsByCase[sub.CaseID], sub)
+	}
 
-		for _, problem := range contestCases {
-			problemSubmissions := submissionsByCase[problem.CaseID]
-			problemResult := calculateProblemResult(problemSubmissions, contestStartTime)
-			row.ProblemResults[problem.ProblemCode] = problemResult
-			if problemResult.IsSolved {
-				row.SolvedCount++
-				row.TotalPenalty += problemResult.SolveTimeMinutes + (problemResult.WrongAttempts * penaltyPerWrongAttempt)
-			}
+	for _, problem := range contestCases {
+		problemResult := calculateProblemResult(submissionsByCase[problem.CaseID], startTime)
+		row.ProblemResults[problem.ProblemCode] = problemResult // Use ProblemCode like "A", "B"
+		if problemResult.IsSolved {
+			row.SolvedCount++
+			row.TotalPenalty += problemResult.SolveTimeMinutes + (problemResult.WrongAttempts * penaltyPerWrongAttempt)
 		}
-		leaderboardRows = append(leaderboardRows, row)
 	}
+	return row
+}
 
-	// Step 4: Sort leaderboard
+// rankRows sorts the leaderboard by ICPC rules and assigns the final ranks.
+func rankRows(leaderboardRows []leaderboardModel.LeaderboardRow) {
 	sort.Slice(leaderboardRows, func(i, j int) bool {
 		if leaderboardRows[i].SolvedCount != leaderboardRows[j].SolvedCount {
 			return leaderboardRows[i].SolvedCount > leaderboardRows[j].SolvedCount
@@ -255,13 +307,20 @@ func (s *serviceImpl) GetGlobalContestLeaderboard(ctx context.Context, contestID
 		}
 		return leaderboardRows[i].UserName < leaderboardRows[j].UserName
 	})
-
-	// Step 5: Assign ranks
 	for i := range leaderboardRows {
 		leaderboardRows[i].Rank = i + 1
 	}
+}
 
-	return leaderboardRows, nil
+// submissionsInWindow keeps the submissions made between start and end. They stay in time order.
+func submissionsInWindow(submissions []submissionModel.Submission, start, end time.Time) []submissionModel.Submission {
+	kept := make([]submissionModel.Submission, 0, len(submissions))
+	for _, sub := range submissions {
+		if !sub.CreatedAt.Before(start) && !sub.CreatedAt.After(end) {
+			kept = append(kept, sub)
+		}
+	}
+	return kept
 }
 
 // calculateProblemResult logic remains the same as it's pure computation.
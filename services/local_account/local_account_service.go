@@ -0,0 +1,38 @@
+package localAccountServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"io"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+)
+
+var (
+	ErrUsernameTaken     = errors.New("username is already taken")
+	ErrInvalidUsername   = errors.New("username must not be empty or contain spaces")
+	ErrInvalidName       = errors.New("name is required")
+	ErrInvalidRole       = errors.New("role must be Student or Assistant")
+	ErrWeakPassword      = errors.New("password must be at least 8 characters")
+	ErrAccountNotFound   = errors.New("account not found")
+	ErrNotLocalAccount   = errors.New("account logs in through Messier")
+	ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+	ErrInvalidCSV        = errors.New("invalid CSV")
+	ErrImportRejected    = errors.New("import rejected, no accounts were created")
+)
+
+type LocalAccountService interface {
+	// CreateAccount creates a local account. Without a password the response carries a reset token the
+	// account holder uses to choose one.
+	CreateAccount(ctx context.Context, req requests.LocalAccountRequest, createdBy uuid.UUID) (*responses.LocalAccountResponse, error)
+	// ImportAccounts creates an account per CSV row (header: username,name[,role][,password]). Either
+	// every row is created or, with ErrImportRejected, none is and the response lists the bad rows.
+	ImportAccounts(ctx context.Context, csvFile io.Reader, createdBy uuid.UUID) (*responses.LocalAccountImportResponse, error)
+	// IssueResetToken replaces any outstanding reset token of a local account with a new one.
+	IssueResetToken(ctx context.Context, userID uuid.UUID, issuedBy uuid.UUID) (*responses.PasswordResetTokenResponse, error)
+	ResetPassword(ctx context.Context, req requests.PasswordResetRequest) error
+	// EnsureBootstrapAdmin creates a local ADMIN_USERNAME account with LOCAL_ADMIN_PASSWORD when both are
+	// set and the account does not exist yet, so an instance without Messier can be administered.
+	EnsureBootstrapAdmin(ctx context.Context) error
+}
@@ -0,0 +1,407 @@
+package localAccountServ
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	localCredentialRepo "neptune/backend/repositories/local_credential"
+	userRepo "neptune/backend/repositories/user"
+	sessionServ "neptune/backend/services/session"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minPasswordLength    = 8
+	maxImportRows        = 5000
+	defaultResetTokenTTL = 72 * time.Hour
+)
+
+type localAccountService struct {
+	userRepo       userRepo.UserRepository
+	credentialRepo localCredentialRepo.LocalCredentialRepository
+	txManager      database.TransactionManager
+	sessionService sessionServ.SessionService
+}
+
+func NewLocalAccountService(
+	userRepo userRepo.UserRepository,
+	credentialRepo localCredentialRepo.LocalCredentialRepository,
+	txManager database.TransactionManager,
+	sessionService sessionServ.SessionService,
+) LocalAccountService {
+	return &localAccountService{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		txManager:      txManager,
+		sessionService: sessionService,
+	}
+}
+
+func (s *localAccountService) CreateAccount(ctx context.Context, req requests.LocalAccountRequest, createdBy uuid.UUID) (*responses.LocalAccountResponse, error) {
+	account, err := normalizeAccount(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *responses.LocalAccountResponse
+	err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = s.createAccount(ctx, account, createdBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Created local account %s (%s)", resp.Username, resp.Role)
+	return resp, nil
+}
+
+func (s *localAccountService) ImportAccounts(ctx context.Context, csvFile io.Reader, createdBy uuid.UUID) (*responses.LocalAccountImportResponse, error) {
+	reader := csv.NewReader(csvFile)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %v", ErrInvalidCSV, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"username", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", ErrInvalidCSV, required)
+		}
+	}
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	resp := &responses.LocalAccountImportResponse{
+		Accounts: []responses.LocalAccountResponse{},
+		Errors:   []responses.LocalAccountImportError{},
+	}
+	var accounts []requests.LocalAccountRequest
+	rows := make([]int, 0)
+	seen := make(map[string]int)
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidCSV, row, err)
+		}
+		if len(accounts)+len(resp.Errors) >= maxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidCSV, maxImportRows)
+		}
+
+		account, err := normalizeAccount(requests.LocalAccountRequest{
+			Username: field(record, "username"),
+			Name:     field(record, "name"),
+			Role:     field(record, "role"),
+			Password: field(record, "password"),
+		})
+		if err == nil {
+			if first, dup := seen[strings.ToLower(account.Username)]; dup {
+				err = fmt.Errorf("duplicate of row %d", first)
+			} else if existing, lookupErr := s.userRepo.GetUserByUsername(ctx, account.Username); lookupErr != nil {
+				return nil, lookupErr
+			} else if existing != nil {
+				err = ErrUsernameTaken
+			}
+		}
+		if err != nil {
+			resp.Errors = append(resp.Errors, responses.LocalAccountImportError{Row: row, Username: field(record, "username"), Error: err.Error()})
+			continue
+		}
+		seen[strings.ToLower(account.Username)] = row
+		accounts = append(accounts, account)
+		rows = append(rows, row)
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp, ErrImportRejected
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidCSV)
+	}
+
+	err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		for i, account := range accounts {
+			created, err := s.createAccount(ctx, account, createdBy)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", rows[i], err)
+			}
+			resp.Accounts = append(resp.Accounts, *created)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Created = len(resp.Accounts)
+	log.Printf("Imported %d local accounts", resp.Created)
+	return resp, nil
+}
+
+func (s *localAccountService) IssueResetToken(ctx context.Context, userID uuid.UUID, issuedBy uuid.UUID) (*responses.PasswordResetTokenResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrAccountNotFound
+	}
+	if !user.IsLocal() {
+		return nil, ErrNotLocalAccount
+	}
+
+	var resp *responses.PasswordResetTokenResponse
+	err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = s.issueResetToken(ctx, user.ID, issuedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *localAccountService) ResetPassword(ctx context.Context, req requests.PasswordResetRequest) error {
+	if len(req.NewPassword) < minPasswordLength {
+		return ErrWeakPassword
+	}
+
+	token, err := s.credentialRepo.GetResetTokenByHash(ctx, hashResetToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		// Only the first of two concurrent resets with the same token gets through.
+		used, err := s.credentialRepo.UseResetToken(ctx, token.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidResetToken
+		}
+		if err := s.credentialRepo.SaveCredential(ctx, &model.LocalCredential{
+			UserID:            token.UserID,
+			PasswordHash:      string(hash),
+			PasswordChangedAt: now,
+		}); err != nil {
+			return err
+		}
+		return s.credentialRepo.RevokeResetTokens(ctx, token.UserID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Whoever knew the old password is logged out.
+	if _, err := s.sessionService.RevokeAllForUser(ctx, token.UserID, uuid.Nil, "password reset"); err != nil {
+		log.Printf("Failed to revoke sessions of user %s after password reset: %v", token.UserID, err)
+	}
+	return nil
+}
+
+func (s *localAccountService) EnsureBootstrapAdmin(ctx context.Context) error {
+	username := strings.TrimSpace(os.Getenv("ADMIN_USERNAME"))
+	password := os.Getenv("LOCAL_ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		return nil
+	}
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("LOCAL_ADMIN_PASSWORD: %w", ErrWeakPassword)
+	}
+
+	existing, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if !existing.IsLocal() {
+			log.Printf("ADMIN_USERNAME %s is a Messier account, LOCAL_ADMIN_PASSWORD is ignored", username)
+		}
+		return nil
+	}
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user := &model.User{
+			ID:           uuid.New(),
+			Username:     username,
+			Name:         username,
+			Role:         model.RoleAdmin,
+			AuthProvider: model.AuthProviderLocal,
+			CreatedAt:    time.Now(),
+		}
+		if err := s.userRepo.CreateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to create bootstrap admin: %w", err)
+		}
+		if err := s.setPassword(ctx, user.ID, password); err != nil {
+			return err
+		}
+		log.Printf("Created local admin account %s", username)
+		return nil
+	})
+}
+
+// createAccount creates a normalized account inside the caller's transaction.
+func (s *localAccountService) createAccount(ctx context.Context, account requests.LocalAccountRequest, createdBy uuid.UUID) (*responses.LocalAccountResponse, error) {
+	existing, err := s.userRepo.GetUserByUsername(ctx, account.Username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrUsernameTaken
+	}
+
+	user := &model.User{
+		ID:           uuid.New(),
+		Username:     account.Username,
+		Name:         account.Name,
+		Role:         model.Role(account.Role),
+		AuthProvider: model.AuthProviderLocal,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", user.Username, err)
+	}
+
+	resp := &responses.LocalAccountResponse{
+		UserID:   user.ID.String(),
+		Username: user.Username,
+		Name:     user.Name,
+		Role:     user.Role.String(),
+	}
+	if account.Password != "" {
+		return resp, s.setPassword(ctx, user.ID, account.Password)
+	}
+
+	token, err := s.issueResetToken(ctx, user.ID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	resp.ResetToken = token.Token
+	resp.ResetTokenExpiresAt = &token.ExpiresAt
+	return resp, nil
+}
+
+func (s *localAccountService) setPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	return s.credentialRepo.SaveCredential(ctx, &model.LocalCredential{
+		UserID:            userID,
+		PasswordHash:      string(hash),
+		PasswordChangedAt: time.Now(),
+	})
+}
+
+func (s *localAccountService) issueResetToken(ctx context.Context, userID uuid.UUID, issuedBy uuid.UUID) (*responses.PasswordResetTokenResponse, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := time.Now()
+	if err := s.credentialRepo.RevokeResetTokens(ctx, userID, now); err != nil {
+		return nil, err
+	}
+	token := &model.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashResetToken(raw),
+		ExpiresAt: now.Add(resetTokenTTL()),
+		CreatedAt: now,
+	}
+	if issuedBy != uuid.Nil {
+		token.CreatedBy = &issuedBy
+	}
+	if err := s.credentialRepo.CreateResetToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return &responses.PasswordResetTokenResponse{
+		UserID:    userID.String(),
+		Token:     raw,
+		ExpiresAt: token.ExpiresAt,
+	}, nil
+}
+
+// normalizeAccount trims the request, defaults the role and validates everything but uniqueness.
+func normalizeAccount(req requests.LocalAccountRequest) (requests.LocalAccountRequest, error) {
+	req.Username = strings.TrimSpace(req.Username)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Username == "" || strings.ContainsAny(req.Username, " \t\r\n") {
+		return req, ErrInvalidUsername
+	}
+	if req.Name == "" {
+		return req, ErrInvalidName
+	}
+
+	// Staff roles are granted separately, a local account itself is a Student or an Assistant.
+	if req.Role == "" {
+		req.Role = string(model.RoleStudent)
+	}
+	role, err := model.ParseRole(req.Role)
+	if err != nil || role.Rank() > model.RoleAssistant.Rank() {
+		return req, ErrInvalidRole
+	}
+	req.Role = string(role)
+
+	if req.Password != "" && len(req.Password) < minPasswordLength {
+		return req, ErrWeakPassword
+	}
+	return req, nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func resetTokenTTL() time.Duration {
+	raw := os.Getenv("PASSWORD_RESET_TTL_HOURS")
+	if raw == "" {
+		return defaultResetTokenTTL
+	}
+	hours, err := strconv.Atoi(raw)
+	if err != nil || hours <= 0 {
+		log.Printf("Invalid PASSWORD_RESET_TTL_HOURS=%q, using default %s", raw, defaultResetTokenTTL)
+		return defaultResetTokenTTL
+	}
+	return time.Duration(hours) * time.Hour
+}
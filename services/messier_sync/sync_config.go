@@ -0,0 +1,61 @@
+package messierSync
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSyncInterval   = 6 * time.Hour
+	defaultSyncRetries    = 2
+	defaultSyncRetryDelay = 30 * time.Second
+	defaultSyncRunTimeout = time.Hour
+)
+
+// syncConfig controls the scheduled sync. The scheduler only runs when a service account is configured,
+// because scheduled runs have no admin whose Messier token could be borrowed.
+type syncConfig struct {
+	Username     string
+	Password     string
+	Interval     time.Duration // 0 disables the scheduler
+	ClassRetries int
+	RetryDelay   time.Duration
+	RunTimeout   time.Duration // Always positive; runs still marked running after this long are considered dead
+}
+
+func loadSyncConfig() syncConfig {
+	// A run without a deadline would never end, and every running run would count as stale
+	runTimeoutMinutes := intFromEnv("SYNC_RUN_TIMEOUT_MINUTES", int(defaultSyncRunTimeout/time.Minute))
+	if runTimeoutMinutes == 0 {
+		log.Printf("SYNC_RUN_TIMEOUT_MINUTES must be positive, using default %d", int(defaultSyncRunTimeout/time.Minute))
+		runTimeoutMinutes = int(defaultSyncRunTimeout / time.Minute)
+	}
+	return syncConfig{
+		Username:     os.Getenv("MESSIER_SYNC_USERNAME"),
+		Password:     os.Getenv("MESSIER_SYNC_PASSWORD"),
+		Interval:     time.Duration(intFromEnv("SYNC_INTERVAL_MINUTES", int(defaultSyncInterval/time.Minute))) * time.Minute,
+		ClassRetries: intFromEnv("SYNC_CLASS_RETRIES", defaultSyncRetries),
+		RetryDelay:   time.Duration(intFromEnv("SYNC_RETRY_DELAY_SECONDS", int(defaultSyncRetryDelay/time.Second))) * time.Second,
+		RunTimeout:   time.Duration(runTimeoutMinutes) * time.Minute,
+	}
+}
+
+func (c syncConfig) hasServiceAccount() bool {
+	return c.Username != "" && c.Password != ""
+}
+
+// intFromEnv reads a non-negative integer, falling back when the variable is unset or invalid.
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("Invalid %s=%q, using default %d", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,19 @@
+package messierSync
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"neptune/backend/pkg/responses"
+)
+
+// SyncService runs the Messier sync pipeline (semesters -> classes -> students -> assistants) for the
+// active catalogue courses on a schedule or on demand, and keeps the history of runs.
+type SyncService interface {
+	// RunNow starts a run in the background and returns it immediately. triggeredBy is the admin asking
+	// for the run; their Messier token is used when no service account is configured.
+	RunNow(ctx context.Context, triggeredBy uuid.UUID) (*responses.SyncRunResponse, error)
+	GetRuns(ctx context.Context, limit, offset int) (*responses.SyncRunListResponse, error)
+	GetRun(ctx context.Context, runID uuid.UUID) (*responses.SyncRunResponse, error)
+	// StartScheduler blocks, starting a run every configured interval until ctx is cancelled.
+	StartScheduler(ctx context.Context)
+}
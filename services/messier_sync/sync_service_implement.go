@@ -0,0 +1,458 @@
+package messierSync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"neptune/backend/messier"
+	"neptune/backend/messier/auth/log_on"
+	syncRunModel "neptune/backend/models/sync_run"
+	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/utils"
+	courseRepo "neptune/backend/repositories/course"
+	messierTokenRepo "neptune/backend/repositories/messier_token"
+	internalSemesterRepo "neptune/backend/repositories/semester"
+	syncRunRepo "neptune/backend/repositories/sync_run"
+	"neptune/backend/services/internal_class"
+	"neptune/backend/services/internal_semester"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSyncRunNotFound   = errors.New("sync run not found")
+	ErrNoSyncCredentials = errors.New("no Messier credentials available for sync")
+)
+
+const (
+	stepSemesters  = "semesters"
+	stepClasses    = "classes"
+	stepStudents   = "students"
+	stepAssistants = "assistants"
+)
+
+type syncService struct {
+	runRepo          syncRunRepo.SyncRunRepository
+	semesterRepo     internalSemesterRepo.SemesterRepository
+	courseRepo       courseRepo.CourseRepository
+	semesterService  internal_semester.SemesterService
+	classService     internal_class.ClassService
+	logOnService     log_on.LogOnService
+	messierTokenRepo messierTokenRepo.MessierTokenRepository
+	config           syncConfig
+
+	tokenMu      sync.Mutex
+	token        string
+	tokenExpires time.Time
+}
+
+func NewSyncService(
+	runRepo syncRunRepo.SyncRunRepository,
+	semesterRepo internalSemesterRepo.SemesterRepository,
+	courseRepo courseRepo.CourseRepository,
+	semesterService internal_semester.SemesterService,
+	classService internal_class.ClassService,
+	logOnService log_on.LogOnService,
+	messierTokenRepo messierTokenRepo.MessierTokenRepository,
+) SyncService {
+	return &syncService{
+		runRepo:          runRepo,
+		semesterRepo:     semesterRepo,
+		courseRepo:       courseRepo,
+		semesterService:  semesterService,
+		classService:     classService,
+		logOnService:     logOnService,
+		messierTokenRepo: messierTokenRepo,
+		config:           loadSyncConfig(),
+	}
+}
+
+func (s *syncService) RunNow(ctx context.Context, triggeredBy uuid.UUID) (*responses.SyncRunResponse, error) {
+	token, err := s.resolveToken(ctx, triggeredBy.String())
+	if err != nil {
+		return nil, err
+	}
+
+	s.failStaleRuns(ctx)
+	run := &syncRunModel.SyncRun{
+		Trigger:     syncRunModel.SyncTriggerManual,
+		TriggeredBy: &triggeredBy,
+		StartedAt:   time.Now(),
+	}
+	if err := s.runRepo.CreateRunning(ctx, run); err != nil {
+		return nil, err
+	}
+
+	// The request context ends with the HTTP response, the run must outlive it.
+	go s.execute(context.Background(), run, token)
+
+	response := toSyncRunResponse(run)
+	return &response, nil
+}
+
+func (s *syncService) GetRuns(ctx context.Context, limit, offset int) (*responses.SyncRunListResponse, error) {
+	runs, total, err := s.runRepo.FindAll(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &responses.SyncRunListResponse{
+		Runs:   make([]responses.SyncRunResponse, 0, len(runs)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for i := range runs {
+		result.Runs = append(result.Runs, toSyncRunResponse(&runs[i]))
+	}
+	return result, nil
+}
+
+func (s *syncService) GetRun(ctx context.Context, runID uuid.UUID) (*responses.SyncRunResponse, error) {
+	run, err := s.runRepo.FindByID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrSyncRunNotFound
+	}
+	response := toSyncRunResponse(run)
+	return &response, nil
+}
+
+func (s *syncService) StartScheduler(ctx context.Context) {
+	if s.config.Interval == 0 {
+		log.Printf("Scheduled Messier sync disabled (SYNC_INTERVAL_MINUTES=0)")
+		return
+	}
+	if !s.config.hasServiceAccount() {
+		log.Printf("Scheduled Messier sync disabled: MESSIER_SYNC_USERNAME and MESSIER_SYNC_PASSWORD are not set")
+		return
+	}
+
+	log.Printf("Scheduled Messier sync every %s", s.config.Interval)
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runScheduled(ctx)
+		}
+	}
+}
+
+func (s *syncService) runScheduled(ctx context.Context) {
+	token, err := s.serviceAccountToken(ctx)
+	if err != nil {
+		log.Printf("Scheduled Messier sync skipped: %v", err)
+		return
+	}
+
+	s.failStaleRuns(ctx)
+	run := &syncRunModel.SyncRun{
+		Trigger:   syncRunModel.SyncTriggerScheduled,
+		StartedAt: time.Now(),
+	}
+	if err := s.runRepo.CreateRunning(ctx, run); err != nil {
+		if errors.Is(err, syncRunRepo.ErrSyncRunInProgress) {
+			log.Printf("Scheduled Messier sync skipped: another run is in progress")
+			return
+		}
+		log.Printf("Scheduled Messier sync failed to start: %v", err)
+		return
+	}
+	s.execute(ctx, run, token)
+}
+
+// execute runs the pipeline for a run that is already stored as running, recording every step, and
+// always finishes the run.
+func (s *syncService) execute(ctx context.Context, run *syncRunModel.SyncRun, token string) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.RunTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Messier sync run %s panicked: %v", run.ID, r)
+			s.finish(run, syncRunModel.SyncRunStatusFailed, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	log.Printf("Messier sync run %s started (%s)", run.ID, run.Trigger)
+	position := 0
+	nextPosition := func() int {
+		position++
+		return position
+	}
+
+	semesters, err := s.runStep(ctx, run, nextPosition(), stepSemesters, "", false, func(ctx context.Context, _ []string) (internal_class.SyncOutcome, error) {
+		saved, err := s.semesterService.SyncSemesterWithToken(ctx, token)
+		return internal_class.SyncOutcome{Processed: saved, Synced: saved}, err
+	})
+	if semesters.Status == syncRunModel.SyncRunStatusFailed {
+		s.finish(run, syncRunModel.SyncRunStatusFailed, failureMessage("semester sync failed", err))
+		return
+	}
+
+	currentSemester, err := s.semesterRepo.FindCurrentSemester(ctx)
+	if err != nil || currentSemester == nil {
+		message := "no current semester"
+		if err != nil {
+			message = fmt.Sprintf("failed to find current semester: %v", err)
+		}
+		s.finish(run, syncRunModel.SyncRunStatusFailed, message)
+		return
+	}
+	run.SemesterID = currentSemester.ID
+
+	classes, err := s.runStep(ctx, run, nextPosition(), stepClasses, "", false, func(ctx context.Context, _ []string) (internal_class.SyncOutcome, error) {
+		return s.classService.SyncClassesWithToken(ctx, run.SemesterID, token)
+	})
+	if classes.Status == syncRunModel.SyncRunStatusFailed {
+		s.finish(run, syncRunModel.SyncRunStatusFailed, failureMessage("class sync failed", err))
+		return
+	}
+
+	status := syncRunModel.SyncRunStatusSucceeded
+	if classes.Status != syncRunModel.SyncRunStatusSucceeded {
+		status = syncRunModel.SyncRunStatusPartial
+	}
+	courses, err := s.courseRepo.FindActiveCoursesForSemester(ctx, run.SemesterID)
+	if err != nil {
+		s.finish(run, syncRunModel.SyncRunStatusFailed, fmt.Sprintf("failed to load courses: %v", err))
+		return
+	}
+	for _, course := range courses {
+		courseID := course.CourseOutlineID.String()
+		students, studentsErr := s.runStep(ctx, run, nextPosition(), stepStudents, courseID, true, func(ctx context.Context, onlyClassIDs []string) (internal_class.SyncOutcome, error) {
+			return s.classService.SyncClassStudentsWithToken(ctx, run.SemesterID, courseID, token, internal_class.RosterSyncOptions{OnlyClassIDs: onlyClassIDs})
+		})
+		// Assistants are matched against the student roster, so they are synced after it.
+		assistants, assistantsErr := s.runStep(ctx, run, nextPosition(), stepAssistants, courseID, true, func(ctx context.Context, onlyClassIDs []string) (internal_class.SyncOutcome, error) {
+			return s.classService.SyncClassAssistantsWithToken(ctx, run.SemesterID, courseID, token, internal_class.RosterSyncOptions{OnlyClassIDs: onlyClassIDs})
+		})
+		if students.Status != syncRunModel.SyncRunStatusSucceeded || assistants.Status != syncRunModel.SyncRunStatusSucceeded {
+			status = syncRunModel.SyncRunStatusPartial
+		}
+		// The remaining courses would be rejected with the same token.
+		if errors.Is(studentsErr, messier.ErrUnauthorized) || errors.Is(assistantsErr, messier.ErrUnauthorized) {
+			s.finish(run, syncRunModel.SyncRunStatusFailed, rejectedTokenMessage)
+			return
+		}
+	}
+
+	message := ""
+	if status == syncRunModel.SyncRunStatusPartial {
+		message = "some steps finished with errors"
+	}
+	s.finish(run, status, message)
+}
+
+// rejectedTokenMessage ends a run whose token Messier rejected; later steps would fail the same way.
+const rejectedTokenMessage = "messier rejected the sync token"
+
+// failureMessage explains a failed step that ends the run, naming a rejected token as the cause.
+func failureMessage(message string, err error) string {
+	if errors.Is(err, messier.ErrUnauthorized) {
+		return rejectedTokenMessage
+	}
+	return message
+}
+
+// runStep executes one step and stores its progress. Retryable steps are re-run for the classes that
+// failed, up to the configured number of retries. The returned error is the one that ended the step early.
+func (s *syncService) runStep(
+	ctx context.Context,
+	run *syncRunModel.SyncRun,
+	position int,
+	name string,
+	courseOutlineID string,
+	retryable bool,
+	fn func(ctx context.Context, onlyClassIDs []string) (internal_class.SyncOutcome, error),
+) (*syncRunModel.SyncRunStep, error) {
+	step := &syncRunModel.SyncRunStep{
+		SyncRunID:       run.ID,
+		Position:        position,
+		Name:            name,
+		CourseOutlineID: courseOutlineID,
+		Status:          syncRunModel.SyncRunStatusRunning,
+		StartedAt:       time.Now(),
+	}
+	s.saveStep(step)
+
+	var errorLines []string
+	var onlyClassIDs []string
+	var stepErr error
+	unresolved := 0 // errors reported by the last attempt
+	for {
+		step.Attempts++
+		outcome, err := fn(ctx, onlyClassIDs)
+		if step.Attempts == 1 {
+			step.Processed = outcome.Processed
+		}
+		step.Synced += outcome.Synced
+		step.Failed = len(outcome.FailedClassIDs)
+		unresolved = len(outcome.Errors)
+		for _, e := range outcome.Errors {
+			errorLines = append(errorLines, fmt.Sprintf("attempt %d: %s", step.Attempts, e))
+		}
+		if err != nil {
+			stepErr = err
+			if errors.Is(err, messier.ErrUnauthorized) {
+				s.forgetServiceToken()
+			}
+			errorLines = append(errorLines, fmt.Sprintf("attempt %d: %v", step.Attempts, err))
+			break
+		}
+		if !retryable || len(outcome.FailedClassIDs) == 0 || step.Attempts > s.config.ClassRetries {
+			break
+		}
+
+		log.Printf("Sync run %s: retrying %d failed classes in step %s %s", run.ID, len(outcome.FailedClassIDs), name, courseOutlineID)
+		onlyClassIDs = outcome.FailedClassIDs
+		step.Errors = strings.Join(errorLines, "\n")
+		s.saveStep(step)
+		select {
+		case <-ctx.Done():
+			stepErr = ctx.Err()
+		case <-time.After(s.config.RetryDelay):
+		}
+		if stepErr != nil {
+			errorLines = append(errorLines, fmt.Sprintf("attempt %d: %v", step.Attempts, stepErr))
+			break
+		}
+	}
+
+	switch {
+	case stepErr != nil:
+		step.Status = syncRunModel.SyncRunStatusFailed
+	case step.Failed > 0 || unresolved > 0:
+		step.Status = syncRunModel.SyncRunStatusPartial
+	default:
+		step.Status = syncRunModel.SyncRunStatusSucceeded
+	}
+	now := time.Now()
+	step.FinishedAt = &now
+	step.Errors = strings.Join(errorLines, "\n")
+	s.saveStep(step)
+	return step, stepErr
+}
+
+func (s *syncService) saveStep(step *syncRunModel.SyncRunStep) {
+	// Use a fresh context so the step is still recorded when the run timed out.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.runRepo.SaveStep(ctx, step); err != nil {
+		log.Printf("Failed to save sync step %s of run %s: %v", step.Name, step.SyncRunID, err)
+	}
+}
+
+func (s *syncService) finish(run *syncRunModel.SyncRun, status syncRunModel.SyncRunStatus, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	run.Status = status
+	run.Error = message
+	run.FinishedAt = &now
+	if err := s.runRepo.Finish(ctx, run); err != nil {
+		log.Printf("Failed to finish sync run %s: %v", run.ID, err)
+		return
+	}
+	log.Printf("Messier sync run %s finished: %s", run.ID, status)
+}
+
+func (s *syncService) failStaleRuns(ctx context.Context) {
+	failed, err := s.runRepo.FailStaleRuns(ctx, time.Now().Add(-s.config.RunTimeout))
+	if err != nil {
+		log.Printf("Failed to clean up stale sync runs: %v", err)
+		return
+	}
+	if failed > 0 {
+		log.Printf("Marked %d stale sync runs as failed", failed)
+	}
+}
+
+// resolveToken prefers the service account and falls back to the Messier token of the admin who
+// triggered the run.
+func (s *syncService) resolveToken(ctx context.Context, userID string) (string, error) {
+	if s.config.hasServiceAccount() {
+		token, err := s.serviceAccountToken(ctx)
+		if err == nil {
+			return token, nil
+		}
+		log.Printf("Messier sync service account login failed, falling back to admin token: %v", err)
+	}
+
+	token, err := utils.GetAndValidateMessierToken(ctx, userID, s.messierTokenRepo)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoSyncCredentials, err)
+	}
+	return token, nil
+}
+
+// serviceAccountToken logs the service account on, reusing the token until shortly before it expires.
+func (s *syncService) serviceAccountToken(ctx context.Context) (string, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.tokenExpires) {
+		return s.token, nil
+	}
+
+	resp, err := s.logOnService.LogOnAssistant(ctx, s.config.Username, s.config.Password)
+	if err != nil {
+		return "", fmt.Errorf("failed to log on sync service account: %w", err)
+	}
+	if resp == nil || resp.AccessToken == "" {
+		return "", fmt.Errorf("sync service account log on returned no token")
+	}
+
+	s.token = resp.AccessToken
+	s.tokenExpires = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
+
+// forgetServiceToken drops the cached service account token after Messier rejected it, so the next run
+// logs on again instead of waiting for the token to expire.
+func (s *syncService) forgetServiceToken() {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	s.token = ""
+	s.tokenExpires = time.Time{}
+}
+
+func toSyncRunResponse(run *syncRunModel.SyncRun) responses.SyncRunResponse {
+	response := responses.SyncRunResponse{
+		ID:          run.ID,
+		Trigger:     run.Trigger,
+		TriggeredBy: run.TriggeredBy,
+		Status:      string(run.Status),
+		SemesterID:  run.SemesterID,
+		Error:       run.Error,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+	}
+	for _, step := range run.Steps {
+		stepResponse := responses.SyncRunStepResponse{
+			Name:            step.Name,
+			CourseOutlineID: step.CourseOutlineID,
+			Status:          string(step.Status),
+			Attempts:        step.Attempts,
+			Processed:       step.Processed,
+			Synced:          step.Synced,
+			Failed:          step.Failed,
+			StartedAt:       step.StartedAt,
+			FinishedAt:      step.FinishedAt,
+		}
+		if step.Errors != "" {
+			stepResponse.Errors = strings.Split(step.Errors, "\n")
+		}
+		response.Steps = append(response.Steps, stepResponse)
+	}
+	return response
+}
@@ -0,0 +1,139 @@
+package messierSync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"neptune/backend/messier"
+	"neptune/backend/messier/auth/log_on"
+	"neptune/backend/messier/fake"
+	"neptune/backend/messier/semester"
+	syncRunModel "neptune/backend/models/sync_run"
+	syncRunRepo "neptune/backend/repositories/sync_run"
+	"neptune/backend/services/internal_class"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stepRecorder keeps the steps a run saves; the sync steps under test use no other repository method.
+type stepRecorder struct {
+	syncRunRepo.SyncRunRepository
+	steps []syncRunModel.SyncRunStep
+}
+
+func (r *stepRecorder) SaveStep(ctx context.Context, step *syncRunModel.SyncRunStep) error {
+	r.steps = append(r.steps, *step)
+	return nil
+}
+
+// newFakeMessierSync returns a sync service whose service account logs on to a fake Messier.
+func newFakeMessierSync(t *testing.T) (*syncService, *fake.Server, semester.MessierSemesterService) {
+	t.Helper()
+	server := fake.NewServer(fake.DefaultFixture())
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	client := messier.NewClient(messier.ClientConfig{BaseURL: httpServer.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	s := &syncService{
+		runRepo:      &stepRecorder{},
+		logOnService: log_on.NewLogOnService(client),
+		config:       syncConfig{Username: "XY24-1", Password: "password", RunTimeout: time.Minute},
+	}
+	return s, server, semester.NewExternalSemesterService(client)
+}
+
+func semesterStep(semesters semester.MessierSemesterService, token string) func(context.Context, []string) (internal_class.SyncOutcome, error) {
+	return func(ctx context.Context, _ []string) (internal_class.SyncOutcome, error) {
+		saved, err := semesters.GetSemesters(ctx, token)
+		return internal_class.SyncOutcome{Processed: len(saved), Synced: len(saved)}, err
+	}
+}
+
+func TestRejectedServiceTokenLogsOnAgain(t *testing.T) {
+	ctx := context.Background()
+	s, server, semesters := newFakeMessierSync(t)
+	run := &syncRunModel.SyncRun{}
+
+	token, err := s.serviceAccountToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.serviceAccountToken(ctx); again != token {
+		t.Error("a valid service account token was not reused")
+	}
+
+	server.RevokeTokens()
+	step, err := s.runStep(ctx, run, 1, stepSemesters, "", false, semesterStep(semesters, token))
+	if !errors.Is(err, messier.ErrUnauthorized) || step.Status != syncRunModel.SyncRunStatusFailed {
+		t.Fatalf("step with a revoked token = %s, %v, want failed with ErrUnauthorized", step.Status, err)
+	}
+
+	fresh, err := s.serviceAccountToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh == token {
+		t.Fatal("the rejected token was reused instead of logging on again")
+	}
+	step, err = s.runStep(ctx, run, 2, stepSemesters, "", false, semesterStep(semesters, fresh))
+	if err != nil || step.Status != syncRunModel.SyncRunStatusSucceeded {
+		t.Errorf("step after logging on again = %s, %v, want succeeded", step.Status, err)
+	}
+}
+
+func TestServiceAccountLogOnRetriesTransientFailures(t *testing.T) {
+	ctx := context.Background()
+	s, server, semesters := newFakeMessierSync(t)
+
+	server.FailNext("/Account/LogOn", http.StatusServiceUnavailable, 2)
+	token, err := s.serviceAccountToken(ctx)
+	if err != nil {
+		t.Fatalf("log on after two 503s = %v", err)
+	}
+
+	server.FailNext("/Semester/GetSemestersWithActiveDate", http.StatusBadGateway, 2)
+	step, err := s.runStep(ctx, &syncRunModel.SyncRun{}, 1, stepSemesters, "", false, semesterStep(semesters, token))
+	if err != nil || step.Status != syncRunModel.SyncRunStatusSucceeded {
+		t.Errorf("step after two 502s = %s, %v, want succeeded", step.Status, err)
+	}
+
+	server.FailNext("/Account/LogOn", http.StatusServiceUnavailable, 3)
+	s.forgetServiceToken()
+	if _, err := s.serviceAccountToken(ctx); !errors.Is(err, messier.ErrUnavailable) {
+		t.Errorf("log on after three 503s = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestRunTimeoutIsAlwaysPositive(t *testing.T) {
+	for raw, want := range map[string]time.Duration{
+		"":    defaultSyncRunTimeout,
+		"0":   defaultSyncRunTimeout,
+		"-5":  defaultSyncRunTimeout,
+		"abc": defaultSyncRunTimeout,
+		"15":  15 * time.Minute,
+	} {
+		t.Setenv("SYNC_RUN_TIMEOUT_MINUTES", raw)
+		if got := loadSyncConfig().RunTimeout; got != want {
+			t.Errorf("SYNC_RUN_TIMEOUT_MINUTES=%q gives %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestFailureMessageNamesRejectedToken(t *testing.T) {
+	rejected := &messier.APIError{Method: http.MethodGet, Path: "/Student/Class", StatusCode: http.StatusUnauthorized}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, "class sync failed"},
+		{errors.New("database is down"), "class sync failed"},
+		{rejected, rejectedTokenMessage},
+		{fmt.Errorf("course x: %w", rejected), rejectedTokenMessage},
+	}
+	for _, tt := range tests {
+		if got := failureMessage("class sync failed", tt.err); got != tt.want {
+			t.Errorf("failureMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package roleServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+)
+
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrClassNotFound        = errors.New("class not found")
+	ErrAssignmentNotFound   = errors.New("role assignment not found")
+	ErrInvalidRole          = errors.New("role must be Admin, Assistant or Lecturer")
+	ErrScopeNotAllowed      = errors.New("only Lecturer grants can be limited to a class")
+	ErrRoleAlreadyGranted   = errors.New("role is already granted")
+	ErrCannotRevokeOwnAdmin = errors.New("admins cannot revoke their own Admin role")
+)
+
+type RoleService interface {
+	GrantRole(ctx context.Context, userID uuid.UUID, req requests.GrantRoleRequest, grantedBy uuid.UUID) (*responses.RoleAssignmentResponse, error)
+	RevokeRole(ctx context.Context, userID, assignmentID uuid.UUID, reason string, revokedBy uuid.UUID) error
+	GetUserRoles(ctx context.Context, userID uuid.UUID) (*responses.UserRolesResponse, error)
+	// GetAuditTrail lists grants and revokes newest first, only those of userID when it is not nil.
+	GetAuditTrail(ctx context.Context, userID *uuid.UUID, limit, offset int) (*responses.RoleAuditListResponse, error)
+
+	// EffectiveRole is the strongest of the user's own role and their grants. ADMIN_USERNAME is always
+	// Admin, so a fresh instance can be administered before anything was granted.
+	EffectiveRole(ctx context.Context, user *model.User) (model.Role, error)
+	// CanManageClass tells whether a user with the given token role may manage the class: admins manage
+	// every class, lecturers those they were granted.
+	CanManageClass(ctx context.Context, userID uuid.UUID, role model.Role, classTransactionID uuid.UUID) (bool, error)
+}
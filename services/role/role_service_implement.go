@@ -0,0 +1,273 @@
+package roleServ
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/database"
+	"neptune/backend/pkg/requests"
+	"neptune/backend/pkg/responses"
+	classRepository "neptune/backend/repositories/class"
+	roleRepo "neptune/backend/repositories/role"
+	sessionRepo "neptune/backend/repositories/session"
+	userRepo "neptune/backend/repositories/user"
+	"os"
+	"strings"
+	"time"
+)
+
+type roleService struct {
+	roleRepo    roleRepo.RoleRepository
+	userRepo    userRepo.UserRepository
+	classRepo   classRepository.ClassRepository
+	sessionRepo sessionRepo.SessionRepository
+	txManager   database.TransactionManager
+}
+
+func NewRoleService(
+	roleRepo roleRepo.RoleRepository,
+	userRepo userRepo.UserRepository,
+	classRepo classRepository.ClassRepository,
+	sessionRepo sessionRepo.SessionRepository,
+	txManager database.TransactionManager,
+) RoleService {
+	return &roleService{
+		roleRepo:    roleRepo,
+		userRepo:    userRepo,
+		classRepo:   classRepo,
+		sessionRepo: sessionRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *roleService) GrantRole(ctx context.Context, userID uuid.UUID, req requests.GrantRoleRequest, grantedBy uuid.UUID) (*responses.RoleAssignmentResponse, error) {
+	role, err := model.ParseRole(req.Role)
+	if err != nil || role == model.RoleStudent {
+		return nil, ErrInvalidRole
+	}
+
+	var classID *uuid.UUID
+	if raw := strings.TrimSpace(req.ClassTransactionID); raw != "" {
+		if role != model.RoleLecturer {
+			return nil, ErrScopeNotAllowed
+		}
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrClassNotFound, raw)
+		}
+		class, err := s.classRepo.FindClassByTransactionID(ctx, parsed.String())
+		if err != nil {
+			return nil, err
+		}
+		if class == nil {
+			return nil, fmt.Errorf("%w: %s", ErrClassNotFound, raw)
+		}
+		classID = &parsed
+	}
+
+	if _, err := s.findUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	assignment := &model.RoleAssignment{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		Role:               role,
+		ClassTransactionID: classID,
+		GrantedBy:          actor(grantedBy),
+		GrantedAt:          now,
+	}
+	err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.roleRepo.FindAssignment(ctx, userID, role, classID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrRoleAlreadyGranted
+		}
+		if err := s.roleRepo.CreateAssignment(ctx, assignment); err != nil {
+			return err
+		}
+		return s.recordChange(ctx, assignment, model.RoleAuditGrant, grantedBy, req.Reason, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Granted %s to user %s (assignment %s) by %s", role, userID, assignment.ID, grantedBy)
+	resp := toAssignmentResponse(*assignment)
+	return &resp, nil
+}
+
+func (s *roleService) RevokeRole(ctx context.Context, userID, assignmentID uuid.UUID, reason string, revokedBy uuid.UUID) error {
+	now := time.Now()
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		assignment, err := s.roleRepo.FindAssignmentByID(ctx, assignmentID)
+		if err != nil {
+			return err
+		}
+		if assignment == nil || assignment.UserID != userID {
+			return ErrAssignmentNotFound
+		}
+		if assignment.Role == model.RoleAdmin && assignment.UserID == revokedBy {
+			return ErrCannotRevokeOwnAdmin
+		}
+		if err := s.roleRepo.DeleteAssignment(ctx, assignment.ID); err != nil {
+			return err
+		}
+		return s.recordChange(ctx, assignment, model.RoleAuditRevoke, revokedBy, reason, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Revoked role assignment %s of user %s by %s", assignmentID, userID, revokedBy)
+	return nil
+}
+
+// recordChange writes the audit entry and makes the user's sessions pick up the new role on their next
+// request.
+func (s *roleService) recordChange(ctx context.Context, assignment *model.RoleAssignment, action model.RoleAuditAction, actorID uuid.UUID, reason string, at time.Time) error {
+	entry := &model.RoleAuditEntry{
+		ID:                 uuid.New(),
+		AssignmentID:       assignment.ID,
+		UserID:             assignment.UserID,
+		Role:               assignment.Role,
+		ClassTransactionID: assignment.ClassTransactionID,
+		Action:             action,
+		ActorID:            actor(actorID),
+		Reason:             strings.TrimSpace(reason),
+		CreatedAt:          at,
+	}
+	if err := s.roleRepo.SaveAuditEntry(ctx, entry); err != nil {
+		return err
+	}
+	return s.sessionRepo.RequireRefresh(ctx, assignment.UserID, at)
+}
+
+func (s *roleService) GetUserRoles(ctx context.Context, userID uuid.UUID) (*responses.UserRolesResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.roleRepo.FindAssignmentsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.UserRolesResponse{
+		UserID:        user.ID.String(),
+		Username:      user.Username,
+		BaseRole:      user.Role.String(),
+		EffectiveRole: s.strongestRole(user, assignments).String(),
+		Assignments:   make([]responses.RoleAssignmentResponse, 0, len(assignments)),
+	}
+	for _, assignment := range assignments {
+		resp.Assignments = append(resp.Assignments, toAssignmentResponse(assignment))
+	}
+	return resp, nil
+}
+
+func (s *roleService) GetAuditTrail(ctx context.Context, userID *uuid.UUID, limit, offset int) (*responses.RoleAuditListResponse, error) {
+	entries, total, err := s.roleRepo.FindAuditEntries(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &responses.RoleAuditListResponse{
+		Entries: make([]responses.RoleAuditEntryResponse, 0, len(entries)),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, responses.RoleAuditEntryResponse{
+			ID:                 entry.ID.String(),
+			AssignmentID:       entry.AssignmentID.String(),
+			UserID:             entry.UserID.String(),
+			Role:               entry.Role.String(),
+			ClassTransactionID: optionalID(entry.ClassTransactionID),
+			Action:             string(entry.Action),
+			ActorID:            optionalID(entry.ActorID),
+			Reason:             entry.Reason,
+			CreatedAt:          entry.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+func (s *roleService) EffectiveRole(ctx context.Context, user *model.User) (model.Role, error) {
+	assignments, err := s.roleRepo.FindAssignmentsByUserID(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	return s.strongestRole(user, assignments), nil
+}
+
+func (s *roleService) CanManageClass(ctx context.Context, userID uuid.UUID, role model.Role, classTransactionID uuid.UUID) (bool, error) {
+	switch role {
+	case model.RoleAdmin:
+		return true, nil
+	case model.RoleLecturer:
+		return s.roleRepo.HasClassRole(ctx, userID, model.RoleLecturer, classTransactionID)
+	}
+	return false, nil
+}
+
+func (s *roleService) strongestRole(user *model.User, assignments []model.RoleAssignment) model.Role {
+	if isBootstrapAdmin(user.Username) {
+		return model.RoleAdmin
+	}
+	role := user.Role
+	for _, assignment := range assignments {
+		if assignment.Role.Rank() > role.Rank() {
+			role = assignment.Role
+		}
+	}
+	return role
+}
+
+func (s *roleService) findUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	return user, nil
+}
+
+func isBootstrapAdmin(username string) bool {
+	admin := os.Getenv("ADMIN_USERNAME")
+	return admin != "" && strings.EqualFold(username, admin)
+}
+
+func toAssignmentResponse(assignment model.RoleAssignment) responses.RoleAssignmentResponse {
+	return responses.RoleAssignmentResponse{
+		ID:                 assignment.ID.String(),
+		UserID:             assignment.UserID.String(),
+		Role:               assignment.Role.String(),
+		ClassTransactionID: optionalID(assignment.ClassTransactionID),
+		GrantedBy:          optionalID(assignment.GrantedBy),
+		GrantedAt:          assignment.GrantedAt,
+	}
+}
+
+// actor turns the ID of whoever made a change into a nullable column; uuid.Nil means the system.
+func actor(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
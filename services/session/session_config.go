@@ -0,0 +1,46 @@
+package sessionServ
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+	// sessionCheckTTL is how long RequireAuth trusts a cached answer. Revocations made by this process
+	// apply at once; those made by another replica apply within this time.
+	sessionCheckTTL = 15 * time.Second
+	// refreshReuseGrace tolerates two tabs refreshing with the same token at once: the loser is turned
+	// away without revoking the session.
+	refreshReuseGrace = 10 * time.Second
+	sessionRetention  = 7 * 24 * time.Hour
+	cleanupInterval   = time.Hour
+)
+
+type sessionConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+func loadSessionConfig() sessionConfig {
+	return sessionConfig{
+		AccessTokenTTL:  durationFromEnv("ACCESS_TOKEN_TTL_MINUTES", time.Minute, defaultAccessTokenTTL),
+		RefreshTokenTTL: durationFromEnv("REFRESH_TOKEN_TTL_HOURS", time.Hour, defaultRefreshTokenTTL),
+	}
+}
+
+func durationFromEnv(key string, unit time.Duration, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, raw, fallback)
+		return fallback
+	}
+	return time.Duration(value) * unit
+}
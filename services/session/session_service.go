@@ -0,0 +1,55 @@
+package sessionServ
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/responses"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// RoleResolver works out the role that goes into a user's access token.
+type RoleResolver interface {
+	EffectiveRole(ctx context.Context, user *model.User) (model.Role, error)
+}
+
+// Meta describes the client a session was started from.
+type Meta struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Tokens are handed to the client after a login or refresh. The refresh token is only ever returned here;
+// the server keeps its hash.
+type Tokens struct {
+	SessionID           uuid.UUID
+	Role                model.Role // Role carried by the access token
+	AccessToken         string
+	AccessTokenExpires  time.Time
+	RefreshToken        string
+	RefreshTokenExpires time.Time
+}
+
+type SessionService interface {
+	Start(ctx context.Context, user *model.User, meta Meta) (*Tokens, error)
+	// Refresh rotates the refresh token and issues an access token with the user's current name and role.
+	// Presenting a refresh token that was already rotated revokes the whole session.
+	Refresh(ctx context.Context, refreshToken string) (*Tokens, error)
+	// Revoke ends one session of the user; sessions of other users are reported as not found.
+	Revoke(ctx context.Context, userID, sessionID uuid.UUID, reason string) error
+	// RevokeAllForUser ends every session of the user except keep (uuid.Nil keeps none).
+	RevokeAllForUser(ctx context.Context, userID, keep uuid.UUID, reason string) (int, error)
+	GetActiveSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]responses.SessionResponse, error)
+	// IsSessionActive is checked by RequireAuth on every request. It also turns down access tokens issued
+	// before the user's roles last changed. Answers are cached for a few seconds.
+	IsSessionActive(ctx context.Context, sessionID string, issuedAt time.Time) (bool, error)
+	// StartCleanup deletes long expired and revoked sessions until ctx is cancelled.
+	StartCleanup(ctx context.Context)
+}
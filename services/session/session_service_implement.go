@@ -0,0 +1,285 @@
+package sessionServ
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/jwt"
+	"neptune/backend/pkg/responses"
+	sessionRepo "neptune/backend/repositories/session"
+	userRepo "neptune/backend/repositories/user"
+	"sync"
+	"time"
+)
+
+const maxCachedSessions = 10000
+
+type sessionCheck struct {
+	active            bool
+	refreshRequiredAt *time.Time
+	checkedAt         time.Time
+}
+
+type sessionService struct {
+	sessionRepo sessionRepo.SessionRepository
+	userRepo    userRepo.UserRepository
+	roles       RoleResolver
+	config      sessionConfig
+
+	cacheMu sync.Mutex
+	cache   map[uuid.UUID]sessionCheck
+}
+
+func NewSessionService(sessionRepo sessionRepo.SessionRepository, userRepo userRepo.UserRepository, roles RoleResolver) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		roles:       roles,
+		config:      loadSessionConfig(),
+		cache:       make(map[uuid.UUID]sessionCheck),
+	}
+}
+
+func (s *sessionService) Start(ctx context.Context, user *model.User, meta Meta) (*Tokens, error) {
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		ID:               uuid.New(),
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        truncate(meta.UserAgent, 512),
+		IPAddress:        truncate(meta.IPAddress, 64),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.config.RefreshTokenTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user, session.ID, refreshToken, session.ExpiresAt)
+}
+
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	hash := hashToken(refreshToken)
+	now := time.Now()
+
+	session, err := s.sessionRepo.FindByRefreshTokenHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, s.handleStaleRefreshToken(ctx, hash, now)
+	}
+	if !session.Active(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		_ = s.revoke(ctx, session.ID, "user deleted", now)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(s.config.RefreshTokenTTL)
+	rotated, err := s.sessionRepo.Rotate(ctx, session.ID, hash, newHash, now, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Another request rotated the token between our read and write.
+		return nil, ErrInvalidRefreshToken
+	}
+	return s.issue(ctx, user, session.ID, newToken, expiresAt)
+}
+
+// handleStaleRefreshToken decides what an unknown refresh token means. A token that was rotated away
+// moments ago is a benign race; an older one was most likely stolen, so its session is revoked.
+func (s *sessionService) handleStaleRefreshToken(ctx context.Context, hash string, now time.Time) error {
+	session, err := s.sessionRepo.FindByPreviousRefreshTokenHash(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return ErrInvalidRefreshToken
+	}
+	if session.RotatedAt != nil && now.Sub(*session.RotatedAt) < refreshReuseGrace {
+		return ErrInvalidRefreshToken
+	}
+
+	log.Printf("Refresh token reuse detected for session %s of user %s, revoking it", session.ID, session.UserID)
+	if err := s.revoke(ctx, session.ID, "refresh token reuse", now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID uuid.UUID, reason string) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return s.revoke(ctx, sessionID, reason, time.Now())
+}
+
+func (s *sessionService) RevokeAllForUser(ctx context.Context, userID, keep uuid.UUID, reason string) (int, error) {
+	ids, err := s.sessionRepo.RevokeAllForUser(ctx, userID, keep, reason, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.remember(id, sessionCheck{active: false})
+	}
+	return len(ids), nil
+}
+
+func (s *sessionService) GetActiveSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]responses.SessionResponse, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]responses.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, responses.SessionResponse{
+			ID:         session.ID.String(),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+func (s *sessionService) IsSessionActive(ctx context.Context, sessionID string, issuedAt time.Time) (bool, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return false, nil
+	}
+
+	s.cacheMu.Lock()
+	check, ok := s.cache[id]
+	s.cacheMu.Unlock()
+	if !ok || time.Since(check.checkedAt) >= sessionCheckTTL {
+		session, err := s.sessionRepo.FindByID(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		check = sessionCheck{}
+		if session != nil {
+			check = sessionCheck{active: session.Active(time.Now()), refreshRequiredAt: session.RefreshRequiredAt}
+		}
+		s.remember(id, check)
+	}
+
+	if !check.active {
+		return false, nil
+	}
+	return model.Session{RefreshRequiredAt: check.refreshRequiredAt}.AcceptsAccessToken(issuedAt), nil
+}
+
+func (s *sessionService) StartCleanup(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.sessionRepo.DeleteExpired(ctx, time.Now().Add(-sessionRetention))
+			if err != nil {
+				log.Printf("Failed to delete expired sessions: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Deleted %d expired sessions", deleted)
+			}
+		}
+	}
+}
+
+func (s *sessionService) revoke(ctx context.Context, sessionID uuid.UUID, reason string, at time.Time) error {
+	if err := s.sessionRepo.Revoke(ctx, sessionID, reason, at); err != nil {
+		return err
+	}
+	s.remember(sessionID, sessionCheck{active: false})
+	return nil
+}
+
+func (s *sessionService) remember(sessionID uuid.UUID, check sessionCheck) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if len(s.cache) >= maxCachedSessions {
+		for id, cached := range s.cache {
+			if time.Since(cached.checkedAt) >= sessionCheckTTL {
+				delete(s.cache, id)
+			}
+		}
+	}
+	check.checkedAt = time.Now()
+	s.cache[sessionID] = check
+}
+
+func (s *sessionService) issue(ctx context.Context, user *model.User, sessionID uuid.UUID, refreshToken string, refreshExpires time.Time) (*Tokens, error) {
+	role, err := s.roles.EffectiveRole(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve role of user %s: %w", user.ID, err)
+	}
+
+	accessExpires := time.Now().Add(s.config.AccessTokenTTL)
+	accessToken, err := jwt.CreateJWT(user.ID.String(), user.Username, user.Name, role, sessionID.String(), accessExpires)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access token: %w", err)
+	}
+	return &Tokens{
+		SessionID:           sessionID,
+		Role:                role,
+		AccessToken:         accessToken,
+		AccessTokenExpires:  accessExpires,
+		RefreshToken:        refreshToken,
+		RefreshTokenExpires: refreshExpires,
+	}, nil
+}
+
+func newRefreshToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
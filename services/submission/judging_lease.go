@@ -0,0 +1,145 @@
+package submissionServ
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultJudgingLeaseTTL        = 60 * time.Second
+	defaultJudgingReaperInterval  = 30 * time.Second
+	defaultJudgingQueueTimeout    = 10 * time.Minute
+	judgingLeaseHeartbeatFraction = 3 // Heartbeat this many times per lease TTL
+)
+
+// judgingLeaseConfig controls how long a worker may hold a submission without a heartbeat and how often
+// expired leases are reaped.
+type judgingLeaseConfig struct {
+	LeaseTTL       time.Duration // A lease without a heartbeat for this long is considered dead
+	ReaperInterval time.Duration // How often the reaper looks for dead leases
+	QueueTimeout   time.Duration // How long a submission may wait in the queue before it is republished
+}
+
+func loadJudgingLeaseConfig() judgingLeaseConfig {
+	return judgingLeaseConfig{
+		LeaseTTL:       durationFromEnvSeconds("JUDGE_LEASE_TTL_SECONDS", defaultJudgingLeaseTTL),
+		ReaperInterval: durationFromEnvSeconds("JUDGE_REAPER_INTERVAL_SECONDS", defaultJudgingReaperInterval),
+		QueueTimeout:   durationFromEnvSeconds("JUDGE_QUEUE_TIMEOUT_SECONDS", defaultJudgingQueueTimeout),
+	}
+}
+
+func durationFromEnvSeconds(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 {
+		log.Printf("Invalid value %q for %s, using default %s", raw, key, fallback)
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newWorkerID identifies this process in judging leases. JUDGE_WORKER_ID can pin it, e.g. to a pod name.
+func newWorkerID() string {
+	if id := os.Getenv("JUDGE_WORKER_ID"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+}
+
+// startLeaseHeartbeat keeps the lease of a submission alive while it is being judged, and after that
+// until its result is recorded. The returned context is cancelled when the lease is lost or ended, so the
+// judging loop can stop early. Call stop when done.
+func (s *submissionService) startLeaseHeartbeat(ctx context.Context, submissionID uuid.UUID, attempt int) (context.Context, func()) {
+	judgeCtx, cancel := context.WithCancel(ctx)
+	interval := s.leaseConfig.LeaseTTL / judgingLeaseHeartbeatFraction
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-judgeCtx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := s.submissionRepository.RenewJudgingLease(judgeCtx, submissionID, s.workerID, attempt)
+				if err != nil {
+					log.Printf("Failed to renew judging lease for submission %s: %v", submissionID, err)
+					continue
+				}
+				if !renewed {
+					// Lost to the reaper while judging, or ended because the result was recorded
+					log.Printf("Judging lease for submission %s (attempt %d) is no longer held by this worker", submissionID, attempt)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return judgeCtx, cancel
+}
+
+// holdLeaseForResult lets the heartbeat run on after the result was published. It ends by itself once the
+// result consumer records the verdict and clears the lease. Should the result never arrive, the heartbeat
+// is stopped after the queue timeout and the reaper requeues the submission.
+func (s *submissionService) holdLeaseForResult(stopHeartbeat func()) {
+	time.AfterFunc(s.leaseConfig.QueueTimeout, stopHeartbeat)
+}
+
+// startLeaseReaper periodically requeues submissions whose judging lease expired, or that have waited in
+// the queue for too long without any worker picking them up. It runs once immediately on startup.
+func (s *submissionService) startLeaseReaper(ctx context.Context) {
+	ticker := time.NewTicker(s.leaseConfig.ReaperInterval)
+	defer ticker.Stop()
+
+	for {
+		s.reapExpiredLeases(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *submissionService) reapExpiredLeases(ctx context.Context) {
+	now := time.Now()
+	expired, err := s.submissionRepository.FindExpiredJudgingLeases(ctx, now.Add(-s.leaseConfig.LeaseTTL), now.Add(-s.leaseConfig.QueueTimeout))
+	if err != nil {
+		log.Printf("Error fetching expired judging leases: %v", err)
+		return
+	}
+
+	for i := range expired {
+		submission := &expired[i]
+		released, err := s.submissionRepository.ReleaseExpiredJudgingLease(ctx, submission)
+		if err != nil {
+			log.Printf("Error releasing judging lease for submission %s: %v", submission.ID, err)
+			continue
+		}
+		if !released {
+			// Another replica reaped it first, or the worker came back.
+			continue
+		}
+
+		if err := s.publishJudgeJob(ctx, submission.ID); err != nil {
+			log.Printf("Failed to re-queue submission %s: %v", submission.ID, err)
+			continue
+		}
+		log.Printf("Re-queued submission %s (lease held by %q, attempt %d)", submission.ID, submission.JudgingWorkerID, submission.JudgingAttempt)
+	}
+}
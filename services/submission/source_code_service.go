@@ -4,69 +4,72 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
+	"neptune/backend/pkg/storage"
 	submissionRepo "neptune/backend/repositories/submission"
-	"os"
+	fileServ "neptune/backend/services/file"
 	"path/filepath"
-	"strings"
 )
 
 // SubmissionReviewService defines the interface for reviewing submission code.
 type SubmissionReviewService interface {
-	GetSubmissionCode(ctx context.Context, submissionID uuid.UUID) ([]byte, string, error)
-	GetSubmissionCodeAsZip(ctx context.Context, submissionID uuid.UUID) (*bytes.Buffer, string, error)
+	GetSubmissionCode(ctx context.Context, submissionID uuid.UUID, viewer fileServ.Viewer) ([]byte, string, error)
+	GetSubmissionCodeAsZip(ctx context.Context, submissionID uuid.UUID, viewer fileServ.Viewer) (*bytes.Buffer, string, error)
 }
 
 type submissionReviewServiceImpl struct {
 	submissionRepo submissionRepo.SubmissionRepository
+	blobStore      storage.BlobStore
+	fileService    fileServ.FileService
 }
 
 // NewSubmissionReviewService creates a new instance of the review service.
-func NewSubmissionReviewService(submissionRepo submissionRepo.SubmissionRepository) SubmissionReviewService {
-	return &submissionReviewServiceImpl{submissionRepo: submissionRepo}
+func NewSubmissionReviewService(submissionRepo submissionRepo.SubmissionRepository, blobStore storage.BlobStore, fileService fileServ.FileService) SubmissionReviewService {
+	return &submissionReviewServiceImpl{submissionRepo: submissionRepo, blobStore: blobStore, fileService: fileService}
 }
 
 // GetSubmissionCode retrieves the raw source code and its content type.
-func (s *submissionReviewServiceImpl) GetSubmissionCode(ctx context.Context, submissionID uuid.UUID) ([]byte, string, error) {
+// Only the author, admins and assistants of the submission's class may read it.
+func (s *submissionReviewServiceImpl) GetSubmissionCode(ctx context.Context, submissionID uuid.UUID, viewer fileServ.Viewer) ([]byte, string, error) {
+	if err := s.fileService.AuthorizeSubmissionSource(ctx, submissionID, viewer); err != nil {
+		return nil, "", err
+	}
 	submission, err := s.submissionRepo.FindByID(ctx, submissionID.String())
 	if err != nil {
 		return nil, "", fmt.Errorf("submission with ID %s not found: %w", submissionID, err)
 	}
 
-	// The path stored in DB is like "/public/submissions/..."
-	// We need a local file system path, so we trim the leading slash.
-	filePath := strings.TrimPrefix(submission.SourceCodePath, "/")
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, "", fmt.Errorf("source code file not found on disk for submission %s", submissionID)
-	}
-
-	code, err := os.ReadFile(filePath)
+	// The path stored in DB is like "/private/submissions/<id>/main.cpp"; the blob key is the same path without the leading slash.
+	filePath := storage.KeyFromURL(submission.SourceCodePath)
+	code, err := storage.ReadAll(ctx, s.blobStore, filePath)
 	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, "", fmt.Errorf("source code file not found for submission %s", submissionID)
+		}
 		return nil, "", fmt.Errorf("failed to read source code file: %w", err)
 	}
 
 	// Determine content type for proper browser rendering
-	contentType := "text/plain; charset=utf-8"
-	if strings.HasSuffix(filePath, ".py") {
-		contentType = "text/x-python; charset=utf-8"
-	} else if strings.HasSuffix(filePath, ".go") {
-		contentType = "text/x-go; charset=utf-8"
-	}
+	contentType := fileServ.SourceContentType(filePath)
 
 	return code, contentType, nil
 }
 
 // GetSubmissionCodeAsZip creates a zip archive containing the submission's source code.
-func (s *submissionReviewServiceImpl) GetSubmissionCodeAsZip(ctx context.Context, submissionID uuid.UUID) (*bytes.Buffer, string, error) {
+func (s *submissionReviewServiceImpl) GetSubmissionCodeAsZip(ctx context.Context, submissionID uuid.UUID, viewer fileServ.Viewer) (*bytes.Buffer, string, error) {
+	if err := s.fileService.AuthorizeSubmissionSource(ctx, submissionID, viewer); err != nil {
+		return nil, "", err
+	}
 	submission, err := s.submissionRepo.FindByID(ctx, submissionID.String())
 	if err != nil {
 		return nil, "", fmt.Errorf("submission with ID %s not found: %w", submissionID, err)
 	}
 
-	filePath := strings.TrimPrefix(submission.SourceCodePath, "/")
-	code, err := os.ReadFile(filePath)
+	filePath := storage.KeyFromURL(submission.SourceCodePath)
+	code, err := storage.ReadAll(ctx, s.blobStore, filePath)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read source code file for zipping: %w", err)
 	}
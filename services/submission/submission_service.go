@@ -3,12 +3,16 @@ package submissionServ
 import (
 	"context"
 	"github.com/google/uuid"
+	"neptune/backend/models/user"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
 )
 
 type SubmissionService interface {
-	SubmitCode(ctx context.Context, request *requests.SubmitCodeRequest, userID uuid.UUID) (*responses.SubmitCodeResponse, error)
+	// SubmitCode queues a submission. Students submit officially inside their contest window, to their
+	// virtual participation while it runs, or in upsolve mode once the contest is over; assistants,
+	// lecturers and admins may submit at any time.
+	SubmitCode(ctx context.Context, request *requests.SubmitCodeRequest, userID uuid.UUID, role user.Role) (*responses.SubmitCodeResponse, error)
 	GetSubmissionByUserInContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID, classTransactionID *uuid.UUID) ([]responses.GetUserSubmissionsResponse, error)
 	GetClassContestSubmissions(ctx context.Context, classTransactionID uuid.UUID, contestID uuid.UUID) ([]responses.GetSubmissionPerContestResponse, error)
 	StartListeners() error
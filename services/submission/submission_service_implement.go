@@ -1,24 +1,28 @@
 package submissionServ
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"io"
 	"log"
 	submissionModel "neptune/backend/models/submission"
+	"neptune/backend/models/user"
 	"neptune/backend/pkg/amqp_messages"
+	"neptune/backend/pkg/database"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/storage"
 	submissionRepo "neptune/backend/repositories/submission"
 	testCaseRepo "neptune/backend/repositories/test_case"
 	userRepo "neptune/backend/repositories/user"
 	contestService "neptune/backend/services/contest"
 	judgeServ "neptune/backend/services/judge0"
 	webSocketService "neptune/backend/services/web_socket_service"
-	"os"
-	"path/filepath"
+	"path"
 	"strconv"
 	"time"
 )
@@ -31,32 +35,37 @@ type submissionService struct {
 	judgeClient          judgeServ.Judge0Client
 	webSocketManager     webSocketService.WebSocketService
 	userRepository       userRepo.UserRepository
+	workerID             string
+	leaseConfig          judgingLeaseConfig
+	txManager            database.TransactionManager
+	blobStore            storage.BlobStore
 }
 
-func (s *submissionService) SubmitCode(ctx context.Context, req *requests.SubmitCodeRequest, userID uuid.UUID) (*responses.SubmitCodeResponse, error) {
-	submission := &submissionModel.Submission{
-		ID:                 uuid.New(),
-		CaseID:             req.CaseID,
-		UserID:             userID,
-		LanguageID:         req.LanguageID,
-		ClassTransactionID: req.ClassTransactionID,
-		ContestID:          &req.ContestID,
-		Status:             submissionModel.SubmissionStatusJudging, // Start as In Queue
-		Score:              0,
+func (s *submissionService) SubmitCode(ctx context.Context, req *requests.SubmitCodeRequest, userID uuid.UUID, role user.Role) (*responses.SubmitCodeResponse, error) {
+	admission, err := s.contestService.AdmitSubmission(ctx, req.ContestID, req.CaseID, req.ClassTransactionID, userID, role, req.Mode)
+	if err != nil {
+		return nil, err
 	}
 
-	submissionDir := filepath.Join("public/submissions", submission.ID.String())
-	if err := os.MkdirAll(submissionDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create submission directory: %w", err)
+	submission := &submissionModel.Submission{
+		ID:                     uuid.New(),
+		CaseID:                 req.CaseID,
+		UserID:                 userID,
+		LanguageID:             req.LanguageID,
+		ClassTransactionID:     req.ClassTransactionID,
+		ContestID:              &req.ContestID,
+		Status:                 submissionModel.SubmissionStatusJudging, // Start as In Queue
+		Score:                  0,
+		Mode:                   admission.Mode,
+		VirtualParticipationID: admission.VirtualParticipationID,
 	}
 
 	// Use the validated extension from the request struct
 	fileName := "main" + req.FileExtension
-	sourcePath := filepath.Join(submissionDir, fileName)
-	submission.SourceCodePath = "/" + sourcePath // Store URL path
+	submission.SourceCodePath = "/" + path.Join("private/submissions", submission.ID.String(), fileName) // Store URL path
 
 	// Use the byte slice from the request struct, which could have come from the string or file
-	if err := os.WriteFile(sourcePath, req.SourceCodeBytes, 0644); err != nil {
+	if err := s.blobStore.Put(ctx, storage.KeyFromURL(submission.SourceCodePath), bytes.NewReader(req.SourceCodeBytes), int64(len(req.SourceCodeBytes)), "text/plain"); err != nil {
 		return nil, fmt.Errorf("failed to write source code: %w", err)
 	}
 
@@ -65,24 +74,10 @@ func (s *submissionService) SubmitCode(ctx context.Context, req *requests.Submit
 		return nil, fmt.Errorf("failed to save submission record: %w", err)
 	}
 
-	// --- Publish to RabbitMQ (logic remains the same) ---
-	msgBody, err := json.Marshal(amqp_messages.JudgeQueueMessage{SubmissionID: submission.ID})
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal judge queue message: %w", err)
-	}
-
-	err = s.rabbitChannel.Publish(
-		"",                           // exchange
-		amqp_messages.JudgeQueueName, // routing key (queue name)
-		false,                        // mandatory
-		false,                        // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        msgBody,
-		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to publish to judge queue: %w", err)
+	// --- Publish to RabbitMQ ---
+	// If this fails the submission stays in Judging without a lease and the lease reaper requeues it.
+	if err := s.publishJudgeJob(ctx, submission.ID); err != nil {
+		return nil, err
 	}
 
 	log.Printf("Successfully queued submission %s for judging", submission.ID)
@@ -117,6 +112,7 @@ func (s *submissionService) GetSubmissionByUserInContest(ctx context.Context, us
 			Score:        sub.Score,
 			SubmitTime:   sub.CreatedAt.Format(time.RFC3339),
 			LanguageID:   sub.LanguageID,
+			Mode:         string(sub.Mode),
 		})
 	}
 	return resp, nil
@@ -153,6 +149,7 @@ func (s *submissionService) GetClassContestSubmissions(ctx context.Context, clas
 			Score:        sub.Score,
 			SubmitTime:   sub.CreatedAt.Format(time.RFC3339),
 			LanguageID:   sub.LanguageID,
+			Mode:         string(sub.Mode),
 		})
 	}
 
@@ -160,10 +157,6 @@ func (s *submissionService) GetClassContestSubmissions(ctx context.Context, clas
 }
 
 func (s *submissionService) StartListeners() error {
-	// --- FIX: Re-queue stuck submissions on startup ---
-	go s.requeueStuckSubmissions(context.Background())
-	// --------------------------------------------------
-
 	_, err := s.rabbitChannel.QueueDeclare(amqp_messages.JudgeQueueName, true, false, false, false, nil)
 	if err != nil {
 		return fmt.Errorf("failed to declare judge queue: %w", err)
@@ -173,6 +166,9 @@ func (s *submissionService) StartListeners() error {
 		return fmt.Errorf("failed to declare result queue: %w", err)
 	}
 
+	// Requeue only submissions whose judging lease expired, instead of everything still in Judging.
+	go s.startLeaseReaper(context.Background())
+
 	// Listener for judge_queue
 	judgeMsgs, err := s.rabbitChannel.Consume(amqp_messages.JudgeQueueName, "", false, false, false, false, nil)
 	if err != nil {
@@ -200,55 +196,55 @@ func (s *submissionService) StartListeners() error {
 		}
 	}()
 
-	log.Println("Submission listeners started successfully")
+	log.Printf("Submission listeners started successfully (worker %s)", s.workerID)
 	return nil
 }
 
-// requeueStuckSubmissions finds submissions with a "Judging" status and republishes them.
-func (s *submissionService) requeueStuckSubmissions(ctx context.Context) {
-	log.Println("Checking for stuck submissions to re-queue...")
-
-	// 1. Find all submissions that are stuck in the "Judging" state.
-	stuckSubmissions, err := s.submissionRepository.FindByStatus(ctx, submissionModel.SubmissionStatusJudging)
+// publishJudgeJob puts a submission on the judge queue.
+func (s *submissionService) publishJudgeJob(ctx context.Context, submissionID uuid.UUID) error {
+	msgBody, err := json.Marshal(amqp_messages.JudgeQueueMessage{SubmissionID: submissionID})
 	if err != nil {
-		log.Printf("Error fetching stuck submissions: %v", err)
-		return
+		return fmt.Errorf("failed to marshal judge queue message: %w", err)
 	}
 
-	if len(stuckSubmissions) == 0 {
-		log.Println("No stuck submissions found.")
-		return
+	err = s.rabbitChannel.PublishWithContext(
+		ctx,
+		"",                           // exchange
+		amqp_messages.JudgeQueueName, // routing key (queue name)
+		false,                        // mandatory
+		false,                        // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         msgBody,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish to judge queue: %w", err)
 	}
+	return nil
+}
 
-	log.Printf("Found %d stuck submissions. Re-queueing now...", len(stuckSubmissions))
-
-	// 2. Loop through them and republish a job for each one.
-	for _, submission := range stuckSubmissions {
-		msgBody, err := json.Marshal(amqp_messages.JudgeQueueMessage{SubmissionID: submission.ID})
-		if err != nil {
-			log.Printf("Error marshalling message for stuck submission %s: %v", submission.ID, err)
-			continue // Skip to the next one
-		}
-
-		err = s.rabbitChannel.PublishWithContext(
-			ctx,
-			"",                           // exchange
-			amqp_messages.JudgeQueueName, // routing key
-			false,                        // mandatory
-			false,                        // immediate
-			amqp.Publishing{
-				ContentType: "application/json",
-				Body:        msgBody,
-			},
-		)
-
-		if err != nil {
-			log.Printf("Failed to re-queue submission %s: %v", submission.ID, err)
-		} else {
-			log.Printf("Successfully re-queued submission %s", submission.ID)
-		}
+// publishResult puts the outcome of a judging attempt on the result queue. It reports whether the
+// result was published.
+func (s *submissionService) publishResult(ctx context.Context, resultMsg amqp_messages.ResultQueueMessage) bool {
+	resultBody, err := json.Marshal(resultMsg)
+	if err != nil {
+		log.Printf("Error marshalling result for submission %s: %v", resultMsg.SubmissionID, err)
+		return false
+	}
+	err = s.rabbitChannel.PublishWithContext(ctx, "", amqp_messages.ResultQueueName, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         resultBody,
+	})
+	if err != nil {
+		log.Printf("Error publishing result for submission %s: %v", resultMsg.SubmissionID, err)
+		return false
 	}
+	return true
 }
+
 func (s *submissionService) processSubmissionJob(ctx context.Context, d amqp.Delivery) {
 	defer d.Ack(false) // Acknowledge message when done
 
@@ -258,36 +254,44 @@ func (s *submissionService) processSubmissionJob(ctx context.Context, d amqp.Del
 		return
 	}
 
-	submission, err := s.submissionRepository.FindByID(ctx, msg.SubmissionID.String())
+	// Claim the submission. Without the lease another worker is judging it (or it is already judged),
+	// so this delivery is a duplicate and can be dropped.
+	submission, err := s.submissionRepository.AcquireJudgingLease(ctx, msg.SubmissionID, s.workerID, s.leaseConfig.LeaseTTL)
 	if err != nil {
-		log.Printf("Error finding submission %s: %v", msg.SubmissionID, err)
+		log.Printf("Error acquiring judging lease for submission %s: %v", msg.SubmissionID, err)
 		return
 	}
+	if submission == nil {
+		log.Printf("Skipping submission %s: already judged or leased by another worker", msg.SubmissionID)
+		return
+	}
+	attempt := submission.JudgingAttempt
+
+	// The verdict is only recorded once the result consumer gets to it, so after publishing the lease is
+	// kept alive until then. Otherwise a slow result queue would get the submission reaped, and the
+	// verdict of this attempt discarded as stale.
+	judgeCtx, stopHeartbeat := s.startLeaseHeartbeat(ctx, submission.ID, attempt)
+	published := false
+	defer func() {
+		if published {
+			s.holdLeaseForResult(stopHeartbeat)
+			return
+		}
+		stopHeartbeat()
+	}()
 
 	// Helper function to publish an error status and exit
 	publishError := func(status submissionModel.SubmissionStatus) {
-		resultMsg := amqp_messages.ResultQueueMessage{
+		published = s.publishResult(ctx, amqp_messages.ResultQueueMessage{
 			SubmissionID: submission.ID,
 			FinalStatus:  status,
 			Results:      []submissionModel.SubmissionResult{},
 			Score:        0,
-		}
-		resultBody, _ := json.Marshal(resultMsg)
-		s.rabbitChannel.Publish("", amqp_messages.ResultQueueName, false, false, amqp.Publishing{
-			ContentType: "application/json",
-			Body:        resultBody,
+			WorkerID:     s.workerID,
+			Attempt:      attempt,
 		})
 	}
 
-	// Update status to Judging and notify client
-	submission.Status = submissionModel.SubmissionStatusJudging
-	err = s.submissionRepository.Update(ctx, submission)
-	if err != nil {
-		log.Printf("Error updating submission %s to Judging: %v", submission.ID, err)
-		publishError(submissionModel.SubmissionStatusInternalError)
-		return
-	}
-
 	resp := responses.FinalResultResponse{
 		SubmissionID: submission.ID.String(),
 		Status:       submission.Status.String(),
@@ -304,7 +308,7 @@ func (s *submissionService) processSubmissionJob(ctx context.Context, d amqp.Del
 		return
 	}
 
-	sourceCodeBytes, err := os.ReadFile(submission.SourceCodePath[1:]) // remove leading '/'
+	sourceCodeBytes, err := storage.ReadAll(ctx, s.blobStore, storage.KeyFromURL(submission.SourceCodePath))
 	if err != nil {
 		log.Printf("Error reading source code for submission %s: %v", submission.ID, err)
 		publishError(submissionModel.SubmissionStatusInternalError)
@@ -316,31 +320,30 @@ func (s *submissionService) processSubmissionJob(ctx context.Context, d amqp.Del
 
 	// ---- Main Judging Loop ----
 	for _, tc := range testcases {
-		inputBytes, err := os.ReadFile(tc.InputUrl[1:])
-		if err != nil {
-			log.Printf("Error reading input file %s: %v", tc.InputUrl, err)
-			overallStatus = submissionModel.SubmissionStatusInternalError
-			break
+		if judgeCtx.Err() != nil {
+			// Lease lost: whoever holds it now will publish the verdict.
+			return
 		}
 
-		expectedOutputBytes, err := os.ReadFile(tc.OutputUrl[1:])
+		// Testcases can be large, so input and expected output are streamed and only a preview is kept
+		inputPreview := &resultPreview{}
+		judgeResult, err := s.runTestcase(judgeCtx, string(sourceCodeBytes), tc.InputUrl, inputPreview, submission.LanguageID)
 		if err != nil {
-			log.Printf("Error reading output file %s: %v", tc.OutputUrl, err)
+			log.Printf("Error judging testcase %d of submission %s: %v", tc.Number, submission.ID, err)
 			overallStatus = submissionModel.SubmissionStatusInternalError
 			break
 		}
-		expectedOutput := string(expectedOutputBytes)
 
-		// Call Judge0
-		judgeResult, err := s.judgeClient.SubmitCode(string(sourceCodeBytes), string(inputBytes), submission.LanguageID)
+		expectedPreview := &resultPreview{}
+		outputMatches, err := s.matchExpectedOutput(judgeCtx, tc.OutputUrl, judgeResult.Stdout, expectedPreview)
 		if err != nil {
-			log.Printf("Error submitting to Judge0: %v", err)
+			log.Printf("Error reading output file %s: %v", tc.OutputUrl, err)
 			overallStatus = submissionModel.SubmissionStatusInternalError
 			break
 		}
 
 		// 1. Convert Judge0 status to our internal status
-		currentTestcaseStatus := mapJudge0Status(judgeResult.Status.ID, judgeResult.Stdout, expectedOutput)
+		currentTestcaseStatus := mapJudge0Status(judgeResult.Status.ID, outputMatches)
 
 		// 2. Build the detailed result object
 		time, _ := strconv.ParseFloat(judgeResult.Time, 64)
@@ -350,8 +353,8 @@ func (s *submissionService) processSubmissionJob(ctx context.Context, d amqp.Del
 			Status:         currentTestcaseStatus,
 			TimeSeconds:    time,
 			MemoryKB:       judgeResult.Memory,
-			Input:          string(inputBytes),
-			ExpectedOutput: expectedOutput,
+			Input:          inputPreview.String(),
+			ExpectedOutput: expectedPreview.String(),
 			ActualOutput:   judgeResult.Stdout,
 		}
 
@@ -377,29 +380,101 @@ func (s *submissionService) processSubmissionJob(ctx context.Context, d amqp.Del
 	}
 
 	// ---- Post-Judging ----
+	if judgeCtx.Err() != nil {
+		return
+	}
+
 	resultMsg := amqp_messages.ResultQueueMessage{
 		SubmissionID: submission.ID,
 		FinalStatus:  overallStatus,
 		Results:      results,
 		Score:        0,
+		WorkerID:     s.workerID,
+		Attempt:      attempt,
 	}
 
 	if overallStatus == submissionModel.SubmissionStatusAccepted {
 		resultMsg.Score = 100
 	}
 
-	resultBody, _ := json.Marshal(resultMsg)
-	s.rabbitChannel.Publish("", amqp_messages.ResultQueueName, false, false, amqp.Publishing{
-		ContentType: "application/json",
-		Body:        resultBody,
-	})
+	published = s.publishResult(ctx, resultMsg)
+}
+
+// runTestcase streams the input of a testcase from the blob store into Judge0, keeping a preview of it.
+func (s *submissionService) runTestcase(ctx context.Context, sourceCode, inputURL string, preview *resultPreview, languageID int) (*judgeServ.Judge0Result, error) {
+	input, err := s.blobStore.Get(ctx, storage.KeyFromURL(inputURL))
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	return s.judgeClient.SubmitCode(ctx, sourceCode, io.TeeReader(input, preview), languageID)
+}
+
+// matchExpectedOutput compares the expected output in the blob store with stdout while streaming it,
+// keeping a preview of it.
+func (s *submissionService) matchExpectedOutput(ctx context.Context, outputURL, stdout string, preview *resultPreview) (bool, error) {
+	expected, err := s.blobStore.Get(ctx, storage.KeyFromURL(outputURL))
+	if err != nil {
+		return false, err
+	}
+	defer expected.Close()
+	matches, err := outputMatches(io.TeeReader(expected, preview), stdout)
+	if err != nil {
+		return false, fmt.Errorf("failed to read blob %s: %w", outputURL, err)
+	}
+	// A mismatch stops reading early; read on so the preview is as complete as on a match
+	if _, err := io.CopyN(io.Discard, io.TeeReader(expected, preview), int64(preview.remaining())); err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read blob %s: %w", outputURL, err)
+	}
+	return matches, nil
 }
 
-func mapJudge0Status(judgeStatusID int, stdout, expectedOutput string) submissionModel.SubmissionStatus {
+// outputMatches reports whether expected holds exactly stdout, reading it a chunk at a time.
+func outputMatches(expected io.Reader, stdout string) (bool, error) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := expected.Read(buf)
+		if n > len(stdout) || string(buf[:n]) != stdout[:n] {
+			return false, nil
+		}
+		stdout = stdout[n:]
+		if err == io.EOF {
+			return stdout == "", nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// maxResultPreviewBytes caps the input and expected output kept with each testcase result.
+const maxResultPreviewBytes = 64 << 10
+
+// resultPreview keeps the first maxResultPreviewBytes written to it and drops the rest.
+type resultPreview struct {
+	buf []byte
+}
+
+func (p *resultPreview) Write(b []byte) (int, error) {
+	if n := p.remaining(); n > 0 {
+		p.buf = append(p.buf, b[:min(n, len(b))]...)
+	}
+	return len(b), nil
+}
+
+func (p *resultPreview) remaining() int {
+	return maxResultPreviewBytes - len(p.buf)
+}
+
+func (p *resultPreview) String() string {
+	return string(p.buf)
+}
+
+func mapJudge0Status(judgeStatusID int, outputMatches bool) submissionModel.SubmissionStatus {
 	switch judgeStatusID {
 	case 3: // Accepted
 		// CRITICAL: Judge0's "Accepted" only means the code ran. We must verify the output.
-		if stdout == expectedOutput {
+		if outputMatches {
 			return submissionModel.SubmissionStatusAccepted
 		}
 		return submissionModel.SubmissionStatusWrongAnswer
@@ -439,18 +514,31 @@ func (s *submissionService) processResultJob(ctx context.Context, d amqp.Deliver
 
 	// Create Response to backend
 
-	// Use a transaction to update submission and save results
-	// tx := s.db.Begin() ... (For simplicity, not showing full transaction code)
 	finalScore := getFinalScore(msg.Results)
 	submission.Score = finalScore
-	if err := s.submissionRepository.Update(ctx, submission); err != nil {
+
+	// The verdict and the per-testcase results are written in one transaction, so a crash in between
+	// cannot leave a final status without its results. Only the attempt that currently owns the
+	// submission may write its verdict: a late result from a worker whose lease was reaped, or a
+	// redelivered message, must not overwrite a newer verdict.
+	applied := false
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		applied, err = s.submissionRepository.CompleteJudging(txCtx, submission, msg.Attempt)
+		if err != nil || !applied {
+			return err
+		}
+		if err := s.submissionRepository.SaveResultsBatch(txCtx, msg.Results); err != nil {
+			return fmt.Errorf("failed to save batch results: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		log.Printf("Error performing final update on submission %s: %v", submission.ID, err)
 		return
 	}
-
-	// Save the detailed per-testcase results
-	if err := s.submissionRepository.SaveResultsBatch(ctx, msg.Results); err != nil {
-		log.Printf("Error saving batch results for submission %s: %v", submission.ID, err)
+	if !applied {
+		log.Printf("Ignoring stale result for submission %s (attempt %d from worker %q)", submission.ID, msg.Attempt, msg.WorkerID)
 		return
 	}
 
@@ -501,7 +589,9 @@ func NewSubmissionService(repo submissionRepo.SubmissionRepository,
 	judgeClient judgeServ.Judge0Client,
 	webSocketManager webSocketService.WebSocketService,
 	contestServ contestService.ContestService,
-	userRepo userRepo.UserRepository) SubmissionService {
+	userRepo userRepo.UserRepository,
+	txManager database.TransactionManager,
+	blobStore storage.BlobStore) SubmissionService {
 	return &submissionService{
 		submissionRepository: repo,
 		testCaseRepository:   testCaseRepo,
@@ -510,5 +600,9 @@ func NewSubmissionService(repo submissionRepo.SubmissionRepository,
 		webSocketManager:     webSocketManager,
 		contestService:       contestServ,
 		userRepository:       userRepo, // Assuming you have a user repository
+		workerID:             newWorkerID(),
+		leaseConfig:          loadJudgingLeaseConfig(),
+		txManager:            txManager,
+		blobStore:            blobStore,
 	}
 }
@@ -0,0 +1,60 @@
+package submissionServ
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestOutputMatches(t *testing.T) {
+	long := strings.Repeat("0123456789\n", 10000)
+	tests := []struct {
+		name     string
+		expected string
+		stdout   string
+		want     bool
+	}{
+		{"equal", "3\n", "3\n", true},
+		{"both empty", "", "", true},
+		{"different", "3\n", "4\n", false},
+		{"stdout shorter", "3\n", "3", false},
+		{"stdout longer", "3", "3\n", false},
+		{"empty stdout", "3\n", "", false},
+		{"long equal", long, long, true},
+		{"long differs at the end", long, long[:len(long)-1] + "x", false},
+		{"long stdout cut", long, long[:len(long)/2], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range []io.Reader{strings.NewReader(tt.expected), iotest.OneByteReader(strings.NewReader(tt.expected)), iotest.DataErrReader(strings.NewReader(tt.expected))} {
+				got, err := outputMatches(r, tt.stdout)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("outputMatches = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputMatchesReportsReadErrors(t *testing.T) {
+	if _, err := outputMatches(iotest.ErrReader(io.ErrUnexpectedEOF), "3\n"); err == nil {
+		t.Error("a failed read was taken for an answer")
+	}
+}
+
+func TestResultPreviewKeepsTheStart(t *testing.T) {
+	preview := &resultPreview{}
+	chunk := strings.Repeat("x", 40<<10)
+	for i := 0; i < 3; i++ {
+		if n, err := preview.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write = %d, %v", n, err)
+		}
+	}
+	if len(preview.String()) != maxResultPreviewBytes || preview.remaining() != 0 {
+		t.Errorf("preview holds %d bytes, want %d", len(preview.String()), maxResultPreviewBytes)
+	}
+}
@@ -0,0 +1,110 @@
+package testCaseServ
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"log"
+	"neptune/backend/pkg/responses"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidArchive marks uploads rejected because of their content rather than a server failure.
+var ErrInvalidArchive = errors.New("invalid testcase archive")
+
+const (
+	defaultArchiveMaxFiles         = 2000
+	defaultArchiveMaxFileBytes     = 64 << 20  // 64 MiB per file
+	defaultArchiveMaxTotalBytes    = 512 << 20 // 512 MiB uncompressed
+	defaultArchiveMaxCompression   = 200       // uncompressed/compressed ratio
+	archiveCompressionCheckMinSize = 1 << 20   // tiny files compress extremely well, only check larger ones
+	defaultReplacedGrace           = time.Hour
+)
+
+// ArchiveLimits protect the importers of testcase archives and problem packages against zip bombs and
+// oversized uploads.
+type ArchiveLimits struct {
+	MaxFiles         int
+	MaxFileBytes     uint64
+	MaxTotalBytes    uint64
+	MaxCompressRatio uint64
+}
+
+func LoadArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxFiles:         int(uintFromEnv("TESTCASE_ARCHIVE_MAX_FILES", defaultArchiveMaxFiles)),
+		MaxFileBytes:     uintFromEnv("TESTCASE_ARCHIVE_MAX_FILE_BYTES", defaultArchiveMaxFileBytes),
+		MaxTotalBytes:    uintFromEnv("TESTCASE_ARCHIVE_MAX_TOTAL_BYTES", defaultArchiveMaxTotalBytes),
+		MaxCompressRatio: uintFromEnv("TESTCASE_ARCHIVE_MAX_COMPRESSION_RATIO", defaultArchiveMaxCompression),
+	}
+}
+
+// loadReplacedGrace reads TESTCASE_REPLACED_GRACE_SECONDS. It must stay well above the judge queue timeout
+// and the time judging one submission takes.
+func loadReplacedGrace() time.Duration {
+	return time.Duration(uintFromEnv("TESTCASE_REPLACED_GRACE_SECONDS", uint64(defaultReplacedGrace/time.Second))) * time.Second
+}
+
+func uintFromEnv(key string, fallback uint64) uint64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || value == 0 {
+		log.Printf("Invalid value %q for %s, using default %d", raw, key, fallback)
+		return fallback
+	}
+	return value
+}
+
+// Check validates the declared sizes of a single entry.
+func (l ArchiveLimits) Check(f *zip.File) error {
+	if f.UncompressedSize64 > l.MaxFileBytes {
+		return fmt.Errorf("file expands to %d bytes, the limit is %d", f.UncompressedSize64, l.MaxFileBytes)
+	}
+	if f.UncompressedSize64 >= archiveCompressionCheckMinSize {
+		if f.CompressedSize64 == 0 || f.UncompressedSize64/f.CompressedSize64 > l.MaxCompressRatio {
+			return fmt.Errorf("compression ratio is suspiciously high (possible zip bomb)")
+		}
+	}
+	return nil
+}
+
+func failImport(report *responses.TestCaseImportReport, err error) (*responses.TestCaseImportReport, error) {
+	report.Imported = false
+	report.Errors = append(report.Errors, err.Error())
+	for i := range report.Files {
+		if report.Files[i].Status == "" || report.Files[i].Status == fileStatusOK {
+			report.Files[i].Status = fileStatusSkip
+			report.Files[i].TestCaseNumber = 0
+			report.Files[i].Message = "not imported, the existing testcases were kept"
+		}
+	}
+	return report, err
+}
+
+func markFileImported(report *responses.TestCaseImportReport, entry *archiveEntry, number int) {
+	if fileReport := findFileReport(report, entry.Path); fileReport != nil {
+		fileReport.Status = fileStatusOK
+		fileReport.TestCaseNumber = number
+	}
+}
+
+func markFileSkipped(report *responses.TestCaseImportReport, entry *archiveEntry, message string) {
+	if fileReport := findFileReport(report, entry.Path); fileReport != nil {
+		fileReport.Status = fileStatusSkip
+		fileReport.Message = message
+	}
+}
+
+func findFileReport(report *responses.TestCaseImportReport, filePath string) *responses.TestCaseImportFileReport {
+	for i := range report.Files {
+		if report.Files[i].Path == filePath {
+			return &report.Files[i]
+		}
+	}
+	return nil
+}
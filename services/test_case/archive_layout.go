@@ -0,0 +1,203 @@
+package testCaseServ
+
+import (
+	"archive/zip"
+	"neptune/backend/pkg/utils"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Supported archive layouts. Files are matched against the rules below in order; the first match decides
+// whether a file is an input or an output and which testcase it belongs to.
+//
+//	flat / per folder   1.in + 1.out (or .ans), also nested: 1/1.in + 1/1.out
+//	input/output dirs   input/input01.txt + output/output01.txt, in/1.txt + out/1.txt
+//	Polygon             tests/01 + tests/01.a
+//	CMS                 input.0 + output.0, input0.txt + output0.txt
+//
+// Folders holding exactly one unmatched input and one unmatched output (the layout the importer used to
+// require) are paired as a fallback.
+const (
+	layoutFlat       = "flat"
+	layoutInOutDirs  = "input-output-dirs"
+	layoutPolygon    = "polygon"
+	layoutCMS        = "cms"
+	layoutPerFolder  = "per-folder"
+	layoutMixed      = "mixed"
+	roleInput        = "input"
+	roleOutput       = "output"
+	fileStatusOK     = "imported"
+	fileStatusSkip   = "skipped"
+	fileStatusReject = "rejected"
+)
+
+var (
+	polygonInputPattern  = regexp.MustCompile(`^\d+$`)
+	polygonOutputPattern = regexp.MustCompile(`^(\d+)\.a$`)
+	cmsPattern           = regexp.MustCompile(`^(input|output)[._-]?(\d+)(\.txt)?$`)
+	inOutPrefixPattern   = regexp.MustCompile(`^(input|output|in|out)[._-]?`)
+)
+
+var (
+	inputDirNames  = map[string]bool{"input": true, "inputs": true, "in": true}
+	outputDirNames = map[string]bool{"output": true, "outputs": true, "out": true, "answer": true, "answers": true}
+)
+
+// archiveEntry is one file of the archive after classification.
+type archiveEntry struct {
+	File   *zip.File
+	Path   string // normalised path inside the archive
+	Role   string // roleInput or roleOutput
+	Key    string // entries sharing a key form one testcase
+	Layout string
+}
+
+// testcasePair is an input/output pair that will become one testcase.
+type testcasePair struct {
+	Key    string
+	Input  *archiveEntry
+	Output *archiveEntry
+}
+
+// classifyArchiveEntry decides the role and testcase key of a file, or returns ok=false when the file
+// does not follow any supported naming convention.
+func classifyArchiveEntry(filePath string) (role string, key string, layout string, ok bool) {
+	dir, name := path.Split(filePath)
+	dir = strings.TrimSuffix(dir, "/")
+	lowerName := strings.ToLower(name)
+	parentDir, dirName := path.Split(dir)
+	parentDir = strings.TrimSuffix(parentDir, "/")
+	lowerDirName := strings.ToLower(dirName)
+	ext := path.Ext(lowerName)
+	stem := strings.TrimSuffix(lowerName, ext)
+
+	// input/... + output/...: the folder decides the role, the file name (minus any input/output prefix
+	// and extension) decides the testcase.
+	if inputDirNames[lowerDirName] || outputDirNames[lowerDirName] {
+		role = roleInput
+		if outputDirNames[lowerDirName] {
+			role = roleOutput
+		}
+		normalized := inOutPrefixPattern.ReplaceAllString(stem, "")
+		if normalized == "" {
+			normalized = stem
+		}
+		return role, path.Join(parentDir, normalized), layoutInOutDirs, true
+	}
+
+	switch ext {
+	case ".in":
+		return roleInput, path.Join(dir, stem), layoutFlat, true
+	case ".out", ".ans":
+		return roleOutput, path.Join(dir, stem), layoutFlat, true
+	}
+
+	if lowerDirName == "tests" {
+		if polygonInputPattern.MatchString(lowerName) {
+			return roleInput, path.Join(dir, lowerName), layoutPolygon, true
+		}
+		if m := polygonOutputPattern.FindStringSubmatch(lowerName); m != nil {
+			return roleOutput, path.Join(dir, m[1]), layoutPolygon, true
+		}
+	}
+
+	if m := cmsPattern.FindStringSubmatch(lowerName); m != nil {
+		role = roleInput
+		if m[1] == "output" {
+			role = roleOutput
+		}
+		return role, path.Join(dir, m[2]), layoutCMS, true
+	}
+
+	return "", "", "", false
+}
+
+// isSampleKey reports testcases an archive marks as samples by name, such as Kattis data/sample/1 or
+// sample01.in.
+func isSampleKey(key string) bool {
+	for _, part := range strings.Split(strings.ToLower(key), "/") {
+		if strings.HasPrefix(part, "sample") || strings.HasPrefix(part, "example") {
+			return true
+		}
+	}
+	return false
+}
+
+// pairArchiveEntries groups classified entries into testcases, sorted naturally by key so numbering is
+// deterministic. Entries that could not be paired are returned separately.
+func pairArchiveEntries(entries []*archiveEntry) ([]testcasePair, []*archiveEntry, []*archiveEntry) {
+	byKey := make(map[string]*testcasePair)
+	var duplicates []*archiveEntry
+	for _, entry := range entries {
+		pair, ok := byKey[entry.Key]
+		if !ok {
+			pair = &testcasePair{Key: entry.Key}
+			byKey[entry.Key] = pair
+		}
+		slot := &pair.Input
+		if entry.Role == roleOutput {
+			slot = &pair.Output
+		}
+		if *slot != nil {
+			duplicates = append(duplicates, entry)
+			continue
+		}
+		*slot = entry
+	}
+
+	var pairs []testcasePair
+	unmatchedByDir := make(map[string][]*archiveEntry)
+	for _, pair := range byKey {
+		if pair.Input != nil && pair.Output != nil {
+			pairs = append(pairs, *pair)
+			continue
+		}
+		for _, entry := range []*archiveEntry{pair.Input, pair.Output} {
+			if entry != nil {
+				dir := path.Dir(entry.Path)
+				unmatchedByDir[dir] = append(unmatchedByDir[dir], entry)
+			}
+		}
+	}
+
+	// Fallback for one testcase per folder with arbitrary file names, e.g. 3/data.in + 3/answer.out.
+	var unmatched []*archiveEntry
+	for dir, leftovers := range unmatchedByDir {
+		if len(leftovers) == 2 && leftovers[0].Role != leftovers[1].Role {
+			pair := testcasePair{Key: dir}
+			for _, entry := range leftovers {
+				entry.Key = dir
+				entry.Layout = layoutPerFolder
+				if entry.Role == roleInput {
+					pair.Input = entry
+				} else {
+					pair.Output = entry
+				}
+			}
+			pairs = append(pairs, pair)
+			continue
+		}
+		unmatched = append(unmatched, leftovers...)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return utils.NaturalLess(pairs[i].Key, pairs[j].Key) })
+	sort.Slice(unmatched, func(i, j int) bool { return utils.NaturalLess(unmatched[i].Path, unmatched[j].Path) })
+	return pairs, unmatched, duplicates
+}
+
+// detectLayout names the layout used by the paired testcases, or "mixed" when several were combined.
+func detectLayout(pairs []testcasePair) string {
+	layout := ""
+	for _, pair := range pairs {
+		for _, l := range []string{pair.Input.Layout, pair.Output.Layout} {
+			if layout == "" {
+				layout = l
+			} else if layout != l {
+				return layoutMixed
+			}
+		}
+	}
+	return layout
+}
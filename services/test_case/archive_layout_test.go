@@ -0,0 +1,249 @@
+package testCaseServ
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/utils"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyArchiveEntry(t *testing.T) {
+	tests := []struct {
+		path   string
+		role   string
+		key    string
+		layout string
+	}{
+		{"1.in", roleInput, "1", layoutFlat},
+		{"1.ans", roleOutput, "1", layoutFlat},
+		{"problem/3/3.OUT", roleOutput, "problem/3/3", layoutFlat},
+		{"input/input01.txt", roleInput, "01", layoutInOutDirs},
+		{"output/output01.txt", roleOutput, "01", layoutInOutDirs},
+		{"problem/in/1.txt", roleInput, "problem/1", layoutInOutDirs},
+		{"problem/answers/1.txt", roleOutput, "problem/1", layoutInOutDirs},
+		{"out/output", roleOutput, "output", layoutInOutDirs},
+		{"tests/01", roleInput, "tests/01", layoutPolygon},
+		{"problem/tests/01.a", roleOutput, "problem/tests/01", layoutPolygon},
+		{"input.0", roleInput, "0", layoutCMS},
+		{"cms/output_12.txt", roleOutput, "cms/12", layoutCMS},
+		{"input7.txt", roleInput, "7", layoutCMS},
+		{"README.md", "", "", ""},
+		{"01", "", "", ""},
+		{"tests/01.b", "", "", ""},
+		{"statement/input.txt", "", "", ""},
+	}
+	for _, tt := range tests {
+		role, key, layout, ok := classifyArchiveEntry(tt.path)
+		if ok != (tt.role != "") || role != tt.role || key != tt.key || layout != tt.layout {
+			t.Errorf("classifyArchiveEntry(%q) = %q, %q, %q, %v; want %q, %q, %q",
+				tt.path, role, key, layout, ok, tt.role, tt.key, tt.layout)
+		}
+	}
+}
+
+func TestIsSampleKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"data/sample/1":    true,
+		"Sample01":         true,
+		"examples/2":       true,
+		"data/secret/1":    false,
+		"tests/01":         false,
+		"problem/resample": false,
+	} {
+		if got := isSampleKey(key); got != want {
+			t.Errorf("isSampleKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func classifiedEntries(t *testing.T, paths ...string) []*archiveEntry {
+	t.Helper()
+	entries := make([]*archiveEntry, 0, len(paths))
+	for _, p := range paths {
+		role, key, layout, ok := classifyArchiveEntry(p)
+		if !ok {
+			t.Fatalf("%q is not a testcase file", p)
+		}
+		entries = append(entries, &archiveEntry{Path: p, Role: role, Key: key, Layout: layout})
+	}
+	return entries
+}
+
+func TestPairArchiveEntries(t *testing.T) {
+	tests := []struct {
+		name       string
+		paths      []string
+		keys       []string
+		unmatched  []string
+		duplicates []string
+		layout     string
+	}{
+		{
+			name:   "flat, ordered naturally",
+			paths:  []string{"10.in", "2.out", "10.out", "2.in", "1.in", "1.ans"},
+			keys:   []string{"1", "2", "10"},
+			layout: layoutFlat,
+		},
+		{
+			name:   "input/output dirs under a root folder",
+			paths:  []string{"p/input/input2.txt", "p/output/output2.txt", "p/input/input10.txt", "p/output/output10.txt"},
+			keys:   []string{"p/2", "p/10"},
+			layout: layoutInOutDirs,
+		},
+		{
+			name:   "one testcase per folder with arbitrary names",
+			paths:  []string{"3/data.in", "3/answer.out", "12/x.in", "12/y.out"},
+			keys:   []string{"3", "12"},
+			layout: layoutPerFolder,
+		},
+		{
+			name:      "a folder with two leftovers of the same role is not paired",
+			paths:     []string{"1/a.in", "1/b.in", "2.in", "2.out"},
+			keys:      []string{"2"},
+			unmatched: []string{"1/a.in", "1/b.in"},
+			layout:    layoutFlat,
+		},
+		{
+			name:       "an output and an answer for the same testcase",
+			paths:      []string{"1.in", "1.out", "1.ans"},
+			keys:       []string{"1"},
+			duplicates: []string{"1.ans"},
+			layout:     layoutFlat,
+		},
+		{
+			name:   "layouts combined",
+			paths:  []string{"tests/1", "tests/1.a", "input.2", "output.2"},
+			keys:   []string{"2", "tests/1"},
+			layout: layoutMixed,
+		},
+		{
+			name:      "nothing pairs",
+			paths:     []string{"1.in", "2.out", "3.in", "tests/3"},
+			unmatched: []string{"1.in", "2.out", "3.in", "tests/3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, unmatched, duplicates := pairArchiveEntries(classifiedEntries(t, tt.paths...))
+			var keys, unmatchedPaths, duplicatePaths []string
+			for _, pair := range pairs {
+				keys = append(keys, pair.Key)
+				if pair.Input.Role != roleInput || pair.Output.Role != roleOutput {
+					t.Errorf("pair %q has roles %s and %s", pair.Key, pair.Input.Role, pair.Output.Role)
+				}
+			}
+			for _, entry := range unmatched {
+				unmatchedPaths = append(unmatchedPaths, entry.Path)
+			}
+			for _, entry := range duplicates {
+				duplicatePaths = append(duplicatePaths, entry.Path)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) || !reflect.DeepEqual(unmatchedPaths, tt.unmatched) ||
+				!reflect.DeepEqual(duplicatePaths, tt.duplicates) {
+				t.Errorf("pairs %v, unmatched %v, duplicates %v; want %v, %v, %v",
+					keys, unmatchedPaths, duplicatePaths, tt.keys, tt.unmatched, tt.duplicates)
+			}
+			if layout := detectLayout(pairs); layout != tt.layout {
+				t.Errorf("layout = %q, want %q", layout, tt.layout)
+			}
+		})
+	}
+}
+
+func testArchive(t *testing.T, names ...string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte("1\n"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestInspectArchive(t *testing.T) {
+	s := testcaseServiceImpl{limits: ArchiveLimits{MaxFiles: 8, MaxFileBytes: 1 << 20, MaxTotalBytes: 1 << 20, MaxCompressRatio: 100}}
+	tests := []struct {
+		name     string
+		files    []string
+		keys     []string
+		statuses map[string]string
+		invalid  bool
+	}{
+		{
+			name:     "Polygon package zipped with its root folder",
+			files:    []string{"problem/", "problem/tests/", "problem/tests/2", "problem/tests/2.a", "problem/tests/10", "problem/tests/10.a", "problem/problem.xml"},
+			keys:     []string{"problem/tests/2", "problem/tests/10"},
+			statuses: map[string]string{"problem/problem.xml": fileStatusSkip},
+		},
+		{
+			name:     "macOS metadata and stray files",
+			files:    []string{"data/1.in", "data/1.out", "__MACOSX/data/._1.in", "data/.DS_Store", "data/2.in"},
+			keys:     []string{"data/1"},
+			statuses: map[string]string{"data/2.in": fileStatusSkip},
+		},
+		{
+			name:     "Windows separators",
+			files:    []string{`root\input\1.txt`, `root\output\1.txt`},
+			keys:     []string{"root/1"},
+			statuses: map[string]string{},
+		},
+		{
+			name:     "an entry outside the archive root",
+			files:    []string{"1.in", "1.out", "../2.in"},
+			statuses: map[string]string{"../2.in": fileStatusReject},
+			invalid:  true,
+		},
+		{
+			name:     "no testcases",
+			files:    []string{"README.md", "1.in"},
+			statuses: map[string]string{"README.md": fileStatusSkip, "1.in": fileStatusSkip},
+			invalid:  true,
+		},
+		{
+			name:     "too many entries",
+			files:    []string{"1.in", "1.out", "2.in", "2.out", "3.in", "3.out", "4.in", "4.out", "5.in"},
+			statuses: map[string]string{},
+			invalid:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &responses.TestCaseImportReport{}
+			pairs, err := s.inspectArchive(testArchive(t, tt.files...), report)
+			if tt.invalid != errors.Is(err, ErrInvalidArchive) {
+				t.Fatalf("err = %v, invalid want %v", err, tt.invalid)
+			}
+			var keys []string
+			for _, pair := range pairs {
+				keys = append(keys, pair.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("testcases %v, want %v", keys, tt.keys)
+			}
+			for filePath, status := range tt.statuses {
+				if fileReport := findFileReport(report, filePath); fileReport == nil || fileReport.Status != status {
+					t.Errorf("report for %s = %+v, want status %q", filePath, fileReport, status)
+				}
+			}
+			for _, fileReport := range report.Files {
+				if utils.IsArchiveMetadata(fileReport.Path) {
+					t.Errorf("metadata file %s is in the report", fileReport.Path)
+				}
+			}
+		})
+	}
+}
@@ -2,11 +2,19 @@ package testCaseServ
 
 import (
 	"context"
+	"errors"
+	"github.com/google/uuid"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
 )
 
+var ErrUnknownTestCase = errors.New("case has no testcase with this number")
+
 type TestCaseService interface {
-	UploadTestCases(ctx context.Context, req requests.AddTestCaseRequest) error
+	// UploadTestCases replaces the testcases of a case. It fails with ErrContestLocked from the contest
+	// service, unless forced, while a contest using the case is running or has submissions for it.
+	UploadTestCases(ctx context.Context, req requests.AddTestCaseRequest, force bool) (*responses.TestCaseImportReport, error)
 	GetTestCasesByCaseID(ctx context.Context, caseID string) ([]responses.TestCaseResponse, error)
+	// SetSamples marks the listed testcases as samples, shown in the statement, and the others as hidden.
+	SetSamples(ctx context.Context, caseID uuid.UUID, req requests.SetSampleTestCasesRequest) ([]responses.TestCaseResponse, error)
 }
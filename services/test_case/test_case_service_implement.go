@@ -4,198 +4,229 @@ import (
 	"archive/zip"
 	"context"
 	"fmt"
-	"io"
+	"github.com/google/uuid"
 	"log"
 	testCaseModel "neptune/backend/models/test_case"
+	"neptune/backend/pkg/database"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
+	"neptune/backend/pkg/storage"
+	"neptune/backend/pkg/utils"
 	caseRepository "neptune/backend/repositories/case"
 	testCaseRepo "neptune/backend/repositories/test_case"
-	"os"
-	"path/filepath"
-	"strconv"
-	"strings"
+	contestService "neptune/backend/services/contest"
 	"time"
 )
 
 type testcaseServiceImpl struct {
-	testcaseRepo testCaseRepo.TestCaseRepository
-	caseRepo     caseRepository.CaseRepository
+	testcaseRepo   testCaseRepo.TestCaseRepository
+	caseRepo       caseRepository.CaseRepository
+	blobStore      storage.BlobStore
+	txManager      database.TransactionManager
+	contestService contestService.ContestService
+	limits         ArchiveLimits
+	replacedGrace  time.Duration // How long the blobs of a replaced testcase set are kept
 }
 
-func (s testcaseServiceImpl) UploadTestCases(ctx context.Context, req requests.AddTestCaseRequest) error {
+// UploadTestCases imports a testcase archive. Every file is validated and the new set is staged in the
+// blob store before anything is replaced, so a rejected or failed upload keeps the existing testcases.
+// The returned report lists the outcome for each file, also when err is not nil. Replacing the testcases
+// changes verdicts, so it needs force while a contest using the case is running or has submissions for it.
+func (s testcaseServiceImpl) UploadTestCases(ctx context.Context, req requests.AddTestCaseRequest, force bool) (*responses.TestCaseImportReport, error) {
+	report := &responses.TestCaseImportReport{CaseID: req.CaseID.String(), Files: []responses.TestCaseImportFileReport{}}
+
 	// 1. Verify the Case exists
 	problemCase, err := s.caseRepo.FindCaseByID(ctx, req.CaseID)
 	if err != nil {
-		return fmt.Errorf("failed to find parent case %s: %w", req.CaseID.String(), err)
+		return report, fmt.Errorf("failed to find parent case %s: %w", req.CaseID.String(), err)
 	}
 	if problemCase == nil {
-		return fmt.Errorf("case with ID %s not found", req.CaseID.String())
+		return report, fmt.Errorf("case with ID %s not found", req.CaseID.String())
 	}
-
-	// 2. Clear existing testcases for this problem
-	if err := s.testcaseRepo.DeleteTestCaseByCaseID(ctx, req.CaseID.String()); err != nil {
-		return fmt.Errorf("failed to clear existing testcases for case %s: %w", req.CaseID.String(), err)
+	if !force {
+		if err := s.contestService.GuardCaseChange(ctx, req.CaseID); err != nil {
+			return report, err
+		}
 	}
 
-	// 3. Open the uploaded zip file
+	// 2. Open the uploaded zip file
 	src, err := req.File.Open()
 	if err != nil {
-		return fmt.Errorf("failed to open uploaded zip file: %w", err)
+		return report, fmt.Errorf("failed to open uploaded zip file: %w", err)
 	}
 	defer src.Close()
 
 	zipReader, err := zip.NewReader(src, req.File.Size)
 	if err != nil {
-		return fmt.Errorf("failed to read zip file from multipart header: %w", err)
+		return failImport(report, fmt.Errorf("%w: not a readable zip file: %v", ErrInvalidArchive, err))
 	}
 
-	caseTestcasesDir := filepath.Join("./private/test_case", req.CaseID.String())
-	if err := os.MkdirAll(caseTestcasesDir, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create testcase directory %s: %w", caseTestcasesDir, err)
+	// 3. Validate and classify every entry before writing anything
+	pairs, err := s.inspectArchive(zipReader, report)
+	if err != nil {
+		return failImport(report, err)
 	}
-	tempTestcasesByDir := make(map[string]*struct {
-		InputFile  *zip.File
-		OutputFile *zip.File
-	})
-
-	// No longer need currentTestcaseNumber here; it will be assigned sequentially *after* grouping.
-
-	for _, f := range zipReader.File {
-		// Skip directories themselves, we only care about files.
-		if f.FileInfo().IsDir() {
-			continue
+	report.Layout = detectLayout(pairs)
+
+	// 4. Stage the new set under a fresh prefix so judges keep reading the current set meanwhile
+	stagePrefix := fmt.Sprintf("private/test_case/%s/%s", req.CaseID.String(), uuid.New().String())
+	newTestcases := make([]testCaseModel.TestCase, 0, len(pairs))
+	for i, pair := range pairs {
+		number := i + 1
+		inputURL := fmt.Sprintf("/%s/%d/t%03d.in", stagePrefix, number, number)
+		outputURL := fmt.Sprintf("/%s/%d/t%03d.out", stagePrefix, number, number)
+
+		if err := s.saveZipEntry(ctx, pair.Input.File, storage.KeyFromURL(inputURL)); err != nil {
+			s.discardStaged(stagePrefix)
+			return failImport(report, fmt.Errorf("failed to store input of testcase %d: %w", number, err))
 		}
-
-		// Normalize the path to use forward slashes for consistent splitting
-		normalizedPath := strings.ReplaceAll(f.Name, "\\", "/")
-
-		// Extract filename and directory path
-		dir, filename := filepath.Split(normalizedPath)
-		dir = strings.TrimSuffix(dir, "/") // Remove trailing slash if it's a directory
-
-		// Identify input/output files regardless of their original names
-		var itemType string
-		if strings.Contains(strings.ToLower(filename), ".in") {
-			itemType = "input.in" // Standardized name for input
-		} else if strings.Contains(strings.ToLower(filename), ".out") {
-			itemType = "output.out" // Standardized name for output
-		} else {
-			// Skip files that are not clearly input or output
-			log.Printf("Skipping file %s in directory '%s': not recognized as input or output.", filename, dir)
-			continue
+		if err := s.saveZipEntry(ctx, pair.Output.File, storage.KeyFromURL(outputURL)); err != nil {
+			s.discardStaged(stagePrefix)
+			return failImport(report, fmt.Errorf("failed to store output of testcase %d: %w", number, err))
 		}
+		markFileImported(report, pair.Input, number)
+		markFileImported(report, pair.Output, number)
 
-		// Use the directory name as a temporary key to group files
-		if _, ok := tempTestcasesByDir[dir]; !ok {
-			tempTestcasesByDir[dir] = &struct {
-				InputFile  *zip.File
-				OutputFile *zip.File
-			}{}
-		}
+		newTestcases = append(newTestcases, testCaseModel.TestCase{
+			CaseID:    req.CaseID,
+			Number:    number,
+			InputUrl:  inputURL,
+			OutputUrl: outputURL,
+			IsSample:  isSampleKey(pair.Key),
+			CreatedAt: time.Now(),
+		})
+	}
 
-		if itemType == "input.in" {
-			// Check for duplicates within the same directory group
-			if tempTestcasesByDir[dir].InputFile != nil {
-				log.Printf("Warning: Duplicate input file found for directory '%s'. Keeping the first one encountered: %s", dir, tempTestcasesByDir[dir].InputFile.Name)
-				continue // Skip this duplicate
-			}
-			tempTestcasesByDir[dir].InputFile = f
-		} else if itemType == "output.out" {
-			// Check for duplicates within the same directory group
-			if tempTestcasesByDir[dir].OutputFile != nil {
-				log.Printf("Warning: Duplicate output file found for directory '%s'. Keeping the first one encountered: %s", dir, tempTestcasesByDir[dir].OutputFile.Name)
-				continue // Skip this duplicate
-			}
-			tempTestcasesByDir[dir].OutputFile = f
+	// 5. Swap the rows atomically, then remove the blobs of the previous set once no judge can read them
+	oldTestcases, err := s.testcaseRepo.FindTestCaseByCaseID(ctx, req.CaseID.String())
+	if err != nil {
+		s.discardStaged(stagePrefix)
+		return failImport(report, err)
+	}
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.testcaseRepo.DeleteTestCaseByCaseID(txCtx, req.CaseID.String()); err != nil {
+			return fmt.Errorf("failed to clear existing testcases for case %s: %w", req.CaseID.String(), err)
+		}
+		if err := s.testcaseRepo.SaveTestCaseBatch(txCtx, newTestcases); err != nil {
+			return fmt.Errorf("failed to save testcases to DB: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		s.discardStaged(stagePrefix)
+		return failImport(report, err)
 	}
 
-	// Now iterate through grouped files to create actual testcases, assigning sequential numbers
-	var newTestcases []testCaseModel.TestCase
-	currentTestcaseNumber := 1 // Start numbering from 1 for the final saved testcases
+	s.deleteReplacedLater(oldTestcases)
 
-	for _, entry := range tempTestcasesByDir {
-		if entry.InputFile == nil || entry.OutputFile == nil {
-			// If a group (directory) doesn't have both input and output, log and skip it.
-			// You might want more specific logging here to indicate which directory was incomplete.
-			log.Printf("Skipping a testcase pair due to missing input or output file in its group.")
-			continue
-		}
+	report.Imported = true
+	report.TestCaseCount = len(newTestcases)
+	return report, nil
+}
 
-		// Assign a new sequential testcase number
-		testcaseNum := currentTestcaseNumber
-		currentTestcaseNumber++
+// inspectArchive checks every entry against the safety limits and naming conventions, filling in the
+// report. It returns the testcases to import, or an ErrInvalidArchive error when the archive is rejected.
+func (s testcaseServiceImpl) inspectArchive(zipReader *zip.Reader, report *responses.TestCaseImportReport) ([]testcasePair, error) {
+	if len(zipReader.File) > s.limits.MaxFiles {
+		return nil, fmt.Errorf("%w: archive has %d entries, the limit is %d", ErrInvalidArchive, len(zipReader.File), s.limits.MaxFiles)
+	}
 
-		// Create the target directory for the sequentially numbered testcase
-		// This directory will be just the number (e.g., /testcases/<case_id>/1/)
-		testcaseNumDir := filepath.Join(caseTestcasesDir, strconv.Itoa(testcaseNum))
-		if err := os.MkdirAll(testcaseNumDir, os.ModePerm); err != nil {
-			return fmt.Errorf("failed to create testcase number directory %s: %w", testcaseNumDir, err)
+	var entries []*archiveEntry
+	var totalBytes uint64
+	rejected := false
+	for _, f := range zipReader.File {
+		if f.FileInfo().IsDir() {
+			continue
 		}
+		fileReport := responses.TestCaseImportFileReport{Path: f.Name, SizeBytes: int64(f.UncompressedSize64)}
 
-		// Handle input file: Save with standardized name
-		inputFileName := fmt.Sprintf("t%03d.in", testcaseNum) // e.g., t001.in
-		inputPath := filepath.Join(testcaseNumDir, inputFileName)
-		inputURL := fmt.Sprintf("/private/test_case/%s/%d/%s", req.CaseID.String(), testcaseNum, inputFileName)
-
-		if err := saveZipEntry(entry.InputFile, inputPath); err != nil {
-			log.Printf("Failed to save input file for testcase %d (%s): %v", testcaseNum, entry.InputFile.Name, err)
-			continue // Skip this testcase if input file fails to save
+		// archive/zip refuses to read more than the declared size, so checking the header sizes is enough.
+		filePath, err := utils.NormalizeZipPath(f.Name)
+		if err == nil {
+			err = s.limits.Check(f)
 		}
-
-		// Handle output file: Save with standardized name
-		outputFileName := fmt.Sprintf("t%03d.out", testcaseNum) // e.g., t001.out
-		outputPath := filepath.Join(testcaseNumDir, outputFileName)
-		outputURL := fmt.Sprintf("/private/test_case/%s/%d/%s", req.CaseID.String(), testcaseNum, outputFileName)
-
-		if err := saveZipEntry(entry.OutputFile, outputPath); err != nil {
-			log.Printf("Failed to save output file for testcase %d (%s): %v", testcaseNum, entry.OutputFile.Name, err)
-			continue // Skip this testcase if output file fails to save
+		if err != nil {
+			fileReport.Status = fileStatusReject
+			fileReport.Message = err.Error()
+			report.Files = append(report.Files, fileReport)
+			rejected = true
+			continue
 		}
+		totalBytes += f.UncompressedSize64
+		fileReport.Path = filePath
 
-		newTestcases = append(newTestcases, testCaseModel.TestCase{
-			CaseID:    req.CaseID,
-			Number:    testcaseNum,
-			InputUrl:  inputURL,
-			OutputUrl: outputURL,
-			CreatedAt: time.Now(),
-		})
+		if utils.IsArchiveMetadata(filePath) {
+			continue
+		}
+		role, key, layout, ok := classifyArchiveEntry(filePath)
+		if !ok {
+			fileReport.Status = fileStatusSkip
+			fileReport.Message = "file name does not match any supported testcase layout"
+			report.Files = append(report.Files, fileReport)
+			continue
+		}
+		fileReport.Role = role
+		report.Files = append(report.Files, fileReport)
+		entries = append(entries, &archiveEntry{File: f, Path: filePath, Role: role, Key: key, Layout: layout})
 	}
 
-	// Final validation: Ensure we processed at least one valid testcase pair
-	if len(newTestcases) == 0 {
-		return fmt.Errorf("no valid testcase input/output pairs found in the zip file. Ensure each pair is in a folder and files contain 'input' and 'output' in their names.")
+	if rejected {
+		return nil, fmt.Errorf("%w: some entries are unsafe or too large", ErrInvalidArchive)
+	}
+	if totalBytes > s.limits.MaxTotalBytes {
+		return nil, fmt.Errorf("%w: archive expands to %d bytes, the limit is %d", ErrInvalidArchive, totalBytes, s.limits.MaxTotalBytes)
 	}
 
-	// No need for the `tc.CreatedAt.IsZero()` check here as it's set when appended above.
-	// The `InputURL` and `OutputURL` nil checks are also implicitly handled by `continue`
-	// in the loop above if a pair is incomplete or fails to save.
-
-	// 4. Save new testcases to DB in batch
-	if err := s.testcaseRepo.SaveTestCaseBatch(ctx, newTestcases); err != nil {
-		return fmt.Errorf("failed to save testcases to DB: %w", err)
+	pairs, unmatched, duplicates := pairArchiveEntries(entries)
+	for _, entry := range duplicates {
+		markFileSkipped(report, entry, fmt.Sprintf("another %s already belongs to testcase %q", entry.Role, entry.Key))
+	}
+	for _, entry := range unmatched {
+		missing := roleOutput
+		if entry.Role == roleOutput {
+			missing = roleInput
+		}
+		markFileSkipped(report, entry, fmt.Sprintf("no matching %s file", missing))
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("%w: no input/output pairs found; see the report for supported layouts", ErrInvalidArchive)
 	}
+	return pairs, nil
+}
 
-	return nil
+// deleteReplacedLater removes the blobs of a replaced testcase set after the grace period. A judge loads
+// the testcase rows before it streams their blobs, so a submission judged during the swap still reads the
+// previous set. Blobs of a set replaced just before a restart are left behind.
+func (s testcaseServiceImpl) deleteReplacedLater(replaced []testCaseModel.TestCase) {
+	if len(replaced) == 0 {
+		return
+	}
+	time.AfterFunc(s.replacedGrace, func() {
+		for _, old := range replaced {
+			for _, url := range []string{old.InputUrl, old.OutputUrl} {
+				if err := s.blobStore.Delete(context.Background(), storage.KeyFromURL(url)); err != nil {
+					log.Printf("Failed to delete replaced testcase file %s: %v", url, err)
+				}
+			}
+		}
+	})
 }
 
-// saveZipEntry is a helper function to open and save a zip.File to disk.
-func saveZipEntry(f *zip.File, targetPath string) error {
-	outFile, err := os.Create(targetPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", targetPath, err)
+func (s testcaseServiceImpl) discardStaged(stagePrefix string) {
+	if err := s.blobStore.DeletePrefix(context.Background(), stagePrefix); err != nil {
+		log.Printf("Failed to clean up staged testcases %s: %v", stagePrefix, err)
 	}
-	defer outFile.Close()
+}
 
+// saveZipEntry is a helper function to stream a zip.File into the blob store.
+func (s testcaseServiceImpl) saveZipEntry(ctx context.Context, f *zip.File, key string) error {
 	rc, err := f.Open()
 	if err != nil {
 		return fmt.Errorf("failed to open zip entry %s: %w", f.Name, err)
 	}
 	defer rc.Close()
 
-	_, err = io.Copy(outFile, rc)
-	if err != nil {
+	if err := s.blobStore.Put(ctx, key, rc, int64(f.UncompressedSize64), "text/plain"); err != nil {
 		return fmt.Errorf("failed to copy content for %s: %w", f.Name, err)
 	}
 	return nil
@@ -212,17 +243,44 @@ func (s testcaseServiceImpl) GetTestCasesByCaseID(ctx context.Context, caseID st
 		resp[i] = responses.TestCaseResponse{
 			CaseID:    tc.CaseID.String(),
 			Number:    tc.Number,
-			InputUrl:  tc.InputUrl,
-			OutputUrl: tc.OutputUrl,
+			InputUrl:  responses.TestCaseFileURL(tc.CaseID.String(), tc.Number, "input"),
+			OutputUrl: responses.TestCaseFileURL(tc.CaseID.String(), tc.Number, "output"),
+			IsSample:  tc.IsSample,
 		}
 	}
 
 	return resp, nil
 }
 
-func NewTestCaseService(testcaseRepo testCaseRepo.TestCaseRepository, caseRepo caseRepository.CaseRepository) TestCaseService {
+// SetSamples marks which testcases are samples. Every number must belong to the case.
+func (s testcaseServiceImpl) SetSamples(ctx context.Context, caseID uuid.UUID, req requests.SetSampleTestCasesRequest) ([]responses.TestCaseResponse, error) {
+	testcases, err := s.testcaseRepo.FindTestCaseByCaseID(ctx, caseID.String())
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[int]bool, len(testcases))
+	for _, tc := range testcases {
+		exists[tc.Number] = true
+	}
+	for _, number := range req.Numbers {
+		if !exists[number] {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownTestCase, number)
+		}
+	}
+	if err := s.testcaseRepo.SetSamples(ctx, caseID, req.Numbers); err != nil {
+		return nil, err
+	}
+	return s.GetTestCasesByCaseID(ctx, caseID.String())
+}
+
+func NewTestCaseService(testcaseRepo testCaseRepo.TestCaseRepository, caseRepo caseRepository.CaseRepository, blobStore storage.BlobStore, contestService contestService.ContestService, txManager database.TransactionManager) TestCaseService {
 	return &testcaseServiceImpl{
-		testcaseRepo: testcaseRepo,
-		caseRepo:     caseRepo,
+		testcaseRepo:   testcaseRepo,
+		caseRepo:       caseRepo,
+		blobStore:      blobStore,
+		txManager:      txManager,
+		contestService: contestService,
+		limits:         LoadArchiveLimits(),
+		replacedGrace:  loadReplacedGrace(),
 	}
 }
@@ -0,0 +1,66 @@
+package testCaseServ
+
+import (
+	"context"
+	testCaseModel "neptune/backend/models/test_case"
+	"neptune/backend/pkg/storage"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// deleteRecorder is a blob store that only records deletions.
+type deleteRecorder struct {
+	storage.BlobStore
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (r *deleteRecorder) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, key)
+	return nil
+}
+
+func (r *deleteRecorder) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := append([]string(nil), r.deleted...)
+	sort.Strings(keys)
+	return keys
+}
+
+func TestReplacedTestcasesOutliveTheGracePeriodOnly(t *testing.T) {
+	store := &deleteRecorder{}
+	s := testcaseServiceImpl{blobStore: store, replacedGrace: 100 * time.Millisecond}
+
+	s.deleteReplacedLater([]testCaseModel.TestCase{
+		{InputUrl: "/private/test_case/c1/old/1/t001.in", OutputUrl: "/private/test_case/c1/old/1/t001.out"},
+	})
+	time.Sleep(20 * time.Millisecond)
+	if keys := store.keys(); len(keys) != 0 {
+		t.Fatalf("deleted %v while judges may still read them", keys)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	want := []string{"private/test_case/c1/old/1/t001.in", "private/test_case/c1/old/1/t001.out"}
+	if keys := store.keys(); len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("deleted %v after the grace period, want %v", keys, want)
+	}
+}
+
+func TestReplacedGrace(t *testing.T) {
+	for raw, want := range map[string]time.Duration{
+		"":     defaultReplacedGrace,
+		"0":    defaultReplacedGrace,
+		"-1":   defaultReplacedGrace,
+		"7200": 2 * time.Hour,
+	} {
+		t.Setenv("TESTCASE_REPLACED_GRACE_SECONDS", raw)
+		if got := loadReplacedGrace(); got != want {
+			t.Errorf("TESTCASE_REPLACED_GRACE_SECONDS=%q gives %s, want %s", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package user
+
+import (
+	"context"
+	"errors"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+)
+
+// ErrInvalidCredentials is returned when a local account does not exist or the password does not match.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// AuthProvider checks the credentials of the accounts it owns and returns the internal user to sign in,
+// creating or refreshing it when the provider is the source of the profile. existing is nil for a
+// username Neptune has not seen yet.
+type AuthProvider interface {
+	Name() model.AuthProvider
+	Authenticate(ctx context.Context, req *requests.LoginRequest, existing *model.User) (*model.User, error)
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	localCredentialRepo "neptune/backend/repositories/local_credential"
+	userRepo "neptune/backend/repositories/user"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// localAuthProvider checks the bcrypt password of accounts created by an admin. It never talks to
+// Messier, so these accounts also work for guests and offline exams.
+type localAuthProvider struct {
+	userRepo       userRepo.UserRepository
+	credentialRepo localCredentialRepo.LocalCredentialRepository
+}
+
+func (p *localAuthProvider) Name() model.AuthProvider {
+	return model.AuthProviderLocal
+}
+
+func (p *localAuthProvider) Authenticate(ctx context.Context, req *requests.LoginRequest, existing *model.User) (*model.User, error) {
+	if existing == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	credential, err := p.credentialRepo.GetCredentialByUserID(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Accounts waiting for their first password reset have no credential yet.
+	if credential == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(credential.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return existing, nil
+}
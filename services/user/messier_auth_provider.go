@@ -0,0 +1,146 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"neptune/backend/messier/auth/log_on"
+	"neptune/backend/messier/auth/me"
+	model "neptune/backend/models/user"
+	"neptune/backend/pkg/requests"
+	messierTokenRepo "neptune/backend/repositories/messier_token"
+	userRepo "neptune/backend/repositories/user"
+	"regexp"
+	"time"
+)
+
+var nimRegex = regexp.MustCompile(`^\d{10}$`)
+
+// messierAuthProvider logs Binus accounts on through Messier. Students log on with their NIM, everyone
+// else as an assistant. The profile from Messier is copied into the internal user and the Messier token
+// is kept for later syncs.
+type messierAuthProvider struct {
+	userRepo         userRepo.UserRepository
+	logOnSvc         log_on.LogOnService
+	meSvc            me.MeService
+	messierTokenRepo messierTokenRepo.MessierTokenRepository
+}
+
+func (p *messierAuthProvider) Name() model.AuthProvider {
+	return model.AuthProviderMessier
+}
+
+func (p *messierAuthProvider) Authenticate(ctx context.Context, req *requests.LoginRequest, internalUser *model.User) (*model.User, error) {
+	if nimRegex.MatchString(req.Username) {
+		return p.logOnStudent(ctx, req, internalUser)
+	}
+	return p.logOnAssistant(ctx, req, internalUser)
+}
+
+func (p *messierAuthProvider) logOnAssistant(ctx context.Context, req *requests.LoginRequest, internalUser *model.User) (*model.User, error) {
+	// 1. Always check the password with Messier. A cached token only proves an earlier log on, not that
+	// whoever is logging on now knows the password, so it is replaced rather than reused.
+	logOnResp, err := p.logOnSvc.LogOnAssistant(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log on assistant: %w", err)
+	}
+	messierAccessToken := logOnResp.AccessToken
+	messierTokenExpires := time.Now().Add(time.Duration(logOnResp.ExpiresIn) * time.Second)
+
+	// 2. Read the profile with the new token
+	meResp, err := p.meSvc.GetAssistantProfile(ctx, messierAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assistant profile with new token: %w", err)
+	}
+	log.Printf("Successfully obtained new Messier token for user %s", req.Username)
+
+	// 3. Determine user's role. Messier may only say Student or Assistant; staff roles are granted in
+	// Neptune and added when the token is issued.
+	userRole := model.RoleAssistant // Default role
+	if meResp.Role != "" {
+		if parsed, err := model.ParseRole(string(meResp.Role)); err == nil && parsed.Rank() <= model.RoleAssistant.Rank() {
+			userRole = parsed
+		} else {
+			log.Printf("Ignoring role %q from Messier for user %s", meResp.Role, meResp.Username)
+		}
+	}
+
+	// 4. Upsert/Update User record in our DB
+	if internalUser == nil {
+		// User does not exist internally, create new
+		internalUser = &model.User{
+			ID:           uuid.New(), // Generate new UUID for internal user
+			Username:     meResp.Username,
+			Name:         meResp.Name,
+			Role:         userRole,
+			AuthProvider: model.AuthProviderMessier,
+			CreatedAt:    time.Now(),
+		}
+		log.Printf("Creating new internal user record for %s", internalUser.Username)
+	} else {
+		// User exists internally, update details if changed
+		if internalUser.Name != meResp.Name || internalUser.Role != userRole {
+			internalUser.Name = meResp.Name
+			internalUser.Role = userRole
+			log.Printf("Updating existing internal user record for %s", internalUser.Username)
+		} else {
+			log.Printf("Internal user record for %s is up-to-date.", internalUser.Username)
+		}
+	}
+
+	if err := p.saveUserAndToken(ctx, internalUser, messierAccessToken, messierTokenExpires); err != nil {
+		return nil, err
+	}
+	return internalUser, nil
+}
+
+func (p *messierAuthProvider) logOnStudent(ctx context.Context, req *requests.LoginRequest, internalUser *model.User) (*model.User, error) {
+	logOnResp, err := p.logOnSvc.LogOnStudent(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log on student: %w", err)
+	}
+	log.Printf("Successfully obtained new Messier token for student %s", req.Username)
+
+	userRole := model.RoleStudent // Students always have RoleStudent
+	if internalUser == nil {
+		internalUser = &model.User{
+			ID:           uuid.New(),
+			Username:     logOnResp.Student.UserName,
+			Name:         logOnResp.Student.Name,
+			Role:         userRole,
+			AuthProvider: model.AuthProviderMessier,
+			CreatedAt:    time.Now(),
+		}
+		log.Printf("Creating new internal student record for %s", internalUser.Username)
+	} else {
+		if internalUser.Name != logOnResp.Student.Name || internalUser.Role != userRole {
+			internalUser.Name = logOnResp.Student.Name
+			internalUser.Role = userRole
+			log.Printf("Updating existing internal student record for %s", internalUser.Username)
+		} else {
+			log.Printf("Internal student record for %s is up-to-date.", internalUser.Username)
+		}
+	}
+
+	if err := p.saveUserAndToken(ctx, internalUser, logOnResp.Token.Token, logOnResp.Token.Expires); err != nil {
+		return nil, err
+	}
+	return internalUser, nil
+}
+
+func (p *messierAuthProvider) saveUserAndToken(ctx context.Context, internalUser *model.User, accessToken string, expires time.Time) error {
+	if err := p.userRepo.Save(ctx, internalUser); err != nil {
+		return fmt.Errorf("failed to save/update internal user record: %w", err)
+	}
+
+	messierTokenRecord := &model.MessierToken{
+		UserID:              internalUser.ID.String(),
+		MessierAccessToken:  accessToken,
+		MessierTokenExpires: expires,
+	}
+	if err := p.messierTokenRepo.Save(ctx, messierTokenRecord); err != nil {
+		return fmt.Errorf("failed to save messier token to DB: %w", err)
+	}
+	return nil
+}
@@ -6,12 +6,11 @@ import (
 	model "neptune/backend/models/user"
 	"neptune/backend/pkg/requests"
 	"neptune/backend/pkg/responses"
-	"time"
+	sessionServ "neptune/backend/services/session"
 )
 
 type UserService interface {
-	LoginAssistant(ctx context.Context, req *requests.LoginRequest) (*responses.LoginResponse, string, time.Time, error)
-	LoginStudent(ctx context.Context, req *requests.LoginRequest) (*responses.LoginResponse, string, time.Time, error)
+	Login(ctx context.Context, req *requests.LoginRequest, meta sessionServ.Meta) (*responses.LoginResponse, *sessionServ.Tokens, error)
 	GetUserProfile(ctx context.Context, userID uuid.UUID) (*model.User, error)
 	DeleteUserAccessToken(ctx context.Context, userID string) error
 	GetDetailedUserProfile(ctx context.Context, userID uuid.UUID) (*responses.UserMeResponse, error)
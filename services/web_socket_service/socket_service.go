@@ -1,12 +1,69 @@
 package webSocketService
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"neptune/backend/models/user"
+	"sync"
 )
 
 type WebSocketService interface {
 	SendUpdateToClient(submissionID uuid.UUID, payload interface{})
 	Register(submissionID uuid.UUID, conn *websocket.Conn)
 	Unregister(submissionID uuid.UUID, connToRemove *websocket.Conn)
+
+	RegisterContestListener(contestID uuid.UUID, listener *ContestListener)
+	UnregisterContestListener(contestID uuid.UUID, listener *ContestListener)
+	// SendToContest queues payload for the contest's listeners for which deliver returns true. It does not
+	// wait for the writes; a listener whose queue is full is disconnected.
+	SendToContest(contestID uuid.UUID, deliver func(listener *ContestListener) bool, payload interface{})
+}
+
+// ContestListener is one connection following a class contest, or a global contest when
+// ClassTransactionID is nil.
+type ContestListener struct {
+	Conn               *websocket.Conn
+	UserID             uuid.UUID
+	ClassTransactionID *uuid.UUID
+	Staff              bool // assistants, lecturers and admins see every question
+
+	// Events wait in send for the listener's own writer, so a slow client never holds up a broadcast.
+	send      chan []byte
+	closeOnce sync.Once
+}
+
+// drop closes the connection. The handler's read loop then ends and unregisters the listener.
+func (l *ContestListener) drop() {
+	l.closeOnce.Do(func() {
+		l.Conn.Close()
+	})
+}
+
+// InScope reports whether the listener follows the given class contest, or the global contest when
+// classTransactionID is nil.
+func (l *ContestListener) InScope(classTransactionID *uuid.UUID) bool {
+	if l.ClassTransactionID == nil || classTransactionID == nil {
+		return l.ClassTransactionID == nil && classTransactionID == nil
+	}
+	return *l.ClassTransactionID == *classTransactionID
+}
+
+// ContestEvent is the message sent to contest listeners.
+type ContestEvent struct {
+	Type string      `json:"type"` // e.g. "clarification.asked", "clarification.answered"
+	Data interface{} `json:"data"`
+}
+
+type ContestRole int
+
+const (
+	ContestOutsider ContestRole = iota
+	ContestParticipant
+	ContestStaff
+)
+
+// ContestAccess decides who may follow a contest's events.
+type ContestAccess interface {
+	ContestRoleOf(ctx context.Context, contestID uuid.UUID, classTransactionID *uuid.UUID, userID uuid.UUID, role user.Role) (ContestRole, error)
 }
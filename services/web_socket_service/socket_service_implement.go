@@ -1,14 +1,22 @@
 package webSocketService
 
 import (
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"log"
 	"sync"
+	"time"
+)
+
+const (
+	contestSendQueueSize = 32               // Events a contest listener may fall behind before it is dropped
+	contestWriteTimeout  = 10 * time.Second // Longest a single write to a contest listener may take
 )
 
 type webSocketService struct {
-	clients map[uuid.UUID][]*websocket.Conn
+	clients          map[uuid.UUID][]*websocket.Conn
+	contestListeners map[uuid.UUID][]*ContestListener
 	sync.RWMutex
 }
 
@@ -60,8 +68,75 @@ func (s *webSocketService) SendUpdateToClient(submissionID uuid.UUID, payload in
 	}
 }
 
+// RegisterContestListener adds a connection following a contest and starts its writer.
+func (s *webSocketService) RegisterContestListener(contestID uuid.UUID, listener *ContestListener) {
+	listener.send = make(chan []byte, contestSendQueueSize)
+	go writeContestEvents(contestID, listener)
+
+	s.Lock()
+	defer s.Unlock()
+	s.contestListeners[contestID] = append(s.contestListeners[contestID], listener)
+	log.Printf("Registered new websocket listener for contest %s", contestID)
+}
+
+// writeContestEvents writes the queued events of one listener until it is unregistered. A write that
+// fails or runs past the deadline drops the connection.
+func writeContestEvents(contestID uuid.UUID, listener *ContestListener) {
+	for message := range listener.send {
+		listener.Conn.SetWriteDeadline(time.Now().Add(contestWriteTimeout))
+		if err := listener.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			log.Printf("Websocket write error for contest %s: %v", contestID, err)
+			listener.drop()
+		}
+	}
+}
+
+// UnregisterContestListener removes a connection following a contest.
+func (s *webSocketService) UnregisterContestListener(contestID uuid.UUID, listener *ContestListener) {
+	s.Lock()
+	defer s.Unlock()
+
+	listeners := s.contestListeners[contestID]
+	for i, l := range listeners {
+		if l == listener {
+			s.contestListeners[contestID] = append(listeners[:i], listeners[i+1:]...)
+			// Senders hold the read lock, so none can still be queueing to it
+			close(listener.send)
+			break
+		}
+	}
+	if len(s.contestListeners[contestID]) == 0 {
+		delete(s.contestListeners, contestID)
+	}
+	log.Printf("Unregistered websocket listener for contest %s", contestID)
+}
+
+func (s *webSocketService) SendToContest(contestID uuid.UUID, deliver func(listener *ContestListener) bool, payload interface{}) {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode websocket event for contest %s: %v", contestID, err)
+		return
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, listener := range s.contestListeners[contestID] {
+		if !deliver(listener) {
+			continue
+		}
+		select {
+		case listener.send <- message:
+		default:
+			log.Printf("Dropping websocket listener of user %s on contest %s: %d events behind", listener.UserID, contestID, contestSendQueueSize)
+			listener.drop()
+		}
+	}
+}
+
 func NewWebSocketService() WebSocketService {
 	return &webSocketService{
-		clients: make(map[uuid.UUID][]*websocket.Conn),
+		clients:          make(map[uuid.UUID][]*websocket.Conn),
+		contestListeners: make(map[uuid.UUID][]*ContestListener),
 	}
 }